@@ -8,10 +8,14 @@ package main
 import (
 	_ "insist-backend-golang/docs"
 	"insist-backend-golang/internal/config"
+	"insist-backend-golang/internal/cron"
 	"insist-backend-golang/internal/middleware"
 	"insist-backend-golang/internal/routes"
+	"insist-backend-golang/internal/service"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -32,7 +36,8 @@ func main() {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     os.Getenv("CORS"),
 		AllowMethods:     "GET,POST,PUT,DELETE",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, If-Match, If-None-Match",
+		ExposeHeaders:    "ETag, Content-Disposition",
 		AllowCredentials: true,
 	}))
 
@@ -40,12 +45,14 @@ func main() {
 
 	api := app.Group("/api")
 
+	activityLogger := middleware.ActivityLogger(service.NewActivityLogWriter(config.DBINSIST))
+
 	// Auth Routes
-	apiAuth := api.Group("/auth")
+	apiAuth := api.Group("/auth", activityLogger)
 	routes.AuthRoutes(apiAuth, config.DBINSIST)
 
 	// ADM Routes
-	apiADM := api.Group("/admin", middleware.VerifyToken)
+	apiADM := api.Group("/admin", middleware.VerifyToken, activityLogger)
 	routes.UserRoutes(apiADM, config.DBINSIST)
 	routes.DeptRoutes(apiADM, config.DBINSIST)
 	routes.RoleRoutes(apiADM, config.DBINSIST)
@@ -60,9 +67,12 @@ func main() {
 	routes.ApprovalUserRoutes(apiADM, config.DBINSIST)
 	routes.ApprovalStructureRoutes(apiADM, config.DBINSIST)
 	routes.ApprovalHistoryRoutes(apiADM, config.DBINSIST)
+	routes.ApprovalEngineRoutes(apiADM, config.DBINSIST)
+	routes.AuditRoutes(apiADM, config.DBINSIST)
+	routes.ImportRoutes(apiADM, config.DBINSIST)
 
 	// General Routes
-	apiGeneral := api.Group("/general", middleware.VerifyToken)
+	apiGeneral := api.Group("/general", middleware.VerifyToken, activityLogger)
 	routes.BillingTermRoutes(apiGeneral, config.DBINSIST)
 	routes.ItemRoutes(apiGeneral, config.DBINSIST)
 	routes.ItemCategoryRoutes(apiGeneral, config.DBINSIST)
@@ -77,7 +87,7 @@ func main() {
 	routes.ItemRawMaterialRoutes(apiGeneral, config.DBINSIST)
 
 	// ACF Routes
-	apiACF := api.Group("/acf", middleware.VerifyToken)
+	apiACF := api.Group("/acf", middleware.VerifyToken, activityLogger)
 	routes.ChartOfAccountRoutes(apiACF, config.DBINSIST)
 	routes.TaxCodeRoutes(apiACF, config.DBINSIST)
 	routes.CurrencyRoutes(apiACF, config.DBINSIST)
@@ -85,23 +95,25 @@ func main() {
 	routes.BankRoutes(apiACF, config.DBINSIST)
 
 	// EGD Routes
-	apiEGD := api.Group("/egd", middleware.VerifyToken)
+	apiEGD := api.Group("/egd", middleware.VerifyToken, activityLogger)
 	routes.ProcessRoutes(apiEGD, config.DBINSIST)
 	routes.UoMRoutes(apiEGD, config.DBINSIST)
 	routes.MaterialRoutes(apiEGD, config.DBINSIST)
 	routes.MaterialDetailRoutes(apiEGD, config.DBINSIST)
 
 	// MNT Routes
-	apiMNT := api.Group("/mnt", middleware.VerifyToken)
+	apiMNT := api.Group("/mnt", middleware.VerifyToken, activityLogger)
 	routes.MachineRoutes(apiMNT, config.DBINSIST)
+	routes.MaintenancePlanRoutes(apiMNT, config.DBINSIST)
+	routes.WorkOrderRoutes(apiMNT, config.DBINSIST)
 
 	// PID Routes
-	apiPID := api.Group("/pid", middleware.VerifyToken)
+	apiPID := api.Group("/pid", middleware.VerifyToken, activityLogger)
 	routes.WarehouseRoutes(apiPID, config.DBINSIST)
 	routes.LocationRoutes(apiPID, config.DBINSIST)
 
 	// PRD Routes
-	apiPRD := api.Group("/prd", middleware.VerifyToken)
+	apiPRD := api.Group("/prd", middleware.VerifyToken, activityLogger)
 	routes.BuildingRoutes(apiPRD, config.DBINSIST)
 	routes.FCSRoutes(apiPRD, config.DBINSIST)
 	routes.SectionRoutes(apiPRD, config.DBINSIST)
@@ -111,8 +123,30 @@ func main() {
 	// Log Route
 	routes.ActivityLogRoutes(api, config.DBINSIST)
 
+	// Purge soft deleted master data
+	softDeleteService := service.NewSoftDeleteService(config.DBINSIST)
+	cron.SetupCron(func() {
+		// SOFT_DELETE_RETENTION_DAYS defaults to 90; 0 keeps deleted data forever.
+		retentionDays, err := strconv.Atoi(os.Getenv("SOFT_DELETE_RETENTION_DAYS"))
+		if err != nil {
+			retentionDays = 90
+		}
+
+		if retentionDays <= 0 {
+			return
+		}
+
+		purged, err := softDeleteService.Purge(time.Now().AddDate(0, 0, -retentionDays))
+		if err != nil {
+			log.Println("Error purging soft deleted data:", err)
+			return
+		}
+
+		log.Printf("Purged %d records deleted more than %d days ago", purged, retentionDays)
+	}, "0 2 * * *")
+
 	// INFOR'
-	apiINFOR := api.Group("/infor", middleware.VerifyToken)
+	apiINFOR := api.Group("/infor", middleware.VerifyToken, activityLogger)
 	routes.ItemInforRoutes(apiINFOR, config.DBINFOR)
 
 	println("Starting app with port " + os.Getenv("PORT"))
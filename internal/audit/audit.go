@@ -0,0 +1,464 @@
+package audit
+
+import (
+	"encoding/json"
+	"insist-backend-golang/internal/model"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+const (
+	actorKey  = "audit:actor"
+	beforeKey = "audit:before"
+	skipKey   = "audit:skip"
+)
+
+// maxBatchRows caps how many rows a conditional update or delete loads to
+// build its before state.
+const maxBatchRows = 1000
+
+// ignoredTables are not master data or change on every request.
+var ignoredTables = map[string]bool{
+	"audit_logs":          true,
+	"activity_logs":       true,
+	"user_sessions":       true,
+	"login_throttles":     true,
+	"mfa_challenges":      true,
+	"password_resets":     true,
+	"user_recovery_codes": true,
+	"import_jobs":         true,
+}
+
+var ignoredColumns = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+}
+
+var maskedColumns = map[string]bool{
+	"password":        true,
+	"refresh_token":   true,
+	"otp_key":         true,
+	"otp_url":         true,
+	"otp_pending_key": true,
+	"otp_pending_url": true,
+	"otp_last_step":   true,
+}
+
+type Change struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// WithActor attributes the changes made through the returned session to
+// userID, for writes whose model does not carry IDCreatedby/IDUpdatedby.
+func WithActor(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Set(actorKey, userID)
+}
+
+// Skip disables auditing for the changes made through the returned session.
+func Skip(db *gorm.DB) *gorm.DB {
+	return db.Set(skipKey, true)
+}
+
+// Register installs the audit callbacks so every create, update and delete
+// made through db is written to audit_logs in the same transaction.
+func Register(db *gorm.DB) error {
+	callback := db.Callback()
+
+	if err := callback.Create().After("gorm:create").Register("audit:after_create", afterCreate); err != nil {
+		return err
+	}
+
+	if err := callback.Update().Before("gorm:update").Register("audit:before_update", before); err != nil {
+		return err
+	}
+
+	if err := callback.Update().After("gorm:update").Register("audit:after_update", afterUpdate); err != nil {
+		return err
+	}
+
+	if err := callback.Delete().Before("gorm:delete").Register("audit:before_delete", before); err != nil {
+		return err
+	}
+
+	return callback.Delete().After("gorm:delete").Register("audit:after_delete", afterDelete)
+}
+
+func enabled(db *gorm.DB) bool {
+	if db.Error != nil || db.Statement.Schema == nil || ignoredTables[db.Statement.Table] {
+		return false
+	}
+
+	if skip, ok := db.Get(skipKey); ok && skip.(bool) {
+		return false
+	}
+
+	return db.Statement.Schema.PrioritizedPrimaryField != nil
+}
+
+func before(db *gorm.DB) {
+	if !enabled(db) {
+		return
+	}
+
+	ids := primaryKeys(db)
+
+	query := session(db).Table(db.Statement.Table)
+	if len(ids) > 0 {
+		query = query.Where(clause.IN{Column: clause.Column{Name: primaryColumn(db)}, Values: toInterfaces(ids)})
+	} else if where, ok := db.Statement.Clauses["WHERE"]; ok {
+		query = query.Clauses(where.Expression).Limit(maxBatchRows)
+	} else {
+		return
+	}
+
+	var rows []map[string]interface{}
+	if err := query.Find(&rows).Error; err != nil {
+		return
+	}
+
+	db.Statement.Settings.Store(beforeKey, rows)
+}
+
+func afterCreate(db *gorm.DB) {
+	if !enabled(db) {
+		return
+	}
+
+	ids := primaryKeys(db)
+	if len(ids) == 0 {
+		return
+	}
+
+	after, err := load(db, ids)
+	if err != nil {
+		return
+	}
+
+	var logs []model.AuditLog
+	for _, id := range ids {
+		if changes := diff(nil, after[id]); changes != nil {
+			logs = append(logs, newLog(db, id, ActionCreate, changes))
+		}
+	}
+
+	write(db, logs)
+}
+
+func afterUpdate(db *gorm.DB) {
+	if !enabled(db) {
+		return
+	}
+
+	beforeRows := takeBefore(db)
+	if len(beforeRows) == 0 {
+		return
+	}
+
+	after, err := load(db, mapKeys(beforeRows))
+	if err != nil {
+		return
+	}
+
+	var logs []model.AuditLog
+	for id := range beforeRows {
+		if changes := diff(beforeRows[id], after[id]); changes != nil {
+			logs = append(logs, newLog(db, id, ActionUpdate, changes))
+		}
+	}
+
+	write(db, logs)
+}
+
+func afterDelete(db *gorm.DB) {
+	if !enabled(db) {
+		return
+	}
+
+	beforeRows := takeBefore(db)
+	if len(beforeRows) == 0 {
+		return
+	}
+
+	after, err := load(db, mapKeys(beforeRows))
+	if err != nil {
+		return
+	}
+
+	var logs []model.AuditLog
+	for id, row := range beforeRows {
+		// A soft delete keeps the row, so only the changed columns are kept.
+		changes := diff(row, nil)
+		if current, ok := after[id]; ok {
+			changes = diff(row, current)
+		}
+
+		if changes != nil {
+			logs = append(logs, newLog(db, id, ActionDelete, changes))
+		}
+	}
+
+	write(db, logs)
+}
+
+func takeBefore(db *gorm.DB) map[uint]map[string]interface{} {
+	value, ok := db.Statement.Settings.LoadAndDelete(beforeKey)
+	if !ok {
+		return nil
+	}
+
+	result := map[uint]map[string]interface{}{}
+	for _, row := range value.([]map[string]interface{}) {
+		if id, ok := toUint(row[primaryColumn(db)]); ok {
+			result[id] = row
+		}
+	}
+
+	return result
+}
+
+func load(db *gorm.DB, ids []uint) (map[uint]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+	if err := session(db).Table(db.Statement.Table).
+		Where(clause.IN{Column: clause.Column{Name: primaryColumn(db)}, Values: toInterfaces(ids)}).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	result := map[uint]map[string]interface{}{}
+	for _, row := range rows {
+		if id, ok := toUint(row[primaryColumn(db)]); ok {
+			result[id] = row
+		}
+	}
+
+	return result, nil
+}
+
+func write(db *gorm.DB, logs []model.AuditLog) {
+	if len(logs) == 0 {
+		return
+	}
+
+	if err := session(db).Create(&logs).Error; err != nil {
+		db.AddError(err)
+	}
+}
+
+func newLog(db *gorm.DB, id uint, action string, changes map[string]Change) model.AuditLog {
+	data, _ := json.Marshal(changes)
+
+	return model.AuditLog{
+		TableName: db.Statement.Table,
+		RecordID:  id,
+		Action:    action,
+		IDUser:    actor(db, action),
+		Changes:   data,
+		CreatedAt: time.Now(),
+	}
+}
+
+// actor prefers an explicit WithActor, then the created/updated by column of
+// the model being written.
+func actor(db *gorm.DB, action string) *uint {
+	if value, ok := db.Get(actorKey); ok {
+		if userID, ok := value.(uint); ok && userID != 0 {
+			return &userID
+		}
+	}
+
+	name := "IDUpdatedby"
+	if action == ActionCreate {
+		name = "IDCreatedby"
+	}
+
+	field := db.Statement.Schema.LookUpField(name)
+	if field == nil {
+		return nil
+	}
+
+	value := db.Statement.ReflectValue
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		if value.Len() == 0 {
+			return nil
+		}
+		value = value.Index(0)
+	}
+
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fieldValue, isZero := field.ValueOf(db.Statement.Context, value)
+	if isZero {
+		return nil
+	}
+
+	if userID, ok := toUint(fieldValue); ok {
+		return &userID
+	}
+
+	return nil
+}
+
+func diff(before map[string]interface{}, after map[string]interface{}) map[string]Change {
+	changes := map[string]Change{}
+
+	for column, value := range after {
+		if ignoredColumns[column] {
+			continue
+		}
+
+		old, existed := before[column]
+		if existed && equal(old, value) {
+			continue
+		}
+
+		if !existed && before == nil && value == nil {
+			continue
+		}
+
+		changes[column] = change(column, old, value)
+	}
+
+	if after == nil {
+		for column, value := range before {
+			if ignoredColumns[column] || value == nil {
+				continue
+			}
+
+			changes[column] = change(column, value, nil)
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return changes
+}
+
+func change(column string, old interface{}, new interface{}) Change {
+	if maskedColumns[column] {
+		if old != nil {
+			old = "***"
+		}
+		if new != nil {
+			new = "***"
+		}
+	}
+
+	return Change{Old: normalize(old), New: normalize(new)}
+}
+
+func normalize(value interface{}) interface{} {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+
+	return value
+}
+
+func equal(a interface{}, b interface{}) bool {
+	ja, errA := json.Marshal(normalize(a))
+	jb, errB := json.Marshal(normalize(b))
+	if errA != nil || errB != nil {
+		return reflect.DeepEqual(a, b)
+	}
+
+	return string(ja) == string(jb)
+}
+
+func primaryKeys(db *gorm.DB) []uint {
+	field := db.Statement.Schema.PrioritizedPrimaryField
+	value := db.Statement.ReflectValue
+
+	var ids []uint
+	collect := func(item reflect.Value) {
+		for item.Kind() == reflect.Ptr {
+			if item.IsNil() {
+				return
+			}
+			item = item.Elem()
+		}
+
+		if item.Kind() != reflect.Struct {
+			return
+		}
+
+		if id, isZero := field.ValueOf(db.Statement.Context, item); !isZero {
+			if id, ok := toUint(id); ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			collect(value.Index(i))
+		}
+	default:
+		collect(value)
+	}
+
+	return ids
+}
+
+func primaryColumn(db *gorm.DB) string {
+	return db.Statement.Schema.PrioritizedPrimaryField.DBName
+}
+
+func session(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Set(skipKey, true)
+}
+
+func toUint(value interface{}) (uint, bool) {
+	switch v := value.(type) {
+	case uint:
+		return v, true
+	case uint32:
+		return uint(v), true
+	case uint64:
+		return uint(v), true
+	case int:
+		return uint(v), true
+	case int32:
+		return uint(v), true
+	case int64:
+		return uint(v), true
+	}
+
+	return 0, false
+}
+
+func toInterfaces(ids []uint) []interface{} {
+	values := make([]interface{}, len(ids))
+	for i, id := range ids {
+		values[i] = id
+	}
+
+	return values
+}
+
+func mapKeys(rows map[uint]map[string]interface{}) []uint {
+	ids := make([]uint, 0, len(rows))
+	for id := range rows {
+		ids = append(ids, id)
+	}
+
+	return ids
+}
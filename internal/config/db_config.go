@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"insist-backend-golang/internal/audit"
+	"insist-backend-golang/internal/softdelete"
 	"log"
 	"os"
 	"time"
@@ -38,13 +40,23 @@ func ConnectDBINSIST() {
 		os.Getenv("DB_INSIST_PORT"),
 	)
 
-	DBINSIST, err = gorm.Open(postgres.Open(dsnINSIST), &gorm.Config{Logger: newLogger})
+	// TranslateError turns unique violations into gorm.ErrDuplicatedKey so
+	// handlers can answer 409 instead of 500.
+	DBINSIST, err = gorm.Open(postgres.Open(dsnINSIST), &gorm.Config{Logger: newLogger, TranslateError: true})
 	if err != nil {
 		log.Fatalf("Error connecting to the INSIST database: %v", err)
 	}
 
 	fmt.Println("Connected to the INSIST database!")
 
+	if err := audit.Register(DBINSIST); err != nil {
+		log.Fatalf("Failed to register audit callbacks: %v", err)
+	}
+
+	if err := softdelete.Register(DBINSIST); err != nil {
+		log.Fatalf("Failed to register soft delete callbacks: %v", err)
+	}
+
 	IDBINSIST, err := DBINSIST.DB()
 	if err != nil {
 		log.Fatalf("Failed to get DB instance from GORM: %v", err)
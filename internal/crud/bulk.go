@@ -0,0 +1,197 @@
+package crud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/service"
+	"insist-backend-golang/pkg"
+	"reflect"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Bulk serves the /bulk endpoint of a master table, which creates, updates
+// and deletes records the way the single record endpoints do, in a single
+// transaction. The action of every operation is authorized by
+// middleware.VerifyPermission.
+type Bulk[T any] struct {
+	// Name is used in response messages, e.g. "UoM not found".
+	Name string
+	// Input makes the data of create and update operations, which holds the
+	// only fields a client can set.
+	Input func() dto.Input[T]
+	// After runs once the operations are saved, e.g. to drop a cache the
+	// records are part of.
+	After func()
+
+	db     *gorm.DB
+	schema *schema.Schema
+}
+
+func NewBulk[T any](db *gorm.DB, bulk Bulk[T]) *Bulk[T] {
+	s, err := schema.Parse(new(T), &sync.Map{}, db.NamingStrategy)
+	if err != nil {
+		panic(err)
+	}
+
+	if bulk.Input == nil {
+		panic("crud: " + bulk.Name + " has no Input")
+	}
+
+	bulk.db = db
+	bulk.schema = s
+
+	return &bulk
+}
+
+// Run applies the operations of the request, see service.RunBulk.
+func (b *Bulk[T]) Run(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input dto.BulkRequest
+	if err := c.BodyParser(&input); err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if len(input.Operations) == 0 {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, "Operations are required"))
+	}
+
+	if maxSize := pkg.BulkMaxSize(); len(input.Operations) > maxSize {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("At most %d operations are allowed", maxSize)))
+	}
+
+	results, err := service.RunBulk(b.db, input.Operations, func(tx *gorm.DB, operation dto.BulkOperation) (interface{}, error) {
+		return b.apply(tx, operation, userID)
+	})
+	if errors.Is(err, service.ErrBulkConflict) {
+		return pkg.Response(c, fiber.StatusConflict, "An operation conflicts with an existing record, nothing was saved", results)
+	}
+	if errors.Is(err, service.ErrBulkFailed) {
+		return pkg.Response(c, fiber.StatusUnprocessableEntity, "An operation failed, nothing was saved", results)
+	}
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	if b.After != nil {
+		b.After()
+	}
+
+	return pkg.Response(c, fiber.StatusOK, b.Name+" bulk operations applied successfully", results)
+}
+
+// apply runs one bulk operation the way Create, Update and Delete do.
+func (b *Bulk[T]) apply(tx *gorm.DB, operation dto.BulkOperation, userID uint) (interface{}, error) {
+	if operation.Action == dto.BulkActionCreate {
+		input, err := b.input(operation.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		var value T
+		input.Apply(&value)
+
+		setField(b.schema, &value, "IDCreatedby", userID)
+		setField(b.schema, &value, "IDUpdatedby", userID)
+
+		if err := tx.Create(&value).Error; err != nil {
+			return nil, err
+		}
+
+		return primaryKey(b.schema, &value), nil
+	}
+
+	if operation.Action != dto.BulkActionUpdate && operation.Action != dto.BulkActionDelete {
+		return nil, fmt.Errorf("invalid action %q, use create, update or delete", operation.Action)
+	}
+
+	if operation.ID == 0 {
+		return nil, errors.New("id is required")
+	}
+
+	var value T
+	if err := tx.First(&value, operation.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return operation.ID, errors.New(b.Name + " not found")
+		}
+		return operation.ID, err
+	}
+
+	if operation.Action == dto.BulkActionUpdate {
+		if operation.ETag == "" && etag.Of(&value) != "" {
+			return operation.ID, errors.New("etag is required, send the one the record was read with")
+		}
+
+		if !etag.Matches(operation.ETag, &value) {
+			return operation.ID, errors.New("the record was changed by someone else, reload it and try again")
+		}
+
+		input, err := b.input(operation.Data)
+		if err != nil {
+			return operation.ID, err
+		}
+
+		input.Apply(&value)
+		setField(b.schema, &value, "IDUpdatedby", userID)
+
+		if err := service.SaveVersion(tx, &value); err != nil {
+			if errors.Is(err, service.ErrStale) {
+				return operation.ID, errors.New("the record was changed by someone else, reload it and try again")
+			}
+			return operation.ID, err
+		}
+
+		return operation.ID, nil
+	}
+
+	if operation.ETag != "" && !etag.Matches(operation.ETag, &value) {
+		return operation.ID, errors.New("the record was changed by someone else, reload it and try again")
+	}
+
+	whereUsed, err := service.NewDependencyService(tx).GetWhereUsed(new(T), operation.ID)
+	if err != nil {
+		return operation.ID, err
+	}
+
+	if len(whereUsed) > 0 {
+		return operation.ID, errors.New("data is still used by other records")
+	}
+
+	setField(b.schema, &value, "IDUpdatedby", userID)
+
+	return operation.ID, service.SoftDelete(tx, &value, userID)
+}
+
+// input reads and validates the data of a bulk operation the way BindBody
+// does a request body.
+func (b *Bulk[T]) input(data json.RawMessage) (dto.Input[T], error) {
+	input := b.Input()
+	if err := json.Unmarshal(data, input); err != nil {
+		return nil, err
+	}
+
+	if err := pkg.Validate(input); err != nil {
+		return nil, err
+	}
+
+	return input, nil
+}
+
+func setField[T any](s *schema.Schema, value *T, name string, fieldValue interface{}) {
+	if field := s.LookUpField(name); field != nil {
+		field.Set(context.Background(), reflect.ValueOf(value).Elem(), fieldValue)
+	}
+}
+
+func primaryKey[T any](s *schema.Schema, value *T) interface{} {
+	ID, _ := s.PrioritizedPrimaryField.ValueOf(context.Background(), reflect.ValueOf(value).Elem())
+	return ID
+}
@@ -0,0 +1,462 @@
+package crud
+
+import (
+	"errors"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/export"
+	"insist-backend-golang/internal/filter"
+	"insist-backend-golang/internal/service"
+	"insist-backend-golang/pkg"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Preload loads a relation of the resource, limited to Columns when set.
+type Preload struct {
+	Name    string
+	Columns string
+}
+
+// Filter narrows a list to the rows whose Column equals the value of the
+// Query parameter. Empty and zero values do not filter.
+type Filter struct {
+	Query  string
+	Column string
+}
+
+// Resource serves a master table through the standard list, get, create,
+// update and delete endpoints, configured declaratively. Each master wraps it
+// in a handler that carries the swagger documentation of its routes.
+type Resource[T any] struct {
+	// Name is used in response messages, e.g. "UoM found successfully".
+	Name string
+	// SearchColumns are matched with ILIKE against ?search=.
+	SearchColumns []string
+	// SortFields are accepted by ?sortBy=; FilterFields when empty.
+	SortFields filter.Fields
+	// DefaultSort orders the list when ?sortBy= is not given.
+	DefaultSort string
+	Joins       []string
+	Preloads    []Preload
+	Filters     []Filter
+	// ExportColumns are the columns of the ?format=xlsx|csv download at
+	// /export.
+	ExportColumns []export.Column
+	// FilterFields are accepted by ?filter[field][op]=; every column of T
+	// when empty.
+	FilterFields filter.Fields
+	// Input makes the request body of create and update, which holds the
+	// only fields a client can set.
+	Input func() dto.Input[T]
+
+	db     *gorm.DB
+	schema *schema.Schema
+	bulk   *Bulk[T]
+}
+
+// Query holds the list parameters of a request.
+type Query struct {
+	Offset         int
+	Limit          int
+	Search         string
+	Sorts          []filter.Order
+	IncludeDeleted bool
+	Filters        map[string]string
+	Conditions     []filter.Condition
+}
+
+func New[T any](db *gorm.DB, resource Resource[T]) *Resource[T] {
+	s, err := schema.Parse(new(T), &sync.Map{}, db.NamingStrategy)
+	if err != nil {
+		panic(err)
+	}
+
+	resource.db = db
+	resource.schema = s
+
+	if resource.Input == nil {
+		panic("crud: " + resource.Name + " has no Input")
+	}
+
+	resource.bulk = NewBulk(db, Bulk[T]{Name: resource.Name, Input: resource.Input})
+
+	if resource.FilterFields == nil {
+		resource.FilterFields = filter.Columns(new(T))
+	}
+
+	if resource.SortFields == nil {
+		resource.SortFields = resource.FilterFields
+	}
+
+	return &resource
+}
+
+func (r *Resource[T]) GetByID(ID uint) (*T, error) {
+	var value T
+	if err := r.preload(r.db).First(&value, ID).Error; err != nil {
+		return nil, err
+	}
+
+	return &value, nil
+}
+
+func (r *Resource[T]) GetTotal(query Query) (int64, error) {
+	var count int64
+
+	if err := r.filter(service.WithDeleted(r.db, query.IncludeDeleted).Model(new(T)), query).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *Resource[T]) GetAll(query Query) ([]T, error) {
+	var values []T
+
+	db := r.preload(service.WithDeleted(r.db, query.IncludeDeleted).Model(new(T))).
+		Offset(query.Offset).
+		Limit(query.Limit)
+
+	if len(query.Sorts) > 0 {
+		db = filter.Sort(db, query.Sorts)
+	} else if r.DefaultSort != "" {
+		db = db.Order(r.DefaultSort)
+	}
+
+	if err := r.filter(db, query).Find(&values).Error; err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// GetPage lists the records after the cursor, see filter.Page.
+func (r *Resource[T]) GetPage(query Query, after string) ([]T, string, error) {
+	db := r.preload(service.WithDeleted(r.db, query.IncludeDeleted).Model(new(T)))
+
+	return filter.Page[T](r.filter(db, query), query.Sorts, after, query.Limit)
+}
+
+// List serves the records by page or by cursor, with search, filters and
+// sorting.
+func (r *Resource[T]) List(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	rows := c.QueryInt("rows", 20)
+	offset := (page - 1) * rows
+
+	query, err := r.query(c)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+	query.Offset = offset
+	query.Limit = rows
+
+	if filter.CursorMode(c) {
+		values, next, err := r.GetPage(query, c.Query("after"))
+		if errors.Is(err, filter.ErrInvalidCursor) {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+		}
+		if err != nil {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+		}
+
+		var nextCursor *string
+		if next != "" {
+			nextCursor = &next
+		}
+
+		result := map[string]interface{}{
+			"items": values,
+			"pagination": map[string]interface{}{
+				"rows_per_page": rows,
+				"next_cursor":   nextCursor,
+			},
+		}
+
+		return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+	}
+
+	total, err := r.GetTotal(query)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	values, err := r.GetAll(query)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(rows)))
+
+	var start *int
+	if int(total) == 0 {
+		start = nil
+	} else {
+		value := offset + 1
+		start = &value
+	}
+
+	var end *int
+	if int(total) == 0 {
+		end = nil
+	} else {
+		value := int(math.Min(float64(offset+rows), float64(total)))
+		end = &value
+	}
+
+	var nextPage *int
+	if page < totalPages {
+		nextPageVal := page + 1
+		nextPage = &nextPageVal
+	}
+
+	result := map[string]interface{}{
+		"items": values,
+		"pagination": map[string]interface{}{
+			"current_page":  page,
+			"next_page":     nextPage,
+			"total_pages":   totalPages,
+			"rows_per_page": rows,
+			"total_rows":    total,
+			"from":          start,
+			"to":            end,
+		},
+	}
+
+	if len(values) == 0 {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+}
+
+// Export downloads the records matching the list parameters as an Excel or
+// CSV file.
+func (r *Resource[T]) Export(c *fiber.Ctx) error {
+	query, err := r.query(c)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	name := strings.ToLower(strings.ReplaceAll(r.Name, " ", "_"))
+
+	if err := export.Write(c, name, r.ExportColumns, func(offset, limit int) ([]T, error) {
+		query.Offset = offset
+		query.Limit = limit
+		return r.GetAll(query)
+	}); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return nil
+}
+
+// Get serves a record by ID with its ETag.
+func (r *Resource[T]) Get(c *fiber.Ctx) error {
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	value, err := r.GetByID(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, r.Name+" not found"))
+	}
+
+	if etag.Set(c, value) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	return pkg.Response(c, fiber.StatusOK, r.Name+" found successfully", value)
+}
+
+// Create adds a record from the Input of the resource.
+func (r *Resource[T]) Create(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	input := r.Input()
+	if err := pkg.BindBody(c, input); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	var value T
+	input.Apply(&value)
+
+	r.set(&value, "IDCreatedby", userID)
+	r.set(&value, "IDUpdatedby", userID)
+
+	err := r.db.Create(&value).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusConflict, r.Name+" already exists"))
+		}
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	result := map[string]interface{}{
+		"id": r.id(&value),
+	}
+
+	return pkg.Response(c, fiber.StatusCreated, r.Name+" created successfully", result)
+}
+
+// Update saves the Input of the resource over a record, rejecting stale
+// versions per If-Match.
+func (r *Resource[T]) Update(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	value, err := r.GetByID(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, r.Name+" not found"))
+	}
+
+	if ok, err := etag.Check(c, value); !ok {
+		return err
+	}
+
+	input := r.Input()
+	if err := pkg.BindBody(c, input); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	input.Apply(value)
+	r.set(value, "IDUpdatedby", userID)
+
+	err = service.SaveVersion(r.db, value)
+	if errors.Is(err, service.ErrStale) {
+		return etag.Stale(c, func() (interface{}, error) {
+			return r.GetByID(uint(ID))
+		})
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusConflict, r.Name+" already exists"))
+	}
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	result := map[string]interface{}{
+		"id": r.id(value),
+	}
+
+	return pkg.Response(c, fiber.StatusOK, r.Name+" updated successfully", result)
+}
+
+// Delete soft deletes a record.
+func (r *Resource[T]) Delete(c *fiber.Ctx) error {
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	value, err := r.GetByID(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, r.Name+" not found"))
+	}
+
+	userID := c.Locals("userID").(uint)
+	r.set(value, "IDUpdatedby", userID)
+
+	err = service.SoftDelete(r.db, value, userID)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return pkg.Response(c, fiber.StatusOK, r.Name+" deleted successfully", nil)
+}
+
+// Bulk applies creates, updates and deletes in a single transaction, see
+// Bulk.Run.
+func (r *Resource[T]) Bulk(c *fiber.Ctx) error {
+	return r.bulk.Run(c)
+}
+
+// query reads the search, sort and filter parameters shared by the list and
+// its export.
+func (r *Resource[T]) query(c *fiber.Ctx) (Query, error) {
+	query := Query{
+		Search:         c.Query("search"),
+		IncludeDeleted: c.QueryBool("includeDeleted"),
+		Filters:        map[string]string{},
+	}
+
+	sorts, err := filter.ParseSort(c.Query("sortBy", ""), c.QueryBool("sortDirection"), r.SortFields)
+	if err != nil {
+		return query, err
+	}
+	query.Sorts = sorts
+
+	for _, param := range r.Filters {
+		query.Filters[param.Query] = c.Query(param.Query)
+	}
+
+	conditions, err := filter.Parse(c, r.FilterFields)
+	if err != nil {
+		return query, err
+	}
+	query.Conditions = conditions
+
+	return query, nil
+}
+
+func (r *Resource[T]) preload(db *gorm.DB) *gorm.DB {
+	for _, preload := range r.Preloads {
+		if preload.Columns == "" {
+			db = db.Preload(preload.Name)
+			continue
+		}
+
+		columns := preload.Columns
+		db = db.Preload(preload.Name, func(db *gorm.DB) *gorm.DB {
+			return db.Select(columns)
+		})
+	}
+
+	return db
+}
+
+func (r *Resource[T]) filter(db *gorm.DB, query Query) *gorm.DB {
+	for _, join := range r.Joins {
+		db = db.Joins(join)
+	}
+
+	db = filter.Apply(db, query.Conditions)
+
+	for _, param := range r.Filters {
+		if value := query.Filters[param.Query]; value != "" && value != "0" {
+			db = db.Where(param.Column+" = ?", value)
+		}
+	}
+
+	if query.Search != "" && len(r.SearchColumns) > 0 {
+		conditions := make([]string, len(r.SearchColumns))
+		values := make([]interface{}, len(r.SearchColumns))
+		for i, column := range r.SearchColumns {
+			conditions[i] = column + " ILIKE ?"
+			values[i] = "%" + query.Search + "%"
+		}
+
+		db = db.Where("("+strings.Join(conditions, " OR ")+")", values...)
+	}
+
+	return db
+}
+
+func (r *Resource[T]) set(value *T, name string, fieldValue interface{}) {
+	setField(r.schema, value, name, fieldValue)
+}
+
+func (r *Resource[T]) id(value *T) interface{} {
+	return primaryKey(r.schema, value)
+}
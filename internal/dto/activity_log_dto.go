@@ -0,0 +1,18 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+// ActivityLogRequest leaves the user, IP address and user agent, which are
+// taken from the request. Method, path, status and latency are written by the
+// request logger only, so clients cannot forge request logs.
+type ActivityLogRequest struct {
+	Action    string `json:"action" validate:"required,max=100"`
+	IsSuccess bool   `json:"is_success"`
+	Message   string `json:"message" validate:"max=1000"`
+}
+
+func (r *ActivityLogRequest) Apply(activityLog *model.ActivityLog) {
+	activityLog.Action = r.Action
+	activityLog.IsSuccess = r.IsSuccess
+	activityLog.Message = r.Message
+}
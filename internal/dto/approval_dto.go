@@ -0,0 +1,19 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type ApprovalRequest struct {
+	IDMenu uint   `json:"id_menu" validate:"required"`
+	Status string `json:"status" validate:"required,max=50"`
+	Action string `json:"action" validate:"required,max=50"`
+	Count  uint   `json:"count" validate:"required,gt=0"`
+	Level  int    `json:"level" validate:"gt=0"`
+}
+
+func (r *ApprovalRequest) Apply(approval *model.MstApproval) {
+	approval.IDMenu = r.IDMenu
+	approval.Status = r.Status
+	approval.Action = r.Action
+	approval.Count = r.Count
+	approval.Level = r.Level
+}
@@ -0,0 +1,25 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+// ApprovalAction is the message of an approval action. The chain a document
+// is submitted to is the one of the menu its table is registered with.
+type ApprovalAction struct {
+	Message string `json:"message"`
+}
+
+type ApprovalState struct {
+	RefTable      string                  `json:"ref_table"`
+	RefID         uint                    `json:"ref_id"`
+	IDMenu        *uint                   `json:"id_menu"`
+	State         string                  `json:"state"`
+	Level         *int                    `json:"level"`
+	Status        *string                 `json:"status"`
+	PendingLevel  *int                    `json:"pending_level"`
+	PendingAction *string                 `json:"pending_action"`
+	Approved      uint                    `json:"approved"`
+	Required      uint                    `json:"required"`
+	Approvers     []model.MstUser         `json:"approvers"`
+	SubmittedBy   *uint                   `json:"submitted_by"`
+	Histories     []model.ApprovalHistory `json:"histories"`
+}
@@ -3,11 +3,19 @@ package dto
 type UserLogin struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Device   string `json:"device"`
 }
 
 type TwoFactorAuth struct {
-	OtpKey   string `json:"otp_key"`
-	Username string `json:"username"`
+	ChallengeToken string `json:"challenge_token"`
+	OtpKey         string `json:"otp_key"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+type TwoFactorReauth struct {
+	Password     string `json:"password"`
+	OtpKey       string `json:"otp_key"`
+	RecoveryCode string `json:"recovery_code"`
 }
 
 type OTPKey struct {
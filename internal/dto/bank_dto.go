@@ -0,0 +1,33 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type BankRequest struct {
+	Code       string `json:"code" validate:"required,max=50"`
+	Name       string `json:"name" validate:"required,max=100"`
+	AccountNum string `json:"account_num" validate:"required,max=50"`
+	IDAccount  uint   `json:"id_account" validate:"required"`
+	IDCurrency uint   `json:"id_currency" validate:"required"`
+	BIC        string `json:"bic" validate:"omitempty,alphanum,min=8,max=11"`
+	Country    string `json:"country" validate:"required,max=100"`
+	State      string `json:"state" validate:"required,max=100"`
+	City       string `json:"city" validate:"required,max=100"`
+	Address    string `json:"address" validate:"required,max=255"`
+	ZipCode    string `json:"zip_code" validate:"required,max=20"`
+	Remarks    string `json:"remarks" validate:"max=255"`
+}
+
+func (r *BankRequest) Apply(bank *model.MstBank) {
+	bank.Code = r.Code
+	bank.Name = r.Name
+	bank.AccountNum = r.AccountNum
+	bank.IDAccount = r.IDAccount
+	bank.IDCurrency = r.IDCurrency
+	bank.BIC = r.BIC
+	bank.Country = r.Country
+	bank.State = r.State
+	bank.City = r.City
+	bank.Address = r.Address
+	bank.ZipCode = r.ZipCode
+	bank.Remarks = r.Remarks
+}
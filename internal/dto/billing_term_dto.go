@@ -0,0 +1,37 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type BillingTermRequest struct {
+	Code                      string  `json:"code" validate:"required,max=50"`
+	Description               string  `json:"description" validate:"required,max=255"`
+	DueDays                   int     `json:"due_days" validate:"gte=0,lte=3650"`
+	DiscountDays              int     `json:"discount_days" validate:"gte=0,lte=3650"`
+	IsCashOnly                bool    `json:"is_cash_only"`
+	ProxDueDay                int     `json:"prox_due_day" validate:"gte=0,lte=31"`
+	ProxDiscountDay           int     `json:"prox_discount_day" validate:"gte=0,lte=31"`
+	ProxMonthsForward         int     `json:"prox_months_forward" validate:"gte=0,lte=12"`
+	ProxDiscountMonthsForward int     `json:"prox_discount_months_forward" validate:"gte=0,lte=12"`
+	CutoffDay                 int     `json:"cutoff_day" validate:"gte=0,lte=31"`
+	DiscountPercent           float64 `json:"discount_percent" validate:"gte=0,lt=100"`
+	HolidayOffsetMethod       string  `json:"holiday_offset_method" validate:"max=50"`
+	IsAdvancedTerms           bool    `json:"is_advanced_terms"`
+	ProxCode                  int     `json:"prox_code" validate:"gte=0"`
+}
+
+func (r *BillingTermRequest) Apply(billingTerm *model.MstBillingTerm) {
+	billingTerm.Code = r.Code
+	billingTerm.Description = r.Description
+	billingTerm.DueDays = r.DueDays
+	billingTerm.DiscountDays = r.DiscountDays
+	billingTerm.IsCashOnly = r.IsCashOnly
+	billingTerm.ProxDueDay = r.ProxDueDay
+	billingTerm.ProxDiscountDay = r.ProxDiscountDay
+	billingTerm.ProxMonthsForward = r.ProxMonthsForward
+	billingTerm.ProxDiscountMonthsForward = r.ProxDiscountMonthsForward
+	billingTerm.CutoffDay = r.CutoffDay
+	billingTerm.DiscountPercent = r.DiscountPercent
+	billingTerm.HolidayOffsetMethod = r.HolidayOffsetMethod
+	billingTerm.IsAdvancedTerms = r.IsAdvancedTerms
+	billingTerm.ProxCode = r.ProxCode
+}
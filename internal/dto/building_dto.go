@@ -0,0 +1,17 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type BuildingRequest struct {
+	Code        string `json:"code" validate:"required,max=50"`
+	Description string `json:"description" validate:"required,max=255"`
+	Plant       string `json:"plant" validate:"required,max=50"`
+	Remarks     string `json:"remarks" validate:"max=255"`
+}
+
+func (r *BuildingRequest) Apply(building *model.MstBuilding) {
+	building.Code = r.Code
+	building.Description = r.Description
+	building.Plant = r.Plant
+	building.Remarks = r.Remarks
+}
@@ -0,0 +1,38 @@
+package dto
+
+import "encoding/json"
+
+const (
+	BulkActionCreate = "create"
+	BulkActionUpdate = "update"
+	BulkActionDelete = "delete"
+)
+
+const (
+	BulkStatusOK         = "ok"
+	BulkStatusFailed     = "failed"
+	BulkStatusRolledBack = "rolled_back"
+)
+
+type BulkRequest struct {
+	Operations []BulkOperation `json:"operations"`
+}
+
+// BulkOperation is one change of a bulk request. ID is the record updated or
+// deleted, Data the fields of the record created or updated. ETag is the
+// version the record was read with, as with If-Match, required to update it
+// and checked on delete when given.
+type BulkOperation struct {
+	Action string          `json:"action"`
+	ID     uint            `json:"id,omitempty"`
+	Data   json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+	ETag   string          `json:"etag,omitempty"`
+}
+
+type BulkResult struct {
+	Index  int         `json:"index"`
+	Action string      `json:"action"`
+	ID     interface{} `json:"id,omitempty"`
+	Status string      `json:"status"`
+	Error  string      `json:"error,omitempty"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type ChartOfAccountRequest struct {
+	Account          int    `json:"account" validate:"required,gt=0"`
+	Description      string `json:"description" validate:"required,max=255"`
+	Type             string `json:"type" validate:"required,max=50"`
+	Class            string `json:"class" validate:"required,max=50"`
+	ExchangeRateType string `json:"exchange_rate_type" validate:"required,max=50"`
+}
+
+func (r *ChartOfAccountRequest) Apply(chartOfAccount *model.MstChartOfAccount) {
+	chartOfAccount.Account = r.Account
+	chartOfAccount.Description = r.Description
+	chartOfAccount.Type = r.Type
+	chartOfAccount.Class = r.Class
+	chartOfAccount.ExchangeRateType = r.ExchangeRateType
+}
@@ -0,0 +1,13 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type CurrencyRequest struct {
+	Currency    string `json:"currency" validate:"required,max=10"`
+	Description string `json:"description" validate:"required,max=255"`
+}
+
+func (r *CurrencyRequest) Apply(currency *model.MstCurrency) {
+	currency.Currency = r.Currency
+	currency.Description = r.Description
+}
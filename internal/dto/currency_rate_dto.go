@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"insist-backend-golang/internal/model"
+	"time"
+)
+
+type CurrencyRateRequest struct {
+	IDFromCurrency uint      `json:"id_from_currency" validate:"required"`
+	IDToCurrency   uint      `json:"id_to_currency" validate:"required,nefield=IDFromCurrency"`
+	BuyRate        float64   `json:"buy_rate" validate:"gt=0"`
+	SellRate       float64   `json:"sell_rate" validate:"gt=0"`
+	EffectiveDate  time.Time `json:"effective_date" validate:"required"`
+}
+
+func (r *CurrencyRateRequest) Apply(currencyRate *model.MstCurrencyRate) {
+	currencyRate.IDFromCurrency = r.IDFromCurrency
+	currencyRate.IDToCurrency = r.IDToCurrency
+	currencyRate.BuyRate = r.BuyRate
+	currencyRate.SellRate = r.SellRate
+	currencyRate.EffectiveDate = r.EffectiveDate
+}
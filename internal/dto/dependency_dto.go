@@ -0,0 +1,13 @@
+package dto
+
+type WhereUsed struct {
+	Table   string            `json:"table"`
+	Column  string            `json:"column"`
+	Total   int64             `json:"total"`
+	Records []WhereUsedRecord `json:"records"`
+}
+
+type WhereUsedRecord struct {
+	ID    uint   `json:"id"`
+	Label string `json:"label,omitempty"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type DeptRequest struct {
+	Code        string `json:"code" validate:"required,max=50"`
+	Description string `json:"description" validate:"required,max=255"`
+	Remarks     string `json:"remarks" validate:"max=255"`
+}
+
+func (r *DeptRequest) Apply(dept *model.MstDept) {
+	dept.Code = r.Code
+	dept.Description = r.Description
+	dept.Remarks = r.Remarks
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type FCSRequest struct {
+	Code        string `json:"code" validate:"required,max=50"`
+	Description string `json:"description" validate:"required,max=255"`
+	Remarks     string `json:"remarks" validate:"max=255"`
+}
+
+func (r *FCSRequest) Apply(fcs *model.MstFCS) {
+	fcs.Code = r.Code
+	fcs.Description = r.Description
+	fcs.Remarks = r.Remarks
+}
@@ -0,0 +1,8 @@
+package dto
+
+// Input is a create or update request body that is copied onto a model field
+// by field, so a client can set only the fields it declares and never the
+// ID, audit columns or secrets of the model.
+type Input[T any] interface {
+	Apply(*T)
+}
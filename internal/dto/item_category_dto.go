@@ -0,0 +1,15 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type ItemCategoryRequest struct {
+	Code        string `json:"code" validate:"required,max=50"`
+	Description string `json:"description" validate:"required,max=255"`
+	Remarks     string `json:"remarks" validate:"max=255"`
+}
+
+func (r *ItemCategoryRequest) Apply(itemCategory *model.MstItemCategory) {
+	itemCategory.Code = r.Code
+	itemCategory.Description = r.Description
+	itemCategory.Remarks = r.Remarks
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type ItemRequest struct {
+	IDItemCategory   uint   `json:"id_item_category" validate:"required"`
+	IDUOM            uint   `json:"id_uom" validate:"required"`
+	Code             string `json:"code" validate:"required,max=50"`
+	Description      string `json:"description" validate:"required,max=255"`
+	InforCode        string `json:"infor_code" validate:"required,max=50"`
+	InforDescription string `json:"infor_description" validate:"required,max=255"`
+	Remarks          string `json:"remarks" validate:"max=255"`
+}
+
+func (r *ItemRequest) Apply(item *model.MstItem) {
+	item.IDItemCategory = r.IDItemCategory
+	item.IDUOM = r.IDUOM
+	item.Code = r.Code
+	item.Description = r.Description
+	item.InforCode = r.InforCode
+	item.InforDescription = r.InforDescription
+	item.Remarks = r.Remarks
+}
@@ -0,0 +1,17 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type ItemGroupRequest struct {
+	IDItemProductType uint   `json:"id_item_product_type" validate:"required"`
+	Code              string `json:"code" validate:"required,max=50"`
+	Description       string `json:"description" validate:"required,max=255"`
+	Remarks           string `json:"remarks" validate:"max=255"`
+}
+
+func (r *ItemGroupRequest) Apply(itemGroup *model.MstItemGroup) {
+	itemGroup.IDItemProductType = r.IDItemProductType
+	itemGroup.Code = r.Code
+	itemGroup.Description = r.Description
+	itemGroup.Remarks = r.Remarks
+}
@@ -0,0 +1,17 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type ItemGroupTypeRequest struct {
+	IDItemGroup uint   `json:"id_item_group" validate:"required"`
+	Code        string `json:"code" validate:"required,max=50"`
+	Description string `json:"description" validate:"required,max=255"`
+	Remarks     string `json:"remarks" validate:"max=255"`
+}
+
+func (r *ItemGroupTypeRequest) Apply(itemGroupType *model.MstItemGroupType) {
+	itemGroupType.IDItemGroup = r.IDItemGroup
+	itemGroupType.Code = r.Code
+	itemGroupType.Description = r.Description
+	itemGroupType.Remarks = r.Remarks
+}
@@ -0,0 +1,17 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type ItemProcessRequest struct {
+	IDItemCategory uint   `json:"id_item_category" validate:"required"`
+	Code           string `json:"code" validate:"required,max=50"`
+	Description    string `json:"description" validate:"required,max=255"`
+	Remarks        string `json:"remarks" validate:"max=255"`
+}
+
+func (r *ItemProcessRequest) Apply(itemProcess *model.MstItemProcess) {
+	itemProcess.IDItemCategory = r.IDItemCategory
+	itemProcess.Code = r.Code
+	itemProcess.Description = r.Description
+	itemProcess.Remarks = r.Remarks
+}
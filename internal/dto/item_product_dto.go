@@ -0,0 +1,19 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type ItemProductRequest struct {
+	IDItemCategory    uint   `json:"id_item_category" validate:"required"`
+	IDItemSubCategory *uint  `json:"id_item_sub_category" validate:"omitempty,gt=0"`
+	Code              string `json:"code" validate:"required,max=50"`
+	Description       string `json:"description" validate:"required,max=255"`
+	Remarks           string `json:"remarks" validate:"max=255"`
+}
+
+func (r *ItemProductRequest) Apply(itemProduct *model.MstItemProduct) {
+	itemProduct.IDItemCategory = r.IDItemCategory
+	itemProduct.IDItemSubCategory = r.IDItemSubCategory
+	itemProduct.Code = r.Code
+	itemProduct.Description = r.Description
+	itemProduct.Remarks = r.Remarks
+}
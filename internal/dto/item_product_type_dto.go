@@ -0,0 +1,17 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type ItemProductTypeRequest struct {
+	IDItemProduct uint   `json:"id_item_product" validate:"required"`
+	Code          string `json:"code" validate:"required,max=50"`
+	Description   string `json:"description" validate:"required,max=255"`
+	Remarks       string `json:"remarks" validate:"max=255"`
+}
+
+func (r *ItemProductTypeRequest) Apply(itemProductType *model.MstItemProductType) {
+	itemProductType.IDItemProduct = r.IDItemProduct
+	itemProductType.Code = r.Code
+	itemProductType.Description = r.Description
+	itemProductType.Remarks = r.Remarks
+}
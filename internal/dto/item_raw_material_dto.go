@@ -0,0 +1,27 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type ItemRawMaterialRequest struct {
+	IDItem            uint   `json:"id_item" validate:"required"`
+	IDItemProductType uint   `json:"id_item_product_type" validate:"required"`
+	IDItemGroupType   uint   `json:"id_item_group_type" validate:"required"`
+	IDItemProcess     uint   `json:"id_item_process" validate:"required"`
+	IDItemSurface     uint   `json:"id_item_surface" validate:"required"`
+	IDItemSource      uint   `json:"id_item_source" validate:"required"`
+	DiameterSize      string `json:"diameter_size" validate:"required,max=50"`
+	LengthSize        string `json:"length_size" validate:"required,max=50"`
+	InnerDiameterSize string `json:"inner_diameter_size" validate:"required,max=50"`
+}
+
+func (r *ItemRawMaterialRequest) Apply(itemRawMaterial *model.MstItemRawMaterial) {
+	itemRawMaterial.IDItem = r.IDItem
+	itemRawMaterial.IDItemProductType = r.IDItemProductType
+	itemRawMaterial.IDItemGroupType = r.IDItemGroupType
+	itemRawMaterial.IDItemProcess = r.IDItemProcess
+	itemRawMaterial.IDItemSurface = r.IDItemSurface
+	itemRawMaterial.IDItemSource = r.IDItemSource
+	itemRawMaterial.DiameterSize = r.DiameterSize
+	itemRawMaterial.LengthSize = r.LengthSize
+	itemRawMaterial.InnerDiameterSize = r.InnerDiameterSize
+}
@@ -0,0 +1,17 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type ItemSourceRequest struct {
+	IDItemCategory uint   `json:"id_item_category" validate:"required"`
+	Code           string `json:"code" validate:"required,max=50"`
+	Description    string `json:"description" validate:"required,max=255"`
+	Remarks        string `json:"remarks" validate:"max=255"`
+}
+
+func (r *ItemSourceRequest) Apply(itemSource *model.MstItemSource) {
+	itemSource.IDItemCategory = r.IDItemCategory
+	itemSource.Code = r.Code
+	itemSource.Description = r.Description
+	itemSource.Remarks = r.Remarks
+}
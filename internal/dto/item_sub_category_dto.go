@@ -0,0 +1,17 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type ItemSubCategoryRequest struct {
+	IDItemCategory uint   `json:"id_item_category" validate:"required"`
+	Code           string `json:"code" validate:"required,max=50"`
+	Description    string `json:"description" validate:"required,max=255"`
+	Remarks        string `json:"remarks" validate:"max=255"`
+}
+
+func (r *ItemSubCategoryRequest) Apply(itemSubCategory *model.MstItemSubCategory) {
+	itemSubCategory.IDItemCategory = r.IDItemCategory
+	itemSubCategory.Code = r.Code
+	itemSubCategory.Description = r.Description
+	itemSubCategory.Remarks = r.Remarks
+}
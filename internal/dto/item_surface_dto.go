@@ -0,0 +1,17 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type ItemSurfaceRequest struct {
+	IDItemCategory uint   `json:"id_item_category" validate:"required"`
+	Code           string `json:"code" validate:"required,max=50"`
+	Description    string `json:"description" validate:"required,max=255"`
+	Remarks        string `json:"remarks" validate:"max=255"`
+}
+
+func (r *ItemSurfaceRequest) Apply(itemSurface *model.MstItemSurface) {
+	itemSurface.IDItemCategory = r.IDItemCategory
+	itemSurface.Code = r.Code
+	itemSurface.Description = r.Description
+	itemSurface.Remarks = r.Remarks
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type KeyValueRequest struct {
+	Key     string `json:"key" validate:"required,max=100"`
+	Value   string `json:"value" validate:"required,max=255"`
+	Remarks string `json:"remarks" validate:"max=255"`
+}
+
+func (r *KeyValueRequest) Apply(keyValue *model.MstKeyValue) {
+	keyValue.Key = r.Key
+	keyValue.Value = r.Value
+	keyValue.Remarks = r.Remarks
+}
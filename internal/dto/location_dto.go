@@ -0,0 +1,15 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type LocationRequest struct {
+	IDWarehouse uint   `json:"id_warehouse" validate:"required"`
+	Location    string `json:"location" validate:"required,max=50"`
+	Remarks     string `json:"remarks" validate:"max=255"`
+}
+
+func (r *LocationRequest) Apply(location *model.MstLocation) {
+	location.IDWarehouse = r.IDWarehouse
+	location.Location = r.Location
+	location.Remarks = r.Remarks
+}
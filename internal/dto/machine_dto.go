@@ -3,7 +3,80 @@ package dto
 import "insist-backend-golang/internal/model"
 
 type CreateMachinePayload struct {
-	Machine       model.MstMachine       `json:"machine"`
-	MachineDetail model.MstMachineDetail `json:"machine_detail"`
-	MachineStatus model.MstMachineStatus `json:"machine_status"`
+	MachineDetail MachineDetailRequest `json:"machine_detail"`
+	MachineStatus MachineStatusRequest `json:"machine_status"`
+}
+
+type MachineDetailRequest struct {
+	Code                string  `json:"code" validate:"required,max=50"`
+	CodeOld             *string `json:"code_old" validate:"omitempty,max=50"`
+	AssetNum            string  `json:"asset_num" validate:"required,max=50"`
+	AssetNumOld         *string `json:"asset_num_old" validate:"omitempty,max=50"`
+	Description         string  `json:"description" validate:"required,max=255"`
+	Name                string  `json:"name" validate:"required,max=100"`
+	Maker               string  `json:"maker" validate:"required,max=100"`
+	Power               float64 `json:"power" validate:"gte=0"`
+	IDPowerUOM          uint    `json:"id_power_uom"`
+	Electricity         float64 `json:"electricity" validate:"gte=0"`
+	IDElectricityUOM    uint    `json:"id_electricity_uom"`
+	Cavity              int     `json:"cavity" validate:"gte=0"`
+	Lubricant           string  `json:"lubricant" validate:"max=100"`
+	LubricantCapacity   float64 `json:"lubricant_capacity" validate:"gte=0"`
+	IDLubricantUOM      uint    `json:"id_lubricant_uom"`
+	Sliding             string  `json:"sliding" validate:"max=100"`
+	SlidingCapacity     float64 `json:"sliding_capacity" validate:"gte=0"`
+	IDSlidingUOM        uint    `json:"id_sliding_uom"`
+	Coolant             string  `json:"coolant" validate:"max=100"`
+	CoolantCapacity     float64 `json:"coolant_capacity" validate:"gte=0"`
+	IDCoolantUOM        uint    `json:"id_coolant_uom"`
+	Hydraulic           string  `json:"hydraulic" validate:"max=100"`
+	HydraulicCapacity   float64 `json:"hydraulic_capacity" validate:"gte=0"`
+	IDHydraulicUOM      uint    `json:"id_hydraulic_uom"`
+	DimensionFront      float64 `json:"dimension_front" validate:"gte=0"`
+	IDDimensionFrontUOM uint    `json:"id_dimension_front_uom"`
+	DimensionSide       float64 `json:"dimension_side" validate:"gte=0"`
+	IDDimensionSideUOM  uint    `json:"id_dimension_side_uom"`
+	IDBuilding          *uint   `json:"id_building"`
+}
+
+func (r *MachineDetailRequest) Apply(machineDetail *model.MstMachineDetail) {
+	machineDetail.Code = r.Code
+	machineDetail.CodeOld = r.CodeOld
+	machineDetail.AssetNum = r.AssetNum
+	machineDetail.AssetNumOld = r.AssetNumOld
+	machineDetail.Description = r.Description
+	machineDetail.Name = r.Name
+	machineDetail.Maker = r.Maker
+	machineDetail.Power = r.Power
+	machineDetail.IDPowerUOM = r.IDPowerUOM
+	machineDetail.Electricity = r.Electricity
+	machineDetail.IDElectricityUOM = r.IDElectricityUOM
+	machineDetail.Cavity = r.Cavity
+	machineDetail.Lubricant = r.Lubricant
+	machineDetail.LubricantCapacity = r.LubricantCapacity
+	machineDetail.IDLubricantUOM = r.IDLubricantUOM
+	machineDetail.Sliding = r.Sliding
+	machineDetail.SlidingCapacity = r.SlidingCapacity
+	machineDetail.IDSlidingUOM = r.IDSlidingUOM
+	machineDetail.Coolant = r.Coolant
+	machineDetail.CoolantCapacity = r.CoolantCapacity
+	machineDetail.IDCoolantUOM = r.IDCoolantUOM
+	machineDetail.Hydraulic = r.Hydraulic
+	machineDetail.HydraulicCapacity = r.HydraulicCapacity
+	machineDetail.IDHydraulicUOM = r.IDHydraulicUOM
+	machineDetail.DimensionFront = r.DimensionFront
+	machineDetail.IDDimensionFrontUOM = r.IDDimensionFrontUOM
+	machineDetail.DimensionSide = r.DimensionSide
+	machineDetail.IDDimensionSideUOM = r.IDDimensionSideUOM
+	machineDetail.IDBuilding = r.IDBuilding
+}
+
+type MachineStatusRequest struct {
+	IDReason uint    `json:"id_reason" validate:"required"`
+	Remarks  *string `json:"remarks" validate:"omitempty,max=255"`
+}
+
+func (r *MachineStatusRequest) Apply(machineStatus *model.MstMachineStatus) {
+	machineStatus.IDReason = r.IDReason
+	machineStatus.Remarks = r.Remarks
 }
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"insist-backend-golang/internal/model"
+	"time"
+)
+
+type MachineRevision struct {
+	ID        uint       `json:"id"`
+	RevNo     int        `json:"rev_no"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+// MachineRevisionChange is a field that differs between two revisions. UoMs
+// are given by their code.
+type MachineRevisionChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type MachineRevisionDiff struct {
+	IDMachine uint                    `json:"id_machine"`
+	From      MachineRevision         `json:"from"`
+	To        MachineRevision         `json:"to"`
+	Changes   []MachineRevisionChange `json:"changes"`
+}
+
+// MachineAsOf is a machine as it was at AsOf: the revision and the status in
+// effect then. Status is nil when no status had been set yet.
+type MachineAsOf struct {
+	IDMachine uint                    `json:"id_machine"`
+	AsOf      time.Time               `json:"as_of"`
+	Detail    *model.MstMachineDetail `json:"detail"`
+	Status    *model.MstMachineStatus `json:"status"`
+}
@@ -0,0 +1,60 @@
+package dto
+
+import "time"
+
+// MachineStatusInterval is the time a machine spent in one status, cut to
+// the requested range.
+type MachineStatusInterval struct {
+	IDStatus          uint      `json:"id_status"`
+	IDReason          uint      `json:"id_reason"`
+	ReasonCode        string    `json:"reason_code"`
+	ReasonDescription string    `json:"reason_description"`
+	Downtime          bool      `json:"downtime"`
+	Remarks           *string   `json:"remarks,omitempty"`
+	Start             time.Time `json:"start"`
+	End               time.Time `json:"end"`
+	Hours             float64   `json:"hours"`
+}
+
+type MachineTimeline struct {
+	IDMachine uint                    `json:"id_machine"`
+	From      time.Time               `json:"from"`
+	To        time.Time               `json:"to"`
+	Intervals []MachineStatusInterval `json:"intervals"`
+}
+
+type MachineDowntimeReason struct {
+	IDReason          uint    `json:"id_reason"`
+	ReasonCode        string  `json:"reason_code"`
+	ReasonDescription string  `json:"reason_description"`
+	Hours             float64 `json:"hours"`
+	Count             int     `json:"count"`
+}
+
+// MachineMetrics sums the status intervals of a range. Hours are only
+// counted while a status is known, availability is the uptime share of those
+// hours in percent, and a failure is a change from uptime to downtime. MTBF,
+// MTTR and availability are nil when there is nothing to divide by.
+type MachineMetrics struct {
+	IDMachine     uint                    `json:"id_machine,omitempty"`
+	Code          string                  `json:"code,omitempty"`
+	Name          string                  `json:"name,omitempty"`
+	From          time.Time               `json:"from"`
+	To            time.Time               `json:"to"`
+	ObservedHours float64                 `json:"observed_hours"`
+	UptimeHours   float64                 `json:"uptime_hours"`
+	DowntimeHours float64                 `json:"downtime_hours"`
+	Availability  *float64                `json:"availability"`
+	Failures      int                     `json:"failures"`
+	MTBF          *float64                `json:"mtbf"`
+	MTTR          *float64                `json:"mttr"`
+	TopReasons    []MachineDowntimeReason `json:"top_reasons"`
+}
+
+// BuildingMachineMetrics are the metrics of the machines of a building, in
+// total and per machine.
+type BuildingMachineMetrics struct {
+	IDBuilding uint             `json:"id_building"`
+	Total      MachineMetrics   `json:"total"`
+	Machines   []MachineMetrics `json:"machines"`
+}
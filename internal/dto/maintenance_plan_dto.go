@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"insist-backend-golang/internal/model"
+	"time"
+)
+
+type MaintenancePlanRequest struct {
+	Code          string `json:"code" validate:"required,max=50"`
+	Name          string `json:"name" validate:"required,max=100"`
+	Description   string `json:"description" validate:"max=255"`
+	IntervalType  string `json:"interval_type" validate:"required,oneof=days running_hours"`
+	IntervalValue int    `json:"interval_value" validate:"gt=0"`
+	LeadDays      int    `json:"lead_days" validate:"gte=0"`
+	Remarks       string `json:"remarks" validate:"max=255"`
+}
+
+func (r *MaintenancePlanRequest) Apply(maintenancePlan *model.MstMaintenancePlan) {
+	maintenancePlan.Code = r.Code
+	maintenancePlan.Name = r.Name
+	maintenancePlan.Description = r.Description
+	maintenancePlan.IntervalType = r.IntervalType
+	maintenancePlan.IntervalValue = r.IntervalValue
+	maintenancePlan.LeadDays = r.LeadDays
+	maintenancePlan.Remarks = r.Remarks
+}
+
+type MaintenancePlanTaskRequest struct {
+	IDMaintenancePlan uint   `json:"id_maintenance_plan" validate:"required"`
+	Seq               int    `json:"seq" validate:"gte=0"`
+	Description       string `json:"description" validate:"required,max=255"`
+	Remarks           string `json:"remarks" validate:"max=255"`
+}
+
+func (r *MaintenancePlanTaskRequest) Apply(maintenancePlanTask *model.MstMaintenancePlanTask) {
+	maintenancePlanTask.IDMaintenancePlan = r.IDMaintenancePlan
+	maintenancePlanTask.Seq = r.Seq
+	maintenancePlanTask.Description = r.Description
+	maintenancePlanTask.Remarks = r.Remarks
+}
+
+type MachineMaintenancePlanRequest struct {
+	IDMachine         uint      `json:"id_machine" validate:"required"`
+	IDMaintenancePlan uint      `json:"id_maintenance_plan" validate:"required"`
+	StartDate         time.Time `json:"start_date" validate:"required"`
+}
+
+func (r *MachineMaintenancePlanRequest) Apply(machineMaintenancePlan *model.MstMachineMaintenancePlan) {
+	machineMaintenancePlan.IDMachine = r.IDMachine
+	machineMaintenancePlan.IDMaintenancePlan = r.IDMaintenancePlan
+	machineMaintenancePlan.StartDate = r.StartDate
+}
+
+// MaintenanceSchedule is where a plan assigned to a machine stands. A plan by
+// calendar days is next due a number of days after the last due date, or
+// after its start date before the first. A plan by running hours is due once
+// the machine has run that long since, and NextDueAt estimates when at the
+// pace it has run so far. RunningHours and RemainingHours are set for plans
+// by running hours only.
+type MaintenanceSchedule struct {
+	IDMachineMaintenancePlan uint                      `json:"id_machine_maintenance_plan"`
+	IDMachine                uint                      `json:"id_machine"`
+	MaintenancePlan          *model.MstMaintenancePlan `json:"maintenance_plan"`
+	Since                    time.Time                 `json:"since"`
+	NextDueAt                *time.Time                `json:"next_due_at"`
+	RunningHours             *float64                  `json:"running_hours,omitempty"`
+	RemainingHours           *float64                  `json:"remaining_hours,omitempty"`
+	State                    string                    `json:"state"`
+	OpenWorkOrders           []model.WorkOrder         `json:"open_work_orders"`
+}
@@ -0,0 +1,59 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type MaterialDetailRequest struct {
+	IDMaterial      uint    `json:"id_material" validate:"required"`
+	RevNo           int     `json:"rev_no" validate:"gte=0"`
+	RmssNum         string  `json:"rmss_num" validate:"required,max=50"`
+	OdTolerancePlus float64 `json:"od_tolerance_plus"`
+	OdToleranceMin  float64 `json:"od_tolerance_min"`
+	IdTolerancePlus float64 `json:"id_tolerance_plus"`
+	IdToleranceMin  float64 `json:"id_tolerance_min"`
+	Width           float64 `json:"width" validate:"gte=0"`
+	Height          float64 `json:"height" validate:"gte=0"`
+	Ovality         string  `json:"ovality" validate:"max=50"`
+	CuttingLength   string  `json:"cutting_length" validate:"max=50"`
+	Hardness        string  `json:"hardness" validate:"max=50"`
+	CompotitionC    string  `json:"compotition_c" validate:"max=50"`
+	CompotitionSi   string  `json:"compotition_si" validate:"max=50"`
+	CompotitionMn   string  `json:"compotition_mn" validate:"max=50"`
+	CompotitionP    string  `json:"compotition_p" validate:"max=50"`
+	CompotitionS    string  `json:"compotition_s" validate:"max=50"`
+	CompotitionCu   string  `json:"compotition_cu" validate:"max=50"`
+	CompotitionNi   string  `json:"compotition_ni" validate:"max=50"`
+	CompotitionCr   string  `json:"compotition_cr" validate:"max=50"`
+	CompotitionMo   string  `json:"compotition_mo" validate:"max=50"`
+	TensileStrength string  `json:"tensile_strength" validate:"max=50"`
+	SaRatio         string  `json:"sa_ratio" validate:"max=50"`
+	Origin          string  `json:"origin" validate:"max=100"`
+	Remarks         string  `json:"remarks" validate:"max=255"`
+}
+
+func (r *MaterialDetailRequest) Apply(materialDetail *model.MstMaterialDetail) {
+	materialDetail.IDMaterial = r.IDMaterial
+	materialDetail.RevNo = r.RevNo
+	materialDetail.RmssNum = r.RmssNum
+	materialDetail.OdTolerancePlus = r.OdTolerancePlus
+	materialDetail.OdToleranceMin = r.OdToleranceMin
+	materialDetail.IdTolerancePlus = r.IdTolerancePlus
+	materialDetail.IdToleranceMin = r.IdToleranceMin
+	materialDetail.Width = r.Width
+	materialDetail.Height = r.Height
+	materialDetail.Ovality = r.Ovality
+	materialDetail.CuttingLength = r.CuttingLength
+	materialDetail.Hardness = r.Hardness
+	materialDetail.CompotitionC = r.CompotitionC
+	materialDetail.CompotitionSi = r.CompotitionSi
+	materialDetail.CompotitionMn = r.CompotitionMn
+	materialDetail.CompotitionP = r.CompotitionP
+	materialDetail.CompotitionS = r.CompotitionS
+	materialDetail.CompotitionCu = r.CompotitionCu
+	materialDetail.CompotitionNi = r.CompotitionNi
+	materialDetail.CompotitionCr = r.CompotitionCr
+	materialDetail.CompotitionMo = r.CompotitionMo
+	materialDetail.TensileStrength = r.TensileStrength
+	materialDetail.SaRatio = r.SaRatio
+	materialDetail.Origin = r.Origin
+	materialDetail.Remarks = r.Remarks
+}
@@ -0,0 +1,11 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type MaterialRequest struct {
+	Code string `json:"code" validate:"required,max=50"`
+}
+
+func (r *MaterialRequest) Apply(material *model.MstMaterial) {
+	material.Code = r.Code
+}
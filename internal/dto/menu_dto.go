@@ -0,0 +1,19 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type MenuRequest struct {
+	Label    string `json:"label" validate:"required,max=100"`
+	Path     string `json:"path" validate:"required,max=255"`
+	IDParent uint   `json:"id_parent"`
+	Icon     string `json:"icon" validate:"max=100"`
+	Sort     uint   `json:"sort" validate:"lte=10000"`
+}
+
+func (r *MenuRequest) Apply(menu *model.MstMenu) {
+	menu.Label = r.Label
+	menu.Path = r.Path
+	menu.IDParent = r.IDParent
+	menu.Icon = r.Icon
+	menu.Sort = r.Sort
+}
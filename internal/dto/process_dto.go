@@ -0,0 +1,15 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type ProcessRequest struct {
+	Code        string `json:"code" validate:"required,max=50"`
+	Description string `json:"description" validate:"required,max=255"`
+	Remarks     string `json:"remarks" validate:"max=255"`
+}
+
+func (r *ProcessRequest) Apply(process *model.MstProcess) {
+	process.Code = r.Code
+	process.Description = r.Description
+	process.Remarks = r.Remarks
+}
@@ -0,0 +1,21 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type ReasonRequest struct {
+	IDMenu      uint   `json:"id_menu"`
+	Key         string `json:"key" validate:"required,max=100"`
+	Code        string `json:"code" validate:"max=50"`
+	Description string `json:"description" validate:"required,max=255"`
+	Remarks     string `json:"remarks" validate:"max=255"`
+	Downtime    bool   `json:"downtime"`
+}
+
+func (r *ReasonRequest) Apply(reason *model.MstReason) {
+	reason.IDMenu = r.IDMenu
+	reason.Key = r.Key
+	reason.Code = r.Code
+	reason.Description = r.Description
+	reason.Remarks = r.Remarks
+	reason.Downtime = r.Downtime
+}
@@ -0,0 +1,11 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type RoleRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+func (r *RoleRequest) Apply(role *model.MstRole) {
+	role.Name = r.Name
+}
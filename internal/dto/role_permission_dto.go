@@ -1,5 +1,7 @@
 package dto
 
+import "insist-backend-golang/internal/model"
+
 type MenuWithPermissions struct {
 	Label    string `json:"label"`
 	Path     string `json:"path"`
@@ -9,3 +11,19 @@ type MenuWithPermissions struct {
 	IsUpdate bool   `json:"is_update"`
 	IsDelete bool   `json:"is_delete"`
 }
+
+type RolePermissionRequest struct {
+	IDRole   uint `json:"id_role" validate:"required"`
+	IDMenu   uint `json:"id_menu" validate:"required"`
+	IsCreate bool `json:"is_create"`
+	IsUpdate bool `json:"is_update"`
+	IsDelete bool `json:"is_delete"`
+}
+
+func (r *RolePermissionRequest) Apply(rolePermission *model.MstRolePermission) {
+	rolePermission.IDRole = r.IDRole
+	rolePermission.IDMenu = r.IDMenu
+	rolePermission.IsCreate = r.IsCreate
+	rolePermission.IsUpdate = r.IsUpdate
+	rolePermission.IsDelete = r.IsDelete
+}
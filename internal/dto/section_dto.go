@@ -0,0 +1,17 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type SectionRequest struct {
+	IDFCS       uint   `json:"id_fcs" validate:"required"`
+	Code        string `json:"code" validate:"required,max=50"`
+	Description string `json:"description" validate:"required,max=255"`
+	Remarks     string `json:"remarks" validate:"max=255"`
+}
+
+func (r *SectionRequest) Apply(section *model.MstSection) {
+	section.IDFCS = r.IDFCS
+	section.Code = r.Code
+	section.Description = r.Description
+	section.Remarks = r.Remarks
+}
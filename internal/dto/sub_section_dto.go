@@ -0,0 +1,19 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type SubSectionRequest struct {
+	IDSection   uint   `json:"id_section" validate:"required"`
+	IDBuilding  uint   `json:"id_building" validate:"required"`
+	Code        string `json:"code" validate:"required,max=50"`
+	Description string `json:"description" validate:"required,max=255"`
+	Remarks     string `json:"remarks" validate:"max=255"`
+}
+
+func (r *SubSectionRequest) Apply(subSection *model.MstSubSection) {
+	subSection.IDSection = r.IDSection
+	subSection.IDBuilding = r.IDBuilding
+	subSection.Code = r.Code
+	subSection.Description = r.Description
+	subSection.Remarks = r.Remarks
+}
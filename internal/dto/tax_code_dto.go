@@ -0,0 +1,49 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type TaxCodeRequest struct {
+	Name                   string  `json:"name" validate:"required,max=50"`
+	Description            string  `json:"description" validate:"required,max=255"`
+	Type                   string  `json:"type" validate:"required,max=50"`
+	Rate                   float64 `json:"rate" validate:"gte=0,lte=100"`
+	IncludePrice           bool    `json:"include_price"`
+	IncludeDiscount        bool    `json:"include_discount"`
+	IncludeRestockFee      bool    `json:"include_restock_fee"`
+	Deductible             bool    `json:"deductible"`
+	IncludeFreight         bool    `json:"include_freight"`
+	IncludeDuty            bool    `json:"include_duty"`
+	IncludeBrokerage       bool    `json:"include_brokerage"`
+	IncludeInsurance       bool    `json:"include_insurance"`
+	IncludeLocalFreight    bool    `json:"include_local_freight"`
+	IncludeMisc            bool    `json:"include_misc"`
+	IncludeSurcharge       bool    `json:"include_surcharge"`
+	AssessOnReturn         bool    `json:"assess_on_return"`
+	IncludeTaxOnPrevSystem bool    `json:"include_tax_on_prev_system"`
+	IDAccountAR            uint    `json:"id_account_ar" validate:"required"`
+	IDAccountARProcess     uint    `json:"id_account_ar_process" validate:"required"`
+	IDAccountAP            uint    `json:"id_account_ap" validate:"required"`
+}
+
+func (r *TaxCodeRequest) Apply(taxCode *model.MstTaxCode) {
+	taxCode.Name = r.Name
+	taxCode.Description = r.Description
+	taxCode.Type = r.Type
+	taxCode.Rate = r.Rate
+	taxCode.IncludePrice = r.IncludePrice
+	taxCode.IncludeDiscount = r.IncludeDiscount
+	taxCode.IncludeRestockFee = r.IncludeRestockFee
+	taxCode.Deductible = r.Deductible
+	taxCode.IncludeFreight = r.IncludeFreight
+	taxCode.IncludeDuty = r.IncludeDuty
+	taxCode.IncludeBrokerage = r.IncludeBrokerage
+	taxCode.IncludeInsurance = r.IncludeInsurance
+	taxCode.IncludeLocalFreight = r.IncludeLocalFreight
+	taxCode.IncludeMisc = r.IncludeMisc
+	taxCode.IncludeSurcharge = r.IncludeSurcharge
+	taxCode.AssessOnReturn = r.AssessOnReturn
+	taxCode.IncludeTaxOnPrevSystem = r.IncludeTaxOnPrevSystem
+	taxCode.IDAccountAR = r.IDAccountAR
+	taxCode.IDAccountARProcess = r.IDAccountARProcess
+	taxCode.IDAccountAP = r.IDAccountAP
+}
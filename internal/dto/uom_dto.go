@@ -0,0 +1,15 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type UoMRequest struct {
+	Code        string `json:"code" validate:"required,max=50"`
+	Description string `json:"description" validate:"required,max=255"`
+	Remarks     string `json:"remarks" validate:"max=255"`
+}
+
+func (r *UoMRequest) Apply(uom *model.MstUoms) {
+	uom.Code = r.Code
+	uom.Description = r.Description
+	uom.Remarks = r.Remarks
+}
@@ -1,6 +1,46 @@
 package dto
 
+import "insist-backend-golang/internal/model"
+
 type ChangePassword struct {
 	Password        string `json:"password"`
 	ConfirmPassword string `json:"confirm_password"`
 }
+
+// CreateUser leaves 2FA, which the user turns on by enrolling.
+type CreateUser struct {
+	IDDept   uint   `json:"id_dept" validate:"required"`
+	Name     string `json:"name" validate:"required,max=100"`
+	Email    string `json:"email" validate:"omitempty,email,max=100"`
+	Username string `json:"username" validate:"required,max=50"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+	IsActive bool   `json:"is_active"`
+}
+
+func (r *CreateUser) Apply(user *model.MstUser) {
+	user.IDDept = r.IDDept
+	user.Name = r.Name
+	user.Email = r.Email
+	user.Username = r.Username
+	user.Password = r.Password
+	user.IsActive = r.IsActive
+}
+
+// UpdateUser leaves the password, which is changed through change-password,
+// and 2FA, the tokens and OTP keys, which only the auth flows and the 2FA
+// reset set.
+type UpdateUser struct {
+	IDDept   uint   `json:"id_dept" validate:"required"`
+	Name     string `json:"name" validate:"required,max=100"`
+	Email    string `json:"email" validate:"omitempty,email,max=100"`
+	Username string `json:"username" validate:"required,max=50"`
+	IsActive bool   `json:"is_active"`
+}
+
+func (r *UpdateUser) Apply(user *model.MstUser) {
+	user.IDDept = r.IDDept
+	user.Name = r.Name
+	user.Email = r.Email
+	user.Username = r.Username
+	user.IsActive = r.IsActive
+}
@@ -0,0 +1,17 @@
+package dto
+
+import "insist-backend-golang/internal/model"
+
+type WarehouseRequest struct {
+	IDBuilding  uint   `json:"id_building" validate:"required"`
+	Code        string `json:"code" validate:"required,max=50"`
+	Description string `json:"description" validate:"required,max=255"`
+	Remarks     string `json:"remarks" validate:"max=255"`
+}
+
+func (r *WarehouseRequest) Apply(warehouse *model.MstWarehouse) {
+	warehouse.IDBuilding = r.IDBuilding
+	warehouse.Code = r.Code
+	warehouse.Description = r.Description
+	warehouse.Remarks = r.Remarks
+}
@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"insist-backend-golang/internal/model"
+	"time"
+)
+
+type WorkOrderRequest struct {
+	Type        string     `json:"type" validate:"required,oneof=corrective preventive"`
+	IDMachine   uint       `json:"id_machine" validate:"required"`
+	Title       string     `json:"title" validate:"required,max=255"`
+	Description *string    `json:"description" validate:"omitempty,max=1000"`
+	IDReason    *uint      `json:"id_reason" validate:"required_if=Type corrective"`
+	MachineDown bool       `json:"machine_down"`
+	DueAt       *time.Time `json:"due_at"`
+}
+
+func (r *WorkOrderRequest) Apply(workOrder *model.WorkOrder) {
+	workOrder.Type = r.Type
+	workOrder.IDMachine = r.IDMachine
+	workOrder.Title = r.Title
+	workOrder.Description = r.Description
+	workOrder.IDReason = r.IDReason
+	workOrder.MachineDown = r.MachineDown
+	workOrder.DueAt = r.DueAt
+}
+
+// UpdateWorkOrderRequest leaves out the type and machine, which are fixed
+// once a work order is opened.
+type UpdateWorkOrderRequest struct {
+	Title       string     `json:"title" validate:"required,max=255"`
+	Description *string    `json:"description" validate:"omitempty,max=1000"`
+	IDReason    *uint      `json:"id_reason"`
+	MachineDown bool       `json:"machine_down"`
+	DueAt       *time.Time `json:"due_at"`
+}
+
+func (r *UpdateWorkOrderRequest) Apply(workOrder *model.WorkOrder) {
+	workOrder.Title = r.Title
+	workOrder.Description = r.Description
+	workOrder.IDReason = r.IDReason
+	workOrder.MachineDown = r.MachineDown
+	workOrder.DueAt = r.DueAt
+}
+
+type AssignWorkOrderRequest struct {
+	TechnicianNumber string `json:"technician_number" validate:"required,max=50"`
+}
+
+// CompleteWorkOrderRequest sets the status the machine is brought back up
+// with, by default the one it had before the work order took it down.
+type CompleteWorkOrderRequest struct {
+	IDReason *uint   `json:"id_reason"`
+	Remarks  *string `json:"remarks" validate:"omitempty,max=255"`
+}
+
+type WorkOrderTaskRequest struct {
+	Done bool `json:"done"`
+}
+
+type WorkOrderPartRequest struct {
+	IDItem   uint    `json:"id_item" validate:"required"`
+	Quantity float64 `json:"quantity" validate:"gt=0"`
+	IDUOM    *uint   `json:"id_uom"`
+	Remarks  *string `json:"remarks" validate:"omitempty,max=255"`
+}
+
+func (r *WorkOrderPartRequest) Apply(workOrderPart *model.WorkOrderPart) {
+	workOrderPart.IDItem = r.IDItem
+	workOrderPart.Quantity = r.Quantity
+	if r.IDUOM != nil {
+		workOrderPart.IDUOM = *r.IDUOM
+	}
+	workOrderPart.Remarks = r.Remarks
+}
+
+type WorkOrderLaborRequest struct {
+	EmployeeNumber string    `json:"employee_number" validate:"required,max=50"`
+	WorkDate       time.Time `json:"work_date" validate:"required"`
+	Hours          float64   `json:"hours" validate:"gt=0,lte=24"`
+	Remarks        *string   `json:"remarks" validate:"omitempty,max=255"`
+}
+
+func (r *WorkOrderLaborRequest) Apply(workOrderLabor *model.WorkOrderLabor) {
+	workOrderLabor.EmployeeNumber = r.EmployeeNumber
+	workOrderLabor.WorkDate = r.WorkDate
+	workOrderLabor.Hours = r.Hours
+	workOrderLabor.Remarks = r.Remarks
+}
@@ -0,0 +1,153 @@
+package etag
+
+import (
+	"errors"
+	"insist-backend-golang/pkg"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Of is the version of a record, taken from its UpdatedAt, which changes on
+// every save. Microseconds are what the database keeps. Records without an
+// UpdatedAt have none.
+func Of(value interface{}) string {
+	updatedAt, ok := Version(value)
+	if !ok {
+		return ""
+	}
+
+	return `"` + strconv.FormatInt(updatedAt.UnixMicro(), 10) + `"`
+}
+
+// Version is the UpdatedAt of a record, false when it has none.
+func Version(value interface{}) (time.Time, bool) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return time.Time{}, false
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return time.Time{}, false
+	}
+
+	field := v.FieldByName("UpdatedAt")
+	if !field.IsValid() {
+		return time.Time{}, false
+	}
+
+	var updatedAt time.Time
+	switch t := field.Interface().(type) {
+	case time.Time:
+		updatedAt = t
+	case *time.Time:
+		if t == nil {
+			return time.Time{}, false
+		}
+		updatedAt = *t
+	default:
+		return time.Time{}, false
+	}
+
+	if updatedAt.IsZero() {
+		return time.Time{}, false
+	}
+
+	return updatedAt, true
+}
+
+// Set sends the version of value as the ETag of the response, and tells
+// whether the client already holds it per If-None-Match, in which case the
+// handler answers 304 Not Modified.
+func Set(c *fiber.Ctx, value interface{}) bool {
+	tag := Of(value)
+	if tag == "" {
+		return false
+	}
+
+	c.Set(fiber.HeaderETag, tag)
+
+	return c.Fresh()
+}
+
+// Check verifies that the If-Match header of an update names the current
+// version of value. When it does not, it answers 428 for a missing header and
+// 409 with the current record for a stale one, and returns false with the
+// error the handler returns. Records without a version are not checked.
+func Check(c *fiber.Ctx, value interface{}) (bool, error) {
+	if Of(value) == "" {
+		return true, nil
+	}
+
+	if strings.TrimSpace(c.Get(fiber.HeaderIfMatch)) == "" {
+		return false, pkg.ErrorResponse(c, fiber.NewError(fiber.StatusPreconditionRequired, "If-Match is required, send the ETag the record was read with"))
+	}
+
+	if !Match(c, value) {
+		return false, Conflict(c, value)
+	}
+
+	return true, nil
+}
+
+// Match tells whether the If-Match header of an update names the current
+// version of value.
+func Match(c *fiber.Ctx, value interface{}) bool {
+	return Matches(c.Get(fiber.HeaderIfMatch), value)
+}
+
+// Matches tells whether ifMatch, a list of ETags or *, names the current
+// version of value. An empty ifMatch matches no version, and any ifMatch
+// matches a record without a version.
+func Matches(ifMatch string, value interface{}) bool {
+	tag := Of(value)
+	if tag == "" {
+		return true
+	}
+
+	ifMatch = strings.TrimSpace(ifMatch)
+	if ifMatch == "*" {
+		return true
+	}
+
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Conflict answers an update made over a stale version with 409 and the
+// current record, so the client can merge and retry.
+func Conflict(c *fiber.Ctx, value interface{}) error {
+	if tag := Of(value); tag != "" {
+		c.Set(fiber.HeaderETag, tag)
+	}
+
+	return pkg.Response(c, fiber.StatusConflict, "The record was changed by someone else, reload it and try again", value)
+}
+
+// Stale answers an update whose record was changed by someone else after its
+// If-Match was checked, when the write found the version moved. The record is
+// read again with reload and answered as Conflict does, 404 when it was
+// deleted meanwhile.
+func Stale(c *fiber.Ctx, reload func() (interface{}, error)) error {
+	value, err := reload()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "The record was deleted by someone else"))
+	}
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return Conflict(c, value)
+}
@@ -0,0 +1,262 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+// batchSize is how many rows are read from the database at a time.
+const batchSize = 500
+
+// Column is a column of an exported list. Field is the path to the value
+// from the row, e.g. "Code" or "UOM.Code" to show a referenced record by its
+// code instead of its ID.
+type Column struct {
+	Header string
+	Field  string
+}
+
+// Fetch reads a batch of the list, with the same search, filters and sorting
+// as the list endpoint.
+type Fetch[T any] func(offset, limit int) ([]T, error)
+
+// Write sends the rows fetched in batches as a ?format=xlsx (default) or csv
+// download named after name.
+func Write[T any](c *fiber.Ctx, name string, columns []Column, fetch Fetch[T]) error {
+	format := c.Query("format", "xlsx")
+	if format != "xlsx" && format != "csv" {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid format, use xlsx or csv")
+	}
+
+	rows, err := fetch(0, batchSize)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	if format == "csv" {
+		c.Attachment(filename(name, format))
+		writeCSV(c, columns, rows, fetch)
+		return nil
+	}
+
+	if err := writeXLSX(c, columns, rows, fetch); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	c.Attachment(filename(name, format))
+	return nil
+}
+
+func filename(name string, format string) string {
+	return fmt.Sprintf("%s_%s.%s", name, time.Now().Format("20060102150405"), format)
+}
+
+// writeCSV streams the rows to the client while the next batches are read.
+// Errors past the first batch can only be logged, as the response has begun.
+func writeCSV[T any](c *fiber.Ctx, columns []Column, rows []T, fetch Fetch[T]) {
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		writer := csv.NewWriter(w)
+
+		if err := writer.Write(headers(columns)); err != nil {
+			log.Printf("Failed to write export: %v", err)
+			return
+		}
+
+		err := batches(rows, fetch, func(row *T) error {
+			return writer.Write(texts(columns, row))
+		}, func() error {
+			writer.Flush()
+			return w.Flush()
+		})
+		if err != nil {
+			log.Printf("Failed to write export: %v", err)
+		}
+
+		writer.Flush()
+	})
+}
+
+// writeXLSX writes the rows through excelize's stream writer, which keeps
+// large sheets on disk instead of in memory.
+func writeXLSX[T any](c *fiber.Ctx, columns []Column, rows []T, fetch Fetch[T]) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := f.GetSheetName(0)
+	stream, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = excelize.Cell{StyleID: bold, Value: column.Header}
+	}
+
+	if err := stream.SetRow("A1", header); err != nil {
+		return err
+	}
+
+	line := 1
+	err = batches(rows, fetch, func(row *T) error {
+		line++
+
+		cells := make([]interface{}, len(columns))
+		for i, column := range columns {
+			cells[i] = cell(field(reflect.ValueOf(row).Elem(), column.Field))
+		}
+
+		axis, err := excelize.CoordinatesToCellName(1, line)
+		if err != nil {
+			return err
+		}
+
+		return stream.SetRow(axis, cells)
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := stream.Flush(); err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+
+	return f.Write(c.Response().BodyWriter())
+}
+
+// batches calls write for every row, reading the next batch once the
+// previous one is written, and flush after each batch.
+func batches[T any](rows []T, fetch Fetch[T], write func(row *T) error, flush func() error) error {
+	offset := 0
+	for {
+		for i := range rows {
+			if err := write(&rows[i]); err != nil {
+				return err
+			}
+		}
+
+		if flush != nil {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < batchSize {
+			return nil
+		}
+
+		offset += batchSize
+
+		var err error
+		rows, err = fetch(offset, batchSize)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func headers(columns []Column) []string {
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = column.Header
+	}
+
+	return headers
+}
+
+func texts[T any](columns []Column, row *T) []string {
+	texts := make([]string, len(columns))
+	for i, column := range columns {
+		texts[i] = text(field(reflect.ValueOf(row).Elem(), column.Field))
+	}
+
+	return texts
+}
+
+// field follows path through the row, stopping at the first nil reference.
+func field(value reflect.Value, path string) reflect.Value {
+	for _, name := range strings.Split(path, ".") {
+		for value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				return reflect.Value{}
+			}
+			value = value.Elem()
+		}
+
+		value = value.FieldByName(name)
+		if !value.IsValid() {
+			return value
+		}
+	}
+
+	return value
+}
+
+// cell keeps numbers as numbers in the sheet.
+func cell(value reflect.Value) interface{} {
+	for value.IsValid() && value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.IsValid() {
+		switch value.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return value.Interface()
+		}
+	}
+
+	return text(value)
+}
+
+func text(value reflect.Value) string {
+	for value.IsValid() && value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+
+	if !value.IsValid() {
+		return ""
+	}
+
+	switch v := value.Interface().(type) {
+	case gorm.DeletedAt:
+		if !v.Valid {
+			return ""
+		}
+		return v.Time.Format("2006-01-02 15:04:05")
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format("2006-01-02 15:04:05")
+	case bool:
+		if v {
+			return "Yes"
+		}
+		return "No"
+	}
+
+	return fmt.Sprint(value.Interface())
+}
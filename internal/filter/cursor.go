@@ -0,0 +1,208 @@
+package filter
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorMode tells whether the list is asked for by cursor with ?after=,
+// left empty for the first page.
+func CursorMode(c *fiber.Ctx) bool {
+	return c.Context().QueryArgs().Has("after")
+}
+
+// Page lists the rows of db in cursor mode instead of by page number: it
+// returns up to limit rows following the row after points at, in the order
+// of orders and then of the primary key, and the cursor of the next page,
+// empty on the last page. No total is counted. db must not be limited or
+// ordered yet, and orders may only use columns of T's own table. NULLs sort
+// after every other value, whichever the direction.
+func Page[T any](db *gorm.DB, orders []Order, after string, limit int) ([]T, string, error) {
+	s, err := schema.Parse(new(T), cache, schema.NamingStrategy{})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if s.PrioritizedPrimaryField == nil {
+		return nil, "", fmt.Errorf("%s has no primary key to page by", s.Table)
+	}
+
+	orders = append(orders[:len(orders):len(orders)], Order{
+		Column: s.Table + "." + s.PrioritizedPrimaryField.DBName,
+		Desc:   len(orders) > 0 && orders[len(orders)-1].Desc,
+	})
+
+	fields := make([]*schema.Field, len(orders))
+	for i, order := range orders {
+		table, name, _ := strings.Cut(order.Column, ".")
+		if table != s.Table {
+			return nil, "", fmt.Errorf("%w: cannot page by %s", ErrInvalidCursor, order.Column)
+		}
+
+		fields[i] = s.LookUpField(name)
+		if fields[i] == nil {
+			return nil, "", fmt.Errorf("%w: cannot page by %s", ErrInvalidCursor, order.Column)
+		}
+	}
+
+	if after != "" {
+		values, err := decodeCursor(after, len(orders))
+		if err != nil {
+			return nil, "", err
+		}
+
+		db = db.Where(keyset(orders, fields, values))
+	}
+
+	for _, order := range orders {
+		direction := " ASC"
+		if order.Desc {
+			direction = " DESC"
+		}
+
+		db = db.Order(order.Column + direction + " NULLS LAST")
+	}
+
+	var rows []T
+	if err := db.Limit(limit + 1).Find(&rows).Error; err != nil {
+		return nil, "", err
+	}
+
+	if len(rows) <= limit {
+		return rows, "", nil
+	}
+
+	rows = rows[:limit]
+	last := reflect.ValueOf(&rows[limit-1]).Elem()
+
+	values := make([]*string, len(fields))
+	for i, field := range fields {
+		value, _ := field.ValueOf(context.Background(), last)
+		values[i] = cursorValue(value)
+	}
+
+	next, err := encodeCursor(values)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return rows, next, nil
+}
+
+// keyset selects the rows sorting after values, e.g. for a, b and id:
+// a > ? OR (a = ? AND b > ?) OR (a = ? AND b = ? AND id > ?). A NULL value
+// is only equal to NULL, and every row with a NULL sorts after it.
+func keyset(orders []Order, fields []*schema.Field, values []*string) clause.Expr {
+	var conditions []string
+	var args []interface{}
+
+	for i, order := range orders {
+		var parts []string
+		for j := 0; j < i; j++ {
+			if values[j] == nil {
+				parts = append(parts, orders[j].Column+" IS NULL")
+				continue
+			}
+
+			parts = append(parts, orders[j].Column+" = ?")
+			args = append(args, *values[j])
+		}
+
+		// Nothing sorts after NULL but NULL, which is left to the next
+		// column.
+		if values[i] == nil {
+			continue
+		}
+
+		operator := " > ?"
+		if order.Desc {
+			operator = " < ?"
+		}
+
+		condition := order.Column + operator
+		if !fields[i].PrimaryKey && !fields[i].NotNull {
+			condition = "(" + condition + " OR " + order.Column + " IS NULL)"
+		}
+
+		parts = append(parts, condition)
+		args = append(args, *values[i])
+
+		conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	if len(conditions) == 0 {
+		return gorm.Expr("FALSE")
+	}
+
+	return gorm.Expr("("+strings.Join(conditions, " OR ")+")", args...)
+}
+
+// cursorValue writes value down for the cursor, nil for NULL.
+func cursorValue(value interface{}) *string {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return nil
+		}
+		value = v
+	}
+
+	rv := reflect.ValueOf(value)
+	if !rv.IsValid() {
+		return nil
+	}
+
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		value = rv.Elem().Interface()
+	}
+
+	var text string
+	switch v := value.(type) {
+	case time.Time:
+		text = v.Format(time.RFC3339Nano)
+	default:
+		text = fmt.Sprint(v)
+	}
+
+	return &text
+}
+
+func encodeCursor(values []*string) (string, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(after string, size int) ([]*string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(after)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var values []*string
+	if err := json.Unmarshal(data, &values); err != nil || len(values) != size {
+		return nil, ErrInvalidCursor
+	}
+
+	return values, nil
+}
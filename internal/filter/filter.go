@@ -0,0 +1,184 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Fields maps the names accepted in filter[name] to the column they filter.
+// Only the fields listed can be filtered on.
+type Fields map[string]string
+
+// Condition is one parsed filter[field][op]=value parameter.
+type Condition struct {
+	Column   string
+	Operator string
+	Values   []string
+}
+
+var pattern = regexp.MustCompile(`^filter\[(\w+)\](?:\[(\w+)\])?$`)
+
+var operators = map[string]bool{
+	"eq":      true,
+	"ne":      true,
+	"in":      true,
+	"gt":      true,
+	"gte":     true,
+	"lt":      true,
+	"lte":     true,
+	"between": true,
+	"like":    true,
+	"isnull":  true,
+}
+
+var cache = &sync.Map{}
+
+// Columns lists every column of model as a filter field, qualified with the
+// table so it stays unambiguous when the list query joins other tables.
+func Columns(model interface{}) Fields {
+	s, err := schema.Parse(model, cache, schema.NamingStrategy{})
+	if err != nil {
+		panic(err)
+	}
+
+	fields := Fields{}
+	for _, name := range s.DBNames {
+		fields[name] = s.Table + "." + name
+	}
+
+	return fields
+}
+
+// With returns the fields together with extra ones, like the columns of a
+// joined table.
+func (f Fields) With(extra Fields) Fields {
+	fields := Fields{}
+	for name, column := range f {
+		fields[name] = column
+	}
+
+	for name, column := range extra {
+		fields[name] = column
+	}
+
+	return fields
+}
+
+// Without returns the fields except the given ones, to keep secrets like
+// password hashes out of reach of filters.
+func (f Fields) Without(names ...string) Fields {
+	fields := f.With(nil)
+	for _, name := range names {
+		delete(fields, name)
+	}
+
+	return fields
+}
+
+// Parse reads the filter parameters of the request, e.g.
+// filter[effective_date][between]=2025-01-01,2025-01-31 or
+// filter[id_uom][in]=1,2. The operator defaults to eq. Unknown fields,
+// operators and malformed values are rejected.
+func Parse(c *fiber.Ctx, fields Fields) ([]Condition, error) {
+	var conditions []Condition
+	var err error
+
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		if err != nil {
+			return
+		}
+
+		match := pattern.FindStringSubmatch(string(key))
+		if match == nil {
+			return
+		}
+
+		column, ok := fields[match[1]]
+		if !ok {
+			err = fmt.Errorf("invalid filter field: %s", match[1])
+			return
+		}
+
+		operator := match[2]
+		if operator == "" {
+			operator = "eq"
+		}
+
+		if !operators[operator] {
+			err = fmt.Errorf("invalid filter operator: %s", operator)
+			return
+		}
+
+		values := []string{string(value)}
+		switch operator {
+		case "in":
+			values = strings.Split(string(value), ",")
+		case "between":
+			values = strings.Split(string(value), ",")
+			if len(values) != 2 {
+				err = fmt.Errorf("filter %s between needs two values separated by a comma", match[1])
+				return
+			}
+		case "isnull":
+			if string(value) != "true" && string(value) != "false" {
+				err = fmt.Errorf("filter %s isnull must be true or false", match[1])
+				return
+			}
+		}
+
+		conditions = append(conditions, Condition{
+			Column:   column,
+			Operator: operator,
+			Values:   values,
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return conditions, nil
+}
+
+// Apply adds the conditions to a list query.
+func Apply(db *gorm.DB, conditions []Condition) *gorm.DB {
+	for _, condition := range conditions {
+		column := condition.Column
+		value := condition.Values[0]
+
+		switch condition.Operator {
+		case "eq":
+			db = db.Where(column+" = ?", value)
+		case "ne":
+			db = db.Where(column+" <> ?", value)
+		case "in":
+			db = db.Where(column+" IN ?", condition.Values)
+		case "gt":
+			db = db.Where(column+" > ?", value)
+		case "gte":
+			db = db.Where(column+" >= ?", value)
+		case "lt":
+			db = db.Where(column+" < ?", value)
+		case "lte":
+			db = db.Where(column+" <= ?", value)
+		case "between":
+			db = db.Where(column+" BETWEEN ? AND ?", condition.Values[0], condition.Values[1])
+		case "like":
+			db = db.Where("CAST("+column+" AS VARCHAR) ILIKE ?", "%"+value+"%")
+		case "isnull":
+			if value == "true" {
+				db = db.Where(column + " IS NULL")
+			} else {
+				db = db.Where(column + " IS NOT NULL")
+			}
+		}
+	}
+
+	return db
+}
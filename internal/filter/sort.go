@@ -0,0 +1,55 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Order sorts a list by a whitelisted column.
+type Order struct {
+	Column string
+	Desc   bool
+}
+
+// ParseSort reads a sortBy parameter of comma separated fields, e.g.
+// "code,-updated_at". A field prefixed with - sorts descending, other fields
+// sort descending when desc is set. Fields outside of fields are rejected.
+func ParseSort(sortBy string, desc bool, fields Fields) ([]Order, error) {
+	var orders []Order
+
+	for _, name := range strings.Split(sortBy, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		order := Order{Desc: desc}
+		if strings.HasPrefix(name, "-") {
+			name = strings.TrimPrefix(name, "-")
+			order.Desc = true
+		}
+
+		column, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid sortBy field: %s", name)
+		}
+
+		order.Column = column
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+// Sort orders a list query. The columns come from a whitelist, so they are
+// used as is.
+func Sort(db *gorm.DB, orders []Order) *gorm.DB {
+	for _, order := range orders {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: order.Column, Raw: true}, Desc: order.Desc})
+	}
+
+	return db
+}
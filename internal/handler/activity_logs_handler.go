@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
@@ -28,6 +31,9 @@ func NewActivityLogHandler(ActivityLogService *service.ActivityLogService) *Acti
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering Activity Log"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -46,12 +52,47 @@ func (h *ActivityLogHandler) GetActivityLogs(c *fiber.Ctx) error {
 	arrayDate := strings.Split(rangeDate, "~")
 	fmt.Println(arrayDate)
 
-	total, err := h.ActivityLogService.GetTotal(search, action, isSuccess, arrayDate)
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.ActivityLogFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.ActivityLogFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if filter.CursorMode(c) {
+		ActivityLogs, next, err := h.ActivityLogService.GetPage(c.Query("after"), rows, search, action, isSuccess, sorts, arrayDate, filters)
+		if errors.Is(err, filter.ErrInvalidCursor) {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+		}
+		if err != nil {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+		}
+
+		var nextCursor *string
+		if next != "" {
+			nextCursor = &next
+		}
+
+		result := map[string]interface{}{
+			"items": ActivityLogs,
+			"pagination": map[string]interface{}{
+				"rows_per_page": rows,
+				"next_cursor":   nextCursor,
+			},
+		}
+
+		return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+	}
+
+	total, err := h.ActivityLogService.GetTotal(search, action, isSuccess, arrayDate, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	ActivityLogs, err := h.ActivityLogService.GetAll(offset, rows, search, action, isSuccess, sortBy, sortDirection, arrayDate)
+	ActivityLogs, err := h.ActivityLogService.GetAll(offset, rows, search, action, isSuccess, sorts, arrayDate, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -126,28 +167,38 @@ func (h *ActivityLogHandler) GetActivityLog(c *fiber.Ctx) error {
 
 // CreateActivityLog godoc
 // @Summary Create a new Activity Log
-// @Description Create a new Activity Log with the provided details
+// @Description Create a new Activity Log for the logged-in user; user, IP and user agent are taken from the request and the log is stored with source "client"
 // @Tags Activity Log
 // @Accept json
 // @Produce json
-// @Param ActivityLog body model.ActivityLog true "Activity Log details"
+// @Param ActivityLog body dto.ActivityLogRequest true "Activity Log details"
 // @Success 201 {object} map[string]interface{} "Activity Log created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/master/ActivityLog [post]
 func (h *ActivityLogHandler) CreateActivityLog(c *fiber.Ctx) error {
-	var ActivityLog model.ActivityLog
-	if err := c.BodyParser(&ActivityLog); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	userID := c.Locals("userID").(uint)
+
+	var input dto.ActivityLogRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
-	user, err := h.ActivityLogService.GetByUsername(ActivityLog.Username)
+	var ActivityLog model.ActivityLog
+	input.Apply(&ActivityLog)
+
+	user, err := h.ActivityLogService.GetUserByID(userID)
 	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusOK, err.Error()))
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "User not found"))
 	}
 
+	ActivityLog.Source = service.ActivityLogSourceClient
 	ActivityLog.IDUser = user.ID
+	ActivityLog.Username = user.Username
 	ActivityLog.IPAddress = c.IP()
+	ActivityLog.UserAgent = c.Get(fiber.HeaderUserAgent)
+	ActivityLog.OS = pkg.ParseOS(ActivityLog.UserAgent)
 
 	err = h.ActivityLogService.Create(&ActivityLog)
 	if err != nil {
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"errors"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/service"
+	"insist-backend-golang/pkg"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type ApprovalEngineHandler struct {
+	approvalEngineService *service.ApprovalEngineService
+}
+
+func NewApprovalEngineHandler(approvalEngineService *service.ApprovalEngineService) *ApprovalEngineHandler {
+	return &ApprovalEngineHandler{approvalEngineService: approvalEngineService}
+}
+
+// GetApprovalState godoc
+// @Summary Get the approval state of a document
+// @Description Replays the approval history of a document and returns its current level, pending approvers and history
+// @Tags Approval Engine
+// @Accept json
+// @Produce json
+// @Param refTable path string true "Reference Table"
+// @Param refID path int true "Reference ID"
+// @Success 200 {object} map[string]interface{} "Approval state found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not read the menu of the table"
+// @Failure 404 {object} map[string]interface{} "Not Found: The table does not go through approval, or the document does not exist"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/approval-engine/{refTable}/{refID} [get]
+func (h *ApprovalEngineHandler) GetApprovalState(c *fiber.Ctx) error {
+	refID, err := c.ParamsInt("refID")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	state, err := h.approvalEngineService.GetState(c.Params("refTable"), uint(refID))
+	if err != nil {
+		return approvalErrorResponse(c, err)
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Approval state found successfully", state)
+}
+
+// SubmitApproval godoc
+// @Summary Submit a document for approval
+// @Description Starts a new approval cycle using the approval chain of the menu the table is registered with, which needs at least one approver level
+// @Tags Approval Engine
+// @Accept json
+// @Produce json
+// @Param refTable path string true "Reference Table"
+// @Param refID path int true "Reference ID"
+// @Param input body dto.ApprovalAction true "Message"
+// @Success 200 {object} map[string]interface{} "Approval submitted successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: The menu has no approval chain, or no approver level"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not read the menu of the table, or is not allowed to submit"
+// @Failure 404 {object} map[string]interface{} "Not Found: The table does not go through approval, or the document does not exist"
+// @Failure 409 {object} map[string]interface{} "Conflict: Invalid approval state"
+// @Router /admin/approval-engine/{refTable}/{refID}/submit [post]
+func (h *ApprovalEngineHandler) SubmitApproval(c *fiber.Ctx) error {
+	return h.act(c, service.ApprovalKeySubmit, "Approval submitted successfully")
+}
+
+// ApproveApproval godoc
+// @Summary Approve the pending level of a document
+// @Description Records an approval for the pending level; the document advances once the level count is reached
+// @Tags Approval Engine
+// @Accept json
+// @Produce json
+// @Param refTable path string true "Reference Table"
+// @Param refID path int true "Reference ID"
+// @Param input body dto.ApprovalAction true "Message"
+// @Success 200 {object} map[string]interface{} "Approval approved successfully"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not read the menu of the table, or is not an approver"
+// @Failure 404 {object} map[string]interface{} "Not Found: The table does not go through approval, or the document does not exist"
+// @Failure 409 {object} map[string]interface{} "Conflict: Invalid approval state"
+// @Router /admin/approval-engine/{refTable}/{refID}/approve [post]
+func (h *ApprovalEngineHandler) ApproveApproval(c *fiber.Ctx) error {
+	return h.act(c, service.ApprovalKeyApprove, "Approval approved successfully")
+}
+
+// RejectApproval godoc
+// @Summary Reject a document
+// @Description Rejects the document at the pending level and ends the approval cycle
+// @Tags Approval Engine
+// @Accept json
+// @Produce json
+// @Param refTable path string true "Reference Table"
+// @Param refID path int true "Reference ID"
+// @Param input body dto.ApprovalAction true "Message"
+// @Success 200 {object} map[string]interface{} "Approval rejected successfully"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not read the menu of the table, or is not an approver"
+// @Failure 404 {object} map[string]interface{} "Not Found: The table does not go through approval, or the document does not exist"
+// @Failure 409 {object} map[string]interface{} "Conflict: Invalid approval state"
+// @Router /admin/approval-engine/{refTable}/{refID}/reject [post]
+func (h *ApprovalEngineHandler) RejectApproval(c *fiber.Ctx) error {
+	return h.act(c, service.ApprovalKeyReject, "Approval rejected successfully")
+}
+
+// ReturnApproval godoc
+// @Summary Return a document to its submitter
+// @Description Returns the document at the pending level so the submitter can revise and resubmit it
+// @Tags Approval Engine
+// @Accept json
+// @Produce json
+// @Param refTable path string true "Reference Table"
+// @Param refID path int true "Reference ID"
+// @Param input body dto.ApprovalAction true "Message"
+// @Success 200 {object} map[string]interface{} "Approval returned successfully"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not read the menu of the table, or is not an approver"
+// @Failure 404 {object} map[string]interface{} "Not Found: The table does not go through approval, or the document does not exist"
+// @Failure 409 {object} map[string]interface{} "Conflict: Invalid approval state"
+// @Router /admin/approval-engine/{refTable}/{refID}/return [post]
+func (h *ApprovalEngineHandler) ReturnApproval(c *fiber.Ctx) error {
+	return h.act(c, service.ApprovalKeyReturn, "Approval returned successfully")
+}
+
+// CancelApproval godoc
+// @Summary Cancel a pending approval
+// @Description Cancels the running approval cycle; only allowed for the submitter
+// @Tags Approval Engine
+// @Accept json
+// @Produce json
+// @Param refTable path string true "Reference Table"
+// @Param refID path int true "Reference ID"
+// @Param input body dto.ApprovalAction true "Message"
+// @Success 200 {object} map[string]interface{} "Approval cancelled successfully"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not read the menu of the table, or is not the submitter"
+// @Failure 404 {object} map[string]interface{} "Not Found: The table does not go through approval, or the document does not exist"
+// @Failure 409 {object} map[string]interface{} "Conflict: Invalid approval state"
+// @Router /admin/approval-engine/{refTable}/{refID}/cancel [post]
+func (h *ApprovalEngineHandler) CancelApproval(c *fiber.Ctx) error {
+	return h.act(c, service.ApprovalKeyCancel, "Approval cancelled successfully")
+}
+
+func (h *ApprovalEngineHandler) act(c *fiber.Ctx, key string, message string) error {
+	userID := c.Locals("userID").(uint)
+
+	refID, err := c.ParamsInt("refID")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	var input dto.ApprovalAction
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+		}
+	}
+
+	state, err := h.approvalEngineService.Act(key, c.Params("refTable"), uint(refID), userID, input)
+	if err != nil {
+		return approvalErrorResponse(c, err)
+	}
+
+	return pkg.Response(c, fiber.StatusOK, message, state)
+}
+
+func approvalErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Menu not found"))
+	case errors.Is(err, service.ErrApprovalUnknownTable), errors.Is(err, service.ErrApprovalNotFound):
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, err.Error()))
+	case errors.Is(err, service.ErrApprovalNotApprover), errors.Is(err, service.ErrApprovalNotSubmitter), errors.Is(err, service.ErrMachineRevisionNotAuthor):
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusForbidden, err.Error()))
+	case errors.Is(err, service.ErrApprovalInvalidState), errors.Is(err, service.ErrApprovalAlreadyActed), errors.Is(err, service.ErrMachineRevisionNotDraft):
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusConflict, err.Error()))
+	case errors.Is(err, service.ErrApprovalNoChain), errors.Is(err, service.ErrApprovalNoApprovers), errors.Is(err, service.ErrApprovalUnknownAction):
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+}
@@ -1,6 +1,11 @@
 package handler
 
 import (
+	"errors"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/export"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
@@ -9,6 +14,19 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+var approvalExportColumns = []export.Column{
+	{Header: "Menu", Field: "Menu.Label"},
+	{Header: "Path", Field: "Menu.Path"},
+	{Header: "Status", Field: "Status"},
+	{Header: "Action", Field: "Action"},
+	{Header: "Count", Field: "Count"},
+	{Header: "Level", Field: "Level"},
+	{Header: "Created By", Field: "CreatedBy.Name"},
+	{Header: "Created At", Field: "CreatedAt"},
+	{Header: "Updated By", Field: "UpdatedBy.Name"},
+	{Header: "Updated At", Field: "UpdatedAt"},
+}
+
 type ApprovalHandler struct {
 	approvalService *service.ApprovalService
 }
@@ -26,6 +44,8 @@ func NewApprovalHandler(approvalService *service.ApprovalService) *ApprovalHandl
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering approval"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -35,13 +55,19 @@ func (h *ApprovalHandler) GetApprovals(c *fiber.Ctx) error {
 	rows := c.QueryInt("rows", 20)
 	search := c.Query("search")
 	offset := (page - 1) * rows
+	includeDeleted := c.QueryBool("includeDeleted")
 
-	total, err := h.approvalService.GetTotal(search)
+	filters, err := filter.Parse(c, service.ApprovalFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.approvalService.GetTotal(search, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	approvals, err := h.approvalService.GetAll(offset, rows, search)
+	approvals, err := h.approvalService.GetAll(offset, rows, search, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -89,6 +115,34 @@ func (h *ApprovalHandler) GetApprovals(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
 }
 
+// ExportApprovals godoc
+// @Summary Export Approvals
+// @Description Downloads the approvals matching the list parameters as an Excel or CSV file
+// @Tags Approval
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/approval/export [get]
+func (h *ApprovalHandler) ExportApprovals(c *fiber.Ctx) error {
+	search := c.Query("search")
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	filters, err := filter.Parse(c, service.ApprovalFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := export.Write(c, "approvals", approvalExportColumns, func(offset, limit int) ([]model.MstApproval, error) {
+		return h.approvalService.GetAll(offset, limit, search, includeDeleted, filters)
+	}); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return nil
+}
+
 // GetApproval godoc
 // @Summary Get approval by ID
 // @Description Retrieve a specific approval by its ID
@@ -96,7 +150,9 @@ func (h *ApprovalHandler) GetApprovals(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Approval ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Approval found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Approval not found"
 // @Router /admin/approval/{id} [get]
@@ -111,6 +167,10 @@ func (h *ApprovalHandler) GetApproval(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Approval not found"))
 	}
 
+	if etag.Set(c, approval) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Approval found successfully", approval)
 }
 
@@ -145,9 +205,10 @@ func (h *ApprovalHandler) GetApprovalByIdMenu(c *fiber.Ctx) error {
 // @Tags Approval
 // @Accept json
 // @Produce json
-// @Param approval body model.MstApproval true "Approval details"
+// @Param approval body dto.ApprovalRequest true "Approval details"
 // @Success 201 {object} map[string]interface{} "Approval created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/approval [post]
 func (h *ApprovalHandler) CreateApproval(c *fiber.Ctx) error {
@@ -156,15 +217,18 @@ func (h *ApprovalHandler) CreateApproval(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusUnauthorized, "Invalid userID"))
 	}
 
-	var approval *model.MstApproval
-	if err := c.BodyParser(&approval); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, "Failed to parse request body"))
+	var input dto.ApprovalRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	var approval model.MstApproval
+	input.Apply(&approval)
+
 	approval.IDCreatedby = userID
 	approval.IDUpdatedby = userID
 
-	if err := h.approvalService.Create(approval); err != nil {
+	if err := h.approvalService.Create(&approval); err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
@@ -182,10 +246,14 @@ func (h *ApprovalHandler) CreateApproval(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Approval ID"
-// @Param approval body model.MstApproval true "Updated approval details"
+// @Param approval body dto.ApprovalRequest true "Updated approval details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Approval updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Approval not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/approval/{id} [put]
 func (h *ApprovalHandler) UpdateApproval(c *fiber.Ctx) error {
@@ -202,14 +270,26 @@ func (h *ApprovalHandler) UpdateApproval(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Approval not found"))
 	}
 
-	if err := c.BodyParser(approval); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	if ok, err := etag.Check(c, approval); !ok {
+		return err
+	}
+
+	var input dto.ApprovalRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	input.Apply(approval)
+
 	approval.ID = uint(ID)
 	approval.IDUpdatedby = userID
 
 	err = h.approvalService.Update(approval)
+	if errors.Is(err, service.ErrStale) {
+		return etag.Stale(c, func() (interface{}, error) {
+			return h.approvalService.GetByID(uint(ID))
+		})
+	}
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -242,6 +322,8 @@ func (h *ApprovalHandler) DeleteApproval(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Approval not found"))
 	}
 
+	user.IDUpdatedby = c.Locals("userID").(uint)
+
 	err = h.approvalService.Delete(user)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
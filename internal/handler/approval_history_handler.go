@@ -1,7 +1,7 @@
 package handler
 
 import (
-	"insist-backend-golang/internal/model"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
 	"math"
@@ -26,6 +26,7 @@ func NewApprovalHistoryHandler(approvalHistoryService *service.ApprovalHistorySe
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering approvalHistory"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -38,12 +39,22 @@ func (h *ApprovalHistoryHandler) GetApprovalHistories(c *fiber.Ctx) error {
 	sortDirection := c.QueryBool("sortDirection")
 	offset := (page - 1) * rows
 
-	total, err := h.approvalHistoryService.GetTotal(search)
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.ApprovalHistoryFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.ApprovalHistoryFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.approvalHistoryService.GetTotal(search, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	approvalHistorys, err := h.approvalHistoryService.GetAll(offset, rows, search, sortBy, sortDirection)
+	approvalHistorys, err := h.approvalHistoryService.GetAll(offset, rows, search, sorts, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -116,114 +127,6 @@ func (h *ApprovalHistoryHandler) GetApprovalHistory(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusOK, "Approval History found successfully", approvalHistory)
 }
 
-// CreateApprovalHistory godoc
-// @Summary Create a new approvalHistory
-// @Description Create a new approvalHistory with the provided details
-// @Tags Approval History
-// @Accept json
-// @Produce json
-// @Param approvalHistory body model.ApprovalHistory true "Approval History details"
-// @Success 201 {object} map[string]interface{} "Approval History created successfully"
-// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
-// @Failure 500 {object} map[string]interface{} "Internal Server Error"
-// @Router /admin/approval-history [post]
-func (h *ApprovalHistoryHandler) CreateApprovalHistory(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var approvalHistory model.ApprovalHistory
-	if err := c.BodyParser(&approvalHistory); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	approvalHistory.IDCreatedby = userID
-
-	err := h.approvalHistoryService.Create(&approvalHistory)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": approvalHistory.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusCreated, "Approval History created successfully", result)
-}
-
-// UpdateApprovalHistory godoc
-// @Summary Update an existing approvalHistory
-// @Description Update the details of an existing approvalHistory by its ID
-// @Tags Approval History
-// @Accept json
-// @Produce json
-// @Param id path int true "Approval History ID"
-// @Param approvalHistory body model.ApprovalHistory true "Updated approvalHistory details"
-// @Success 200 {object} map[string]interface{} "Approval History updated successfully"
-// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
-// @Failure 404 {object} map[string]interface{} "Not Found: ApprovalHistory not found"
-// @Failure 500 {object} map[string]interface{} "Internal Server Error"
-// @Router /admin/approval-history/{id} [put]
-func (h *ApprovalHistoryHandler) UpdateApprovalHistory(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	var approvalHistory *model.ApprovalHistory
-	approvalHistory, err = h.approvalHistoryService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Approval History not found"))
-	}
-
-	if err := c.BodyParser(approvalHistory); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	approvalHistory.ID = uint(ID)
-	approvalHistory.IDCreatedby = userID
-
-	err = h.approvalHistoryService.Update(approvalHistory)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": approvalHistory.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Approval History updated successfully", result)
-}
-
-// DeleteApprovalHistory godoc
-// @Summary Delete a approvalHistory
-// @Description Delete a approvalHistory by its ID
-// @Tags Approval History
-// @Param id path int true "Approval History ID"
-// @Success 200 {object} map[string]interface{} "Approval History deleted successfully"
-// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
-// @Failure 404 {object} map[string]interface{} "Not Found: ApprovalHistory not found"
-// @Failure 500 {object} map[string]interface{} "Internal Server Error"
-// @Router /admin/approval-history/{id} [delete]
-func (h *ApprovalHistoryHandler) DeleteApprovalHistory(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	user, err := h.approvalHistoryService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Approval History not found"))
-	}
-
-	err = h.approvalHistoryService.Delete(user)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Approval History deleted successfully", nil)
-}
-
 // GetApprovalNotifications godoc
 // @Summary Get approval notifications for the logged-in user
 // @Description Retrieve approval notifications based on the authenticated user's ID
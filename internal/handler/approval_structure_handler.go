@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
 	"math"
@@ -25,6 +26,7 @@ func NewApprovalStructureHandler(approvalStructureService *service.ApprovalStruc
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering approval structure"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -37,12 +39,22 @@ func (h *ApprovalStructureHandler) GetApprovalStructures(c *fiber.Ctx) error {
 	sortDirection := c.QueryBool("sortDirection")
 	offset := (page - 1) * rows
 
-	total, err := h.approvalStructureService.GetTotal(search)
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.ApprovalStructureFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.ApprovalStructureFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.approvalStructureService.GetTotal(search, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	approvalStructures, err := h.approvalStructureService.GetAll(offset, rows, search, sortBy, sortDirection)
+	approvalStructures, err := h.approvalStructureService.GetAll(offset, rows, search, sorts, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
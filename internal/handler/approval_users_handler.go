@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
@@ -26,10 +29,10 @@ func NewApprovalUserHandler(approvalUserService *service.ApprovalUserService) *A
 // @Accept json
 // @Produce json
 // @Param id path int true "menu ID"
-// @Success 200 {object} map[string]interface{} "Approval Users found successfully"
+// @Success 200 {object} map[string]interface{} "Approval Users found successfully, with the ETag of the approval"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID Approval"
 // @Failure 404 {object} map[string]interface{} "Not Found: Approval Users not found"
-// @Router /admin/approval-user/{id} [get]
+// @Router /admin/approval-user/{id}/approval [get]
 func (h *ApprovalUserHandler) GetApprovalUsersByIdApproval(c *fiber.Ctx) error {
 	ID, err := c.ParamsInt("id")
 	if err != nil {
@@ -41,6 +44,15 @@ func (h *ApprovalUserHandler) GetApprovalUsersByIdApproval(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Approval Users not found"))
 	}
 
+	// The ETag is the version of the approval, which replacing its users
+	// moves. It leaves out the users themselves, so it does not answer
+	// If-None-Match.
+	if version, err := h.approvalUserService.GetApproval(uint(ID)); err == nil {
+		if tag := etag.Of(version); tag != "" {
+			c.Set(fiber.HeaderETag, tag)
+		}
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Approval Users found successfully", approval)
 }
 
@@ -53,6 +65,7 @@ func (h *ApprovalUserHandler) GetApprovalUsersByIdApproval(c *fiber.Ctx) error {
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering approval users"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -65,12 +78,17 @@ func (h *ApprovalUserHandler) GetApprovalUsers(c *fiber.Ctx) error {
 
 	fmt.Println(page)
 
-	total, err := h.approvalUserService.GetTotal(search)
+	filters, err := filter.Parse(c, service.ApprovalUserFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.approvalUserService.GetTotal(search, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	approvalUsers, err := h.approvalUserService.GetAll(offset, rows, search)
+	approvalUsers, err := h.approvalUserService.GetAll(offset, rows, search, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -118,23 +136,37 @@ func (h *ApprovalUserHandler) GetApprovalUsers(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
 }
 
-// CreateApprovalUser godoc
-// @Summary Create a new approval users
-// @Description Create a new approval users with the provided details
+// UpdateApprovalUser godoc
+// @Summary Set the users of an approval
+// @Description Replace the users of an approval by its ID with the provided ones
 // @Tags Approval User
 // @Accept json
 // @Produce json
-// @Param approvalUser body model.MstApprovalUser true "Approval User details"
-// @Success 201 {object} map[string]interface{} "Approval User created successfully"
+// @Param id path int true "Approval ID"
+// @Param approvalUser body dto.ApprovalUsers true "Approval User details"
+// @Param If-Match header string true "ETag of the approval as read, the update is rejected when it changed since"
+// @Success 200 {object} map[string]interface{} "Approval User updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 404 {object} map[string]interface{} "Not Found: Approval not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
-// @Router /admin/approval-user [post]
+// @Router /admin/approval-user/{id} [put]
 func (h *ApprovalUserHandler) UpdateApprovalUser(c *fiber.Ctx) error {
 	ID, err := c.ParamsInt("id")
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
 	}
 
+	approval, err := h.approvalUserService.GetApproval(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Approval not found"))
+	}
+
+	if ok, err := etag.Check(c, approval); !ok {
+		return err
+	}
+
 	var input dto.ApprovalUsers
 	if err := c.BodyParser(&input); err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
@@ -152,11 +184,13 @@ func (h *ApprovalUserHandler) UpdateApprovalUser(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.approvalUserService.Delete(uint(ID)); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	err = h.approvalUserService.Replace(approval, approvalUsers)
+	if errors.Is(err, service.ErrStale) {
+		return etag.Stale(c, func() (interface{}, error) {
+			return h.approvalUserService.GetApproval(uint(ID))
+		})
 	}
-
-	if err := h.approvalUserService.Create(approvalUsers); err != nil {
+	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"errors"
+	"insist-backend-golang/internal/filter"
+	"insist-backend-golang/internal/service"
+	"insist-backend-golang/pkg"
+	"math"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// GetAudits godoc
+// @Summary Get a list of audit logs
+// @Description Retrieves audit logs of master data changes with pagination, filtered by table, record, user, action and date range
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param rows query int false "Number of rows per page" default(20)
+// @Param table query string false "Table name"
+// @Param recordID query int false "Record ID"
+// @Param userID query int false "User ID"
+// @Param action query string false "Action (create, update, delete)"
+// @Param rangeDate query string false "Date range, e.g. 2025-01-01~2025-01-31"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 404 {object} map[string]interface{} "Not Found: No data found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/audit [get]
+func (h *AuditHandler) GetAudits(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	rows := c.QueryInt("rows", 20)
+	tableName := c.Query("table", "")
+	recordID := c.QueryInt("recordID", 0)
+	userID := c.QueryInt("userID", 0)
+	action := c.Query("action", "")
+	rangeDate := c.Query("rangeDate", "")
+	sortBy := c.Query("sortBy", "")
+	sortDirection := c.QueryBool("sortDirection")
+	offset := (page - 1) * rows
+
+	arrayDate := strings.Split(rangeDate, "~")
+
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.AuditFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.AuditFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if filter.CursorMode(c) {
+		audits, next, err := h.auditService.GetPage(c.Query("after"), rows, tableName, uint(recordID), uint(userID), action, sorts, arrayDate, filters)
+		if errors.Is(err, filter.ErrInvalidCursor) {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+		}
+		if err != nil {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+		}
+
+		var nextCursor *string
+		if next != "" {
+			nextCursor = &next
+		}
+
+		result := map[string]interface{}{
+			"items": audits,
+			"pagination": map[string]interface{}{
+				"rows_per_page": rows,
+				"next_cursor":   nextCursor,
+			},
+		}
+
+		return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+	}
+
+	total, err := h.auditService.GetTotal(tableName, uint(recordID), uint(userID), action, arrayDate, filters)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	audits, err := h.auditService.GetAll(offset, rows, tableName, uint(recordID), uint(userID), action, sorts, arrayDate, filters)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(rows)))
+
+	var start *int
+	if int(total) == 0 {
+		start = nil
+	} else {
+		value := offset + 1
+		start = &value
+	}
+
+	var end *int
+	if int(total) == 0 {
+		end = nil
+	} else {
+		value := int(math.Min(float64(offset+rows), float64(total)))
+		end = &value
+	}
+	var nextPage *int
+	if page < totalPages {
+		nextPageVal := page + 1
+		nextPage = &nextPageVal
+	}
+
+	result := map[string]interface{}{
+		"items": audits,
+		"pagination": map[string]interface{}{
+			"current_page":  page,
+			"next_page":     nextPage,
+			"total_pages":   totalPages,
+			"rows_per_page": rows,
+			"total_rows":    total,
+			"from":          start,
+			"to":            end,
+		},
+	}
+
+	if len(audits) == 0 {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+}
+
+// GetAudit godoc
+// @Summary Get audit log by ID
+// @Description Retrieve a specific audit log with its field-level changes
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Param id path int true "Audit Log ID"
+// @Success 200 {object} map[string]interface{} "Audit log found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 404 {object} map[string]interface{} "Not Found: Audit log not found"
+// @Router /admin/audit/{id} [get]
+func (h *AuditHandler) GetAudit(c *fiber.Ctx) error {
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	audit, err := h.auditService.GetByID(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Audit log not found"))
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Audit log found successfully", audit)
+}
+
+// GetRecordHistory godoc
+// @Summary Get the change history of a record
+// @Description Retrieve every audited change of one record, newest first, for use on any master screen
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Param table path string true "Table name, e.g. mst_tax_codes"
+// @Param recordID path int true "Record ID"
+// @Success 200 {object} map[string]interface{} "Record history found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/audit/{table}/{recordID} [get]
+func (h *AuditHandler) GetRecordHistory(c *fiber.Ctx) error {
+	recordID, err := c.ParamsInt("recordID")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	audits, err := h.auditService.GetByRecord(c.Params("table"), uint(recordID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Record history found successfully", audits)
+}
@@ -1,46 +1,61 @@
 package handler
 
 import (
-	"fmt"
+	"encoding/base64"
+	"errors"
 	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
+	"log"
+	"math"
 	"os"
-	"path/filepath"
-	"strings"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/pquerna/otp"
-	"github.com/pquerna/otp/totp"
 	"github.com/skip2/go-qrcode"
 )
 
+const (
+	authActionLogin  = "login"
+	authActionTwoFa  = "two-fa"
+	authActionReauth = "two-fa-reauth"
+)
+
 type AuthHandler struct {
 	authService          *service.AuthService
 	passwordResetService *service.PasswordResetService
+	userSessionService   *service.UserSessionService
+	loginThrottleService *service.LoginThrottleService
+	mfaChallengeService  *service.MfaChallengeService
+	twoFactorService     *service.TwoFactorService
+	activityLogService   *service.ActivityLogService
 }
 
-func NewAuthHandler(authService *service.AuthService, passwordResetService *service.PasswordResetService) *AuthHandler {
+func NewAuthHandler(authService *service.AuthService, passwordResetService *service.PasswordResetService, userSessionService *service.UserSessionService, loginThrottleService *service.LoginThrottleService, mfaChallengeService *service.MfaChallengeService, twoFactorService *service.TwoFactorService, activityLogService *service.ActivityLogService) *AuthHandler {
 	return &AuthHandler{
 		authService:          authService,
 		passwordResetService: passwordResetService,
+		userSessionService:   userSessionService,
+		loginThrottleService: loginThrottleService,
+		mfaChallengeService:  mfaChallengeService,
+		twoFactorService:     twoFactorService,
+		activityLogService:   activityLogService,
 	}
 }
 
 // Login godoc
 // @Summary Login a user
-// @Description Login with username and password, returns access token
+// @Description Login with username and password, returns access token, or a challenge token to pass to /auth/two-fa when two-factor authentication is enabled
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param input body dto.UserLogin true "Login details"
-// @Success 200 {object} map[string]interface{} "Login Successfully"
+// @Success 200 {object} map[string]interface{} "Login Successfully or two-factor authentication required"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
-// @Failure 401 {object} map[string]interface{} "Unauthorized: Incorrect password, inactive user"
-// @Failure 403 {object} map[string]interface{} "Forbidden: Two-factor authentication required"
-// @Failure 404 {object} map[string]interface{} "Not Found: User not found"
+// @Failure 401 {object} map[string]interface{} "Unauthorized: Invalid username or password, inactive user"
+// @Failure 429 {object} map[string]interface{} "Too Many Requests: Too many failed attempts"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
@@ -49,34 +64,61 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
 	}
 
-	user, err := h.authService.GetByUsername(input.Username)
+	var user *model.MstUser
+	wait, valid, err := h.loginThrottleService.Attempt(input.Username, c.IP(), func() (bool, error) {
+		found, err := h.authService.GetByUsername(input.Username)
+		if err != nil {
+			return false, nil
+		}
+
+		user = found
+		return pkg.CheckPassword(input.Password, user.Password), nil
+	})
 	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "User not found"))
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	if !user.IsActive {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusUnauthorized, "User is not active"))
+	if wait > 0 {
+		return h.tooManyAttempts(c, authActionLogin, input.Username, wait)
 	}
 
-	if !pkg.CheckPassword(input.Password, user.Password) {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusUnauthorized, "Invalid password"))
+	if !valid {
+		return h.attemptRejected(c, authActionLogin, input.Username, user, "Invalid username or password")
+	}
+
+	if !user.IsActive {
+		h.recordAttempt(c, authActionLogin, input.Username, user, false, "User is not active")
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusUnauthorized, "User is not active"))
 	}
 
 	if user.IsTwoFa {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusForbidden, "Two-factor authentication is required"))
+		challenge, challengeToken, err := h.mfaChallengeService.Create(user.ID, input.Device)
+		if err != nil {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+		}
+
+		h.recordAttempt(c, authActionLogin, input.Username, user, true, "Two-factor authentication is required")
+
+		return pkg.Response(c, fiber.StatusOK, "Two-factor authentication is required", fiber.Map{
+			"two_fa_required": true,
+			"challenge_token": challengeToken,
+			"expired_at":      challenge.ExpiredAt,
+		})
 	}
 
-	accessToken, err := pkg.GenerateAccessToken(user.ID)
-	if err != nil {
+	if err := h.loginThrottleService.Succeed(input.Username); err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	refreshToken, err := pkg.GenerateRefreshToken(user.ID)
+	h.recordAttempt(c, authActionLogin, input.Username, user, true, "Login successfully")
+
+	session, refreshToken, err := h.userSessionService.Create(user.ID, input.Device, c.IP(), c.Get(fiber.HeaderUserAgent))
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	if err := h.authService.UpdateRefreshToken(user.ID, refreshToken); err != nil {
+	accessToken, err := pkg.GenerateAccessToken(user.ID, session.ID)
+	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
@@ -87,7 +129,7 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		Secure:   false,
 		SameSite: fiber.CookieSameSiteNoneMode,
 		Path:     "/",
-		Expires:  time.Now().Add(1 * 24 * time.Hour),
+		Expires:  time.Now().Add(pkg.RefreshTokenTTL),
 	})
 
 	return pkg.Response(c, fiber.StatusOK, "You have successfully logged in", fiber.Map{
@@ -97,15 +139,15 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 
 // TwoFactorAuth godoc
 // @Summary Verify Two-Factor Authentication
-// @Description Validate the user's two-factor authentication using an OTP key
+// @Description Complete a login by validating the challenge token returned by /auth/login together with an OTP key or a one-time recovery code
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param input body dto.TwoFactorAuth true "Two-factor authentication details"
 // @Success 200 {object} map[string]interface{} "Two-factor authentication successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
-// @Failure 401 {object} map[string]interface{} "Unauthorized: Invalid OTP"
-// @Failure 404 {object} map[string]interface{} "Not Found: User not found"
+// @Failure 401 {object} map[string]interface{} "Unauthorized: Invalid challenge or OTP"
+// @Failure 429 {object} map[string]interface{} "Too Many Requests: Too many failed attempts"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /auth/two-fa [post]
 func (h *AuthHandler) TwoFactorAuth(c *fiber.Ctx) error {
@@ -114,37 +156,77 @@ func (h *AuthHandler) TwoFactorAuth(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
 	}
 
-	user, err := h.authService.GetByUsername(input.Username)
+	challenge, err := h.mfaChallengeService.GetValid(input.ChallengeToken)
 	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "User not found"))
+		if errors.Is(err, service.ErrMfaChallengeInvalid) {
+			return h.attemptFailed(c, authActionTwoFa, "", nil, "Invalid or expired two-factor challenge")
+		}
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	valid, err := totp.ValidateCustom(input.OtpKey, user.OtpKey, time.Now(), totp.ValidateOpts{
-		Period:    30,
-		Skew:      0,
-		Digits:    otp.DigitsSix,
-		Algorithm: otp.AlgorithmSHA1,
-	})
+	user, err := h.authService.GetByID(challenge.IDUser)
+	if err != nil {
+		return h.attemptFailed(c, authActionTwoFa, "", nil, "Invalid or expired two-factor challenge")
+	}
+
+	if !user.IsActive {
+		h.recordAttempt(c, authActionTwoFa, user.Username, user, false, "User is not active")
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusUnauthorized, "User is not active"))
+	}
 
+	var message string
+	wait, valid, err := h.loginThrottleService.Attempt(user.Username, c.IP(), func() (bool, error) {
+		var otpStep int64
+		if input.RecoveryCode == "" {
+			var valid bool
+			otpStep, valid = pkg.ValidateTOTP(input.OtpKey, user.OtpKey, time.Now())
+			if user.OtpKey == "" || !valid {
+				message = "Invalid OTP"
+				return false, nil
+			}
+		}
+
+		if err := h.mfaChallengeService.Complete(challenge, otpStep, input.RecoveryCode); err != nil {
+			switch {
+			case errors.Is(err, service.ErrOtpReused):
+				message = "OTP has already been used"
+			case errors.Is(err, service.ErrRecoveryCodeInvalid):
+				message = "Invalid recovery code"
+			case errors.Is(err, service.ErrMfaChallengeInvalid):
+				message = "Invalid or expired two-factor challenge"
+			default:
+				return false, err
+			}
+			return false, nil
+		}
+
+		return true, nil
+	})
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
+	if wait > 0 {
+		return h.tooManyAttempts(c, authActionTwoFa, user.Username, wait)
+	}
+
 	if !valid {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusUnauthorized, "Invalid OTP"))
+		return h.attemptRejected(c, authActionTwoFa, user.Username, user, message)
 	}
 
-	accessToken, err := pkg.GenerateAccessToken(user.ID)
-	if err != nil {
+	if err := h.loginThrottleService.Succeed(user.Username); err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	refreshToken, err := pkg.GenerateRefreshToken(user.ID)
+	h.recordAttempt(c, authActionTwoFa, user.Username, user, true, "Two-factor authentication successfully")
+
+	session, refreshToken, err := h.userSessionService.Create(user.ID, challenge.Device, c.IP(), c.Get(fiber.HeaderUserAgent))
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	if err := h.authService.UpdateRefreshToken(user.ID, refreshToken); err != nil {
+	accessToken, err := pkg.GenerateAccessToken(user.ID, session.ID)
+	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
@@ -155,7 +237,7 @@ func (h *AuthHandler) TwoFactorAuth(c *fiber.Ctx) error {
 		Secure:   true,
 		SameSite: fiber.CookieSameSiteStrictMode,
 		Path:     "/",
-		Expires:  time.Now().Add(1 * 24 * time.Hour),
+		Expires:  time.Now().Add(pkg.RefreshTokenTTL),
 	})
 
 	return pkg.Response(c, fiber.StatusOK, "You have successfully logged in", fiber.Map{
@@ -165,7 +247,7 @@ func (h *AuthHandler) TwoFactorAuth(c *fiber.Ctx) error {
 
 // RefreshToken godoc
 // @Summary Refresh access token
-// @Description Renew the access token using a valid refresh token from cookies
+// @Description Renew the access token using a valid refresh token from cookies; the refresh token is rotated on every call
 // @Tags Authentication
 // @Accept json
 // @Produce json
@@ -181,12 +263,12 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusUnauthorized, "Missing refresh token"))
 	}
 
-	userID, err := pkg.VerifyRefreshToken(refreshToken)
+	claims, err := pkg.VerifyRefreshToken(refreshToken)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusUnauthorized, "Invalid refresh token"))
 	}
 
-	user, err := h.authService.GetByID(userID)
+	user, err := h.authService.GetByID(claims.UserID)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "User not found"))
 	}
@@ -195,11 +277,32 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusUnauthorized, "User is not active"))
 	}
 
-	accessToken, err := pkg.GenerateAccessToken(user.ID)
+	session, newRefreshToken, err := h.userSessionService.Rotate(claims, refreshToken, c.IP(), c.Get(fiber.HeaderUserAgent))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSessionReused):
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusUnauthorized, "Refresh token reuse detected, please log in again"))
+		case errors.Is(err, service.ErrSessionNotFound), errors.Is(err, service.ErrSessionRevoked), errors.Is(err, service.ErrSessionExpired):
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusUnauthorized, "Invalid refresh token"))
+		}
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	accessToken, err := pkg.GenerateAccessToken(user.ID, session.ID)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
+	c.Cookie(&fiber.Cookie{
+		Name:     "refresh_token",
+		Value:    newRefreshToken,
+		HTTPOnly: true,
+		Secure:   false,
+		SameSite: fiber.CookieSameSiteNoneMode,
+		Path:     "/",
+		Expires:  time.Now().Add(pkg.RefreshTokenTTL),
+	})
+
 	return pkg.Response(c, fiber.StatusOK, "Access token renewed successfully", fiber.Map{
 		"access_token": accessToken,
 	})
@@ -207,7 +310,7 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 
 // Logout godoc
 // @Summary Logout a user
-// @Description Log out the user by invalidating the refresh token
+// @Description Log out the user by revoking the session of the refresh token
 // @Tags Authentication
 // @Accept json
 // @Produce json
@@ -223,17 +326,18 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusUnauthorized, "Missing refresh token"))
 	}
 
-	userID, err := pkg.VerifyRefreshToken(refreshToken)
+	claims, err := pkg.VerifyRefreshToken(refreshToken)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusUnauthorized, "Invalid refresh token"))
 	}
 
-	_, err = h.authService.GetByID(userID)
+	_, err = h.authService.GetByID(claims.UserID)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "User not found"))
 	}
 
-	if err := h.authService.UpdateRefreshToken(userID, ""); err != nil {
+	err = h.userSessionService.Revoke(claims.UserID, claims.SessionID, service.SessionRevokedLogout)
+	if err != nil && !errors.Is(err, service.ErrSessionNotFound) {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
@@ -269,12 +373,12 @@ func (h *AuthHandler) GetUserInfo(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "User not found"))
 	}
 
-	return pkg.Response(c, fiber.StatusOK, "User information", user)
+	return pkg.Response(c, fiber.StatusOK, "User information", user.View())
 }
 
 // ChangePasswordAuth godoc
 // @Summary Change user password auth
-// @Description Change the password for the authenticated user
+// @Description Change the password for the authenticated user and sign out all other sessions
 // @Tags Authentication
 // @Accept json
 // @Produce json
@@ -311,92 +415,138 @@ func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
+	if err := h.userSessionService.RevokeByUser(userID, currentSessionID(c), service.SessionRevokedPasswordChange); err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Password changed successfully", nil)
 }
 
-// SetTwoFactorAuth godoc
-// @Summary Set up two-factor authentication for a user
-// @Description Configures two-factor authentication and generates an OTP key for the specified user
+// EnrollTwoFactorAuth godoc
+// @Summary Start two-factor authentication enrollment
+// @Description Generates a pending OTP key for the logged-in user and returns it with a QR code data URI
 // @Tags Authentication
 // @Accept json
 // @Produce json
-// @Param id path int true "User ID"
-// @Success 200 {object} map[string]interface{} "Two-factor authentication update successfully"
-// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Success 200 {object} map[string]interface{} "Two-factor authentication enrolled successfully"
 // @Failure 404 {object} map[string]interface{} "Not Found: User not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Two-factor authentication already active"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
-// @Router /auth/{id}/two-fa [put]
-func (h *AuthHandler) SetTwoFactorAuth(c *fiber.Ctx) error {
-	userID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
+// @Router /auth/two-fa/enroll [post]
+func (h *AuthHandler) EnrollTwoFactorAuth(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
 
-	user, err := h.authService.GetByID(uint(userID))
+	user, err := h.authService.GetByID(userID)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "User not found"))
 	}
 
-	if user.Email == "" {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, "Email not found"))
+	key, err := h.twoFactorService.Enroll(user)
+	if err != nil {
+		return twoFactorErrorResponse(c, err)
 	}
 
-	err = h.authService.UpdateTwoFactorAuth(uint(userID), true)
+	qrCode, err := qrcode.Encode(key.URL(), qrcode.Medium, 256)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	if user.OtpKey != "" {
-		result := map[string]interface{}{
-			"otp_key": user.OtpKey,
-			"otp_url": user.OtpUrl,
-		}
+	result := map[string]interface{}{
+		"otp_key": key.Secret(),
+		"otp_url": key.URL(),
+		"qr_code": "data:image/png;base64," + base64.StdEncoding.EncodeToString(qrCode),
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Two-factor authentication enrolled successfully", result)
+}
 
-		return pkg.Response(c, fiber.StatusOK, "Two-factor authentication already set", result)
+// ActivateTwoFactorAuth godoc
+// @Summary Activate two-factor authentication
+// @Description Confirms the pending OTP key with a first code, activates two-factor authentication and returns one-time recovery codes
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param input body dto.OTPKey true "First OTP code"
+// @Success 200 {object} map[string]interface{} "Two-factor authentication activated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or not enrolled"
+// @Failure 401 {object} map[string]interface{} "Unauthorized: Invalid OTP"
+// @Failure 409 {object} map[string]interface{} "Conflict: Two-factor authentication already active"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /auth/two-fa/activate [post]
+func (h *AuthHandler) ActivateTwoFactorAuth(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input dto.OTPKey
+	if err := c.BodyParser(&input); err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
 	}
 
-	key, err := totp.Generate(totp.GenerateOpts{
-		Issuer:      "INSIST",
-		AccountName: strings.ToUpper(user.Name),
-	})
+	user, err := h.authService.GetByID(userID)
 	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "User not found"))
 	}
 
-	user.OtpUrl = key.URL()
-	qrCode, err := qrcode.New(key.URL(), qrcode.Medium)
+	recoveryCodes, err := h.twoFactorService.Activate(user, input.OtpKey)
 	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+		return twoFactorErrorResponse(c, err)
 	}
 
-	fmt.Println(user.CreatedAt)
-	folderPath := "./public/images/qrcodeotp"
-	fileName := fmt.Sprintf("%s_%s.png", user.CreatedAt.Format("20060102"), key.Secret())
+	return pkg.Response(c, fiber.StatusOK, "Two-factor authentication activated successfully", fiber.Map{
+		"recovery_codes": recoveryCodes,
+	})
+}
 
-	if _, err := os.Stat(folderPath); os.IsNotExist(err) {
-		err := os.MkdirAll(folderPath, os.ModePerm)
-		if err != nil {
-			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-		}
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Re-authenticates the logged-in user with password and a second factor, then replaces all recovery codes
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param input body dto.TwoFactorReauth true "Password and OTP or recovery code"
+// @Success 200 {object} map[string]interface{} "Recovery codes regenerated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or two-factor authentication not active"
+// @Failure 401 {object} map[string]interface{} "Unauthorized: Invalid password, OTP or recovery code"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /auth/two-fa/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	user, err := h.reauthenticate(c)
+	if user == nil {
+		return err
 	}
 
-	fullFilePath := filepath.Join(folderPath, fileName)
-	err = qrCode.WriteFile(256, fullFilePath)
+	recoveryCodes, err := h.twoFactorService.RegenerateRecoveryCodes(user)
 	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+		return twoFactorErrorResponse(c, err)
 	}
 
-	err = h.authService.UpdateTwoFactorAuthKey(uint(userID), key.Secret(), key.URL())
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	return pkg.Response(c, fiber.StatusOK, "Recovery codes regenerated successfully", fiber.Map{
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+// DisableTwoFactorAuth godoc
+// @Summary Disable two-factor authentication
+// @Description Re-authenticates the logged-in user with password and a second factor, then removes the OTP key and recovery codes
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param input body dto.TwoFactorReauth true "Password and OTP or recovery code"
+// @Success 200 {object} map[string]interface{} "Two-factor authentication disabled successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or two-factor authentication not active"
+// @Failure 401 {object} map[string]interface{} "Unauthorized: Invalid password, OTP or recovery code"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /auth/two-fa [delete]
+func (h *AuthHandler) DisableTwoFactorAuth(c *fiber.Ctx) error {
+	user, err := h.reauthenticate(c)
+	if user == nil {
+		return err
 	}
 
-	result := map[string]interface{}{
-		"otp_key": key.Secret(),
-		"otp_url": key.URL(),
+	if err := h.twoFactorService.Disable(user); err != nil {
+		return twoFactorErrorResponse(c, err)
 	}
 
-	return pkg.Response(c, fiber.StatusOK, "Two-factor authentication update successfully", result)
+	return pkg.Response(c, fiber.StatusOK, "Two-factor authentication disabled successfully", nil)
 }
 
 // SendPasswordReset godoc
@@ -442,7 +592,7 @@ func (h *AuthHandler) SendPasswordReset(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	template := pkg.TemplateSendOTP(user.Name, user.Username, user.OtpKey, token)
+	template := pkg.TemplateSendPasswordReset(user.Name, user.Username, token)
 
 	emailSender := pkg.NewEmailSender(os.Getenv("MAIL_SMTP"), 587, os.Getenv("MAIL_EMAIL"), os.Getenv("MAIL_PASSWORD"))
 	err = emailSender.SendEmail(user.Email, "Reset Password & Aktivasi 2FA", "text/html", template)
@@ -508,5 +658,184 @@ func (h *AuthHandler) PasswordReset(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
+	err = h.userSessionService.RevokeByUser(uint(passwordReset.IDUser), 0, service.SessionRevokedPasswordChange)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Password Reset Successfully", nil)
 }
+
+// GetSessions godoc
+// @Summary Get my active sessions
+// @Description Retrieve the active sessions of the logged-in user; the session of the current access token is flagged
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Sessions found successfully"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /auth/sessions [get]
+func (h *AuthHandler) GetSessions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	sessions, err := h.userSessionService.GetActiveByUser(userID)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	current := currentSessionID(c)
+	for i := range sessions {
+		sessions[i].IsCurrent = sessions[i].ID == current
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Sessions found successfully", sessions)
+}
+
+// RevokeSession godoc
+// @Summary Revoke one of my sessions
+// @Description Sign out one of the logged-in user's sessions, e.g. a lost or shared PC
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param id path int true "Session ID"
+// @Success 200 {object} map[string]interface{} "Session revoked successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 404 {object} map[string]interface{} "Not Found: Session not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := h.userSessionService.Revoke(userID, uint(ID), service.SessionRevokedByUser); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Session not found"))
+		}
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Session revoked successfully", nil)
+}
+
+func currentSessionID(c *fiber.Ctx) uint {
+	sessionID, _ := c.Locals("sessionID").(uint)
+	return sessionID
+}
+
+// attemptFailed counts a failed login or OTP attempt against the username and
+// IP and answers with the same message whether or not the user exists.
+func (h *AuthHandler) attemptFailed(c *fiber.Ctx, action string, username string, user *model.MstUser, message string) error {
+	if err := h.loginThrottleService.Fail(username, c.IP()); err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return h.attemptRejected(c, action, username, user, message)
+}
+
+// attemptRejected answers an attempt Attempt already counted as failed, with
+// the same message whether or not the user exists.
+func (h *AuthHandler) attemptRejected(c *fiber.Ctx, action string, username string, user *model.MstUser, message string) error {
+	h.recordAttempt(c, action, username, user, false, message)
+
+	return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusUnauthorized, message))
+}
+
+func (h *AuthHandler) tooManyAttempts(c *fiber.Ctx, action string, username string, wait time.Duration) error {
+	h.recordAttempt(c, action, username, nil, false, "Too many failed attempts")
+
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusTooManyRequests, "Too many failed attempts, please try again later"))
+}
+
+func (h *AuthHandler) recordAttempt(c *fiber.Ctx, action string, username string, user *model.MstUser, isSuccess bool, message string) {
+	activityLog := model.ActivityLog{
+		Username:  username,
+		IPAddress: c.IP(),
+		Source:    service.ActivityLogSourceAuth,
+		Action:    action,
+		IsSuccess: isSuccess,
+		Message:   message,
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+
+	if user != nil {
+		activityLog.IDUser = user.ID
+	}
+
+	if err := h.activityLogService.Record(&activityLog); err != nil {
+		log.Println("Error recording activity log:", err)
+	}
+}
+
+// reauthenticate checks the password and a second factor of the logged-in
+// user before a 2FA setting is changed. Failures count towards the lockout.
+// A nil user means the error response has already been written.
+func (h *AuthHandler) reauthenticate(c *fiber.Ctx) (*model.MstUser, error) {
+	userID := c.Locals("userID").(uint)
+
+	var input dto.TwoFactorReauth
+	if err := c.BodyParser(&input); err != nil {
+		return nil, pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	user, err := h.authService.GetByID(userID)
+	if err != nil {
+		return nil, pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "User not found"))
+	}
+
+	var message string
+	wait, valid, err := h.loginThrottleService.Attempt(user.Username, c.IP(), func() (bool, error) {
+		if !pkg.CheckPassword(input.Password, user.Password) {
+			message = "Invalid password"
+			return false, nil
+		}
+
+		if user.IsTwoFa {
+			if err := h.twoFactorService.VerifySecondFactor(user, input.OtpKey, input.RecoveryCode); err != nil {
+				switch {
+				case errors.Is(err, service.ErrOtpInvalid), errors.Is(err, service.ErrOtpReused),
+					errors.Is(err, service.ErrRecoveryCodeInvalid), errors.Is(err, service.ErrSecondFactorRequired):
+					message = err.Error()
+					return false, nil
+				}
+				return false, err
+			}
+		}
+
+		return true, nil
+	})
+	if err != nil {
+		return nil, pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	if wait > 0 {
+		return nil, h.tooManyAttempts(c, authActionReauth, user.Username, wait)
+	}
+
+	if !valid {
+		return nil, h.attemptRejected(c, authActionReauth, user.Username, user, message)
+	}
+
+	h.recordAttempt(c, authActionReauth, user.Username, user, true, "Re-authenticated successfully")
+
+	return user, nil
+}
+
+func twoFactorErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrTwoFaAlreadyActive):
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusConflict, "Two-factor authentication is already active"))
+	case errors.Is(err, service.ErrTwoFaNotActive):
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, "Two-factor authentication is not active"))
+	case errors.Is(err, service.ErrTwoFaNotEnrolled):
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, "Two-factor authentication has not been enrolled"))
+	case errors.Is(err, service.ErrOtpInvalid):
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusUnauthorized, "Invalid OTP"))
+	}
+
+	return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+}
@@ -1,95 +1,66 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// BankHandler documents the bank endpoints, which are served by crud.Resource.
 type BankHandler struct {
-	bankService *service.BankService
+	resource *crud.Resource[model.MstBank]
 }
 
-func NewBankHandler(bankService *service.BankService) *BankHandler {
-	return &BankHandler{bankService: bankService}
+// NewBankHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewBankHandler(db *gorm.DB, resource crud.Resource[model.MstBank]) *BankHandler {
+	resource.Input = func() dto.Input[model.MstBank] { return &dto.BankRequest{} }
+
+	return &BankHandler{resource: crud.New(db, resource)}
 }
 
 // GetBanks godoc
 // @Summary Get a list of banks
-// @Description Retrieves banks with pagination and optional search
+// @Description Retrieves banks by page or by cursor, with optional search, filters and sorting
 // @Tags Bank
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering bank"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /acf/master/bank [get]
 func (h *BankHandler) GetBanks(c *fiber.Ctx) error {
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.bankService.GetTotal(search)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	banks, err := h.bankService.GetAll(offset, rows, search, sortBy, sortDirection)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start *int
-	if int(total) == 0 {
-		start = nil
-	} else {
-		value := offset + 1
-		start = &value
-	}
-
-	var end *int
-	if int(total) == 0 {
-		end = nil
-	} else {
-		value := int(math.Min(float64(offset+rows), float64(total)))
-		end = &value
-	}
-
-	var nextPage *int
-	if page < totalPages {
-		nextPageVal := page + 1
-		nextPage = &nextPageVal
-	}
-
-	result := map[string]interface{}{
-		"items": banks,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(banks) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportBanks godoc
+// @Summary Export banks
+// @Description Downloads the banks matching the list parameters as an Excel or CSV file
+// @Tags Bank
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering bank"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /acf/master/bank/export [get]
+func (h *BankHandler) ExportBanks(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetBank godoc
@@ -99,22 +70,14 @@ func (h *BankHandler) GetBanks(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Bank ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Bank found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Bank not found"
 // @Router /acf/master/bank/{id} [get]
 func (h *BankHandler) GetBank(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	bank, err := h.bankService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Bank not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Bank found successfully", bank)
+	return h.resource.Get(c)
 }
 
 // CreateBank godoc
@@ -123,32 +86,33 @@ func (h *BankHandler) GetBank(c *fiber.Ctx) error {
 // @Tags Bank
 // @Accept json
 // @Produce json
-// @Param bank body model.MstBank true "Bank details"
+// @Param bank body dto.BankRequest true "Bank details"
 // @Success 201 {object} map[string]interface{} "Bank created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /acf/master/bank [post]
 func (h *BankHandler) CreateBank(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var bank model.MstBank
-	if err := c.BodyParser(&bank); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	bank.IDCreatedby = userID
-	bank.IDUpdatedby = userID
-
-	err := h.bankService.Create(&bank)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": bank.ID,
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "Bank created successfully", result)
+// BulkBanks godoc
+// @Summary Create, update and delete banks in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Bank
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.BankRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /acf/master/bank/bulk [post]
+func (h *BankHandler) BulkBanks(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateBank godoc
@@ -158,70 +122,31 @@ func (h *BankHandler) CreateBank(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Bank ID"
-// @Param bank body model.MstBank true "Updated bank details"
+// @Param bank body dto.BankRequest true "Updated bank details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Bank updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Bank not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /acf/master/bank/{id} [put]
 func (h *BankHandler) UpdateBank(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	var bank *model.MstBank
-	bank, err = h.bankService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Bank not found"))
-	}
-
-	if err := c.BodyParser(bank); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	bank.ID = uint(ID)
-	bank.IDUpdatedby = userID
-
-	err = h.bankService.Update(bank)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": bank.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Bank updated successfully", result)
+	return h.resource.Update(c)
 }
 
 // DeleteBank godoc
 // @Summary Delete a bank
-// @Description Delete a bank by its ID
+// @Description Soft delete a bank by its ID
 // @Tags Bank
 // @Param id path int true "Bank ID"
 // @Success 200 {object} map[string]interface{} "Bank deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Bank not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /acf/master/bank/{id} [delete]
 func (h *BankHandler) DeleteBank(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	user, err := h.bankService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Bank not found"))
-	}
-
-	err = h.bankService.Delete(user)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Bank deleted successfully", nil)
+	return h.resource.Delete(c)
 }
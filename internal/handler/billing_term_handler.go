@@ -1,95 +1,66 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// BillingTermHandler documents the billing term endpoints, which are served by crud.Resource.
 type BillingTermHandler struct {
-	billingTermService *service.BillingTermService
+	resource *crud.Resource[model.MstBillingTerm]
 }
 
-func NewBillingTermHandler(billingTermService *service.BillingTermService) *BillingTermHandler {
-	return &BillingTermHandler{billingTermService: billingTermService}
+// NewBillingTermHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewBillingTermHandler(db *gorm.DB, resource crud.Resource[model.MstBillingTerm]) *BillingTermHandler {
+	resource.Input = func() dto.Input[model.MstBillingTerm] { return &dto.BillingTermRequest{} }
+
+	return &BillingTermHandler{resource: crud.New(db, resource)}
 }
 
 // GetBillingTerms godoc
 // @Summary Get a list of billing terms
-// @Description Retrieves billing terms with pagination and optional search
+// @Description Retrieves billing terms by page or by cursor, with optional search, filters and sorting
 // @Tags BillingTerm
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering billing term"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/billing-term [get]
 func (h *BillingTermHandler) GetBillingTerms(c *fiber.Ctx) error {
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.billingTermService.GetTotal(search)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	billingTerms, err := h.billingTermService.GetAll(offset, rows, search, sortBy, sortDirection)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start *int
-	if int(total) == 0 {
-		start = nil
-	} else {
-		value := offset + 1
-		start = &value
-	}
-
-	var end *int
-	if int(total) == 0 {
-		end = nil
-	} else {
-		value := int(math.Min(float64(offset+rows), float64(total)))
-		end = &value
-	}
-
-	var nextPage *int
-	if page < totalPages {
-		nextPageVal := page + 1
-		nextPage = &nextPageVal
-	}
-
-	result := map[string]interface{}{
-		"items": billingTerms,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(billingTerms) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportBillingTerms godoc
+// @Summary Export billing terms
+// @Description Downloads the billing terms matching the list parameters as an Excel or CSV file
+// @Tags BillingTerm
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering billing term"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/billing-term/export [get]
+func (h *BillingTermHandler) ExportBillingTerms(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetBillingTerm godoc
@@ -98,23 +69,15 @@ func (h *BillingTermHandler) GetBillingTerms(c *fiber.Ctx) error {
 // @Tags BillingTerm
 // @Accept json
 // @Produce json
-// @Param id path int true "Billing Term ID"
-// @Success 200 {object} map[string]interface{} "Billing Term found successfully"
+// @Param id path int true "BillingTerm ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
+// @Success 200 {object} map[string]interface{} "BillingTerm found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
-// @Failure 404 {object} map[string]interface{} "Not Found: Billing Term not found"
+// @Failure 404 {object} map[string]interface{} "Not Found: BillingTerm not found"
 // @Router /general/master/billing-term/{id} [get]
 func (h *BillingTermHandler) GetBillingTerm(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	billingTerm, err := h.billingTermService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Billing Term not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Billing Term found successfully", billingTerm)
+	return h.resource.Get(c)
 }
 
 // CreateBillingTerm godoc
@@ -123,32 +86,33 @@ func (h *BillingTermHandler) GetBillingTerm(c *fiber.Ctx) error {
 // @Tags BillingTerm
 // @Accept json
 // @Produce json
-// @Param billingTerm body model.MstBillingTerm true "Billing Term details"
-// @Success 201 {object} map[string]interface{} "Billing Term created successfully"
+// @Param billing_term body dto.BillingTermRequest true "BillingTerm details"
+// @Success 201 {object} map[string]interface{} "BillingTerm created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/billing-term [post]
 func (h *BillingTermHandler) CreateBillingTerm(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var billingTerm model.MstBillingTerm
-	if err := c.BodyParser(&billingTerm); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	billingTerm.IDCreatedby = userID
-	billingTerm.IDUpdatedby = userID
-
-	err := h.billingTermService.Create(&billingTerm)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": billingTerm.ID,
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "Billing Term created successfully", result)
+// BulkBillingTerms godoc
+// @Summary Create, update and delete billing terms in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags BillingTerm
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.BillingTermRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/billing-term/bulk [post]
+func (h *BillingTermHandler) BulkBillingTerms(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateBillingTerm godoc
@@ -157,71 +121,32 @@ func (h *BillingTermHandler) CreateBillingTerm(c *fiber.Ctx) error {
 // @Tags BillingTerm
 // @Accept json
 // @Produce json
-// @Param id path int true "Billing Term ID"
-// @Param billingTerm body model.MstBillingTerm true "Updated billing term details"
-// @Success 200 {object} map[string]interface{} "Billing Term updated successfully"
+// @Param id path int true "BillingTerm ID"
+// @Param billing_term body dto.BillingTermRequest true "Updated billing term details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
+// @Success 200 {object} map[string]interface{} "BillingTerm updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
-// @Failure 404 {object} map[string]interface{} "Not Found: Billing Term not found"
+// @Failure 404 {object} map[string]interface{} "Not Found: BillingTerm not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/billing-term/{id} [put]
 func (h *BillingTermHandler) UpdateBillingTerm(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	var billingTerm *model.MstBillingTerm
-	billingTerm, err = h.billingTermService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Billing Term not found"))
-	}
-
-	if err := c.BodyParser(billingTerm); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	billingTerm.ID = uint(ID)
-	billingTerm.IDUpdatedby = userID
-
-	err = h.billingTermService.Update(billingTerm)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": billingTerm.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Billing Term updated successfully", result)
+	return h.resource.Update(c)
 }
 
 // DeleteBillingTerm godoc
 // @Summary Delete a billing term
-// @Description Delete a billing term by its ID
+// @Description Soft delete a billing term by its ID
 // @Tags BillingTerm
-// @Param id path int true "Billing Term ID"
-// @Success 200 {object} map[string]interface{} "Billing Term deleted successfully"
+// @Param id path int true "BillingTerm ID"
+// @Success 200 {object} map[string]interface{} "BillingTerm deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
-// @Failure 404 {object} map[string]interface{} "Not Found: Billing Term not found"
+// @Failure 404 {object} map[string]interface{} "Not Found: BillingTerm not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/billing-term/{id} [delete]
 func (h *BillingTermHandler) DeleteBillingTerm(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	user, err := h.billingTermService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Billing Term not found"))
-	}
-
-	err = h.billingTermService.Delete(user)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Billing Term deleted successfully", nil)
+	return h.resource.Delete(c)
 }
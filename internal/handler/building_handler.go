@@ -1,6 +1,12 @@
 package handler
 
 import (
+	"errors"
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/export"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
@@ -9,12 +15,24 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+var buildingExportColumns = []export.Column{
+	{Header: "Code", Field: "Code"},
+	{Header: "Description", Field: "Description"},
+	{Header: "Plant", Field: "Plant"},
+	{Header: "Remarks", Field: "Remarks"},
+	{Header: "Created By", Field: "CreatedBy.Name"},
+	{Header: "Created At", Field: "CreatedAt"},
+	{Header: "Updated By", Field: "UpdatedBy.Name"},
+	{Header: "Updated At", Field: "UpdatedAt"},
+}
+
 type BuildingHandler struct {
 	buildingService *service.BuildingService
+	bulk            *crud.Bulk[model.MstBuilding]
 }
 
-func NewBuildingHandler(buildingService *service.BuildingService) *BuildingHandler {
-	return &BuildingHandler{buildingService: buildingService}
+func NewBuildingHandler(buildingService *service.BuildingService, bulk *crud.Bulk[model.MstBuilding]) *BuildingHandler {
+	return &BuildingHandler{buildingService: buildingService, bulk: bulk}
 }
 
 // GetBuildings godoc
@@ -26,6 +44,8 @@ func NewBuildingHandler(buildingService *service.BuildingService) *BuildingHandl
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering building"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -39,13 +59,24 @@ func (h *BuildingHandler) GetBuildings(c *fiber.Ctx) error {
 	sortBy := c.Query("sortBy", "")
 	sortDirection := c.QueryBool("sortDirection")
 	offset := (page - 1) * rows
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.BuildingFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
 
-	total, err := h.buildingService.GetTotal(search, uint(idFCS), plant)
+	filters, err := filter.Parse(c, service.BuildingFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.buildingService.GetTotal(search, uint(idFCS), plant, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	buildings, err := h.buildingService.GetAll(offset, rows, search, sortBy, sortDirection, uint(idFCS), plant)
+	buildings, err := h.buildingService.GetAll(offset, rows, search, sorts, uint(idFCS), plant, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -93,6 +124,43 @@ func (h *BuildingHandler) GetBuildings(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
 }
 
+// ExportBuildings godoc
+// @Summary Export Buildings
+// @Description Downloads the buildings matching the list parameters as an Excel or CSV file
+// @Tags Building
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /prd/master/building/export [get]
+func (h *BuildingHandler) ExportBuildings(c *fiber.Ctx) error {
+	idFCS := c.QueryInt("idFCS")
+	plant := c.Query("plant")
+	search := c.Query("search")
+	sortBy := c.Query("sortBy", "")
+	sortDirection := c.QueryBool("sortDirection")
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.BuildingFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.BuildingFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := export.Write(c, "buildings", buildingExportColumns, func(offset, limit int) ([]model.MstBuilding, error) {
+		return h.buildingService.GetAll(offset, limit, search, sorts, uint(idFCS), plant, includeDeleted, filters)
+	}); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return nil
+}
+
 // GetBuilding godoc
 // @Summary Get building by ID
 // @Description Retrieve a specific building by its ID
@@ -100,7 +168,9 @@ func (h *BuildingHandler) GetBuildings(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Building ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Building found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Building not found"
 // @Router /prd/master/building/{id} [get]
@@ -115,6 +185,10 @@ func (h *BuildingHandler) GetBuilding(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Building not found"))
 	}
 
+	if etag.Set(c, building) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Building found successfully", building)
 }
 
@@ -124,19 +198,23 @@ func (h *BuildingHandler) GetBuilding(c *fiber.Ctx) error {
 // @Tags Building
 // @Accept json
 // @Produce json
-// @Param building body model.MstBuilding true "Building details"
+// @Param building body dto.BuildingRequest true "Building details"
 // @Success 201 {object} map[string]interface{} "Building created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /prd/master/building [post]
 func (h *BuildingHandler) CreateBuilding(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
-	var building model.MstBuilding
-	if err := c.BodyParser(&building); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	var input dto.BuildingRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	var building model.MstBuilding
+	input.Apply(&building)
+
 	building.IDCreatedby = userID
 	building.IDUpdatedby = userID
 
@@ -152,6 +230,24 @@ func (h *BuildingHandler) CreateBuilding(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusCreated, "Building created successfully", result)
 }
 
+// BulkBuildings godoc
+// @Summary Create, update and delete buildings in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Building
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.BuildingRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Building bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /prd/master/building/bulk [post]
+func (h *BuildingHandler) BulkBuildings(c *fiber.Ctx) error {
+	return h.bulk.Run(c)
+}
+
 // UpdateBuilding godoc
 // @Summary Update an existing building
 // @Description Update the details of an existing building by its ID
@@ -159,10 +255,14 @@ func (h *BuildingHandler) CreateBuilding(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Building ID"
-// @Param building body model.MstBuilding true "Updated building details"
+// @Param building body dto.BuildingRequest true "Updated building details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Building updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Building not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /prd/master/building/{id} [put]
 func (h *BuildingHandler) UpdateBuilding(c *fiber.Ctx) error {
@@ -179,14 +279,26 @@ func (h *BuildingHandler) UpdateBuilding(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Building not found"))
 	}
 
-	if err := c.BodyParser(building); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	if ok, err := etag.Check(c, building); !ok {
+		return err
+	}
+
+	var input dto.BuildingRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	input.Apply(building)
+
 	building.ID = uint(ID)
 	building.IDUpdatedby = userID
 
 	err = h.buildingService.Update(building)
+	if errors.Is(err, service.ErrStale) {
+		return etag.Stale(c, func() (interface{}, error) {
+			return h.buildingService.GetByID(uint(ID))
+		})
+	}
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -219,6 +331,8 @@ func (h *BuildingHandler) DeleteBuilding(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Building not found"))
 	}
 
+	user.IDUpdatedby = c.Locals("userID").(uint)
+
 	err = h.buildingService.Delete(user)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
@@ -1,95 +1,66 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// ChartOfAccountHandler documents the chart of account endpoints, which are served by crud.Resource.
 type ChartOfAccountHandler struct {
-	chartOfAccountService *service.ChartOfAccountService
+	resource *crud.Resource[model.MstChartOfAccount]
 }
 
-func NewChartOfAccountHandler(chartOfAccountService *service.ChartOfAccountService) *ChartOfAccountHandler {
-	return &ChartOfAccountHandler{chartOfAccountService: chartOfAccountService}
+// NewChartOfAccountHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewChartOfAccountHandler(db *gorm.DB, resource crud.Resource[model.MstChartOfAccount]) *ChartOfAccountHandler {
+	resource.Input = func() dto.Input[model.MstChartOfAccount] { return &dto.ChartOfAccountRequest{} }
+
+	return &ChartOfAccountHandler{resource: crud.New(db, resource)}
 }
 
 // GetChartOfAccounts godoc
 // @Summary Get a list of chart of accounts
-// @Description Retrieves chart of accounts with pagination and optional search
+// @Description Retrieves chart of accounts by page or by cursor, with optional search, filters and sorting
 // @Tags Chart Of Account
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering chart of account"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /acf/master/chart-of-account [get]
 func (h *ChartOfAccountHandler) GetChartOfAccounts(c *fiber.Ctx) error {
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.chartOfAccountService.GetTotal(search)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	chartOfAccounts, err := h.chartOfAccountService.GetAll(offset, rows, search, sortBy, sortDirection)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start *int
-	if int(total) == 0 {
-		start = nil
-	} else {
-		value := offset + 1
-		start = &value
-	}
-
-	var end *int
-	if int(total) == 0 {
-		end = nil
-	} else {
-		value := int(math.Min(float64(offset+rows), float64(total)))
-		end = &value
-	}
-
-	var nextPage *int
-	if page < totalPages {
-		nextPageVal := page + 1
-		nextPage = &nextPageVal
-	}
-
-	result := map[string]interface{}{
-		"items": chartOfAccounts,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(chartOfAccounts) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportChartOfAccounts godoc
+// @Summary Export chart of accounts
+// @Description Downloads the chart of accounts matching the list parameters as an Excel or CSV file
+// @Tags Chart Of Account
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering chart of account"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /acf/master/chart-of-account/export [get]
+func (h *ChartOfAccountHandler) ExportChartOfAccounts(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetChartOfAccount godoc
@@ -99,22 +70,14 @@ func (h *ChartOfAccountHandler) GetChartOfAccounts(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Chart Of Account ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Chart Of Account found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Chart Of Account not found"
 // @Router /acf/master/chart-of-account/{id} [get]
 func (h *ChartOfAccountHandler) GetChartOfAccount(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	chartOfAccount, err := h.chartOfAccountService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Chart Of Account not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Chart Of Account found successfully", chartOfAccount)
+	return h.resource.Get(c)
 }
 
 // CreateChartOfAccount godoc
@@ -123,32 +86,33 @@ func (h *ChartOfAccountHandler) GetChartOfAccount(c *fiber.Ctx) error {
 // @Tags Chart Of Account
 // @Accept json
 // @Produce json
-// @Param chartOfAccount body model.MstChartOfAccount true "Chart Of Account details"
+// @Param chart_of_account body dto.ChartOfAccountRequest true "Chart Of Account details"
 // @Success 201 {object} map[string]interface{} "Chart Of Account created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /acf/master/chart-of-account [post]
 func (h *ChartOfAccountHandler) CreateChartOfAccount(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var chartOfAccount model.MstChartOfAccount
-	if err := c.BodyParser(&chartOfAccount); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	chartOfAccount.IDCreatedby = userID
-	chartOfAccount.IDUpdatedby = userID
-
-	err := h.chartOfAccountService.Create(&chartOfAccount)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": chartOfAccount.ID,
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "Chart Of Account created successfully", result)
+// BulkChartOfAccounts godoc
+// @Summary Create, update and delete chart of accounts in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Chart Of Account
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.ChartOfAccountRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /acf/master/chart-of-account/bulk [post]
+func (h *ChartOfAccountHandler) BulkChartOfAccounts(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateChartOfAccount godoc
@@ -158,70 +122,31 @@ func (h *ChartOfAccountHandler) CreateChartOfAccount(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Chart Of Account ID"
-// @Param chartOfAccount body model.MstChartOfAccount true "Updated chart of account details"
+// @Param chart_of_account body dto.ChartOfAccountRequest true "Updated chart of account details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Chart Of Account updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Chart Of Account not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /acf/master/chart-of-account/{id} [put]
 func (h *ChartOfAccountHandler) UpdateChartOfAccount(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	var chartOfAccount *model.MstChartOfAccount
-	chartOfAccount, err = h.chartOfAccountService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Chart Of Account not found"))
-	}
-
-	if err := c.BodyParser(chartOfAccount); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	chartOfAccount.ID = uint(ID)
-	chartOfAccount.IDUpdatedby = userID
-
-	err = h.chartOfAccountService.Update(chartOfAccount)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": chartOfAccount.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Chart Of Account updated successfully", result)
+	return h.resource.Update(c)
 }
 
 // DeleteChartOfAccount godoc
 // @Summary Delete a chart of account
-// @Description Delete a chart of account by its ID
+// @Description Soft delete a chart of account by its ID
 // @Tags Chart Of Account
 // @Param id path int true "Chart Of Account ID"
 // @Success 200 {object} map[string]interface{} "Chart Of Account deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Chart Of Account not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /acf/master/chart-of-account/{id} [delete]
 func (h *ChartOfAccountHandler) DeleteChartOfAccount(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	user, err := h.chartOfAccountService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Chart Of Account not found"))
-	}
-
-	err = h.chartOfAccountService.Delete(user)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Chart Of Account deleted successfully", nil)
+	return h.resource.Delete(c)
 }
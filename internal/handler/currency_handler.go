@@ -2,7 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/export"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
@@ -12,12 +18,22 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+var currencyExportColumns = []export.Column{
+	{Header: "Currency", Field: "Currency"},
+	{Header: "Description", Field: "Description"},
+	{Header: "Created By", Field: "CreatedBy.Name"},
+	{Header: "Created At", Field: "CreatedAt"},
+	{Header: "Updated By", Field: "UpdatedBy.Name"},
+	{Header: "Updated At", Field: "UpdatedAt"},
+}
+
 type CurrencyHandler struct {
 	currencyService *service.CurrencyService
+	bulk            *crud.Bulk[model.MstCurrency]
 }
 
-func NewCurrencyHandler(currencyService *service.CurrencyService) *CurrencyHandler {
-	return &CurrencyHandler{currencyService: currencyService}
+func NewCurrencyHandler(currencyService *service.CurrencyService, bulk *crud.Bulk[model.MstCurrency]) *CurrencyHandler {
+	return &CurrencyHandler{currencyService: currencyService, bulk: bulk}
 }
 
 // GetCurrencies godoc
@@ -29,6 +45,8 @@ func NewCurrencyHandler(currencyService *service.CurrencyService) *CurrencyHandl
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering currency"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -40,13 +58,24 @@ func (h *CurrencyHandler) GetCurrencies(c *fiber.Ctx) error {
 	sortBy := c.Query("sortBy", "")
 	sortDirection := c.QueryBool("sortDirection")
 	offset := (page - 1) * rows
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.CurrencyFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
 
-	total, err := h.currencyService.GetTotal(search)
+	filters, err := filter.Parse(c, service.CurrencyFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.currencyService.GetTotal(search, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	currencies, err := h.currencyService.GetAll(offset, rows, search, sortBy, sortDirection)
+	currencies, err := h.currencyService.GetAll(offset, rows, search, sorts, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -95,6 +124,41 @@ func (h *CurrencyHandler) GetCurrencies(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
 }
 
+// ExportCurrencies godoc
+// @Summary Export Currencies
+// @Description Downloads the currencies matching the list parameters as an Excel or CSV file
+// @Tags Currency
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /acf/master/currency/export [get]
+func (h *CurrencyHandler) ExportCurrencies(c *fiber.Ctx) error {
+	search := c.Query("search")
+	sortBy := c.Query("sortBy", "")
+	sortDirection := c.QueryBool("sortDirection")
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.CurrencyFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.CurrencyFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := export.Write(c, "currencies", currencyExportColumns, func(offset, limit int) ([]model.MstCurrency, error) {
+		return h.currencyService.GetAll(offset, limit, search, sorts, includeDeleted, filters)
+	}); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return nil
+}
+
 // GetCurrency godoc
 // @Summary Get currency by ID
 // @Description Retrieve a specific currency by its ID
@@ -102,7 +166,9 @@ func (h *CurrencyHandler) GetCurrencies(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Currency ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Currency found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Currency not found"
 // @Router /acf/master/currency/{id} [get]
@@ -117,6 +183,10 @@ func (h *CurrencyHandler) GetCurrency(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Currency not found"))
 	}
 
+	if etag.Set(c, currency) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Currency found successfully", currency)
 }
 
@@ -126,19 +196,23 @@ func (h *CurrencyHandler) GetCurrency(c *fiber.Ctx) error {
 // @Tags Currency
 // @Accept json
 // @Produce json
-// @Param currency body model.MstCurrency true "Currency details"
+// @Param currency body dto.CurrencyRequest true "Currency details"
 // @Success 201 {object} map[string]interface{} "Currency created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /acf/master/currency [post]
 func (h *CurrencyHandler) CreateCurrency(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
-	var currency model.MstCurrency
-	if err := c.BodyParser(&currency); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	var input dto.CurrencyRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	var currency model.MstCurrency
+	input.Apply(&currency)
+
 	currency.IDCreatedby = userID
 	currency.IDUpdatedby = userID
 
@@ -154,6 +228,24 @@ func (h *CurrencyHandler) CreateCurrency(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusCreated, "Currency created successfully", result)
 }
 
+// BulkCurrencies godoc
+// @Summary Create, update and delete currencies in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Currency
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.CurrencyRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Currency bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /acf/master/currency/bulk [post]
+func (h *CurrencyHandler) BulkCurrencies(c *fiber.Ctx) error {
+	return h.bulk.Run(c)
+}
+
 // UpdateCurrency godoc
 // @Summary Update an existing currency
 // @Description Update the details of an existing currency by its ID
@@ -161,10 +253,14 @@ func (h *CurrencyHandler) CreateCurrency(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Currency ID"
-// @Param currency body model.MstCurrency true "Updated currency details"
+// @Param currency body dto.CurrencyRequest true "Updated currency details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Currency updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Currency not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /acf/master/currency/{id} [put]
 func (h *CurrencyHandler) UpdateCurrency(c *fiber.Ctx) error {
@@ -181,14 +277,26 @@ func (h *CurrencyHandler) UpdateCurrency(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Currency not found"))
 	}
 
-	if err := c.BodyParser(currency); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	if ok, err := etag.Check(c, currency); !ok {
+		return err
 	}
 
+	var input dto.CurrencyRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	input.Apply(currency)
+
 	currency.ID = uint(ID)
 	currency.IDUpdatedby = userID
 
 	err = h.currencyService.Update(currency)
+	if errors.Is(err, service.ErrStale) {
+		return etag.Stale(c, func() (interface{}, error) {
+			return h.currencyService.GetByID(uint(ID))
+		})
+	}
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -221,6 +329,8 @@ func (h *CurrencyHandler) DeleteCurrency(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Currency not found"))
 	}
 
+	user.IDUpdatedby = c.Locals("userID").(uint)
+
 	err = h.currencyService.Delete(user)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
@@ -1,6 +1,12 @@
 package handler
 
 import (
+	"errors"
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/export"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
@@ -9,12 +15,25 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+var currencyRateExportColumns = []export.Column{
+	{Header: "From Currency", Field: "FromCurrency.Currency"},
+	{Header: "To Currency", Field: "ToCurrency.Currency"},
+	{Header: "Buy Rate", Field: "BuyRate"},
+	{Header: "Sell Rate", Field: "SellRate"},
+	{Header: "Effective Date", Field: "EffectiveDate"},
+	{Header: "Created By", Field: "CreatedBy.Name"},
+	{Header: "Created At", Field: "CreatedAt"},
+	{Header: "Updated By", Field: "UpdatedBy.Name"},
+	{Header: "Updated At", Field: "UpdatedAt"},
+}
+
 type CurrencyRateHandler struct {
 	currencyService *service.CurrencyRateService
+	bulk            *crud.Bulk[model.MstCurrencyRate]
 }
 
-func NewCurrencyRateHandler(currencyService *service.CurrencyRateService) *CurrencyRateHandler {
-	return &CurrencyRateHandler{currencyService: currencyService}
+func NewCurrencyRateHandler(currencyService *service.CurrencyRateService, bulk *crud.Bulk[model.MstCurrencyRate]) *CurrencyRateHandler {
+	return &CurrencyRateHandler{currencyService: currencyService, bulk: bulk}
 }
 
 // GetCurrencyRates godoc
@@ -29,6 +48,8 @@ func NewCurrencyRateHandler(currencyService *service.CurrencyRateService) *Curre
 // @Param search query string false "Search keyword for filtering currency"
 // @Param sortBy query string false "Field to sort by"
 // @Param sortDirection query bool false "Sorting direction (true for ascending, false for descending)"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -41,13 +62,24 @@ func (h *CurrencyRateHandler) GetCurrencyRates(c *fiber.Ctx) error {
 	sortBy := c.Query("sortBy", "")
 	sortDirection := c.QueryBool("sortDirection")
 	offset := (page - 1) * rows
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.CurrencyRateFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
 
-	total, err := h.currencyService.GetTotal(uint(idCurrency), search)
+	filters, err := filter.Parse(c, service.CurrencyRateFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.currencyService.GetTotal(uint(idCurrency), search, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	currencyRates, err := h.currencyService.GetAll(uint(idCurrency), offset, rows, search, sortBy, sortDirection)
+	currencyRates, err := h.currencyService.GetAll(uint(idCurrency), offset, rows, search, sorts, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -86,6 +118,42 @@ func (h *CurrencyRateHandler) GetCurrencyRates(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
 }
 
+// ExportCurrencyRates godoc
+// @Summary Export Currency Rates
+// @Description Downloads the currency rates matching the list parameters as an Excel or CSV file
+// @Tags Currency Rate
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /acf/master/currency-rate/export [get]
+func (h *CurrencyRateHandler) ExportCurrencyRates(c *fiber.Ctx) error {
+	idCurrency := c.QueryInt("idCurrency", 0)
+	search := c.Query("search")
+	sortBy := c.Query("sortBy", "")
+	sortDirection := c.QueryBool("sortDirection")
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.CurrencyRateFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.CurrencyRateFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := export.Write(c, "currency_rates", currencyRateExportColumns, func(offset, limit int) ([]model.MstCurrencyRate, error) {
+		return h.currencyService.GetAll(uint(idCurrency), offset, limit, search, sorts, includeDeleted, filters)
+	}); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return nil
+}
+
 // GetCurrencyRate godoc
 // @Summary Get currency rate by ID
 // @Description Retrieve a specific currency rate by its ID
@@ -93,7 +161,9 @@ func (h *CurrencyRateHandler) GetCurrencyRates(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Currency Rate ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Currency Rate found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Currency Rate not found"
 // @Router /acf/master/currency-rate/{id} [get]
@@ -108,6 +178,10 @@ func (h *CurrencyRateHandler) GetCurrencyRate(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Currency Rate not found"))
 	}
 
+	if etag.Set(c, currency) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Currency Rate found successfully", currency)
 }
 
@@ -117,19 +191,23 @@ func (h *CurrencyRateHandler) GetCurrencyRate(c *fiber.Ctx) error {
 // @Tags Currency Rate
 // @Accept json
 // @Produce json
-// @Param currency_rate body model.MstCurrencyRate true "Currency Rate details"
+// @Param currency_rate body dto.CurrencyRateRequest true "Currency Rate details"
 // @Success 201 {object} map[string]interface{} "Currency Rate created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /acf/master/currency-rate [post]
 func (h *CurrencyRateHandler) CreateCurrencyRate(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
-	var currencyRate model.MstCurrencyRate
-	if err := c.BodyParser(&currencyRate); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	var input dto.CurrencyRateRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	var currencyRate model.MstCurrencyRate
+	input.Apply(&currencyRate)
+
 	currencyRate.IDCreatedby = userID
 	currencyRate.IDUpdatedby = userID
 
@@ -145,6 +223,24 @@ func (h *CurrencyRateHandler) CreateCurrencyRate(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusCreated, "Currency Rate created successfully", result)
 }
 
+// BulkCurrencyRates godoc
+// @Summary Create, update and delete currency rates in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Currency Rate
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.CurrencyRateRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Currency Rate bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /acf/master/currency-rate/bulk [post]
+func (h *CurrencyRateHandler) BulkCurrencyRates(c *fiber.Ctx) error {
+	return h.bulk.Run(c)
+}
+
 // UpdateCurrencyRate godoc
 // @Summary Update an existing currency
 // @Description Update the details of an existing currency rate by its ID
@@ -152,10 +248,14 @@ func (h *CurrencyRateHandler) CreateCurrencyRate(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Currency Rate ID"
-// @Param currency_rate body model.MstCurrencyRate true "Updated currency rate details"
+// @Param currency_rate body dto.CurrencyRateRequest true "Updated currency rate details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Currency Rate updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Currency Rate not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /acf/master/currency-rate/{id} [put]
 func (h *CurrencyRateHandler) UpdateCurrencyRate(c *fiber.Ctx) error {
@@ -172,14 +272,26 @@ func (h *CurrencyRateHandler) UpdateCurrencyRate(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Currency Rate not found"))
 	}
 
-	if err := c.BodyParser(currencyRate); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	if ok, err := etag.Check(c, currencyRate); !ok {
+		return err
 	}
 
+	var input dto.CurrencyRateRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	input.Apply(currencyRate)
+
 	currencyRate.ID = uint(ID)
 	currencyRate.IDUpdatedby = userID
 
 	err = h.currencyService.Update(currencyRate)
+	if errors.Is(err, service.ErrStale) {
+		return etag.Stale(c, func() (interface{}, error) {
+			return h.currencyService.GetByID(uint(ID))
+		})
+	}
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -212,6 +324,8 @@ func (h *CurrencyRateHandler) DeleteCurrencyRate(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Currency Rate not found"))
 	}
 
+	user.IDUpdatedby = c.Locals("userID").(uint)
+
 	err = h.currencyService.Delete(user)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"insist-backend-golang/internal/service"
+	"insist-backend-golang/pkg"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DependencyHandler struct {
+	dependencyService *service.DependencyService
+}
+
+func NewDependencyHandler(dependencyService *service.DependencyService) *DependencyHandler {
+	return &DependencyHandler{dependencyService: dependencyService}
+}
+
+// WhereUsed godoc
+// @Summary Get the records using a master record
+// @Description List the records that reference a master record, to see the impact before deleting it. Every master resource exposes this as GET /{resource}/{id}/where-used
+// @Tags Dependency
+// @Produce json
+// @Param id path int true "Record ID"
+// @Success 200 {object} map[string]interface{} "Where used found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+func (h *DependencyHandler) WhereUsed(resource interface{}) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ID, err := c.ParamsInt("id")
+		if err != nil {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+		}
+
+		whereUsed, err := h.dependencyService.GetWhereUsed(resource, uint(ID))
+		if err != nil {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+		}
+
+		return pkg.Response(c, fiber.StatusOK, "Where used found successfully", whereUsed)
+	}
+}
+
+// WhereUsedByNumber godoc
+// @Summary Get the records using an employee
+// @Description List the records that reference an employee by its number, like the work orders it is the technician of or logged labor on
+// @Tags Dependency
+// @Produce json
+// @Param number path string true "Employee number"
+// @Success 200 {object} map[string]interface{} "Where used found successfully"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/master/employee/{number}/where-used [get]
+func (h *DependencyHandler) WhereUsedByNumber(resource interface{}) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		whereUsed, err := h.dependencyService.GetWhereUsed(resource, c.Params("number"))
+		if err != nil {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+		}
+
+		return pkg.Response(c, fiber.StatusOK, "Where used found successfully", whereUsed)
+	}
+}
+
+// CheckDelete runs before a delete handler and stops the delete with 409,
+// listing the referencing records, while the record is still in use.
+func (h *DependencyHandler) CheckDelete(resource interface{}) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ID, err := c.ParamsInt("id")
+		if err != nil {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+		}
+
+		whereUsed, err := h.dependencyService.GetWhereUsed(resource, uint(ID))
+		if err != nil {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+		}
+
+		if len(whereUsed) > 0 {
+			return pkg.Response(c, fiber.StatusConflict, "Data is still used by other records", whereUsed)
+		}
+
+		return c.Next()
+	}
+}
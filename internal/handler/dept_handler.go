@@ -1,95 +1,66 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// DeptHandler documents the department endpoints, which are served by crud.Resource.
 type DeptHandler struct {
-	deptService *service.DeptService
+	resource *crud.Resource[model.MstDept]
 }
 
-func NewDeptHandler(deptService *service.DeptService) *DeptHandler {
-	return &DeptHandler{deptService: deptService}
+// NewDeptHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewDeptHandler(db *gorm.DB, resource crud.Resource[model.MstDept]) *DeptHandler {
+	resource.Input = func() dto.Input[model.MstDept] { return &dto.DeptRequest{} }
+
+	return &DeptHandler{resource: crud.New(db, resource)}
 }
 
 // GetDepts godoc
 // @Summary Get a list of departments
-// @Description Retrieves departments with pagination and optional search
+// @Description Retrieves departments by page or by cursor, with optional search, filters and sorting
 // @Tags Department
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering department"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/master/department [get]
 func (h *DeptHandler) GetDepts(c *fiber.Ctx) error {
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.deptService.GetTotal(search)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	depts, err := h.deptService.GetAll(offset, rows, search, sortBy, sortDirection)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start *int
-	if int(total) == 0 {
-		start = nil
-	} else {
-		value := offset + 1
-		start = &value
-	}
-
-	var end *int
-	if int(total) == 0 {
-		end = nil
-	} else {
-		value := int(math.Min(float64(offset+rows), float64(total)))
-		end = &value
-	}
-
-	var nextPage *int
-	if page < totalPages {
-		nextPageVal := page + 1
-		nextPage = &nextPageVal
-	}
-
-	result := map[string]interface{}{
-		"items": depts,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(depts) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportDepts godoc
+// @Summary Export departments
+// @Description Downloads the departments matching the list parameters as an Excel or CSV file
+// @Tags Department
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering department"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/master/department/export [get]
+func (h *DeptHandler) ExportDepts(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetDept godoc
@@ -99,22 +70,14 @@ func (h *DeptHandler) GetDepts(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Department ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Department found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Department not found"
 // @Router /admin/master/department/{id} [get]
 func (h *DeptHandler) GetDept(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	dept, err := h.deptService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Department not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Department found successfully", dept)
+	return h.resource.Get(c)
 }
 
 // CreateDept godoc
@@ -123,32 +86,33 @@ func (h *DeptHandler) GetDept(c *fiber.Ctx) error {
 // @Tags Department
 // @Accept json
 // @Produce json
-// @Param dept body model.MstDept true "Department details"
+// @Param department body dto.DeptRequest true "Department details"
 // @Success 201 {object} map[string]interface{} "Department created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/master/department [post]
 func (h *DeptHandler) CreateDept(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var dept model.MstDept
-	if err := c.BodyParser(&dept); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	dept.IDCreatedby = userID
-	dept.IDUpdatedby = userID
-
-	err := h.deptService.Create(&dept)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": dept.ID,
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "Department created successfully", result)
+// BulkDepts godoc
+// @Summary Create, update and delete departments in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Department
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.DeptRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/master/department/bulk [post]
+func (h *DeptHandler) BulkDepts(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateDept godoc
@@ -158,70 +122,31 @@ func (h *DeptHandler) CreateDept(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Department ID"
-// @Param dept body model.MstDept true "Updated department details"
+// @Param department body dto.DeptRequest true "Updated department details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Department updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Department not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/master/department/{id} [put]
 func (h *DeptHandler) UpdateDept(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	var dept *model.MstDept
-	dept, err = h.deptService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Department not found"))
-	}
-
-	if err := c.BodyParser(dept); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	dept.ID = uint(ID)
-	dept.IDUpdatedby = userID
-
-	err = h.deptService.Update(dept)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": dept.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Department updated successfully", result)
+	return h.resource.Update(c)
 }
 
 // DeleteDept godoc
 // @Summary Delete a department
-// @Description Delete a department by its ID
+// @Description Soft delete a department by its ID
 // @Tags Department
 // @Param id path int true "Department ID"
 // @Success 200 {object} map[string]interface{} "Department deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Department not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/master/department/{id} [delete]
 func (h *DeptHandler) DeleteDept(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	user, err := h.deptService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Department not found"))
-	}
-
-	err = h.deptService.Delete(user)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Department deleted successfully", nil)
+	return h.resource.Delete(c)
 }
@@ -3,6 +3,8 @@ package handler
 import (
 	"encoding/json"
 	"fmt"
+	"insist-backend-golang/internal/export"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
@@ -13,6 +15,18 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+var employeeExportColumns = []export.Column{
+	{Header: "Number", Field: "Number"},
+	{Header: "Name", Field: "Name"},
+	{Header: "Division", Field: "Division"},
+	{Header: "Department", Field: "Department"},
+	{Header: "Position", Field: "Position"},
+	{Header: "Active", Field: "IsActive"},
+	{Header: "Service", Field: "Service"},
+	{Header: "Education", Field: "Education"},
+	{Header: "Birthday", Field: "Birthday"},
+}
+
 type EmployeeHandler struct {
 	employeeService *service.EmployeeService
 }
@@ -30,6 +44,7 @@ func NewEmployeeHandler(employeeService *service.EmployeeService) *EmployeeHandl
 // @Param page query int false "Page number (default is 1)"
 // @Param rows query int false "Number of rows per page (default is 20)"
 // @Param search query string false "Search term for employee name or number"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid query parameters"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
@@ -43,12 +58,22 @@ func (h *EmployeeHandler) GetEmployees(c *fiber.Ctx) error {
 	sortDirection := c.QueryBool("sortDirection")
 	offset := (page - 1) * rows
 
-	total, err := h.employeeService.GetTotal(search)
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.EmployeeFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.EmployeeFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.employeeService.GetTotal(search, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	employees, err := h.employeeService.GetAll(offset, rows, search, sortBy, sortDirection)
+	employees, err := h.employeeService.GetAll(offset, rows, search, sorts, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -96,6 +121,40 @@ func (h *EmployeeHandler) GetEmployees(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
 }
 
+// ExportEmployees godoc
+// @Summary Export Employees
+// @Description Downloads the employees matching the list parameters as an Excel or CSV file
+// @Tags Employee
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/master/employee/export [get]
+func (h *EmployeeHandler) ExportEmployees(c *fiber.Ctx) error {
+	search := c.Query("search", "")
+	sortBy := c.Query("sortBy", "")
+	sortDirection := c.QueryBool("sortDirection")
+
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.EmployeeFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.EmployeeFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := export.Write(c, "employees", employeeExportColumns, func(offset, limit int) ([]model.MstEmployee, error) {
+		return h.employeeService.GetAll(offset, limit, search, sorts, filters)
+	}); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return nil
+}
+
 // GetEmployee godoc
 // @Summary Retrieve Employee by Number
 // @Description Fetch the details of a specific employee using their employee number
@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"insist-backend-golang/internal/dto"
-	"insist-backend-golang/internal/model"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
 	"math"
@@ -27,6 +30,7 @@ func NewFCSBuildingHandler(userRoleService *service.FCSBuildingService) *FCSBuil
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering FCS Building"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -37,12 +41,17 @@ func (h *FCSBuildingHandler) GetFCSBuildings(c *fiber.Ctx) error {
 	search := c.Query("search")
 	offset := (page - 1) * rows
 
-	total, err := h.userRoleService.GetTotal(search)
+	filters, err := filter.Parse(c, service.FCSBuildingFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.userRoleService.GetTotal(search, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	fcsBuilding, err := h.userRoleService.GetAll(offset, rows, search)
+	fcsBuilding, err := h.userRoleService.GetAll(offset, rows, search, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -97,7 +106,7 @@ func (h *FCSBuildingHandler) GetFCSBuildings(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "FCS Building ID"
-// @Success 200 {object} map[string]interface{} "FCS Building found successfully"
+// @Success 200 {object} map[string]interface{} "FCS Building found successfully, with the ETag of the FCS"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: FCS Building not found"
 // @Router /prd/master/fcs-building/{id} [get]
@@ -112,48 +121,103 @@ func (h *FCSBuildingHandler) GetFCSBuilding(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "FCS Building not found"))
 	}
 
+	// The ETag is the version of the FCS, which replacing its buildings
+	// moves. It leaves out the buildings themselves, so it does not answer
+	// If-None-Match.
+	if tag := etag.Of(userRole); tag != "" {
+		c.Set(fiber.HeaderETag, tag)
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "FCS Building found successfully", userRole)
 }
 
-// CreateFCSBuilding godoc
-// @Summary Create a new FCS Building
-// @Description Create a new FCS Building with the provided details
+// UpdateFCSBuilding godoc
+// @Summary Set the buildings of an FCS
+// @Description Replace the buildings of an FCS by its ID with the provided ones
 // @Tags FCS Building
 // @Accept json
 // @Produce json
+// @Param id path int true "FCS ID"
 // @Param FCSBuilding body dto.FCSBuildings true "FCS Building details"
+// @Param If-Match header string true "ETag of the FCS as read, the update is rejected when it changed since"
 // @Success 201 {object} map[string]interface{} "FCS Building created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 404 {object} map[string]interface{} "Not Found: FCS not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
-// @Router /prd/master/fcs-building [post]
+// @Router /prd/master/fcs-building/{id} [put]
 func (h *FCSBuildingHandler) UpdateFCSBuilding(c *fiber.Ctx) error {
 	ID, err := c.ParamsInt("id")
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
 	}
 
+	fcs, err := h.userRoleService.GetByID(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "FCS not found"))
+	}
+
+	if ok, err := etag.Check(c, fcs); !ok {
+		return err
+	}
+
 	var input dto.FCSBuildings
 	if err := c.BodyParser(&input); err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
 	}
 
-	var fcsBuilding []model.MstFCSBuilding
-	for _, buildingID := range input.IDBuilding {
-		fcsBuilding = append(fcsBuilding, model.MstFCSBuilding{
-			IDFCS:      uint(ID),
-			IDBuilding: buildingID,
+	fcsBuilding, err := h.userRoleService.Replace(fcs, input.IDBuilding)
+	if errors.Is(err, service.ErrStale) {
+		return etag.Stale(c, func() (interface{}, error) {
+			return h.userRoleService.GetByID(uint(ID))
 		})
 	}
-
-	err = h.userRoleService.Delete(uint(ID))
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	err = h.userRoleService.Create(&fcsBuilding)
+	return pkg.Response(c, fiber.StatusCreated, "FCS Building created successfully", fcsBuilding)
+}
+
+// BulkFCSBuildings godoc
+// @Summary Set the buildings of several FCS
+// @Description Applies the operations in order in a single transaction. An update operation replaces the buildings of the FCS of its id with the id_building of its data, a delete operation removes them. An update needs the etag the FCS was read with, a delete checks it when given. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags FCS Building
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations"
+// @Success 200 {object} map[string]interface{} "FCS Building bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /prd/master/fcs-building/bulk [post]
+func (h *FCSBuildingHandler) BulkFCSBuildings(c *fiber.Ctx) error {
+	var input dto.BulkRequest
+	if err := c.BodyParser(&input); err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if len(input.Operations) == 0 {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, "Operations are required"))
+	}
+
+	if maxSize := pkg.BulkMaxSize(); len(input.Operations) > maxSize {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("At most %d operations are allowed", maxSize)))
+	}
+
+	results, err := h.userRoleService.Bulk(input.Operations)
+	if errors.Is(err, service.ErrBulkConflict) {
+		return pkg.Response(c, fiber.StatusConflict, "An operation conflicts with an existing record, nothing was saved", results)
+	}
+	if errors.Is(err, service.ErrBulkFailed) {
+		return pkg.Response(c, fiber.StatusUnprocessableEntity, "An operation failed, nothing was saved", results)
+	}
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	return pkg.Response(c, fiber.StatusCreated, "FCS Building created successfully", fcsBuilding)
+	return pkg.Response(c, fiber.StatusOK, "FCS Building bulk operations applied successfully", results)
 }
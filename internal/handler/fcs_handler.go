@@ -1,94 +1,66 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// FCSHandler documents the fcs endpoints, which are served by crud.Resource.
 type FCSHandler struct {
-	fcsService *service.FCSService
+	resource *crud.Resource[model.MstFCS]
 }
 
-func NewFCSHandler(fcsService *service.FCSService) *FCSHandler {
-	return &FCSHandler{fcsService: fcsService}
+// NewFCSHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewFCSHandler(db *gorm.DB, resource crud.Resource[model.MstFCS]) *FCSHandler {
+	resource.Input = func() dto.Input[model.MstFCS] { return &dto.FCSRequest{} }
+
+	return &FCSHandler{resource: crud.New(db, resource)}
 }
 
 // GetFCSs godoc
 // @Summary Get a list of fcs
-// @Description Retrieves fcs with pagination and optional search
+// @Description Retrieves fcs by page or by cursor, with optional search, filters and sorting
 // @Tags FCS
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering fcs"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /prd/master/fcs [get]
 func (h *FCSHandler) GetFCSs(c *fiber.Ctx) error {
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.fcsService.GetTotal(search)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	fcs, err := h.fcsService.GetAll(offset, rows, search, sortBy, sortDirection)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start *int
-	if int(total) == 0 {
-		start = nil
-	} else {
-		value := offset + 1
-		start = &value
-	}
-
-	var end *int
-	if int(total) == 0 {
-		end = nil
-	} else {
-		value := int(math.Min(float64(offset+rows), float64(total)))
-		end = &value
-	}
-	var nextPage *int
-	if page < totalPages {
-		nextPageVal := page + 1
-		nextPage = &nextPageVal
-	}
-
-	result := map[string]interface{}{
-		"items": fcs,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(fcs) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportFCSs godoc
+// @Summary Export fcs
+// @Description Downloads the fcs matching the list parameters as an Excel or CSV file
+// @Tags FCS
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering fcs"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /prd/master/fcs/export [get]
+func (h *FCSHandler) ExportFCSs(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetFCS godoc
@@ -98,22 +70,14 @@ func (h *FCSHandler) GetFCSs(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "FCS ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "FCS found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: FCS not found"
 // @Router /prd/master/fcs/{id} [get]
 func (h *FCSHandler) GetFCS(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	fcs, err := h.fcsService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "FCS not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "FCS found successfully", fcs)
+	return h.resource.Get(c)
 }
 
 // CreateFCS godoc
@@ -122,32 +86,33 @@ func (h *FCSHandler) GetFCS(c *fiber.Ctx) error {
 // @Tags FCS
 // @Accept json
 // @Produce json
-// @Param fcs body model.MstFCS true "FCS details"
+// @Param fcs body dto.FCSRequest true "FCS details"
 // @Success 201 {object} map[string]interface{} "FCS created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /prd/master/fcs [post]
 func (h *FCSHandler) CreateFCS(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var fcs model.MstFCS
-	if err := c.BodyParser(&fcs); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	fcs.IDCreatedby = userID
-	fcs.IDUpdatedby = userID
-
-	err := h.fcsService.Create(&fcs)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": fcs.ID,
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "FCS created successfully", result)
+// BulkFCSs godoc
+// @Summary Create, update and delete fcs in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags FCS
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.FCSRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /prd/master/fcs/bulk [post]
+func (h *FCSHandler) BulkFCSs(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateFCS godoc
@@ -157,70 +122,31 @@ func (h *FCSHandler) CreateFCS(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "FCS ID"
-// @Param fcs body model.MstFCS true "Updated fcs details"
+// @Param fcs body dto.FCSRequest true "Updated fcs details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "FCS updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: FCS not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /prd/master/fcs/{id} [put]
 func (h *FCSHandler) UpdateFCS(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	var fcs *model.MstFCS
-	fcs, err = h.fcsService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "FCS not found"))
-	}
-
-	if err := c.BodyParser(fcs); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	fcs.ID = uint(ID)
-	fcs.IDUpdatedby = userID
-
-	err = h.fcsService.Update(fcs)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": fcs.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "FCS updated successfully", result)
+	return h.resource.Update(c)
 }
 
 // DeleteFCS godoc
 // @Summary Delete a fcs
-// @Description Delete a fcs by its ID
+// @Description Soft delete a fcs by its ID
 // @Tags FCS
 // @Param id path int true "FCS ID"
 // @Success 200 {object} map[string]interface{} "FCS deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: FCS not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /prd/master/fcs/{id} [delete]
 func (h *FCSHandler) DeleteFCS(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	user, err := h.fcsService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "FCS not found"))
-	}
-
-	err = h.fcsService.Delete(user)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "FCS deleted successfully", nil)
+	return h.resource.Delete(c)
 }
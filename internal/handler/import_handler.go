@@ -0,0 +1,314 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"insist-backend-golang/internal/export"
+	"insist-backend-golang/internal/filter"
+	"insist-backend-golang/internal/importer"
+	"insist-backend-golang/internal/model"
+	"insist-backend-golang/internal/service"
+	"insist-backend-golang/pkg"
+	"math"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var importErrorColumns = []export.Column{
+	{Header: "Row", Field: "Row"},
+	{Header: "Column", Field: "Column"},
+	{Header: "Value", Field: "Value"},
+	{Header: "Error", Field: "Message"},
+}
+
+type ImportHandler struct {
+	importService *service.ImportService
+}
+
+func NewImportHandler(importService *service.ImportService) *ImportHandler {
+	return &ImportHandler{importService: importService}
+}
+
+// GetImports godoc
+// @Summary Get a list of import jobs
+// @Description Retrieves the import jobs of the masters whose menu the user may read, with their status and row counts, newest first
+// @Tags Import
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param rows query int false "Number of rows per page" default(20)
+// @Param master query string false "Master, e.g. items"
+// @Param status query string false "Status (running, validated, failed, completed)"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
+// @Failure 404 {object} map[string]interface{} "Not Found: No data found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/import [get]
+func (h *ImportHandler) GetImports(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	page := c.QueryInt("page", 1)
+	rows := c.QueryInt("rows", 20)
+	master := c.Query("master", "")
+	status := c.Query("status", "")
+	sortBy := c.Query("sortBy", "")
+	sortDirection := c.QueryBool("sortDirection")
+	offset := (page - 1) * rows
+
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.ImportFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.ImportFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	masters, err := h.importService.ReadableMasters(userID)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	total, err := h.importService.GetTotal(masters, master, status, filters)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	jobs, err := h.importService.GetAll(offset, rows, masters, master, status, sorts, filters)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(rows)))
+
+	var start *int
+	if int(total) == 0 {
+		start = nil
+	} else {
+		value := offset + 1
+		start = &value
+	}
+
+	var end *int
+	if int(total) == 0 {
+		end = nil
+	} else {
+		value := int(math.Min(float64(offset+rows), float64(total)))
+		end = &value
+	}
+
+	var nextPage *int
+	if page < totalPages {
+		nextPageVal := page + 1
+		nextPage = &nextPageVal
+	}
+
+	result := map[string]interface{}{
+		"items": jobs,
+		"pagination": map[string]interface{}{
+			"current_page":  page,
+			"next_page":     nextPage,
+			"total_pages":   totalPages,
+			"rows_per_page": rows,
+			"total_rows":    total,
+			"from":          start,
+			"to":            end,
+		},
+	}
+
+	if len(jobs) == 0 {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+}
+
+// GetImport godoc
+// @Summary Get import job by ID
+// @Description Retrieve an import job with its status, row counts and errors
+// @Tags Import
+// @Accept json
+// @Produce json
+// @Param id path int true "Import Job ID"
+// @Success 200 {object} map[string]interface{} "Import job found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not read the master of the import job"
+// @Failure 404 {object} map[string]interface{} "Not Found: Import job not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/import/{id} [get]
+func (h *ImportHandler) GetImport(c *fiber.Ctx) error {
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	job, err := h.importService.GetByID(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Import job not found"))
+	}
+
+	if ok, err := h.verifyRead(c, job); !ok {
+		return err
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Import job found successfully", job)
+}
+
+// ExportImportErrors godoc
+// @Summary Download the error report of an import job
+// @Description Downloads the rows of the file that failed validation with the reason, as an Excel or CSV file
+// @Tags Import
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param id path int true "Import Job ID"
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Success 200 {file} file "Error report"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID or format"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not read the master of the import job"
+// @Failure 404 {object} map[string]interface{} "Not Found: Import job not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/import/{id}/errors [get]
+func (h *ImportHandler) ExportImportErrors(c *fiber.Ctx) error {
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	job, err := h.importService.GetByID(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Import job not found"))
+	}
+
+	if ok, err := h.verifyRead(c, job); !ok {
+		return err
+	}
+
+	errs, err := h.importService.GetErrors(job)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	if err := export.Write(c, job.Master+"_import_errors", importErrorColumns, func(offset, limit int) ([]importer.RowError, error) {
+		if offset >= len(errs) {
+			return nil, nil
+		}
+
+		return errs[offset:min(offset+limit, len(errs))], nil
+	}); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return nil
+}
+
+// GetImportTemplate godoc
+// @Summary Download the import template of a master
+// @Description Downloads an Excel file with the columns of the import, a comment on what each column takes, an example row and dropdowns of the accepted codes
+// @Tags Import
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param master path string true "Master (items, item-raw-materials, locations, currency-rates, machine-statuses)"
+// @Success 200 {file} file "Import template"
+// @Failure 404 {object} map[string]interface{} "Not Found: Unknown master"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/import/{master}/template [get]
+func (h *ImportHandler) GetImportTemplate(c *fiber.Ctx) error {
+	master := c.Params("master")
+
+	f, err := h.importService.Template(master)
+	if errors.Is(err, service.ErrUnknownImportMaster) {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Unknown import master "+master))
+	}
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+	defer f.Close()
+
+	c.Set(fiber.HeaderContentType, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Attachment(master + "_template.xlsx")
+
+	if err := f.Write(c.Response().BodyWriter()); err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return nil
+}
+
+// Import godoc
+// @Summary Import master data from a file
+// @Description Validates every row of an Excel or CSV file and, unless it is a dry run, imports them in a single transaction when all rows are valid. References are given by code, e.g. the item category code. The run is recorded as an import job
+// @Tags Import
+// @Accept multipart/form-data
+// @Produce json
+// @Param master path string true "Master (items, item-raw-materials, locations, currency-rates, machine-statuses)"
+// @Param file formData file true "xlsx or csv file, with a header row, see the template"
+// @Param mapping formData string false "JSON object mapping column headers to the headers used in the file, e.g. {\"Code\":\"Item Code\"}"
+// @Param dryRun query bool false "Only validate the file"
+// @Success 200 {object} map[string]interface{} "File is valid"
+// @Success 201 {object} map[string]interface{} "Import completed successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid file or mapping"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not create records of the master"
+// @Failure 404 {object} map[string]interface{} "Not Found: Unknown master"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: The file has errors"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/import/{master} [post]
+func (h *ImportHandler) Import(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	master := c.Params("master")
+	dryRun := c.QueryBool("dryRun")
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, "File is required"))
+	}
+
+	mapping := map[string]string{}
+	if value := c.FormValue("mapping"); value != "" {
+		if err := json.Unmarshal([]byte(value), &mapping); err != nil {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, "Invalid mapping: "+err.Error()))
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+	defer file.Close()
+
+	job, err := h.importService.Import(master, fileHeader.Filename, file, mapping, dryRun, userID)
+	if errors.Is(err, service.ErrUnknownImportMaster) {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Unknown import master "+master))
+	}
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	switch {
+	case job.Status == service.ImportStatusCompleted:
+		return pkg.Response(c, fiber.StatusCreated, "Import completed successfully", job)
+	case job.Status == service.ImportStatusValidated:
+		return pkg.Response(c, fiber.StatusOK, "File is valid, nothing was imported on a dry run", job)
+	case len(job.Errors) > 0:
+		return pkg.Response(c, fiber.StatusUnprocessableEntity, job.Message, job)
+	}
+
+	return pkg.Response(c, fiber.StatusBadRequest, job.Message, job)
+}
+
+// verifyRead checks that the caller may read the import jobs of the master of
+// job. When it may not, it answers 403 and returns false with the error the
+// handler returns.
+func (h *ImportHandler) verifyRead(c *fiber.Ctx, job *model.ImportJob) (bool, error) {
+	ok, err := h.importService.CanRead(c.Locals("userID").(uint), job.Master)
+	if err != nil {
+		return false, pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	if !ok {
+		return false, pkg.ErrorResponse(c, fiber.NewError(fiber.StatusForbidden, "You do not have permission to perform this action"))
+	}
+
+	return true, nil
+}
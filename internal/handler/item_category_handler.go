@@ -1,6 +1,12 @@
 package handler
 
 import (
+	"errors"
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/export"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
@@ -10,12 +16,23 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+var itemCategoryExportColumns = []export.Column{
+	{Header: "Code", Field: "Code"},
+	{Header: "Description", Field: "Description"},
+	{Header: "Remarks", Field: "Remarks"},
+	{Header: "Created By", Field: "CreatedBy.Name"},
+	{Header: "Created At", Field: "CreatedAt"},
+	{Header: "Updated By", Field: "UpdatedBy.Name"},
+	{Header: "Updated At", Field: "UpdatedAt"},
+}
+
 type ItemCategoryHandler struct {
 	itemCategoryService *service.ItemCategoryService
+	bulk                *crud.Bulk[model.MstItemCategory]
 }
 
-func NewItemCategoryHandler(itemCategoryService *service.ItemCategoryService) *ItemCategoryHandler {
-	return &ItemCategoryHandler{itemCategoryService: itemCategoryService}
+func NewItemCategoryHandler(itemCategoryService *service.ItemCategoryService, bulk *crud.Bulk[model.MstItemCategory]) *ItemCategoryHandler {
+	return &ItemCategoryHandler{itemCategoryService: itemCategoryService, bulk: bulk}
 }
 
 // GetItemCategories godoc
@@ -27,6 +44,8 @@ func NewItemCategoryHandler(itemCategoryService *service.ItemCategoryService) *I
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering itemCategory"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -38,13 +57,24 @@ func (h *ItemCategoryHandler) GetItemCategories(c *fiber.Ctx) error {
 	sortBy := c.Query("sortBy", "")
 	sortDirection := c.QueryBool("sortDirection")
 	offset := (page - 1) * rows
+	includeDeleted := c.QueryBool("includeDeleted")
 
-	total, err := h.itemCategoryService.GetTotal(search)
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.ItemCategoryFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.ItemCategoryFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.itemCategoryService.GetTotal(search, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	itemCategories, err := h.itemCategoryService.GetAll(offset, rows, search, sortBy, sortDirection)
+	itemCategories, err := h.itemCategoryService.GetAll(offset, rows, search, sorts, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -93,6 +123,41 @@ func (h *ItemCategoryHandler) GetItemCategories(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
 }
 
+// ExportItemCategories godoc
+// @Summary Export Item Categories
+// @Description Downloads the item categories matching the list parameters as an Excel or CSV file
+// @Tags Item Category
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/category/export [get]
+func (h *ItemCategoryHandler) ExportItemCategories(c *fiber.Ctx) error {
+	search := c.Query("search")
+	sortBy := c.Query("sortBy", "")
+	sortDirection := c.QueryBool("sortDirection")
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.ItemCategoryFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.ItemCategoryFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := export.Write(c, "item_categories", itemCategoryExportColumns, func(offset, limit int) ([]model.MstItemCategory, error) {
+		return h.itemCategoryService.GetAll(offset, limit, search, sorts, includeDeleted, filters)
+	}); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return nil
+}
+
 // GetItemCategory godoc
 // @Summary Get Item Category by ID or Code
 // @Description Retrieve a specific Item Category using either its numeric ID or alphanumeric Code via path param
@@ -100,7 +165,9 @@ func (h *ItemCategoryHandler) GetItemCategories(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param identifier path string true "Item Category ID (number) or Code (string)"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Item Category found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid identifier"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Category not found"
 // @Router /general/master/item/category/{identifier} [get]
@@ -112,6 +179,11 @@ func (h *ItemCategoryHandler) GetItemCategory(c *fiber.Ctx) error {
 		if err != nil {
 			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Category not found"))
 		}
+
+		if etag.Set(c, itemCategory) {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+
 		return pkg.Response(c, fiber.StatusOK, "Item Category found successfully", itemCategory)
 	}
 
@@ -119,6 +191,11 @@ func (h *ItemCategoryHandler) GetItemCategory(c *fiber.Ctx) error {
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Category not found"))
 	}
+
+	if etag.Set(c, itemCategory) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Item Category found successfully", itemCategory)
 }
 
@@ -128,19 +205,23 @@ func (h *ItemCategoryHandler) GetItemCategory(c *fiber.Ctx) error {
 // @Tags Item Category
 // @Accept json
 // @Produce json
-// @Param ItemCategory body model.MstItemCategory true "Item Category details"
+// @Param ItemCategory body dto.ItemCategoryRequest true "Item Category details"
 // @Success 201 {object} map[string]interface{} "Item Category created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/category [post]
 func (h *ItemCategoryHandler) CreateItemCategory(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
-	var itemCategory model.MstItemCategory
-	if err := c.BodyParser(&itemCategory); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	var input dto.ItemCategoryRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	var itemCategory model.MstItemCategory
+	input.Apply(&itemCategory)
+
 	itemCategory.IDCreatedby = userID
 	itemCategory.IDUpdatedby = userID
 
@@ -156,6 +237,24 @@ func (h *ItemCategoryHandler) CreateItemCategory(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusCreated, "Item Category created successfully", result)
 }
 
+// BulkItemCategories godoc
+// @Summary Create, update and delete item categories in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Item Category
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.ItemCategoryRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Item Category bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/category/bulk [post]
+func (h *ItemCategoryHandler) BulkItemCategories(c *fiber.Ctx) error {
+	return h.bulk.Run(c)
+}
+
 // UpdateItemCategory godoc
 // @Summary Update an existing Item Category
 // @Description Update the details of an existing Item Category by its ID
@@ -163,10 +262,14 @@ func (h *ItemCategoryHandler) CreateItemCategory(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Item Category ID"
-// @Param ItemCategory body model.MstItemCategory true "Updated Item Category details"
+// @Param ItemCategory body dto.ItemCategoryRequest true "Updated Item Category details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Item Category updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Category not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/category/{id} [put]
 func (h *ItemCategoryHandler) UpdateItemCategory(c *fiber.Ctx) error {
@@ -183,14 +286,26 @@ func (h *ItemCategoryHandler) UpdateItemCategory(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Category not found"))
 	}
 
-	if err := c.BodyParser(itemCategory); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	if ok, err := etag.Check(c, itemCategory); !ok {
+		return err
+	}
+
+	var input dto.ItemCategoryRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	input.Apply(itemCategory)
+
 	itemCategory.ID = uint(ID)
 	itemCategory.IDUpdatedby = userID
 
 	err = h.itemCategoryService.Update(itemCategory)
+	if errors.Is(err, service.ErrStale) {
+		return etag.Stale(c, func() (interface{}, error) {
+			return h.itemCategoryService.GetByID(uint(ID))
+		})
+	}
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -223,6 +338,8 @@ func (h *ItemCategoryHandler) DeleteItemCategory(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Category not found"))
 	}
 
+	user.IDUpdatedby = c.Locals("userID").(uint)
+
 	err = h.itemCategoryService.Delete(user)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
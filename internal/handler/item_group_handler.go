@@ -1,231 +1,154 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// ItemGroupHandler documents the item group endpoints, which are served by crud.Resource.
 type ItemGroupHandler struct {
-	itemGroupService *service.ItemGroupService
+	resource *crud.Resource[model.MstItemGroup]
 }
 
-func NewItemGroupHandler(itemGroupService *service.ItemGroupService) *ItemGroupHandler {
-	return &ItemGroupHandler{itemGroupService: itemGroupService}
+// NewItemGroupHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewItemGroupHandler(db *gorm.DB, resource crud.Resource[model.MstItemGroup]) *ItemGroupHandler {
+	resource.Input = func() dto.Input[model.MstItemGroup] { return &dto.ItemGroupRequest{} }
+
+	return &ItemGroupHandler{resource: crud.New(db, resource)}
 }
 
 // GetItemGroups godoc
-// @Summary Get a list of Item Groups
-// @Description Retrieves Item Groups with pagination, filtering, and sorting options
+// @Summary Get a list of item groups
+// @Description Retrieves item groups by page or by cursor, with optional search, filters and sorting
 // @Tags Item Group
 // @Accept json
 // @Produce json
-// @Param id_product_type query int false "Filter by Product Type ID"
-// @Param categoryCode query string false "Filter by Item Category Code"
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
-// @Param search query string false "Search keyword for filtering Item Group"
-// @Param sortBy query string false "Field to sort by"
-// @Param sortDirection query boolean false "Sort direction: true for ascending, false for descending"
+// @Param search query string false "Search keyword for filtering item group"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Param idProductType query string false "Filter by id"
+// @Param categoryCode query string false "Filter by code"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/group [get]
 func (h *ItemGroupHandler) GetItemGroups(c *fiber.Ctx) error {
-	idProductType := c.QueryInt("idProductType", 0)
-	categoryCode := c.Query("categoryCode", "")
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.itemGroupService.GetTotal(search, categoryCode, uint(idProductType))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	itemGroups, err := h.itemGroupService.GetAll(offset, rows, search, sortBy, sortDirection, categoryCode, uint(idProductType))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start *int
-	if total == 0 {
-		start = nil
-	} else {
-		val := offset + 1
-		start = &val
-	}
-
-	var end *int
-	if total == 0 {
-		end = nil
-	} else {
-		val := int(math.Min(float64(offset+rows), float64(total)))
-		end = &val
-	}
-
-	var nextPage *int
-	if page < totalPages {
-		val := page + 1
-		nextPage = &val
-	}
-
-	result := map[string]interface{}{
-		"items": itemGroups,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(itemGroups) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportItemGroups godoc
+// @Summary Export item groups
+// @Description Downloads the item groups matching the list parameters as an Excel or CSV file
+// @Tags Item Group
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering item group"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/group/export [get]
+func (h *ItemGroupHandler) ExportItemGroups(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetItemGroup godoc
-// @Summary Get Item Group by ID
-// @Description Retrieve a specific Item Group by its ID
+// @Summary Get item group by ID
+// @Description Retrieve a specific item group by its ID
 // @Tags Item Group
 // @Accept json
 // @Produce json
 // @Param id path int true "Item Group ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Item Group found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Group not found"
 // @Router /general/master/item/group/{id} [get]
 func (h *ItemGroupHandler) GetItemGroup(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemGroup, err := h.itemGroupService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Group not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Group found successfully", itemGroup)
+	return h.resource.Get(c)
 }
 
 // CreateItemGroup godoc
-// @Summary Create a new Item Group
-// @Description Create a new Item Group with the provided details
+// @Summary Create a new item group
+// @Description Create a new item group with the provided details
 // @Tags Item Group
 // @Accept json
 // @Produce json
-// @Param ItemGroup body model.MstItemGroup true "Item Group details"
+// @Param item_group body dto.ItemGroupRequest true "Item Group details"
 // @Success 201 {object} map[string]interface{} "Item Group created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/group [post]
 func (h *ItemGroupHandler) CreateItemGroup(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var itemGroup model.MstItemGroup
-	if err := c.BodyParser(&itemGroup); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemGroup.IDCreatedby = userID
-	itemGroup.IDUpdatedby = userID
-
-	err := h.itemGroupService.Create(&itemGroup)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": itemGroup.ID,
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "Item Group created successfully", result)
+// BulkItemGroups godoc
+// @Summary Create, update and delete item groups in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Item Group
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.ItemGroupRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/group/bulk [post]
+func (h *ItemGroupHandler) BulkItemGroups(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateItemGroup godoc
-// @Summary Update an existing Item Group
-// @Description Update the details of an existing Item Group by its ID
+// @Summary Update an existing item group
+// @Description Update the details of an existing item group by its ID
 // @Tags Item Group
 // @Accept json
 // @Produce json
 // @Param id path int true "Item Group ID"
-// @Param ItemGroup body model.MstItemGroup true "Updated Item Group details"
+// @Param item_group body dto.ItemGroupRequest true "Updated item group details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Item Group updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Group not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/group/{id} [put]
 func (h *ItemGroupHandler) UpdateItemGroup(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemGroup, err := h.itemGroupService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Group not found"))
-	}
-
-	if err := c.BodyParser(itemGroup); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemGroup.IDUpdatedby = userID
-
-	err = h.itemGroupService.Update(itemGroup)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": itemGroup.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Group updated successfully", result)
+	return h.resource.Update(c)
 }
 
 // DeleteItemGroup godoc
-// @Summary Delete an Item Group
-// @Description Delete an Item Group by its ID
+// @Summary Delete an item group
+// @Description Soft delete an item group by its ID
 // @Tags Item Group
 // @Param id path int true "Item Group ID"
 // @Success 200 {object} map[string]interface{} "Item Group deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Group not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/group/{id} [delete]
 func (h *ItemGroupHandler) DeleteItemGroup(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemGroup, err := h.itemGroupService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Group not found"))
-	}
-
-	err = h.itemGroupService.Delete(itemGroup)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Group deleted successfully", nil)
+	return h.resource.Delete(c)
 }
@@ -1,230 +1,153 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// ItemGroupTypeHandler documents the item group type endpoints, which are served by crud.Resource.
 type ItemGroupTypeHandler struct {
-	itemGroupService *service.ItemGroupTypeService
+	resource *crud.Resource[model.MstItemGroupType]
 }
 
-func NewItemGroupTypeHandler(itemGroupService *service.ItemGroupTypeService) *ItemGroupTypeHandler {
-	return &ItemGroupTypeHandler{itemGroupService: itemGroupService}
+// NewItemGroupTypeHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewItemGroupTypeHandler(db *gorm.DB, resource crud.Resource[model.MstItemGroupType]) *ItemGroupTypeHandler {
+	resource.Input = func() dto.Input[model.MstItemGroupType] { return &dto.ItemGroupTypeRequest{} }
+
+	return &ItemGroupTypeHandler{resource: crud.New(db, resource)}
 }
 
 // GetItemGroupTypes godoc
-// @Summary Get a list of Item Group Types
-// @Description Retrieves Item Group Types with pagination, search, filtering, and sorting options
+// @Summary Get a list of item group types
+// @Description Retrieves item group types by page or by cursor, with optional search, filters and sorting
 // @Tags Item Group Type
 // @Accept json
 // @Produce json
-// @Param idItemGroup query int false "Filter by Item Group ID"
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
-// @Param search query string false "Search keyword for filtering Item Group Type"
-// @Param sortBy query string false "Field to sort by"
-// @Param sortDirection query boolean false "Sort direction: true for ascending, false for descending"
+// @Param search query string false "Search keyword for filtering item group type"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Param idItemGroup query string false "Filter by id_item_group"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/group-type [get]
 func (h *ItemGroupTypeHandler) GetItemGroupTypes(c *fiber.Ctx) error {
-	idItemGroup := c.QueryInt("idItemGroup", 0)
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.itemGroupService.GetTotal(search, uint(idItemGroup))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	itemGroupTypes, err := h.itemGroupService.GetAll(offset, rows, search, sortBy, sortDirection, uint(idItemGroup))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start *int
-	if int(total) == 0 {
-		start = nil
-	} else {
-		value := offset + 1
-		start = &value
-	}
-
-	var end *int
-	if int(total) == 0 {
-		end = nil
-	} else {
-		value := int(math.Min(float64(offset+rows), float64(total)))
-		end = &value
-	}
-
-	var nextPage *int
-	if page < totalPages {
-		nextPageVal := page + 1
-		nextPage = &nextPageVal
-	}
-
-	result := map[string]interface{}{
-		"items": itemGroupTypes,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(itemGroupTypes) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportItemGroupTypes godoc
+// @Summary Export item group types
+// @Description Downloads the item group types matching the list parameters as an Excel or CSV file
+// @Tags Item Group Type
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering item group type"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/group-type/export [get]
+func (h *ItemGroupTypeHandler) ExportItemGroupTypes(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetItemGroupType godoc
-// @Summary Get Item Group Type by ID
-// @Description Retrieve a specific Item Group Type by its ID
+// @Summary Get item group type by ID
+// @Description Retrieve a specific item group type by its ID
 // @Tags Item Group Type
 // @Accept json
 // @Produce json
 // @Param id path int true "Item Group Type ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Item Group Type found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Group Type not found"
 // @Router /general/master/item/group-type/{id} [get]
 func (h *ItemGroupTypeHandler) GetItemGroupType(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemGroup, err := h.itemGroupService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Group Type not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Group Type found successfully", itemGroup)
+	return h.resource.Get(c)
 }
 
 // CreateItemGroupType godoc
-// @Summary Create a new Item Group Type
-// @Description Create a new Item Group Type with the provided details
+// @Summary Create a new item group type
+// @Description Create a new item group type with the provided details
 // @Tags Item Group Type
 // @Accept json
 // @Produce json
-// @Param ItemGroupType body model.MstItemGroupType true "Item Group Type details"
+// @Param item_group_type body dto.ItemGroupTypeRequest true "Item Group Type details"
 // @Success 201 {object} map[string]interface{} "Item Group Type created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/group-type [post]
 func (h *ItemGroupTypeHandler) CreateItemGroupType(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var itemGroup model.MstItemGroupType
-	if err := c.BodyParser(&itemGroup); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemGroup.IDCreatedby = userID
-	itemGroup.IDUpdatedby = userID
-
-	err := h.itemGroupService.Create(&itemGroup)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": itemGroup.ID,
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "Item Group Type created successfully", result)
+// BulkItemGroupTypes godoc
+// @Summary Create, update and delete item group types in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Item Group Type
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.ItemGroupTypeRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/group-type/bulk [post]
+func (h *ItemGroupTypeHandler) BulkItemGroupTypes(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateItemGroupType godoc
-// @Summary Update an existing Item Group Type
-// @Description Update the details of an existing Item Group Type by its ID
+// @Summary Update an existing item group type
+// @Description Update the details of an existing item group type by its ID
 // @Tags Item Group Type
 // @Accept json
 // @Produce json
 // @Param id path int true "Item Group Type ID"
-// @Param ItemGroupType body model.MstItemGroupType true "Updated Item Group Type details"
+// @Param item_group_type body dto.ItemGroupTypeRequest true "Updated item group type details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Item Group Type updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Group Type not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/group-type/{id} [put]
 func (h *ItemGroupTypeHandler) UpdateItemGroupType(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemGroup, err := h.itemGroupService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Group Type not found"))
-	}
-
-	if err := c.BodyParser(itemGroup); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemGroup.ID = uint(ID)
-	itemGroup.IDUpdatedby = userID
-
-	err = h.itemGroupService.Update(itemGroup)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": itemGroup.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Group Type updated successfully", result)
+	return h.resource.Update(c)
 }
 
 // DeleteItemGroupType godoc
-// @Summary Delete an Item Group Type
-// @Description Delete an Item Group Type by its ID
+// @Summary Delete an item group type
+// @Description Soft delete an item group type by its ID
 // @Tags Item Group Type
 // @Param id path int true "Item Group Type ID"
 // @Success 200 {object} map[string]interface{} "Item Group Type deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Group Type not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/group-type/{id} [delete]
 func (h *ItemGroupTypeHandler) DeleteItemGroupType(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemGroup, err := h.itemGroupService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Group Type not found"))
-	}
-
-	err = h.itemGroupService.Delete(itemGroup)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Group Type deleted successfully", nil)
+	return h.resource.Delete(c)
 }
@@ -1,20 +1,42 @@
 package handler
 
 import (
+	"errors"
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/export"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
 	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+var itemExportColumns = []export.Column{
+	{Header: "Item Category", Field: "ItemCategory.Code"},
+	{Header: "Code", Field: "Code"},
+	{Header: "Description", Field: "Description"},
+	{Header: "UoM", Field: "UOM.Code"},
+	{Header: "Infor Code", Field: "InforCode"},
+	{Header: "Infor Description", Field: "InforDescription"},
+	{Header: "Remarks", Field: "Remarks"},
+	{Header: "Created By", Field: "CreatedBy.Name"},
+	{Header: "Created At", Field: "CreatedAt"},
+	{Header: "Updated By", Field: "UpdatedBy.Name"},
+	{Header: "Updated At", Field: "UpdatedAt"},
+}
+
 type ItemHandler struct {
 	itemService *service.ItemService
+	bulk        *crud.Bulk[model.MstItem]
 }
 
-func NewItemHandler(itemService *service.ItemService) *ItemHandler {
-	return &ItemHandler{itemService: itemService}
+func NewItemHandler(itemService *service.ItemService, bulk *crud.Bulk[model.MstItem]) *ItemHandler {
+	return &ItemHandler{itemService: itemService, bulk: bulk}
 }
 
 // GetItems godoc
@@ -28,6 +50,8 @@ func NewItemHandler(itemService *service.ItemService) *ItemHandler {
 // @Param idItemCategory query string false "Item Category ID"
 // @Param sortBy query string false "Sort by field"
 // @Param sortDirection query boolean false "true = ASC, false = DESC"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /general/master/items [get]
@@ -40,13 +64,24 @@ func (h *ItemHandler) GetItems(c *fiber.Ctx) error {
 	sortBy := c.Query("sortBy", "")
 	sortDirection := c.QueryBool("sortDirection", true)
 	offset := (page - 1) * rows
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, !sortDirection, service.ItemFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
 
-	total, err := h.itemService.GetTotal(search, uint(idItemCategory), categoryCode)
+	filters, err := filter.Parse(c, service.ItemFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.itemService.GetTotal(search, uint(idItemCategory), categoryCode, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	items, err := h.itemService.GetAll(offset, rows, search, sortBy, sortDirection, uint(idItemCategory), categoryCode)
+	items, err := h.itemService.GetAll(offset, rows, search, sorts, uint(idItemCategory), categoryCode, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -70,11 +105,50 @@ func (h *ItemHandler) GetItems(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
 }
 
+// ExportItems godoc
+// @Summary Export Items
+// @Description Downloads the items matching the list parameters as an Excel or CSV file
+// @Tags Item
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/items/export [get]
+func (h *ItemHandler) ExportItems(c *fiber.Ctx) error {
+	search := c.Query("search", "")
+	idItemCategory := c.QueryInt("idItemCategory", 0)
+	categoryCode := c.Query("categoryCode", "")
+	sortBy := c.Query("sortBy", "")
+	sortDirection := c.QueryBool("sortDirection", true)
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, !sortDirection, service.ItemFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.ItemFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := export.Write(c, "items", itemExportColumns, func(offset, limit int) ([]model.MstItem, error) {
+		return h.itemService.GetAll(offset, limit, search, sorts, uint(idItemCategory), categoryCode, includeDeleted, filters)
+	}); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return nil
+}
+
 // GetItem godoc
 // @Summary Get item by ID
 // @Tags Item
 // @Param id path int true "Item ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} model.MstItem
+// @Success 304 "Not Modified"
 // @Failure 404 {object} map[string]interface{}
 // @Router /general/master/items/{id} [get]
 func (h *ItemHandler) GetItem(c *fiber.Ctx) error {
@@ -88,6 +162,10 @@ func (h *ItemHandler) GetItem(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item not found"))
 	}
 
+	if etag.Set(c, item) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Item found successfully", item)
 }
 
@@ -96,33 +174,63 @@ func (h *ItemHandler) GetItem(c *fiber.Ctx) error {
 // @Tags Item
 // @Accept json
 // @Produce json
-// @Param item body model.MstItem true "Item Body"
+// @Param item body dto.ItemRequest true "Item Body"
 // @Success 201 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "Conflict: Item code already exists, also when the item holding it was deleted"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Router /general/master/items [post]
 func (h *ItemHandler) CreateItem(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
-	var item model.MstItem
-	if err := c.BodyParser(&item); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	var input dto.ItemRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	var item model.MstItem
+	input.Apply(&item)
+
 	item.IDCreatedby = userID
 	item.IDUpdatedby = userID
 
 	if err := h.itemService.Create(&item); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusConflict, "Item code already exists, codes of deleted items stay reserved"))
+		}
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
 	return pkg.Response(c, fiber.StatusCreated, "Item created successfully", map[string]interface{}{"id": item.ID})
 }
 
+// BulkItems godoc
+// @Summary Create, update and delete items in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Item
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.ItemRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Item bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/items/bulk [post]
+func (h *ItemHandler) BulkItems(c *fiber.Ctx) error {
+	return h.bulk.Run(c)
+}
+
 // UpdateItem godoc
 // @Summary Update item
 // @Tags Item
 // @Param id path int true "Item ID"
-// @Param item body model.MstItem true "Updated Item"
+// @Param item body dto.ItemRequest true "Updated Item"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Router /general/master/items/{id} [put]
 func (h *ItemHandler) UpdateItem(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
@@ -136,13 +244,28 @@ func (h *ItemHandler) UpdateItem(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item not found"))
 	}
 
-	if err := c.BodyParser(item); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	if ok, err := etag.Check(c, item); !ok {
+		return err
+	}
+
+	var input dto.ItemRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	input.Apply(item)
+
 	item.IDUpdatedby = userID
 
 	if err := h.itemService.Update(item); err != nil {
+		if errors.Is(err, service.ErrStale) {
+			return etag.Stale(c, func() (interface{}, error) {
+				return h.itemService.GetByID(uint(id))
+			})
+		}
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusConflict, "Item code already exists, codes of deleted items stay reserved"))
+		}
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
@@ -166,6 +289,8 @@ func (h *ItemHandler) DeleteItem(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item not found"))
 	}
 
+	item.IDUpdatedby = c.Locals("userID").(uint)
+
 	if err := h.itemService.Delete(item); err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
 	"math"
@@ -37,12 +38,17 @@ func (h *ItemInforHandler) GetItemInfors(c *fiber.Ctx) error {
 	sortDirection := c.QueryBool("sortDirection", true)
 	offset := (page - 1) * rows
 
+	sorts, err := filter.ParseSort(sortBy, !sortDirection, service.ItemInforSortFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
 	total, err := h.itemInforService.GetTotal(search)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	items, err := h.itemInforService.GetAll(offset, rows, search, sortBy, sortDirection)
+	items, err := h.itemInforService.GetAll(offset, rows, search, sorts)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -1,232 +1,153 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// ItemProcessHandler documents the item process endpoints, which are served by crud.Resource.
 type ItemProcessHandler struct {
-	itemProcessService *service.ItemProcessService
+	resource *crud.Resource[model.MstItemProcess]
 }
 
-func NewItemProcessHandler(itemProcessService *service.ItemProcessService) *ItemProcessHandler {
-	return &ItemProcessHandler{itemProcessService: itemProcessService}
+// NewItemProcessHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewItemProcessHandler(db *gorm.DB, resource crud.Resource[model.MstItemProcess]) *ItemProcessHandler {
+	resource.Input = func() dto.Input[model.MstItemProcess] { return &dto.ItemProcessRequest{} }
+
+	return &ItemProcessHandler{resource: crud.New(db, resource)}
 }
 
 // GetItemProcesses godoc
-// @Summary Get a list of Item Processes
-// @Description Retrieves Item Processes with pagination, search, filtering, and sorting options
+// @Summary Get a list of item processes
+// @Description Retrieves item processes by page or by cursor, with optional search, filters and sorting
 // @Tags Item Process
 // @Accept json
 // @Produce json
-// @Param categoryCode query string false "Filter by Item Category Code"
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
-// @Param search query string false "Search keyword for filtering Item Process"
-// @Param sortBy query string false "Field to sort by"
-// @Param sortDirection query boolean false "Sort direction: true for ascending, false for descending"
+// @Param search query string false "Search keyword for filtering item process"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Param categoryCode query string false "Filter by code"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/process [get]
 func (h *ItemProcessHandler) GetItemProcesses(c *fiber.Ctx) error {
-	categoryCode := c.Query("categoryCode", "")
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.itemProcessService.GetTotal(search, categoryCode)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	itemProcesses, err := h.itemProcessService.GetAll(offset, rows, search, sortBy, sortDirection, categoryCode)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start *int
-	if int(total) == 0 {
-		start = nil
-	} else {
-		value := offset + 1
-		start = &value
-	}
-
-	var end *int
-	if int(total) == 0 {
-		end = nil
-	} else {
-		value := int(math.Min(float64(offset+rows), float64(total)))
-		end = &value
-	}
-
-	var nextPage *int
-	if page < totalPages {
-		nextPageVal := page + 1
-		nextPage = &nextPageVal
-	}
-
-	result := map[string]interface{}{
-		"items": itemProcesses,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(itemProcesses) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportItemProcesses godoc
+// @Summary Export item processes
+// @Description Downloads the item processes matching the list parameters as an Excel or CSV file
+// @Tags Item Process
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering item process"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/process/export [get]
+func (h *ItemProcessHandler) ExportItemProcesses(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetItemProcess godoc
-// @Summary Get Item Process by ID
-// @Description Retrieve a specific Item Process by its ID
+// @Summary Get item process by ID
+// @Description Retrieve a specific item process by its ID
 // @Tags Item Process
 // @Accept json
 // @Produce json
 // @Param id path int true "Item Process ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Item Process found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Process not found"
 // @Router /general/master/item/process/{id} [get]
 func (h *ItemProcessHandler) GetItemProcess(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemProcess, err := h.itemProcessService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Process not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Process found successfully", itemProcess)
+	return h.resource.Get(c)
 }
 
 // CreateItemProcess godoc
-// @Summary Create a new Item Process
-// @Description Create a new Item Process with the provided details
+// @Summary Create a new item process
+// @Description Create a new item process with the provided details
 // @Tags Item Process
 // @Accept json
 // @Produce json
-// @Param ItemProcess body model.MstItemProcess true "Item Process details"
+// @Param item_process body dto.ItemProcessRequest true "Item Process details"
 // @Success 201 {object} map[string]interface{} "Item Process created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/process [post]
 func (h *ItemProcessHandler) CreateItemProcess(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var itemProcess model.MstItemProcess
-	if err := c.BodyParser(&itemProcess); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemProcess.IDCreatedby = userID
-	itemProcess.IDUpdatedby = userID
-
-	err := h.itemProcessService.Create(&itemProcess)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": itemProcess.ID,
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "Item Process created successfully", result)
+// BulkItemProcesses godoc
+// @Summary Create, update and delete item processes in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Item Process
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.ItemProcessRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/process/bulk [post]
+func (h *ItemProcessHandler) BulkItemProcesses(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateItemProcess godoc
-// @Summary Update an existing Item Process
-// @Description Update the details of an existing Item Process by its ID
+// @Summary Update an existing item process
+// @Description Update the details of an existing item process by its ID
 // @Tags Item Process
 // @Accept json
 // @Produce json
 // @Param id path int true "Item Process ID"
-// @Param ItemProcess body model.MstItemProcess true "Updated Item Process details"
+// @Param item_process body dto.ItemProcessRequest true "Updated item process details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Item Process updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Process not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/process/{id} [put]
 func (h *ItemProcessHandler) UpdateItemProcess(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemProcess, err := h.itemProcessService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Process not found"))
-	}
-
-	if err := c.BodyParser(itemProcess); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemProcess.ID = uint(ID)
-	itemProcess.IDUpdatedby = userID
-
-	err = h.itemProcessService.Update(itemProcess)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": itemProcess.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Process updated successfully", result)
+	return h.resource.Update(c)
 }
 
 // DeleteItemProcess godoc
-// @Summary Delete an Item Process
-// @Description Delete an Item Process by its ID
+// @Summary Delete an item process
+// @Description Soft delete an item process by its ID
 // @Tags Item Process
-// @Accept json
-// @Produce json
 // @Param id path int true "Item Process ID"
 // @Success 200 {object} map[string]interface{} "Item Process deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Process not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/process/{id} [delete]
 func (h *ItemProcessHandler) DeleteItemProcess(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemProcess, err := h.itemProcessService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Process not found"))
-	}
-
-	err = h.itemProcessService.Delete(itemProcess)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Process deleted successfully", nil)
+	return h.resource.Delete(c)
 }
@@ -1,233 +1,154 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// ItemProductHandler documents the item product endpoints, which are served by crud.Resource.
 type ItemProductHandler struct {
-	itemProductService *service.ItemProductService
+	resource *crud.Resource[model.MstItemProduct]
 }
 
-func NewItemProductHandler(itemProductService *service.ItemProductService) *ItemProductHandler {
-	return &ItemProductHandler{itemProductService: itemProductService}
+// NewItemProductHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewItemProductHandler(db *gorm.DB, resource crud.Resource[model.MstItemProduct]) *ItemProductHandler {
+	resource.Input = func() dto.Input[model.MstItemProduct] { return &dto.ItemProductRequest{} }
+
+	return &ItemProductHandler{resource: crud.New(db, resource)}
 }
 
 // GetItemProducts godoc
-// @Summary Get a list of Item Products
-// @Description Retrieves Item Products with pagination, search, filtering, and sorting options
+// @Summary Get a list of item products
+// @Description Retrieves item products by page or by cursor, with optional search, filters and sorting
 // @Tags Item Product
 // @Accept json
 // @Produce json
-// @Param categoryCode query string false "Filter by Item Category Code"
-// @Param subCategoryCode query string false "Filter by Item Sub Category Code"
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
-// @Param search query string false "Search keyword for filtering Item Product"
-// @Param sortBy query string false "Field to sort by"
-// @Param sortDirection query boolean false "Sort direction: true for ascending, false for descending"
+// @Param search query string false "Search keyword for filtering item product"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Param categoryCode query string false "Filter by code"
+// @Param subCategoryCode query string false "Filter by code"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/product [get]
 func (h *ItemProductHandler) GetItemProducts(c *fiber.Ctx) error {
-	categoryCode := c.Query("categoryCode", "")
-	subCategoryCode := c.Query("subCategoryCode", "")
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.itemProductService.GetTotal(search, categoryCode, subCategoryCode)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	itemProducts, err := h.itemProductService.GetAll(offset, rows, search, sortBy, sortDirection, categoryCode, subCategoryCode)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start *int
-	if int(total) == 0 {
-		start = nil
-	} else {
-		value := offset + 1
-		start = &value
-	}
-
-	var end *int
-	if int(total) == 0 {
-		end = nil
-	} else {
-		value := int(math.Min(float64(offset+rows), float64(total)))
-		end = &value
-	}
-
-	var nextPage *int
-	if page < totalPages {
-		nextPageVal := page + 1
-		nextPage = &nextPageVal
-	}
-
-	result := map[string]interface{}{
-		"items": itemProducts,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(itemProducts) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportItemProducts godoc
+// @Summary Export item products
+// @Description Downloads the item products matching the list parameters as an Excel or CSV file
+// @Tags Item Product
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering item product"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/product/export [get]
+func (h *ItemProductHandler) ExportItemProducts(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetItemProduct godoc
-// @Summary Get Item Product by ID
-// @Description Retrieve a specific Item Product by its ID
+// @Summary Get item product by ID
+// @Description Retrieve a specific item product by its ID
 // @Tags Item Product
 // @Accept json
 // @Produce json
 // @Param id path int true "Item Product ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Item Product found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Product not found"
 // @Router /general/master/item/product/{id} [get]
 func (h *ItemProductHandler) GetItemProduct(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemProduct, err := h.itemProductService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Product not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Product found successfully", itemProduct)
+	return h.resource.Get(c)
 }
 
 // CreateItemProduct godoc
-// @Summary Create a new Item Product
-// @Description Create a new Item Product with the provided details
+// @Summary Create a new item product
+// @Description Create a new item product with the provided details
 // @Tags Item Product
 // @Accept json
 // @Produce json
-// @Param ItemProduct body model.MstItemProduct true "Item Product details"
+// @Param item_product body dto.ItemProductRequest true "Item Product details"
 // @Success 201 {object} map[string]interface{} "Item Product created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/product [post]
 func (h *ItemProductHandler) CreateItemProduct(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var itemProduct model.MstItemProduct
-	if err := c.BodyParser(&itemProduct); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemProduct.IDCreatedby = userID
-	itemProduct.IDUpdatedby = userID
-
-	err := h.itemProductService.Create(&itemProduct)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": itemProduct.ID,
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "Item Product created successfully", result)
+// BulkItemProducts godoc
+// @Summary Create, update and delete item products in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Item Product
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.ItemProductRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/product/bulk [post]
+func (h *ItemProductHandler) BulkItemProducts(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateItemProduct godoc
-// @Summary Update an existing Item Product
-// @Description Update the details of an existing Item Product by its ID
+// @Summary Update an existing item product
+// @Description Update the details of an existing item product by its ID
 // @Tags Item Product
 // @Accept json
 // @Produce json
 // @Param id path int true "Item Product ID"
-// @Param ItemProduct body model.MstItemProduct true "Updated Item Product details"
+// @Param item_product body dto.ItemProductRequest true "Updated item product details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Item Product updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Product not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/product/{id} [put]
 func (h *ItemProductHandler) UpdateItemProduct(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	var itemProduct *model.MstItemProduct
-	itemProduct, err = h.itemProductService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Product not found"))
-	}
-
-	if err := c.BodyParser(itemProduct); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemProduct.ID = uint(ID)
-	itemProduct.IDUpdatedby = userID
-
-	err = h.itemProductService.Update(itemProduct)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": itemProduct.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Product updated successfully", result)
+	return h.resource.Update(c)
 }
 
 // DeleteItemProduct godoc
-// @Summary Delete a itemProduct
-// @Description Delete a Item Product by its ID
+// @Summary Delete an item product
+// @Description Soft delete an item product by its ID
 // @Tags Item Product
 // @Param id path int true "Item Product ID"
 // @Success 200 {object} map[string]interface{} "Item Product deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Product not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/product/{id} [delete]
 func (h *ItemProductHandler) DeleteItemProduct(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	user, err := h.itemProductService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Product not found"))
-	}
-
-	err = h.itemProductService.Delete(user)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Product deleted successfully", nil)
+	return h.resource.Delete(c)
 }
@@ -1,231 +1,153 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// ItemProductTypeHandler documents the item product type endpoints, which are served by crud.Resource.
 type ItemProductTypeHandler struct {
-	itemProductService *service.ItemProductTypeService
+	resource *crud.Resource[model.MstItemProductType]
 }
 
-func NewItemProductTypeHandler(itemProductService *service.ItemProductTypeService) *ItemProductTypeHandler {
-	return &ItemProductTypeHandler{itemProductService: itemProductService}
+// NewItemProductTypeHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewItemProductTypeHandler(db *gorm.DB, resource crud.Resource[model.MstItemProductType]) *ItemProductTypeHandler {
+	resource.Input = func() dto.Input[model.MstItemProductType] { return &dto.ItemProductTypeRequest{} }
+
+	return &ItemProductTypeHandler{resource: crud.New(db, resource)}
 }
 
 // GetItemProductTypes godoc
-// @Summary Get a list of Item Product Types
-// @Description Retrieves Item Product Types with pagination, search, filtering, and sorting options
+// @Summary Get a list of item product types
+// @Description Retrieves item product types by page or by cursor, with optional search, filters and sorting
 // @Tags Item Product Type
 // @Accept json
 // @Produce json
-// @Param idItemProduct query int false "Filter by Item Product ID"
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
-// @Param search query string false "Search keyword for filtering Item Product Type"
-// @Param sortBy query string false "Field to sort by"
-// @Param sortDirection query boolean false "Sort direction: true for ascending, false for descending"
+// @Param search query string false "Search keyword for filtering item product type"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Param idItemProduct query string false "Filter by id_item_product"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/product-type [get]
 func (h *ItemProductTypeHandler) GetItemProductTypes(c *fiber.Ctx) error {
-	idItemProduct := c.QueryInt("idItemProduct", 0)
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.itemProductService.GetTotal(search, uint(idItemProduct))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	itemProductTypes, err := h.itemProductService.GetAll(offset, rows, search, sortBy, sortDirection, uint(idItemProduct))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start *int
-	if int(total) == 0 {
-		start = nil
-	} else {
-		value := offset + 1
-		start = &value
-	}
-
-	var end *int
-	if int(total) == 0 {
-		end = nil
-	} else {
-		value := int(math.Min(float64(offset+rows), float64(total)))
-		end = &value
-	}
-
-	var nextPage *int
-	if page < totalPages {
-		nextPageVal := page + 1
-		nextPage = &nextPageVal
-	}
-
-	result := map[string]interface{}{
-		"items": itemProductTypes,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(itemProductTypes) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportItemProductTypes godoc
+// @Summary Export item product types
+// @Description Downloads the item product types matching the list parameters as an Excel or CSV file
+// @Tags Item Product Type
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering item product type"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/product-type/export [get]
+func (h *ItemProductTypeHandler) ExportItemProductTypes(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetItemProductType godoc
-// @Summary Get Item Product Type by ID
-// @Description Retrieve a specific Item Product Type by its ID
+// @Summary Get item product type by ID
+// @Description Retrieve a specific item product type by its ID
 // @Tags Item Product Type
 // @Accept json
 // @Produce json
 // @Param id path int true "Item Product Type ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Item Product Type found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Product Type not found"
 // @Router /general/master/item/product-type/{id} [get]
 func (h *ItemProductTypeHandler) GetItemProductType(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemProduct, err := h.itemProductService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Product Type not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Product Type found successfully", itemProduct)
+	return h.resource.Get(c)
 }
 
 // CreateItemProductType godoc
-// @Summary Create a new Item Product Type
-// @Description Create a new Item Product Type with the provided details
+// @Summary Create a new item product type
+// @Description Create a new item product type with the provided details
 // @Tags Item Product Type
 // @Accept json
 // @Produce json
-// @Param ItemProductType body model.MstItemProductType true "Item Product Type details"
+// @Param item_product_type body dto.ItemProductTypeRequest true "Item Product Type details"
 // @Success 201 {object} map[string]interface{} "Item Product Type created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/product-type [post]
 func (h *ItemProductTypeHandler) CreateItemProductType(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var itemProduct model.MstItemProductType
-	if err := c.BodyParser(&itemProduct); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemProduct.IDCreatedby = userID
-	itemProduct.IDUpdatedby = userID
-
-	err := h.itemProductService.Create(&itemProduct)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": itemProduct.ID,
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "Item Product Type created successfully", result)
+// BulkItemProductTypes godoc
+// @Summary Create, update and delete item product types in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Item Product Type
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.ItemProductTypeRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/product-type/bulk [post]
+func (h *ItemProductTypeHandler) BulkItemProductTypes(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateItemProductType godoc
-// @Summary Update an existing Item Product Type
-// @Description Update the details of an existing Item Product Type by its ID
+// @Summary Update an existing item product type
+// @Description Update the details of an existing item product type by its ID
 // @Tags Item Product Type
 // @Accept json
 // @Produce json
 // @Param id path int true "Item Product Type ID"
-// @Param ItemProductType body model.MstItemProductType true "Updated Item Product Type details"
+// @Param item_product_type body dto.ItemProductTypeRequest true "Updated item product type details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Item Product Type updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Product Type not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/product-type/{id} [put]
 func (h *ItemProductTypeHandler) UpdateItemProductType(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	var itemProduct *model.MstItemProductType
-	itemProduct, err = h.itemProductService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Product Type not found"))
-	}
-
-	if err := c.BodyParser(itemProduct); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemProduct.ID = uint(ID)
-	itemProduct.IDUpdatedby = userID
-
-	err = h.itemProductService.Update(itemProduct)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": itemProduct.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Product Type updated successfully", result)
+	return h.resource.Update(c)
 }
 
 // DeleteItemProductType godoc
-// @Summary Delete a itemProduct
-// @Description Delete a Item Product Type by its ID
+// @Summary Delete an item product type
+// @Description Soft delete an item product type by its ID
 // @Tags Item Product Type
 // @Param id path int true "Item Product Type ID"
 // @Success 200 {object} map[string]interface{} "Item Product Type deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Product Type not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/product-type/{id} [delete]
 func (h *ItemProductTypeHandler) DeleteItemProductType(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	user, err := h.itemProductService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Product Type not found"))
-	}
-
-	err = h.itemProductService.Delete(user)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Product Type deleted successfully", nil)
+	return h.resource.Delete(c)
 }
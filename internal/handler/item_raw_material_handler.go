@@ -1,6 +1,12 @@
 package handler
 
 import (
+	"errors"
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/export"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
@@ -9,12 +15,33 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+var itemRawMaterialExportColumns = []export.Column{
+	{Header: "Item", Field: "Item.Code"},
+	{Header: "Item Description", Field: "Item.Description"},
+	{Header: "UoM", Field: "Item.UOM.Code"},
+	{Header: "Item Product", Field: "ItemProductType.ItemProduct.Code"},
+	{Header: "Item Product Type", Field: "ItemProductType.Code"},
+	{Header: "Item Group", Field: "ItemGroupType.ItemGroup.Code"},
+	{Header: "Item Group Type", Field: "ItemGroupType.Code"},
+	{Header: "Item Process", Field: "ItemProcess.Code"},
+	{Header: "Item Surface", Field: "ItemSurface.Code"},
+	{Header: "Item Source", Field: "ItemSource.Code"},
+	{Header: "Diameter Size", Field: "DiameterSize"},
+	{Header: "Length Size", Field: "LengthSize"},
+	{Header: "Inner Diameter Size", Field: "InnerDiameterSize"},
+	{Header: "Created By", Field: "CreatedBy.Name"},
+	{Header: "Created At", Field: "CreatedAt"},
+	{Header: "Updated By", Field: "UpdatedBy.Name"},
+	{Header: "Updated At", Field: "UpdatedAt"},
+}
+
 type ItemRawMaterialHandler struct {
 	itemRawMaterialService *service.ItemRawMaterialService
+	bulk                   *crud.Bulk[model.MstItemRawMaterial]
 }
 
-func NewItemRawMaterialHandler(itemRawMaterialService *service.ItemRawMaterialService) *ItemRawMaterialHandler {
-	return &ItemRawMaterialHandler{itemRawMaterialService: itemRawMaterialService}
+func NewItemRawMaterialHandler(itemRawMaterialService *service.ItemRawMaterialService, bulk *crud.Bulk[model.MstItemRawMaterial]) *ItemRawMaterialHandler {
+	return &ItemRawMaterialHandler{itemRawMaterialService: itemRawMaterialService, bulk: bulk}
 }
 
 // GetItemRawMaterials godoc
@@ -28,6 +55,8 @@ func NewItemRawMaterialHandler(itemRawMaterialService *service.ItemRawMaterialSe
 // @Param categoryCode query string false "Item Category Code"
 // @Param sortBy query string false "Sort by field"
 // @Param sortDirection query boolean false "true = ASC, false = DESC"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}"
 // @Router /general/master/item/generate/raw-material [get]
@@ -39,13 +68,24 @@ func (h *ItemRawMaterialHandler) GetItemRawMaterials(c *fiber.Ctx) error {
 	sortBy := c.Query("sortBy", "")
 	sortDirection := c.QueryBool("sortDirection", true)
 	offset := (page - 1) * rows
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, !sortDirection, service.ItemRawMaterialFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
 
-	total, err := h.itemRawMaterialService.GetTotal(search, categoryCode)
+	filters, err := filter.Parse(c, service.ItemRawMaterialFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.itemRawMaterialService.GetTotal(search, categoryCode, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	itemRawMaterials, err := h.itemRawMaterialService.GetAll(offset, rows, search, categoryCode, sortBy, sortDirection)
+	itemRawMaterials, err := h.itemRawMaterialService.GetAll(offset, rows, search, categoryCode, sorts, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -69,11 +109,49 @@ func (h *ItemRawMaterialHandler) GetItemRawMaterials(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
 }
 
+// ExportItemRawMaterials godoc
+// @Summary Export Item Raw Materials
+// @Description Downloads the item raw materials matching the list parameters as an Excel or CSV file
+// @Tags Item Raw Material
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/generate/raw-material/export [get]
+func (h *ItemRawMaterialHandler) ExportItemRawMaterials(c *fiber.Ctx) error {
+	search := c.Query("search", "")
+	categoryCode := c.Query("categoryCode", "")
+	sortBy := c.Query("sortBy", "")
+	sortDirection := c.QueryBool("sortDirection", true)
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, !sortDirection, service.ItemRawMaterialFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.ItemRawMaterialFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := export.Write(c, "item_raw_materials", itemRawMaterialExportColumns, func(offset, limit int) ([]model.MstItemRawMaterial, error) {
+		return h.itemRawMaterialService.GetAll(offset, limit, search, categoryCode, sorts, includeDeleted, filters)
+	}); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return nil
+}
+
 // GetItemRawMaterial godoc
 // @Summary Get item raw material by ID
 // @Tags Item Raw Material
 // @Param id path int true "Item Raw Material ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} model.MstItemRawMaterial
+// @Success 304 "Not Modified"
 // @Failure 404 {object} map[string]interface{}
 // @Router /general/master/item/generate/raw-material/{id} [get]
 func (h *ItemRawMaterialHandler) GetItemRawMaterial(c *fiber.Ctx) error {
@@ -87,6 +165,10 @@ func (h *ItemRawMaterialHandler) GetItemRawMaterial(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Raw Material not found"))
 	}
 
+	if etag.Set(c, itemRawMaterial) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Item Raw Material found successfully", itemRawMaterial)
 }
 
@@ -95,17 +177,21 @@ func (h *ItemRawMaterialHandler) GetItemRawMaterial(c *fiber.Ctx) error {
 // @Tags Item Raw Material
 // @Accept json
 // @Produce json
-// @Param item_raw_material body model.MstItemRawMaterial true "Item Raw Material Body"
+// @Param item_raw_material body dto.ItemRawMaterialRequest true "Item Raw Material Body"
 // @Success 201 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Router /general/master/item/generate/raw-material [post]
 func (h *ItemRawMaterialHandler) CreateItemRawMaterial(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
-	var itemRawMaterial model.MstItemRawMaterial
-	if err := c.BodyParser(&itemRawMaterial); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	var input dto.ItemRawMaterialRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	var itemRawMaterial model.MstItemRawMaterial
+	input.Apply(&itemRawMaterial)
+
 	itemRawMaterial.IDCreatedby = userID
 	itemRawMaterial.IDUpdatedby = userID
 
@@ -116,12 +202,34 @@ func (h *ItemRawMaterialHandler) CreateItemRawMaterial(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusCreated, "Item Raw Material created successfully", map[string]interface{}{"id": itemRawMaterial.ID})
 }
 
+// BulkItemRawMaterials godoc
+// @Summary Create, update and delete item raw materials in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Item Raw Material
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.ItemRawMaterialRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Item Raw Material bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/generate/raw-material/bulk [post]
+func (h *ItemRawMaterialHandler) BulkItemRawMaterials(c *fiber.Ctx) error {
+	return h.bulk.Run(c)
+}
+
 // UpdateItemRawMaterial godoc
 // @Summary Update item raw material
 // @Tags Item Raw Material
 // @Param id path int true "Item Raw Material ID"
-// @Param item_raw_material body model.MstItemRawMaterial true "Updated Item Raw Material"
+// @Param item_raw_material body dto.ItemRawMaterialRequest true "Updated Item Raw Material"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Router /general/master/item/generate/raw-material/{id} [put]
 func (h *ItemRawMaterialHandler) UpdateItemRawMaterial(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
@@ -135,13 +243,25 @@ func (h *ItemRawMaterialHandler) UpdateItemRawMaterial(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Raw Material not found"))
 	}
 
-	if err := c.BodyParser(itemRawMaterial); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	if ok, err := etag.Check(c, itemRawMaterial); !ok {
+		return err
+	}
+
+	var input dto.ItemRawMaterialRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	input.Apply(itemRawMaterial)
+
 	itemRawMaterial.IDUpdatedby = userID
 
 	if err := h.itemRawMaterialService.Update(itemRawMaterial); err != nil {
+		if errors.Is(err, service.ErrStale) {
+			return etag.Stale(c, func() (interface{}, error) {
+				return h.itemRawMaterialService.GetByID(uint(id))
+			})
+		}
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
@@ -165,6 +285,8 @@ func (h *ItemRawMaterialHandler) DeleteItemRawMaterial(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Raw Material not found"))
 	}
 
+	itemRawMaterial.IDUpdatedby = c.Locals("userID").(uint)
+
 	if err := h.itemRawMaterialService.Delete(itemRawMaterial); err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -1,204 +1,153 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// ItemSourceHandler documents the item source endpoints, which are served by crud.Resource.
 type ItemSourceHandler struct {
-	itemSourceService *service.ItemSourceService
+	resource *crud.Resource[model.MstItemSource]
 }
 
-func NewItemSourceHandler(svc *service.ItemSourceService) *ItemSourceHandler {
-	return &ItemSourceHandler{itemSourceService: svc}
+// NewItemSourceHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewItemSourceHandler(db *gorm.DB, resource crud.Resource[model.MstItemSource]) *ItemSourceHandler {
+	resource.Input = func() dto.Input[model.MstItemSource] { return &dto.ItemSourceRequest{} }
+
+	return &ItemSourceHandler{resource: crud.New(db, resource)}
 }
 
 // GetItemSources godoc
-// @Summary Get a list of Item Sources
-// @Description Retrieves Item Sources with pagination, search, filtering, and sorting options
+// @Summary Get a list of item sources
+// @Description Retrieves item sources by page or by cursor, with optional search, filters and sorting
 // @Tags Item Source
 // @Accept json
 // @Produce json
-// @Param categoryCode query string false "Filter by Item Category Code"
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
-// @Param search query string false "Search keyword for filtering Item Source"
-// @Param sortBy query string false "Field to sort by"
-// @Param sortDirection query boolean false "Sort direction: true for ascending, false for descending"
+// @Param search query string false "Search keyword for filtering item source"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Param categoryCode query string false "Filter by code"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/source [get]
 func (h *ItemSourceHandler) GetItemSources(c *fiber.Ctx) error {
-	categoryCode := c.Query("categoryCode", "")
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.itemSourceService.GetTotal(search, categoryCode)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	sources, err := h.itemSourceService.GetAll(offset, rows, search, sortBy, sortDirection, categoryCode)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start, end *int
-	if int(total) > 0 {
-		s := offset + 1
-		e := int(math.Min(float64(offset+rows), float64(total)))
-		start = &s
-		end = &e
-	}
-
-	var nextPage *int
-	if page < totalPages {
-		n := page + 1
-		nextPage = &n
-	}
-
-	result := map[string]interface{}{
-		"items": sources,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(sources) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportItemSources godoc
+// @Summary Export item sources
+// @Description Downloads the item sources matching the list parameters as an Excel or CSV file
+// @Tags Item Source
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering item source"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/source/export [get]
+func (h *ItemSourceHandler) ExportItemSources(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetItemSource godoc
-// @Summary Get Item Source by ID
+// @Summary Get item source by ID
+// @Description Retrieve a specific item source by its ID
 // @Tags Item Source
+// @Accept json
+// @Produce json
 // @Param id path int true "Item Source ID"
-// @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
+// @Success 200 {object} map[string]interface{} "Item Source found successfully"
+// @Success 304 "Not Modified"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 404 {object} map[string]interface{} "Not Found: Item Source not found"
 // @Router /general/master/item/source/{id} [get]
 func (h *ItemSourceHandler) GetItemSource(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	data, err := h.itemSourceService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Source not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Source found successfully", data)
+	return h.resource.Get(c)
 }
 
 // CreateItemSource godoc
-// @Summary Create a new Item Source
+// @Summary Create a new item source
+// @Description Create a new item source with the provided details
 // @Tags Item Source
 // @Accept json
 // @Produce json
-// @Param ItemSource body model.MstItemSource true "Item Source data"
-// @Success 201 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Param item_source body dto.ItemSourceRequest true "Item Source details"
+// @Success 201 {object} map[string]interface{} "Item Source created successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/source [post]
 func (h *ItemSourceHandler) CreateItemSource(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var data model.MstItemSource
-	if err := c.BodyParser(&data); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	data.IDCreatedby = userID
-	data.IDUpdatedby = userID
-
-	err := h.itemSourceService.Create(&data)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "Item Source created successfully", map[string]interface{}{"id": data.ID})
+// BulkItemSources godoc
+// @Summary Create, update and delete item sources in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Item Source
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.ItemSourceRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/source/bulk [post]
+func (h *ItemSourceHandler) BulkItemSources(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateItemSource godoc
-// @Summary Update Item Source by ID
+// @Summary Update an existing item source
+// @Description Update the details of an existing item source by its ID
 // @Tags Item Source
 // @Accept json
+// @Produce json
 // @Param id path int true "Item Source ID"
-// @Param ItemSource body model.MstItemSource true "Updated Item Source data"
-// @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
+// @Param item_source body dto.ItemSourceRequest true "Updated item source details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
+// @Success 200 {object} map[string]interface{} "Item Source updated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 404 {object} map[string]interface{} "Not Found: Item Source not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/source/{id} [put]
 func (h *ItemSourceHandler) UpdateItemSource(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	data, err := h.itemSourceService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Source not found"))
-	}
-
-	if err := c.BodyParser(data); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	data.IDUpdatedby = userID
-
-	err = h.itemSourceService.Update(data)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Source updated successfully", map[string]interface{}{"id": data.ID})
+	return h.resource.Update(c)
 }
 
 // DeleteItemSource godoc
-// @Summary Delete Item Source by ID
+// @Summary Delete an item source
+// @Description Soft delete an item source by its ID
 // @Tags Item Source
 // @Param id path int true "Item Source ID"
-// @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
+// @Success 200 {object} map[string]interface{} "Item Source deleted successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 404 {object} map[string]interface{} "Not Found: Item Source not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/source/{id} [delete]
 func (h *ItemSourceHandler) DeleteItemSource(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	data, err := h.itemSourceService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Source not found"))
-	}
-
-	err = h.itemSourceService.Delete(data)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Source deleted successfully", nil)
+	return h.resource.Delete(c)
 }
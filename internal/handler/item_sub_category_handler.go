@@ -1,231 +1,153 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// ItemSubCategoryHandler documents the item sub category endpoints, which are served by crud.Resource.
 type ItemSubCategoryHandler struct {
-	itemSubCategoryService *service.ItemSubCategoryService
+	resource *crud.Resource[model.MstItemSubCategory]
 }
 
-func NewItemSubCategoryHandler(itemSubCategoryService *service.ItemSubCategoryService) *ItemSubCategoryHandler {
-	return &ItemSubCategoryHandler{itemSubCategoryService: itemSubCategoryService}
+// NewItemSubCategoryHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewItemSubCategoryHandler(db *gorm.DB, resource crud.Resource[model.MstItemSubCategory]) *ItemSubCategoryHandler {
+	resource.Input = func() dto.Input[model.MstItemSubCategory] { return &dto.ItemSubCategoryRequest{} }
+
+	return &ItemSubCategoryHandler{resource: crud.New(db, resource)}
 }
 
 // GetItemSubCategories godoc
-// @Summary Get a list of Item Sub Categories
-// @Description Retrieves Item Sub Categories with pagination, filtering, search, and sorting
+// @Summary Get a list of item sub categories
+// @Description Retrieves item sub categories by page or by cursor, with optional search, filters and sorting
 // @Tags Item Sub Category
 // @Accept json
 // @Produce json
-// @Param categoryCode query string false "Filter by Item Category Code"
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
-// @Param search query string false "Search keyword for filtering Item Sub Category"
-// @Param sortBy query string false "Field to sort by"
-// @Param sortDirection query boolean false "Sort direction: true for ascending, false for descending"
+// @Param search query string false "Search keyword for filtering item sub category"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Param categoryCode query string false "Filter by code"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/sub-category [get]
 func (h *ItemSubCategoryHandler) GetItemSubCategories(c *fiber.Ctx) error {
-	categoryCode := c.Query("categoryCode", "")
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.itemSubCategoryService.GetTotal(search, categoryCode)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	itemSubCategories, err := h.itemSubCategoryService.GetAll(offset, rows, search, sortBy, sortDirection, categoryCode)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start *int
-	if int(total) == 0 {
-		start = nil
-	} else {
-		value := offset + 1
-		start = &value
-	}
-
-	var end *int
-	if int(total) == 0 {
-		end = nil
-	} else {
-		value := int(math.Min(float64(offset+rows), float64(total)))
-		end = &value
-	}
-
-	var nextPage *int
-	if page < totalPages {
-		nextPageVal := page + 1
-		nextPage = &nextPageVal
-	}
-
-	result := map[string]interface{}{
-		"items": itemSubCategories,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(itemSubCategories) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportItemSubCategories godoc
+// @Summary Export item sub categories
+// @Description Downloads the item sub categories matching the list parameters as an Excel or CSV file
+// @Tags Item Sub Category
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering item sub category"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/sub-category/export [get]
+func (h *ItemSubCategoryHandler) ExportItemSubCategories(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetItemSubCategory godoc
-// @Summary Get Item Sub Category by ID
-// @Description Retrieve a specific Item Sub Category by its ID
+// @Summary Get item sub category by ID
+// @Description Retrieve a specific item sub category by its ID
 // @Tags Item Sub Category
 // @Accept json
 // @Produce json
 // @Param id path int true "Item Sub Category ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Item Sub Category found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Sub Category not found"
 // @Router /general/master/item/sub-category/{id} [get]
 func (h *ItemSubCategoryHandler) GetItemSubCategory(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemSubCategory, err := h.itemSubCategoryService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Sub Category not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Sub Category found successfully", itemSubCategory)
+	return h.resource.Get(c)
 }
 
 // CreateItemSubCategory godoc
-// @Summary Create a new Item Sub Category
-// @Description Create a new Item Sub Category with the provided details
+// @Summary Create a new item sub category
+// @Description Create a new item sub category with the provided details
 // @Tags Item Sub Category
 // @Accept json
 // @Produce json
-// @Param ItemSubCategory body model.MstItemSubCategory true "Item Sub Category details"
+// @Param item_sub_category body dto.ItemSubCategoryRequest true "Item Sub Category details"
 // @Success 201 {object} map[string]interface{} "Item Sub Category created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/sub-category [post]
 func (h *ItemSubCategoryHandler) CreateItemSubCategory(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var itemSubCategory model.MstItemSubCategory
-	if err := c.BodyParser(&itemSubCategory); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemSubCategory.IDCreatedby = userID
-	itemSubCategory.IDUpdatedby = userID
-
-	err := h.itemSubCategoryService.Create(&itemSubCategory)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": itemSubCategory.ID,
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "Item Sub Category created successfully", result)
+// BulkItemSubCategories godoc
+// @Summary Create, update and delete item sub categories in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Item Sub Category
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.ItemSubCategoryRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/sub-category/bulk [post]
+func (h *ItemSubCategoryHandler) BulkItemSubCategories(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateItemSubCategory godoc
-// @Summary Update an existing Item Sub Category
-// @Description Update the details of an existing Item Sub Category by its ID
+// @Summary Update an existing item sub category
+// @Description Update the details of an existing item sub category by its ID
 // @Tags Item Sub Category
 // @Accept json
 // @Produce json
 // @Param id path int true "Item Sub Category ID"
-// @Param ItemSubCategory body model.MstItemSubCategory true "Updated Item Sub Category details"
+// @Param item_sub_category body dto.ItemSubCategoryRequest true "Updated item sub category details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Item Sub Category updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Sub Category not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/sub-category/{id} [put]
 func (h *ItemSubCategoryHandler) UpdateItemSubCategory(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	var itemSubCategory *model.MstItemSubCategory
-	itemSubCategory, err = h.itemSubCategoryService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Sub Category not found"))
-	}
-
-	if err := c.BodyParser(itemSubCategory); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemSubCategory.ID = uint(ID)
-	itemSubCategory.IDUpdatedby = userID
-
-	err = h.itemSubCategoryService.Update(itemSubCategory)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": itemSubCategory.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Sub Category updated successfully", result)
+	return h.resource.Update(c)
 }
 
 // DeleteItemSubCategory godoc
-// @Summary Delete a itemSubCategory
-// @Description Delete a Item Sub Category by its ID
+// @Summary Delete an item sub category
+// @Description Soft delete an item sub category by its ID
 // @Tags Item Sub Category
 // @Param id path int true "Item Sub Category ID"
 // @Success 200 {object} map[string]interface{} "Item Sub Category deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Sub Category not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/sub-category/{id} [delete]
 func (h *ItemSubCategoryHandler) DeleteItemSubCategory(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	user, err := h.itemSubCategoryService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Sub Category not found"))
-	}
-
-	err = h.itemSubCategoryService.Delete(user)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Sub Category deleted successfully", nil)
+	return h.resource.Delete(c)
 }
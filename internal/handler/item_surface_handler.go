@@ -1,214 +1,153 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// ItemSurfaceHandler documents the item surface endpoints, which are served by crud.Resource.
 type ItemSurfaceHandler struct {
-	itemSurfaceService *service.ItemSurfaceService
+	resource *crud.Resource[model.MstItemSurface]
 }
 
-func NewItemSurfaceHandler(itemSurfaceService *service.ItemSurfaceService) *ItemSurfaceHandler {
-	return &ItemSurfaceHandler{itemSurfaceService: itemSurfaceService}
+// NewItemSurfaceHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewItemSurfaceHandler(db *gorm.DB, resource crud.Resource[model.MstItemSurface]) *ItemSurfaceHandler {
+	resource.Input = func() dto.Input[model.MstItemSurface] { return &dto.ItemSurfaceRequest{} }
+
+	return &ItemSurfaceHandler{resource: crud.New(db, resource)}
 }
 
 // GetItemSurfaces godoc
-// @Summary Get a list of Item Surfaces
-// @Description Retrieves Item Surfaces with pagination, search, filtering, and sorting options
+// @Summary Get a list of item surfaces
+// @Description Retrieves item surfaces by page or by cursor, with optional search, filters and sorting
 // @Tags Item Surface
 // @Accept json
 // @Produce json
-// @Param categoryCode query string false "Filter by Item Category Code"
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
-// @Param search query string false "Search keyword for filtering Item Surface"
-// @Param sortBy query string false "Field to sort by"
-// @Param sortDirection query boolean false "Sort direction: true for ascending, false for descending"
+// @Param search query string false "Search keyword for filtering item surface"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Param categoryCode query string false "Filter by code"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/surface [get]
 func (h *ItemSurfaceHandler) GetItemSurfaces(c *fiber.Ctx) error {
-	categoryCode := c.Query("categoryCode", "")
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.itemSurfaceService.GetTotal(search, categoryCode)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	itemSurfaces, err := h.itemSurfaceService.GetAll(offset, rows, search, sortBy, sortDirection, categoryCode)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start, end *int
-	if total > 0 {
-		startVal := offset + 1
-		endVal := int(math.Min(float64(offset+rows), float64(total)))
-		start = &startVal
-		end = &endVal
-	}
-
-	var nextPage *int
-	if page < totalPages {
-		next := page + 1
-		nextPage = &next
-	}
-
-	result := map[string]interface{}{
-		"items": itemSurfaces,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(itemSurfaces) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportItemSurfaces godoc
+// @Summary Export item surfaces
+// @Description Downloads the item surfaces matching the list parameters as an Excel or CSV file
+// @Tags Item Surface
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering item surface"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/surface/export [get]
+func (h *ItemSurfaceHandler) ExportItemSurfaces(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetItemSurface godoc
-// @Summary Get Item Surface by ID
-// @Description Retrieve a specific Item Surface by its ID
+// @Summary Get item surface by ID
+// @Description Retrieve a specific item surface by its ID
 // @Tags Item Surface
 // @Accept json
 // @Produce json
 // @Param id path int true "Item Surface ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Item Surface found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Surface not found"
 // @Router /general/master/item/surface/{id} [get]
 func (h *ItemSurfaceHandler) GetItemSurface(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemSurface, err := h.itemSurfaceService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Surface not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Surface found successfully", itemSurface)
+	return h.resource.Get(c)
 }
 
 // CreateItemSurface godoc
-// @Summary Create a new Item Surface
-// @Description Create a new Item Surface with the provided details
+// @Summary Create a new item surface
+// @Description Create a new item surface with the provided details
 // @Tags Item Surface
 // @Accept json
 // @Produce json
-// @Param ItemSurface body model.MstItemSurface true "Item Surface details"
+// @Param item_surface body dto.ItemSurfaceRequest true "Item Surface details"
 // @Success 201 {object} map[string]interface{} "Item Surface created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/surface [post]
 func (h *ItemSurfaceHandler) CreateItemSurface(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var itemSurface model.MstItemSurface
-	if err := c.BodyParser(&itemSurface); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemSurface.IDCreatedby = userID
-	itemSurface.IDUpdatedby = userID
-
-	if err := h.itemSurfaceService.Create(&itemSurface); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "Item Surface created successfully", map[string]interface{}{
-		"id": itemSurface.ID,
-	})
+// BulkItemSurfaces godoc
+// @Summary Create, update and delete item surfaces in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Item Surface
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.ItemSurfaceRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /general/master/item/surface/bulk [post]
+func (h *ItemSurfaceHandler) BulkItemSurfaces(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateItemSurface godoc
-// @Summary Update an existing Item Surface
-// @Description Update the details of an existing Item Surface by its ID
+// @Summary Update an existing item surface
+// @Description Update the details of an existing item surface by its ID
 // @Tags Item Surface
 // @Accept json
 // @Produce json
 // @Param id path int true "Item Surface ID"
-// @Param ItemSurface body model.MstItemSurface true "Updated Item Surface details"
+// @Param item_surface body dto.ItemSurfaceRequest true "Updated item surface details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Item Surface updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Surface not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/surface/{id} [put]
 func (h *ItemSurfaceHandler) UpdateItemSurface(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemSurface, err := h.itemSurfaceService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Surface not found"))
-	}
-
-	if err := c.BodyParser(itemSurface); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemSurface.IDUpdatedby = userID
-
-	if err := h.itemSurfaceService.Update(itemSurface); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Surface updated successfully", map[string]interface{}{
-		"id": itemSurface.ID,
-	})
+	return h.resource.Update(c)
 }
 
 // DeleteItemSurface godoc
-// @Summary Delete an Item Surface
-// @Description Delete an Item Surface by its ID
+// @Summary Delete an item surface
+// @Description Soft delete an item surface by its ID
 // @Tags Item Surface
 // @Param id path int true "Item Surface ID"
 // @Success 200 {object} map[string]interface{} "Item Surface deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Item Surface not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /general/master/item/surface/{id} [delete]
 func (h *ItemSurfaceHandler) DeleteItemSurface(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	itemSurface, err := h.itemSurfaceService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Item Surface not found"))
-	}
-
-	if err := h.itemSurfaceService.Delete(itemSurface); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Item Surface deleted successfully", nil)
+	return h.resource.Delete(c)
 }
@@ -1,6 +1,12 @@
 package handler
 
 import (
+	"errors"
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/export"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
@@ -9,12 +15,23 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+var keyValueExportColumns = []export.Column{
+	{Header: "Key", Field: "Key"},
+	{Header: "Value", Field: "Value"},
+	{Header: "Remarks", Field: "Remarks"},
+	{Header: "Created By", Field: "CreatedBy.Name"},
+	{Header: "Created At", Field: "CreatedAt"},
+	{Header: "Updated By", Field: "UpdatedBy.Name"},
+	{Header: "Updated At", Field: "UpdatedAt"},
+}
+
 type KeyValueHandler struct {
 	keyValueService *service.KeyValueService
+	bulk            *crud.Bulk[model.MstKeyValue]
 }
 
-func NewKeyValueHandler(keyValueService *service.KeyValueService) *KeyValueHandler {
-	return &KeyValueHandler{keyValueService: keyValueService}
+func NewKeyValueHandler(keyValueService *service.KeyValueService, bulk *crud.Bulk[model.MstKeyValue]) *KeyValueHandler {
+	return &KeyValueHandler{keyValueService: keyValueService, bulk: bulk}
 }
 
 // GetKeyValues godoc
@@ -26,6 +43,8 @@ func NewKeyValueHandler(keyValueService *service.KeyValueService) *KeyValueHandl
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering key value"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -35,13 +54,19 @@ func (h *KeyValueHandler) GetKeyValues(c *fiber.Ctx) error {
 	rows := c.QueryInt("rows", 20)
 	search := c.Query("search")
 	offset := (page - 1) * rows
+	includeDeleted := c.QueryBool("includeDeleted")
 
-	total, err := h.keyValueService.GetTotal(search)
+	filters, err := filter.Parse(c, service.KeyValueFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.keyValueService.GetTotal(search, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	keyValues, err := h.keyValueService.GetAll(offset, rows, search)
+	keyValues, err := h.keyValueService.GetAll(offset, rows, search, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -89,6 +114,34 @@ func (h *KeyValueHandler) GetKeyValues(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
 }
 
+// ExportKeyValues godoc
+// @Summary Export Key Values
+// @Description Downloads the key values matching the list parameters as an Excel or CSV file
+// @Tags Key Value
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/master/key-value/export [get]
+func (h *KeyValueHandler) ExportKeyValues(c *fiber.Ctx) error {
+	search := c.Query("search")
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	filters, err := filter.Parse(c, service.KeyValueFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := export.Write(c, "key_values", keyValueExportColumns, func(offset, limit int) ([]model.MstKeyValue, error) {
+		return h.keyValueService.GetAll(offset, limit, search, includeDeleted, filters)
+	}); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return nil
+}
+
 // GetKeyValue godoc
 // @Summary Get key value by ID
 // @Description Retrieve a specific key value by its ID
@@ -96,7 +149,9 @@ func (h *KeyValueHandler) GetKeyValues(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Key Value ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Key Value found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Key Value not found"
 // @Router /admin/master/key-value/{id} [get]
@@ -111,6 +166,10 @@ func (h *KeyValueHandler) GetKeyValue(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Key Value not found"))
 	}
 
+	if etag.Set(c, keyValue) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Key Value found successfully", keyValue)
 }
 
@@ -120,19 +179,23 @@ func (h *KeyValueHandler) GetKeyValue(c *fiber.Ctx) error {
 // @Tags Key Value
 // @Accept json
 // @Produce json
-// @Param keyValue body model.MstKeyValue true "Key Value details"
+// @Param keyValue body dto.KeyValueRequest true "Key Value details"
 // @Success 201 {object} map[string]interface{} "Key Value created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/master/key-value [post]
 func (h *KeyValueHandler) CreateKeyValue(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
-	var keyValue model.MstKeyValue
-	if err := c.BodyParser(&keyValue); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	var input dto.KeyValueRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	var keyValue model.MstKeyValue
+	input.Apply(&keyValue)
+
 	keyValue.IDCreatedby = userID
 	keyValue.IDUpdatedby = userID
 
@@ -148,6 +211,24 @@ func (h *KeyValueHandler) CreateKeyValue(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusCreated, "Key Value created successfully", result)
 }
 
+// BulkKeyValues godoc
+// @Summary Create, update and delete key values in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Key Value
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.KeyValueRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Key Value bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/master/key-value/bulk [post]
+func (h *KeyValueHandler) BulkKeyValues(c *fiber.Ctx) error {
+	return h.bulk.Run(c)
+}
+
 // UpdateKeyValue godoc
 // @Summary Update an existing key value
 // @Description Update the details of an existing key value by its ID
@@ -155,10 +236,14 @@ func (h *KeyValueHandler) CreateKeyValue(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Key Value ID"
-// @Param keyValue body model.MstKeyValue true "Updated key value details"
+// @Param keyValue body dto.KeyValueRequest true "Updated key value details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Key Value updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Key Value not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/master/key-value/{id} [put]
 func (h *KeyValueHandler) UpdateKeyValue(c *fiber.Ctx) error {
@@ -175,14 +260,26 @@ func (h *KeyValueHandler) UpdateKeyValue(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Key Value not found"))
 	}
 
-	if err := c.BodyParser(keyValue); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	if ok, err := etag.Check(c, keyValue); !ok {
+		return err
 	}
 
+	var input dto.KeyValueRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	input.Apply(keyValue)
+
 	keyValue.ID = uint(ID)
 	keyValue.IDUpdatedby = userID
 
 	err = h.keyValueService.Update(keyValue)
+	if errors.Is(err, service.ErrStale) {
+		return etag.Stale(c, func() (interface{}, error) {
+			return h.keyValueService.GetByID(uint(ID))
+		})
+	}
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -215,6 +312,8 @@ func (h *KeyValueHandler) DeleteKeyValue(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Key Value not found"))
 	}
 
+	user.IDUpdatedby = c.Locals("userID").(uint)
+
 	err = h.keyValueService.Delete(user)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
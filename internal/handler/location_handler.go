@@ -1,95 +1,67 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// LocationHandler documents the location endpoints, which are served by crud.Resource.
 type LocationHandler struct {
-	locationService *service.LocationService
+	resource *crud.Resource[model.MstLocation]
 }
 
-func NewLocationHandler(locationService *service.LocationService) *LocationHandler {
-	return &LocationHandler{locationService: locationService}
+// NewLocationHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewLocationHandler(db *gorm.DB, resource crud.Resource[model.MstLocation]) *LocationHandler {
+	resource.Input = func() dto.Input[model.MstLocation] { return &dto.LocationRequest{} }
+
+	return &LocationHandler{resource: crud.New(db, resource)}
 }
 
 // GetLocations godoc
 // @Summary Get a list of locations
-// @Description Retrieves locations with pagination and optional search
+// @Description Retrieves locations by page or by cursor, with optional search, filters and sorting
 // @Tags Location
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering location"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Param id_warehouse query string false "Filter by id_warehouse"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /pid/master/location [get]
 func (h *LocationHandler) GetLocations(c *fiber.Ctx) error {
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	idWarehouse := c.QueryInt("id_warehouse")
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.locationService.GetTotal(search, uint(idWarehouse))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	locations, err := h.locationService.GetAll(offset, rows, search, sortBy, sortDirection, uint(idWarehouse))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start *int
-	if int(total) == 0 {
-		start = nil
-	} else {
-		value := offset + 1
-		start = &value
-	}
-
-	var end *int
-	if int(total) == 0 {
-		end = nil
-	} else {
-		value := int(math.Min(float64(offset+rows), float64(total)))
-		end = &value
-	}
-	var nextPage *int
-	if page < totalPages {
-		nextPageVal := page + 1
-		nextPage = &nextPageVal
-	}
-
-	result := map[string]interface{}{
-		"items": locations,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(locations) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportLocations godoc
+// @Summary Export locations
+// @Description Downloads the locations matching the list parameters as an Excel or CSV file
+// @Tags Location
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering location"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /pid/master/location/export [get]
+func (h *LocationHandler) ExportLocations(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetLocation godoc
@@ -99,22 +71,14 @@ func (h *LocationHandler) GetLocations(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Location ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Location found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Location not found"
 // @Router /pid/master/location/{id} [get]
 func (h *LocationHandler) GetLocation(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	location, err := h.locationService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Location not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Location found successfully", location)
+	return h.resource.Get(c)
 }
 
 // CreateLocation godoc
@@ -123,32 +87,33 @@ func (h *LocationHandler) GetLocation(c *fiber.Ctx) error {
 // @Tags Location
 // @Accept json
 // @Produce json
-// @Param location body model.MstLocation true "Location details"
+// @Param location body dto.LocationRequest true "Location details"
 // @Success 201 {object} map[string]interface{} "Location created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /pid/master/location [post]
 func (h *LocationHandler) CreateLocation(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var location model.MstLocation
-	if err := c.BodyParser(&location); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	location.IDCreatedby = userID
-	location.IDUpdatedby = userID
-
-	err := h.locationService.Create(&location)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": location.ID,
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "Location created successfully", result)
+// BulkLocations godoc
+// @Summary Create, update and delete locations in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Location
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.LocationRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /pid/master/location/bulk [post]
+func (h *LocationHandler) BulkLocations(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateLocation godoc
@@ -158,70 +123,31 @@ func (h *LocationHandler) CreateLocation(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Location ID"
-// @Param location body model.MstLocation true "Updated location details"
+// @Param location body dto.LocationRequest true "Updated location details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Location updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Location not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /pid/master/location/{id} [put]
 func (h *LocationHandler) UpdateLocation(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	var location *model.MstLocation
-	location, err = h.locationService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Location not found"))
-	}
-
-	if err := c.BodyParser(location); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	location.ID = uint(ID)
-	location.IDUpdatedby = userID
-
-	err = h.locationService.Update(location)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": location.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Location updated successfully", result)
+	return h.resource.Update(c)
 }
 
 // DeleteLocation godoc
 // @Summary Delete a location
-// @Description Delete a location by its ID
+// @Description Soft delete a location by its ID
 // @Tags Location
 // @Param id path int true "Location ID"
 // @Success 200 {object} map[string]interface{} "Location deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Location not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /pid/master/location/{id} [delete]
 func (h *LocationHandler) DeleteLocation(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	user, err := h.locationService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Location not found"))
-	}
-
-	err = h.locationService.Delete(user)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Location deleted successfully", nil)
+	return h.resource.Delete(c)
 }
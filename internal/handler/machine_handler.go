@@ -1,7 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/export"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
@@ -11,12 +15,56 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+var machineExportColumns = []export.Column{
+	{Header: "Code", Field: "Code"},
+	{Header: "Old Code", Field: "CodeOld"},
+	{Header: "Asset Number", Field: "AssetNum"},
+	{Header: "Old Asset Number", Field: "AssetNumOld"},
+	{Header: "Name", Field: "Name"},
+	{Header: "Description", Field: "Description"},
+	{Header: "Maker", Field: "Maker"},
+	{Header: "Revision", Field: "RevNo"},
+	{Header: "Power", Field: "Power"},
+	{Header: "Power UoM", Field: "PowerUOMCode"},
+	{Header: "Electricity", Field: "Electricity"},
+	{Header: "Electricity UoM", Field: "ElectricityUOMCode"},
+	{Header: "Cavity", Field: "Cavity"},
+	{Header: "Lubricant", Field: "Lubricant"},
+	{Header: "Lubricant Capacity", Field: "LubricantCapacity"},
+	{Header: "Lubricant UoM", Field: "LubricantUOMCode"},
+	{Header: "Sliding", Field: "Sliding"},
+	{Header: "Sliding Capacity", Field: "SlidingCapacity"},
+	{Header: "Sliding UoM", Field: "SlidingUOMCode"},
+	{Header: "Coolant", Field: "Coolant"},
+	{Header: "Coolant Capacity", Field: "CoolantCapacity"},
+	{Header: "Coolant UoM", Field: "CoolantUOMCode"},
+	{Header: "Hydraulic", Field: "Hydraulic"},
+	{Header: "Hydraulic Capacity", Field: "HydraulicCapacity"},
+	{Header: "Hydraulic UoM", Field: "HydraulicUOMCode"},
+	{Header: "Dimension Front", Field: "DimensionFront"},
+	{Header: "Dimension Front UoM", Field: "DimensionFrontUOMCode"},
+	{Header: "Dimension Side", Field: "DimensionSide"},
+	{Header: "Dimension Side UoM", Field: "DimensionSideUOMCode"},
+	{Header: "Status", Field: "ReasonCode"},
+	{Header: "Status Description", Field: "ReasonDescription"},
+	{Header: "Status Remarks", Field: "Remarks"},
+	{Header: "Approval Status", Field: "ApprovalStatus"},
+	{Header: "Created By", Field: "MachineCreatedbyName"},
+	{Header: "Created At", Field: "MachineCreatedAt"},
+	{Header: "Updated By", Field: "MachineUpdatedbyName"},
+	{Header: "Updated At", Field: "MachineUpdatedAt"},
+}
+
 type MachineHandler struct {
-	machineService *service.MachineService
+	machineService        *service.MachineService
+	approvalEngineService *service.ApprovalEngineService
 }
 
-func NewMachineHandler(machineService *service.MachineService) *MachineHandler {
-	return &MachineHandler{machineService: machineService}
+func NewMachineHandler(machineService *service.MachineService, approvalEngineService *service.ApprovalEngineService) *MachineHandler {
+	return &MachineHandler{
+		machineService:        machineService,
+		approvalEngineService: approvalEngineService,
+	}
 }
 
 // GetMachines godoc
@@ -28,6 +76,8 @@ func NewMachineHandler(machineService *service.MachineService) *MachineHandler {
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering machine"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -41,13 +91,24 @@ func (h *MachineHandler) GetMachines(c *fiber.Ctx) error {
 	sortBy := c.Query("sortBy", "")
 	sortDirection := c.QueryBool("sortDirection")
 	offset := (page - 1) * rows
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.MachineFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.MachineFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
 
-	total, err := h.machineService.GetTotal(search, uint(reasonID), approval)
+	total, err := h.machineService.GetTotal(search, uint(reasonID), approval, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	machines, err := h.machineService.GetAll(offset, rows, search, uint(reasonID), approval, sortBy, sortDirection)
+	machines, err := h.machineService.GetAll(offset, rows, search, uint(reasonID), approval, sorts, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -95,6 +156,43 @@ func (h *MachineHandler) GetMachines(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
 }
 
+// ExportMachines godoc
+// @Summary Export Machines
+// @Description Downloads the machines matching the list parameters as an Excel or CSV file
+// @Tags Machine
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/master/machine/export [get]
+func (h *MachineHandler) ExportMachines(c *fiber.Ctx) error {
+	reasonID := c.QueryInt("id_reason", 0)
+	approval := c.Query("approval")
+	search := c.Query("search")
+	sortBy := c.Query("sortBy", "")
+	sortDirection := c.QueryBool("sortDirection")
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.MachineFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.MachineFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := export.Write(c, "machines", machineExportColumns, func(offset, limit int) ([]model.ViewMstMachine, error) {
+		return h.machineService.GetAll(offset, limit, search, uint(reasonID), approval, sorts, includeDeleted, filters)
+	}); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return nil
+}
+
 // GetMachine godoc
 // @Summary Get machine by ID
 // @Description Retrieve a specific machine by its ID
@@ -102,9 +200,10 @@ func (h *MachineHandler) GetMachines(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Machine ID"
-// @Success 200 {object} map[string]interface{} "Machine found successfully"
-// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
-// @Failure 404 {object} map[string]interface{} "Not Found: Machine not found"
+// @Param asOf query string false "Timestamp (RFC 3339) or date (YYYY-MM-DD, the end of that day) to get the revision and status in effect then instead of the current machine"
+// @Success 200 {object} map[string]interface{} "Machine found successfully, with the ETag of its latest revision"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID or asOf"
+// @Failure 404 {object} map[string]interface{} "Not Found: Machine not found, or no revision existed at asOf"
 // @Router /mnt/master/machine/{id} [get]
 func (h *MachineHandler) GetMachine(c *fiber.Ctx) error {
 	ID, err := c.ParamsInt("id")
@@ -117,9 +216,94 @@ func (h *MachineHandler) GetMachine(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Machine not found"))
 	}
 
+	if c.Query("asOf") != "" {
+		asOf, err := parseQueryTime("asOf", c.Query("asOf"), true)
+		if err != nil {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+		}
+
+		machineDetail, err := h.machineService.GetDetailAsOf(machine.ID, asOf)
+		if errors.Is(err, service.ErrMachineRevisionNotFound) {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Machine has no revision at "+asOf.Format(time.RFC3339)))
+		}
+		if err != nil {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+		}
+
+		machineStatus, err := h.machineService.GetStatusAsOf(machine.ID, asOf)
+		if err != nil {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+		}
+
+		return pkg.Response(c, fiber.StatusOK, "Machine found successfully", dto.MachineAsOf{
+			IDMachine: machine.ID,
+			AsOf:      asOf,
+			Detail:    machineDetail,
+			Status:    machineStatus,
+		})
+	}
+
+	// The ETag is the version of the latest revision, the draft an update
+	// edits. It leaves out the status, so it does not answer If-None-Match.
+	if tag := etag.Of(model.MstMachineDetail{UpdatedAt: &machine.DetailUpdatedAt}); tag != "" {
+		c.Set(fiber.HeaderETag, tag)
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Machine found successfully", machine)
 }
 
+// DiffMachineRevisions godoc
+// @Summary Compare two revisions of a machine
+// @Description Lists the fields that differ between two revisions of a machine, with UoMs given by their code
+// @Tags Machine
+// @Accept json
+// @Produce json
+// @Param id path int true "Machine ID"
+// @Param from query int false "Revision number to compare from, the revision before to by default"
+// @Param to query int false "Revision number to compare to, the latest revision by default"
+// @Success 200 {object} map[string]interface{} "Machine revisions compared successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID or revision number"
+// @Failure 404 {object} map[string]interface{} "Not Found: Machine or revision not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/master/machine/{id}/diff [get]
+func (h *MachineHandler) DiffMachineRevisions(c *fiber.Ctx) error {
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	machine, err := h.machineService.GetByID(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Machine not found"))
+	}
+
+	toRevNo := c.QueryInt("to", -1)
+	if toRevNo < 0 {
+		toRevNo, err = h.machineService.GetLatestRevNo(machine.ID)
+		if errors.Is(err, service.ErrMachineRevisionNotFound) {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Machine has no revisions"))
+		}
+		if err != nil {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+		}
+	}
+
+	fromRevNo := c.QueryInt("from", toRevNo-1)
+	if fromRevNo < 0 {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, "from must be a revision number, revision 0 has nothing to compare with"))
+	}
+
+	diff, err := h.machineService.DiffRevisions(machine.ID, fromRevNo, toRevNo)
+	if errors.Is(err, service.ErrMachineRevisionNotFound) {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Machine revision not found"))
+	}
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Machine revisions compared successfully", diff)
+}
+
 // CreateMachine godoc
 // @Summary Create a new machine
 // @Description Create a new machine with the provided details
@@ -129,42 +313,49 @@ func (h *MachineHandler) GetMachine(c *fiber.Ctx) error {
 // @Param machine body dto.CreateMachinePayload true "Machine and its details"
 // @Success 201 {object} map[string]interface{} "Machine created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /mnt/master/machine [post]
 func (h *MachineHandler) CreateMachine(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
 	var payload dto.CreateMachinePayload
-
-	if err := c.BodyParser(&payload); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	if err := pkg.BindBody(c, &payload); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
-	payload.Machine.IDCreatedby = userID
-	payload.Machine.IDUpdatedby = userID
+	machine := model.MstMachine{
+		IDCreatedby: userID,
+		IDUpdatedby: userID,
+	}
 
 	tx := h.machineService.BeginTx()
 
-	if err := tx.Create(&payload.Machine).Error; err != nil {
+	if err := tx.Create(&machine).Error; err != nil {
 		tx.Rollback()
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	payload.MachineDetail.RevNo = 0
-	payload.MachineDetail.IDMachine = payload.Machine.ID
-	payload.MachineStatus.IDMachine = payload.Machine.ID
+	var machineDetail model.MstMachineDetail
+	payload.MachineDetail.Apply(&machineDetail)
+	machineDetail.RevNo = 0
+	machineDetail.State = service.MachineRevisionDraft
+	machineDetail.IDMachine = machine.ID
+	machineDetail.IDCreatedby = userID
+	machineDetail.IDUpdatedby = userID
 
-	payload.MachineDetail.IDCreatedby = userID
-	payload.MachineDetail.IDUpdatedby = userID
-	payload.MachineStatus.IDCreatedby = userID
-	payload.MachineStatus.IDUpdatedby = userID
+	var machineStatus model.MstMachineStatus
+	payload.MachineStatus.Apply(&machineStatus)
+	machineStatus.IDMachine = machine.ID
+	machineStatus.IDCreatedby = userID
+	machineStatus.IDUpdatedby = userID
 
-	if err := tx.Create(&payload.MachineDetail).Error; err != nil {
+	if err := tx.Create(&machineDetail).Error; err != nil {
 		tx.Rollback()
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	if err := tx.Create(&payload.MachineStatus).Error; err != nil {
+	if err := tx.Create(&machineStatus).Error; err != nil {
 		tx.Rollback()
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -172,25 +363,30 @@ func (h *MachineHandler) CreateMachine(c *fiber.Ctx) error {
 	tx.Commit()
 
 	result := map[string]interface{}{
-		"id":             payload.Machine.ID,
-		"machine_detail": payload.MachineDetail,
-		"machine_status": payload.MachineStatus,
+		"id":             machine.ID,
+		"machine_detail": machineDetail,
+		"machine_status": machineStatus,
 	}
 
 	return pkg.Response(c, fiber.StatusCreated, "Machine created successfully", result)
 }
 
 // UpdateMachine godoc
-// @Summary Update an existing machine
-// @Description Update the details of an existing machine by its ID
+// @Summary Update a draft revision of a machine
+// @Description Update the details of a draft machine revision by its ID, only by the author of the draft
 // @Tags Machine
 // @Accept json
 // @Produce json
-// @Param id path int true "Machine ID"
-// @Param machine body model.MstMachine true "Updated machine details"
+// @Param id path int true "Detail Machine ID"
+// @Param machine body dto.MachineDetailRequest true "Updated machine details"
+// @Param If-Match header string true "ETag of the draft as read with the machine, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Machine updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User is not the author of the draft"
 // @Failure 404 {object} map[string]interface{} "Not Found: Machine not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Revision is not a draft, or it was changed by someone else and the current draft is returned"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /mnt/master/machine/{id} [put]
 func (h *MachineHandler) UpdateMachine(c *fiber.Ctx) error {
@@ -206,13 +402,28 @@ func (h *MachineHandler) UpdateMachine(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Detail machine not found"))
 	}
 
-	if err := c.BodyParser(machineDetail); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	if err := service.CanEditRevision(machineDetail, userID); err != nil {
+		return machineRevisionErrorResponse(c, err)
+	}
+
+	if ok, err := etag.Check(c, machineDetail); !ok {
+		return err
+	}
+
+	var input dto.MachineDetailRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	input.Apply(machineDetail)
 	machineDetail.IDUpdatedby = userID
 
 	err = h.machineService.UpdateDetail(machineDetail)
+	if errors.Is(err, service.ErrStale) {
+		return etag.Stale(c, func() (interface{}, error) {
+			return h.machineService.GetDetailByID(uint(detailID))
+		})
+	}
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -231,52 +442,54 @@ func (h *MachineHandler) UpdateMachine(c *fiber.Ctx) error {
 // @Param id path int true "Machine ID"
 // @Success 200 {object} map[string]interface{} "Machine deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User is not the author of the draft"
 // @Failure 404 {object} map[string]interface{} "Not Found: Machine not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Latest revision is pending approval"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /mnt/master/machine/{id} [delete]
 func (h *MachineHandler) DeleteMachine(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
 	ID, err := c.ParamsInt("id")
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
 	}
 
-	machine, err := h.machineService.GetByID(uint(ID))
-	if err != nil {
+	if _, err := h.machineService.GetByID(uint(ID)); err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, "Machine not found"))
 	}
 
 	machineDetails, err := h.machineService.GetDetailsByMachineID(uint(ID))
-	if err != nil {
+	if err != nil || len(machineDetails) == 0 {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, "Detail machine not found"))
 	}
 
-	if len(machineDetails) > 1 {
-		err = h.machineService.DeleteDetail(uint(machine.DetailID))
-		if err != nil {
-			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-		}
-	} else if len(machineDetails) == 1 {
-		err = h.machineService.DeleteDetail(uint(machine.DetailID))
-		if err != nil {
-			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	latest := machineDetails[0]
+	for _, machineDetail := range machineDetails[1:] {
+		if machineDetail.RevNo > latest.RevNo {
+			latest = machineDetail
 		}
+	}
 
-		err = h.machineService.DeleteStatus(uint(ID))
-		if err != nil {
-			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	// Deleting a machine whose latest of several revisions is a draft removes
+	// that draft only. Released revisions are never deleted: otherwise the
+	// machine itself is soft deleted with its revisions and status history
+	// kept, so it can be restored.
+	if latest.State == service.MachineRevisionPending {
+		return machineRevisionErrorResponse(c, service.ErrMachineRevisionNotDraft)
+	}
+
+	if len(machineDetails) > 1 && latest.State == service.MachineRevisionDraft {
+		if err := service.CanEditRevision(&latest, userID); err != nil {
+			return machineRevisionErrorResponse(c, err)
 		}
 
-		err = h.machineService.Delete(uint(ID))
+		err = h.machineService.DeleteDetail(latest.ID)
 		if err != nil {
 			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 		}
 	} else {
-		err = h.machineService.DeleteStatus(uint(ID))
-		if err != nil {
-			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-		}
-
-		err = h.machineService.Delete(uint(ID))
+		err = h.machineService.Delete(uint(ID), userID)
 		if err != nil {
 			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 		}
@@ -287,7 +500,7 @@ func (h *MachineHandler) DeleteMachine(c *fiber.Ctx) error {
 
 // RevisionMachine godoc
 // @Summary Create a new revision of an existing machine
-// @Description This endpoint creates a new revision of an existing machine by copying its details and updating the revision number.
+// @Description This endpoint creates a draft revision of an existing machine by copying the given revision and numbering it after the latest one. The draft becomes current once its approval is released.
 // @Tags Machine
 // @Accept json
 // @Produce json
@@ -295,6 +508,7 @@ func (h *MachineHandler) DeleteMachine(c *fiber.Ctx) error {
 // @Success 201 {object} map[string]interface{} "Machine revision created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid machine ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Machine not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Machine already has a draft or pending revision"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /mnt/master/machine/{id}/revision [put]
 func (h *MachineHandler) RevisionMachine(c *fiber.Ctx) error {
@@ -310,24 +524,11 @@ func (h *MachineHandler) RevisionMachine(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Machine not found"))
 	}
 
-	newMachineDetail := *existingMachineDetail
-	newMachineDetail.ID = 0
-	newMachineDetail.RevNo += 1
-	newMachineDetail.IDCreatedby = userID
-	newMachineDetail.IDUpdatedby = userID
-	now := time.Now()
-	newMachineDetail.CreatedAt = &now
-	newMachineDetail.UpdatedAt = &now
-
-	tx := h.machineService.BeginTx()
-
-	if err := tx.Create(&newMachineDetail).Error; err != nil {
-		tx.Rollback()
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	newMachineDetail, err := h.machineService.CreateRevision(existingMachineDetail, userID)
+	if err != nil {
+		return machineRevisionErrorResponse(c, err)
 	}
 
-	tx.Commit()
-
 	result := map[string]interface{}{
 		"id":     newMachineDetail.ID,
 		"rev_no": newMachineDetail.RevNo,
@@ -363,12 +564,17 @@ func (h *MachineHandler) GetMachineDetails(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
 	}
 
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.MachineDetailFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
 	total, err := h.machineService.GetTotalDetail(uint(ID))
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	machineDetails, err := h.machineService.GetAllDetail(offset, rows, sortBy, sortDirection, uint(ID))
+	machineDetails, err := h.machineService.GetAllDetail(offset, rows, sorts, uint(ID))
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -444,12 +650,17 @@ func (h *MachineHandler) GetMachineStatus(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
 	}
 
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.MachineStatusFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
 	total, err := h.machineService.GetTotalStatus(uint(ID))
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	machineStatus, err := h.machineService.GetAllStatus(offset, rows, sortBy, sortDirection, uint(ID))
+	machineStatus, err := h.machineService.GetAllStatus(offset, rows, sorts, uint(ID))
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -504,9 +715,10 @@ func (h *MachineHandler) GetMachineStatus(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Machine ID"
-// @Param body body model.MstMachineStatus true "Machine status details"
+// @Param body body dto.MachineStatusRequest true "Machine status details"
 // @Success 201 {object} map[string]interface{} "Machine status created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /mnt/master/machine/{id}/status [put]
 func (h *MachineHandler) CreateStatusMachine(c *fiber.Ctx) error {
@@ -517,11 +729,13 @@ func (h *MachineHandler) CreateStatusMachine(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
 	}
 
-	var machineStatus model.MstMachineStatus
-	if err := c.BodyParser(&machineStatus); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	var input dto.MachineStatusRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	var machineStatus model.MstMachineStatus
+	input.Apply(&machineStatus)
 	machineStatus.IDMachine = uint(ID)
 	machineStatus.IDCreatedby = userID
 	machineStatus.IDUpdatedby = userID
@@ -537,3 +751,106 @@ func (h *MachineHandler) CreateStatusMachine(c *fiber.Ctx) error {
 
 	return pkg.Response(c, fiber.StatusCreated, "Machine status created successfully", result)
 }
+
+// GetMachineApproval godoc
+// @Summary Get the approval state of a machine revision
+// @Description Returns the approval engine state of a machine detail revision
+// @Tags Machine
+// @Accept json
+// @Produce json
+// @Param id path int true "Detail Machine ID"
+// @Success 200 {object} map[string]interface{} "Machine approval found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 404 {object} map[string]interface{} "Not Found: Detail machine not found"
+// @Router /mnt/master/machine/{id}/approval [get]
+func (h *MachineHandler) GetMachineApproval(c *fiber.Ctx) error {
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if _, err := h.machineService.GetDetailByID(uint(ID)); err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Detail machine not found"))
+	}
+
+	state, err := h.approvalEngineService.GetState(service.MachineApprovalRefTable, uint(ID))
+	if err != nil {
+		return approvalErrorResponse(c, err)
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Machine approval found successfully", state)
+}
+
+// ActionMachineApproval godoc
+// @Summary Run an approval action on a machine revision
+// @Description Submits, approves, rejects, returns or cancels the approval of a machine detail revision
+// @Tags Machine
+// @Accept json
+// @Produce json
+// @Param id path int true "Detail Machine ID"
+// @Param action path string true "submit, approve, reject, return or cancel"
+// @Param input body dto.ApprovalAction true "Message"
+// @Success 200 {object} map[string]interface{} "Machine approval updated successfully"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User is not an approver, or submits a revision they did not write"
+// @Failure 404 {object} map[string]interface{} "Not Found: Detail machine not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Invalid approval state, or the revision is not a draft"
+// @Router /mnt/master/machine/{id}/approval/{action} [post]
+func (h *MachineHandler) ActionMachineApproval(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if _, err := h.machineService.GetDetailByID(uint(ID)); err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Detail machine not found"))
+	}
+
+	var input dto.ApprovalAction
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+		}
+	}
+
+	state, err := h.approvalEngineService.Act(c.Params("action"), service.MachineApprovalRefTable, uint(ID), userID, input)
+	if errors.Is(err, service.ErrMachineRevisionNotDraft) || errors.Is(err, service.ErrMachineRevisionNotAuthor) {
+		return machineRevisionErrorResponse(c, err)
+	}
+	if err != nil {
+		return approvalErrorResponse(c, err)
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Machine approval updated successfully", state)
+}
+
+// parseQueryTime reads the query parameter name as a timestamp, or a date
+// meaning the start or, with endOfDay, the end of that day.
+func parseQueryTime(name string, value string, endOfDay bool) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, errors.New(name + " must be a timestamp like 2025-01-31T08:00:00+07:00 or a date like 2025-01-31")
+	}
+
+	if endOfDay {
+		return date.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+
+	return date, nil
+}
+
+func machineRevisionErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrMachineRevisionNotAuthor):
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusForbidden, err.Error()))
+	case errors.Is(err, service.ErrMachineRevisionNotDraft), errors.Is(err, service.ErrMachineRevisionUnreleased):
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusConflict, err.Error()))
+	}
+
+	return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+}
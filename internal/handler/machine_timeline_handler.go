@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"errors"
+	"insist-backend-golang/internal/service"
+	"insist-backend-golang/pkg"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type MachineTimelineHandler struct {
+	machineTimelineService *service.MachineTimelineService
+	machineService         *service.MachineService
+}
+
+func NewMachineTimelineHandler(machineTimelineService *service.MachineTimelineService, machineService *service.MachineService) *MachineTimelineHandler {
+	return &MachineTimelineHandler{machineTimelineService: machineTimelineService, machineService: machineService}
+}
+
+// GetMachineTimeline godoc
+// @Summary Get the status timeline of a machine
+// @Description Lists the statuses of a machine as intervals over a date range, each with its reason and whether it is downtime
+// @Tags Machine
+// @Accept json
+// @Produce json
+// @Param id path int true "Machine ID"
+// @Param from query string false "Start as a timestamp (RFC 3339) or date (YYYY-MM-DD), the start of the current month by default"
+// @Param to query string false "End as a timestamp (RFC 3339) or date (YYYY-MM-DD, the end of that day), now by default"
+// @Success 200 {object} map[string]interface{} "Machine timeline found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID or date range"
+// @Failure 404 {object} map[string]interface{} "Not Found: Machine not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/master/machine/{id}/timeline [get]
+func (h *MachineTimelineHandler) GetMachineTimeline(c *fiber.Ctx) error {
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	from, to, err := parseTimeRange(c)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if _, err := h.machineService.GetByID(uint(ID)); err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Machine not found"))
+	}
+
+	timeline, err := h.machineTimelineService.GetTimeline(uint(ID), from, to)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Machine timeline found successfully", timeline)
+}
+
+// GetMachineMetrics godoc
+// @Summary Get the downtime metrics of a machine
+// @Description Reports downtime hours, availability, MTBF, MTTR and the top downtime reasons of a machine over a date range
+// @Tags Machine
+// @Accept json
+// @Produce json
+// @Param id path int true "Machine ID"
+// @Param from query string false "Start as a timestamp (RFC 3339) or date (YYYY-MM-DD), the start of the current month by default"
+// @Param to query string false "End as a timestamp (RFC 3339) or date (YYYY-MM-DD, the end of that day), now by default"
+// @Param top query int false "Number of downtime reasons to list (default: 5)"
+// @Success 200 {object} map[string]interface{} "Machine metrics found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID or date range"
+// @Failure 404 {object} map[string]interface{} "Not Found: Machine not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/master/machine/{id}/metrics [get]
+func (h *MachineTimelineHandler) GetMachineMetrics(c *fiber.Ctx) error {
+	top := c.QueryInt("top", 5)
+
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	from, to, err := parseTimeRange(c)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	machine, err := h.machineService.GetByID(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Machine not found"))
+	}
+
+	metrics, err := h.machineTimelineService.GetMetrics(machine.ID, from, to, top)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	metrics.Code = machine.Code
+	metrics.Name = machine.Name
+
+	return pkg.Response(c, fiber.StatusOK, "Machine metrics found successfully", metrics)
+}
+
+// GetBuildingMachineMetrics godoc
+// @Summary Get the downtime metrics of the machines of a building
+// @Description Reports downtime hours, availability, MTBF, MTTR and the top downtime reasons over a date range for the machines whose current revision is in a building, in total and per machine
+// @Tags Machine
+// @Accept json
+// @Produce json
+// @Param id path int true "Building ID"
+// @Param from query string false "Start as a timestamp (RFC 3339) or date (YYYY-MM-DD), the start of the current month by default"
+// @Param to query string false "End as a timestamp (RFC 3339) or date (YYYY-MM-DD, the end of that day), now by default"
+// @Param top query int false "Number of downtime reasons to list (default: 5)"
+// @Success 200 {object} map[string]interface{} "Building machine metrics found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID or date range"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/master/machine/building/{id}/metrics [get]
+func (h *MachineTimelineHandler) GetBuildingMachineMetrics(c *fiber.Ctx) error {
+	top := c.QueryInt("top", 5)
+
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	from, to, err := parseTimeRange(c)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	metrics, err := h.machineTimelineService.GetBuildingMetrics(uint(ID), from, to, top)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Building machine metrics found successfully", metrics)
+}
+
+// parseTimeRange reads the from and to query parameters, by default the
+// current month up to now.
+func parseTimeRange(c *fiber.Ctx) (time.Time, time.Time, error) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	to := now
+
+	if c.Query("from") != "" {
+		value, err := parseQueryTime("from", c.Query("from"), false)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from = value
+	}
+
+	if c.Query("to") != "" {
+		value, err := parseQueryTime("to", c.Query("to"), true)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to = value
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errors.New("from must be before to")
+	}
+
+	return from, to, nil
+}
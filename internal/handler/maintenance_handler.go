@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/service"
+	"insist-backend-golang/pkg"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type MaintenanceHandler struct {
+	maintenanceService *service.MaintenanceService
+	machineService     *service.MachineService
+}
+
+func NewMaintenanceHandler(maintenanceService *service.MaintenanceService, machineService *service.MachineService) *MaintenanceHandler {
+	return &MaintenanceHandler{maintenanceService: maintenanceService, machineService: machineService}
+}
+
+// GetMachineMaintenance godoc
+// @Summary Get the preventive maintenance of a machine
+// @Description Lists the maintenance plans assigned to a machine with when they are next due and their open work orders, each overdue, upcoming or scheduled
+// @Tags Machine
+// @Accept json
+// @Produce json
+// @Param id path int true "Machine ID"
+// @Param within query int false "Days ahead a plan is upcoming (default: 30)"
+// @Param state query string false "Only plans in this state: overdue, upcoming or scheduled"
+// @Success 200 {object} map[string]interface{} "Machine maintenance found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID or state"
+// @Failure 404 {object} map[string]interface{} "Not Found: Machine not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/master/machine/{id}/maintenance [get]
+func (h *MaintenanceHandler) GetMachineMaintenance(c *fiber.Ctx) error {
+	within := c.QueryInt("within", 30)
+	state := c.Query("state")
+
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	switch state {
+	case "", service.MaintenanceStateOverdue, service.MaintenanceStateUpcoming, service.MaintenanceStateScheduled:
+	default:
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, "state must be one of overdue, upcoming, scheduled"))
+	}
+
+	machine, err := h.machineService.GetByID(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Machine not found"))
+	}
+
+	schedules, err := h.maintenanceService.GetSchedule(machine.ID, within, time.Now())
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	items := []dto.MaintenanceSchedule{}
+	for _, schedule := range schedules {
+		if state == "" || schedule.State == state {
+			items = append(items, schedule)
+		}
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Machine maintenance found successfully", items)
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// MaintenancePlanHandler documents the maintenance plan endpoints, which are served by crud.Resource.
+type MaintenancePlanHandler struct {
+	resource *crud.Resource[model.MstMaintenancePlan]
+}
+
+// NewMaintenancePlanHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewMaintenancePlanHandler(db *gorm.DB, resource crud.Resource[model.MstMaintenancePlan]) *MaintenancePlanHandler {
+	resource.Input = func() dto.Input[model.MstMaintenancePlan] { return &dto.MaintenancePlanRequest{} }
+
+	return &MaintenancePlanHandler{resource: crud.New(db, resource)}
+}
+
+// GetMaintenancePlans godoc
+// @Summary Get a list of maintenance plans
+// @Description Retrieves maintenance plans by page or by cursor, with optional search, filters and sorting
+// @Tags Maintenance Plan
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param rows query int false "Number of rows per page" default(20)
+// @Param search query string false "Search keyword for filtering maintenance plan"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Param interval_type query string false "Filter by interval_type"
+// @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
+// @Failure 404 {object} map[string]interface{} "Not Found: No data found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/master/maintenance-plan [get]
+func (h *MaintenancePlanHandler) GetMaintenancePlans(c *fiber.Ctx) error {
+	return h.resource.List(c)
+}
+
+// ExportMaintenancePlans godoc
+// @Summary Export maintenance plans
+// @Description Downloads the maintenance plans matching the list parameters as an Excel or CSV file
+// @Tags Maintenance Plan
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering maintenance plan"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/master/maintenance-plan/export [get]
+func (h *MaintenancePlanHandler) ExportMaintenancePlans(c *fiber.Ctx) error {
+	return h.resource.Export(c)
+}
+
+// GetMaintenancePlan godoc
+// @Summary Get maintenance plan by ID
+// @Description Retrieve a specific maintenance plan by its ID
+// @Tags Maintenance Plan
+// @Accept json
+// @Produce json
+// @Param id path int true "Maintenance Plan ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
+// @Success 200 {object} map[string]interface{} "Maintenance Plan found successfully"
+// @Success 304 "Not Modified"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 404 {object} map[string]interface{} "Not Found: Maintenance Plan not found"
+// @Router /mnt/master/maintenance-plan/{id} [get]
+func (h *MaintenancePlanHandler) GetMaintenancePlan(c *fiber.Ctx) error {
+	return h.resource.Get(c)
+}
+
+// CreateMaintenancePlan godoc
+// @Summary Create a new maintenance plan
+// @Description Create a new maintenance plan with the provided details
+// @Tags Maintenance Plan
+// @Accept json
+// @Produce json
+// @Param maintenance_plan body dto.MaintenancePlanRequest true "Maintenance Plan details"
+// @Success 201 {object} map[string]interface{} "Maintenance Plan created successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/master/maintenance-plan [post]
+func (h *MaintenancePlanHandler) CreateMaintenancePlan(c *fiber.Ctx) error {
+	return h.resource.Create(c)
+}
+
+// BulkMaintenancePlans godoc
+// @Summary Create, update and delete maintenance plans in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Maintenance Plan
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.MaintenancePlanRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/master/maintenance-plan/bulk [post]
+func (h *MaintenancePlanHandler) BulkMaintenancePlans(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
+}
+
+// UpdateMaintenancePlan godoc
+// @Summary Update an existing maintenance plan
+// @Description Update the details of an existing maintenance plan by its ID
+// @Tags Maintenance Plan
+// @Accept json
+// @Produce json
+// @Param id path int true "Maintenance Plan ID"
+// @Param maintenance_plan body dto.MaintenancePlanRequest true "Updated maintenance plan details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
+// @Success 200 {object} map[string]interface{} "Maintenance Plan updated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 404 {object} map[string]interface{} "Not Found: Maintenance Plan not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/master/maintenance-plan/{id} [put]
+func (h *MaintenancePlanHandler) UpdateMaintenancePlan(c *fiber.Ctx) error {
+	return h.resource.Update(c)
+}
+
+// DeleteMaintenancePlan godoc
+// @Summary Delete a maintenance plan
+// @Description Soft delete a maintenance plan by its ID
+// @Tags Maintenance Plan
+// @Param id path int true "Maintenance Plan ID"
+// @Success 200 {object} map[string]interface{} "Maintenance Plan deleted successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 404 {object} map[string]interface{} "Not Found: Maintenance Plan not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/master/maintenance-plan/{id} [delete]
+func (h *MaintenancePlanHandler) DeleteMaintenancePlan(c *fiber.Ctx) error {
+	return h.resource.Delete(c)
+}
@@ -1,6 +1,12 @@
 package handler
 
 import (
+	"errors"
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/export"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
@@ -9,12 +15,45 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+var materialDetailExportColumns = []export.Column{
+	{Header: "Material", Field: "Material.Code"},
+	{Header: "Revision", Field: "RevNo"},
+	{Header: "RMSS Number", Field: "RmssNum"},
+	{Header: "OD Tolerance +", Field: "OdTolerancePlus"},
+	{Header: "OD Tolerance -", Field: "OdToleranceMin"},
+	{Header: "ID Tolerance +", Field: "IdTolerancePlus"},
+	{Header: "ID Tolerance -", Field: "IdToleranceMin"},
+	{Header: "Width", Field: "Width"},
+	{Header: "Height", Field: "Height"},
+	{Header: "Ovality", Field: "Ovality"},
+	{Header: "Cutting Length", Field: "CuttingLength"},
+	{Header: "Hardness", Field: "Hardness"},
+	{Header: "C", Field: "CompotitionC"},
+	{Header: "Si", Field: "CompotitionSi"},
+	{Header: "Mn", Field: "CompotitionMn"},
+	{Header: "P", Field: "CompotitionP"},
+	{Header: "S", Field: "CompotitionS"},
+	{Header: "Cu", Field: "CompotitionCu"},
+	{Header: "Ni", Field: "CompotitionNi"},
+	{Header: "Cr", Field: "CompotitionCr"},
+	{Header: "Mo", Field: "CompotitionMo"},
+	{Header: "Tensile Strength", Field: "TensileStrength"},
+	{Header: "SA Ratio", Field: "SaRatio"},
+	{Header: "Origin", Field: "Origin"},
+	{Header: "Remarks", Field: "Remarks"},
+	{Header: "Created By", Field: "CreatedBy.Name"},
+	{Header: "Created At", Field: "CreatedAt"},
+	{Header: "Updated By", Field: "UpdatedBy.Name"},
+	{Header: "Updated At", Field: "UpdatedAt"},
+}
+
 type MaterialDetailHandler struct {
 	materialDetailService *service.MaterialDetailService
+	bulk                  *crud.Bulk[model.MstMaterialDetail]
 }
 
-func NewMaterialDetailHandler(materialDetailService *service.MaterialDetailService) *MaterialDetailHandler {
-	return &MaterialDetailHandler{materialDetailService: materialDetailService}
+func NewMaterialDetailHandler(materialDetailService *service.MaterialDetailService, bulk *crud.Bulk[model.MstMaterialDetail]) *MaterialDetailHandler {
+	return &MaterialDetailHandler{materialDetailService: materialDetailService, bulk: bulk}
 }
 
 // GetMaterialDetails godoc
@@ -28,6 +67,8 @@ func NewMaterialDetailHandler(materialDetailService *service.MaterialDetailServi
 // @Param idMaterial query int false "Material ID"
 // @Param sortBy query string false "Sort by field"
 // @Param sortDirection query boolean false "true = ASC, false = DESC"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /egd/master/material-detail [get]
@@ -39,13 +80,24 @@ func (h *MaterialDetailHandler) GetMaterialDetails(c *fiber.Ctx) error {
 	sortBy := c.Query("sortBy", "")
 	sortDirection := c.QueryBool("sortDirection", true)
 	offset := (page - 1) * rows
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, !sortDirection, service.MaterialDetailFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
 
-	total, err := h.materialDetailService.GetTotal(search, uint(idMaterial))
+	filters, err := filter.Parse(c, service.MaterialDetailFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.materialDetailService.GetTotal(search, uint(idMaterial), includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	materialDetails, err := h.materialDetailService.GetAll(offset, rows, search, sortBy, sortDirection, uint(idMaterial))
+	materialDetails, err := h.materialDetailService.GetAll(offset, rows, search, sorts, uint(idMaterial), includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -69,11 +121,49 @@ func (h *MaterialDetailHandler) GetMaterialDetails(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
 }
 
+// ExportMaterialDetails godoc
+// @Summary Export Material Details
+// @Description Downloads the material details matching the list parameters as an Excel or CSV file
+// @Tags Material Detail
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /egd/master/material-detail/export [get]
+func (h *MaterialDetailHandler) ExportMaterialDetails(c *fiber.Ctx) error {
+	search := c.Query("search", "")
+	idMaterial := c.QueryInt("idMaterial", 0)
+	sortBy := c.Query("sortBy", "")
+	sortDirection := c.QueryBool("sortDirection", true)
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, !sortDirection, service.MaterialDetailFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.MaterialDetailFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := export.Write(c, "material_details", materialDetailExportColumns, func(offset, limit int) ([]model.MstMaterialDetail, error) {
+		return h.materialDetailService.GetAll(offset, limit, search, sorts, uint(idMaterial), includeDeleted, filters)
+	}); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return nil
+}
+
 // GetMaterialDetail godoc
 // @Summary Get Material Detail by ID
 // @Tags Material Detail
 // @Param id path int true "Material Detail ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} model.MstMaterialDetail
+// @Success 304 "Not Modified"
 // @Failure 404 {object} map[string]interface{}
 // @Router /egd/master/material-detail/{id} [get]
 func (h *MaterialDetailHandler) GetMaterialDetail(c *fiber.Ctx) error {
@@ -87,6 +177,10 @@ func (h *MaterialDetailHandler) GetMaterialDetail(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Material Detail not found"))
 	}
 
+	if etag.Set(c, materialDetail) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Material Detail found successfully", materialDetail)
 }
 
@@ -95,17 +189,21 @@ func (h *MaterialDetailHandler) GetMaterialDetail(c *fiber.Ctx) error {
 // @Tags Material Detail
 // @Accept json
 // @Produce json
-// @Param materialDetail body model.MstMaterialDetail true "Material Detail Body"
+// @Param materialDetail body dto.MaterialDetailRequest true "Material Detail Body"
 // @Success 201 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Router /egd/master/material-detail [post]
 func (h *MaterialDetailHandler) CreateMaterialDetail(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
-	var materialDetail model.MstMaterialDetail
-	if err := c.BodyParser(&materialDetail); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	var input dto.MaterialDetailRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	var materialDetail model.MstMaterialDetail
+	input.Apply(&materialDetail)
+
 	materialDetail.IDCreatedby = userID
 	materialDetail.IDUpdatedby = userID
 
@@ -116,12 +214,34 @@ func (h *MaterialDetailHandler) CreateMaterialDetail(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusCreated, "Material Detail created successfully", map[string]interface{}{"id": materialDetail.ID})
 }
 
+// BulkMaterialDetails godoc
+// @Summary Create, update and delete material details in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Material Detail
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.MaterialDetailRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Material Detail bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /egd/master/material-detail/bulk [post]
+func (h *MaterialDetailHandler) BulkMaterialDetails(c *fiber.Ctx) error {
+	return h.bulk.Run(c)
+}
+
 // UpdateMaterialDetail godoc
 // @Summary Update material detail
 // @Tags Material Detail
 // @Param id path int true "Material Detail ID"
-// @Param materialDetail body model.MstMaterialDetail true "Updated Material Detail"
+// @Param materialDetail body dto.MaterialDetailRequest true "Updated Material Detail"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Router /egd/master/material-detail/{id} [put]
 func (h *MaterialDetailHandler) UpdateMaterialDetail(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
@@ -135,13 +255,25 @@ func (h *MaterialDetailHandler) UpdateMaterialDetail(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Material Detail not found"))
 	}
 
-	if err := c.BodyParser(materialDetail); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	if ok, err := etag.Check(c, materialDetail); !ok {
+		return err
+	}
+
+	var input dto.MaterialDetailRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	input.Apply(materialDetail)
+
 	materialDetail.IDUpdatedby = userID
 
 	if err := h.materialDetailService.Update(materialDetail); err != nil {
+		if errors.Is(err, service.ErrStale) {
+			return etag.Stale(c, func() (interface{}, error) {
+				return h.materialDetailService.GetByID(uint(id))
+			})
+		}
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
@@ -165,6 +297,8 @@ func (h *MaterialDetailHandler) DeleteMaterialDetail(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Material Detail not found"))
 	}
 
+	materialDetail.IDUpdatedby = c.Locals("userID").(uint)
+
 	if err := h.materialDetailService.Delete(materialDetail); err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
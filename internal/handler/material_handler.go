@@ -1,6 +1,12 @@
 package handler
 
 import (
+	"errors"
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/export"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
@@ -9,12 +15,22 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+var materialExportColumns = []export.Column{
+	{Header: "Code", Field: "Code"},
+	{Header: "Description", Field: "Item.Description"},
+	{Header: "Created By", Field: "CreatedBy.Name"},
+	{Header: "Created At", Field: "CreatedAt"},
+	{Header: "Updated By", Field: "UpdatedBy.Name"},
+	{Header: "Updated At", Field: "UpdatedAt"},
+}
+
 type MaterialHandler struct {
 	materialService *service.MaterialService
+	bulk            *crud.Bulk[model.MstMaterial]
 }
 
-func NewMaterialHandler(materialService *service.MaterialService) *MaterialHandler {
-	return &MaterialHandler{materialService: materialService}
+func NewMaterialHandler(materialService *service.MaterialService, bulk *crud.Bulk[model.MstMaterial]) *MaterialHandler {
+	return &MaterialHandler{materialService: materialService, bulk: bulk}
 }
 
 // GetMaterials godoc
@@ -27,6 +43,8 @@ func NewMaterialHandler(materialService *service.MaterialService) *MaterialHandl
 // @Param search query string false "Search"
 // @Param sortBy query string false "Sort by field"
 // @Param sortDirection query boolean false "true = ASC, false = DESC"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /egd/master/materials [get]
@@ -37,13 +55,24 @@ func (h *MaterialHandler) GetMaterials(c *fiber.Ctx) error {
 	sortBy := c.Query("sortBy", "")
 	sortDirection := c.QueryBool("sortDirection", true)
 	offset := (page - 1) * rows
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, !sortDirection, service.MaterialFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
 
-	total, err := h.materialService.GetTotal(search)
+	filters, err := filter.Parse(c, service.MaterialFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.materialService.GetTotal(search, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	materials, err := h.materialService.GetAll(offset, rows, search, sortBy, sortDirection)
+	materials, err := h.materialService.GetAll(offset, rows, search, sorts, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -67,11 +96,48 @@ func (h *MaterialHandler) GetMaterials(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
 }
 
+// ExportMaterials godoc
+// @Summary Export Materials
+// @Description Downloads the materials matching the list parameters as an Excel or CSV file
+// @Tags Material
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /egd/master/materials/export [get]
+func (h *MaterialHandler) ExportMaterials(c *fiber.Ctx) error {
+	search := c.Query("search", "")
+	sortBy := c.Query("sortBy", "")
+	sortDirection := c.QueryBool("sortDirection", true)
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, !sortDirection, service.MaterialFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.MaterialFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := export.Write(c, "materials", materialExportColumns, func(offset, limit int) ([]model.MstMaterial, error) {
+		return h.materialService.GetAll(offset, limit, search, sorts, includeDeleted, filters)
+	}); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return nil
+}
+
 // GetMaterial godoc
 // @Summary Get Material by ID
 // @Tags Material
 // @Param id path int true "Material ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} model.MstMaterial
+// @Success 304 "Not Modified"
 // @Failure 404 {object} map[string]interface{}
 // @Router /egd/master/material/{id} [get]
 func (h *MaterialHandler) GetMaterial(c *fiber.Ctx) error {
@@ -85,6 +151,10 @@ func (h *MaterialHandler) GetMaterial(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Material not found"))
 	}
 
+	if etag.Set(c, material) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Material found successfully", material)
 }
 
@@ -93,17 +163,21 @@ func (h *MaterialHandler) GetMaterial(c *fiber.Ctx) error {
 // @Tags Material
 // @Accept json
 // @Produce json
-// @Param material body model.MstMaterial true "Material Body"
+// @Param material body dto.MaterialRequest true "Material Body"
 // @Success 201 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Router /egd/master/materials [post]
 func (h *MaterialHandler) CreateMaterial(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
-	var material model.MstMaterial
-	if err := c.BodyParser(&material); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	var input dto.MaterialRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	var material model.MstMaterial
+	input.Apply(&material)
+
 	material.IDCreatedby = userID
 	material.IDUpdatedby = userID
 
@@ -114,12 +188,34 @@ func (h *MaterialHandler) CreateMaterial(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusCreated, "Material created successfully", map[string]interface{}{"id": material.ID})
 }
 
+// BulkMaterials godoc
+// @Summary Create, update and delete materials in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Material
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.MaterialRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Material bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /egd/master/materials/bulk [post]
+func (h *MaterialHandler) BulkMaterials(c *fiber.Ctx) error {
+	return h.bulk.Run(c)
+}
+
 // UpdateMaterial godoc
 // @Summary Update material
 // @Tags Material
 // @Param id path int true "Material ID"
-// @Param material body model.MstMaterial true "Updated Material"
+// @Param material body dto.MaterialRequest true "Updated Material"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Router /egd/master/material/{id} [put]
 func (h *MaterialHandler) UpdateMaterial(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
@@ -133,13 +229,25 @@ func (h *MaterialHandler) UpdateMaterial(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Material not found"))
 	}
 
-	if err := c.BodyParser(material); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	if ok, err := etag.Check(c, material); !ok {
+		return err
+	}
+
+	var input dto.MaterialRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	input.Apply(material)
+
 	material.IDUpdatedby = userID
 
 	if err := h.materialService.Update(material); err != nil {
+		if errors.Is(err, service.ErrStale) {
+			return etag.Stale(c, func() (interface{}, error) {
+				return h.materialService.GetByID(uint(id))
+			})
+		}
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
@@ -163,6 +271,8 @@ func (h *MaterialHandler) DeleteMaterial(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Material not found"))
 	}
 
+	material.IDUpdatedby = c.Locals("userID").(uint)
+
 	if err := h.materialService.Delete(material); err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -1,6 +1,12 @@
 package handler
 
 import (
+	"errors"
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/export"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
@@ -9,12 +15,25 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+var menuExportColumns = []export.Column{
+	{Header: "Label", Field: "Label"},
+	{Header: "Path", Field: "Path"},
+	{Header: "Parent", Field: "Parent.Label"},
+	{Header: "Icon", Field: "Icon"},
+	{Header: "Sort", Field: "Sort"},
+	{Header: "Created By", Field: "CreatedBy.Name"},
+	{Header: "Created At", Field: "CreatedAt"},
+	{Header: "Updated By", Field: "UpdatedBy.Name"},
+	{Header: "Updated At", Field: "UpdatedAt"},
+}
+
 type MenuHandler struct {
 	menuService *service.MenuService
+	bulk        *crud.Bulk[model.MstMenu]
 }
 
-func NewMenuHandler(menuService *service.MenuService) *MenuHandler {
-	return &MenuHandler{menuService: menuService}
+func NewMenuHandler(menuService *service.MenuService, bulk *crud.Bulk[model.MstMenu]) *MenuHandler {
+	return &MenuHandler{menuService: menuService, bulk: bulk}
 }
 
 // GetMenus godoc
@@ -26,6 +45,8 @@ func NewMenuHandler(menuService *service.MenuService) *MenuHandler {
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering menu"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -37,13 +58,24 @@ func (h *MenuHandler) GetMenus(c *fiber.Ctx) error {
 	sortBy := c.Query("sortBy", "")
 	sortDirection := c.QueryBool("sortDirection")
 	offset := (page - 1) * rows
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.MenuFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
 
-	total, err := h.menuService.GetTotal(search)
+	filters, err := filter.Parse(c, service.MenuFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.menuService.GetTotal(search, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	menus, err := h.menuService.GetAll(offset, rows, search, sortBy, sortDirection)
+	menus, err := h.menuService.GetAll(offset, rows, search, sorts, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -91,6 +123,41 @@ func (h *MenuHandler) GetMenus(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
 }
 
+// ExportMenus godoc
+// @Summary Export Menus
+// @Description Downloads the menus matching the list parameters as an Excel or CSV file
+// @Tags Menu
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/master/menu/export [get]
+func (h *MenuHandler) ExportMenus(c *fiber.Ctx) error {
+	search := c.Query("search")
+	sortBy := c.Query("sortBy", "")
+	sortDirection := c.QueryBool("sortDirection")
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.MenuFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.MenuFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := export.Write(c, "menus", menuExportColumns, func(offset, limit int) ([]model.MstMenu, error) {
+		return h.menuService.GetAll(offset, limit, search, sorts, includeDeleted, filters)
+	}); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return nil
+}
+
 // GetMenu godoc
 // @Summary Get menu by ID
 // @Description Retrieve a specific menu by its ID
@@ -98,7 +165,9 @@ func (h *MenuHandler) GetMenus(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Menu ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Menu found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Menu not found"
 // @Router /admin/master/menu/{id} [get]
@@ -113,6 +182,10 @@ func (h *MenuHandler) GetMenu(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Menu not found"))
 	}
 
+	if etag.Set(c, menu) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Menu found successfully", menu)
 }
 
@@ -182,19 +255,23 @@ func (h *MenuHandler) GetMenusByUser(c *fiber.Ctx) error {
 // @Tags Menu
 // @Accept json
 // @Produce json
-// @Param menu body model.MstMenu true "Menu details"
+// @Param menu body dto.MenuRequest true "Menu details"
 // @Success 201 {object} map[string]interface{} "Menu created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/master/menu [post]
 func (h *MenuHandler) CreateMenu(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
-	var menu model.MstMenu
-	if err := c.BodyParser(&menu); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	var input dto.MenuRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	var menu model.MstMenu
+	input.Apply(&menu)
+
 	menu.IDCreatedby = userID
 	menu.IDUpdatedby = userID
 
@@ -210,6 +287,24 @@ func (h *MenuHandler) CreateMenu(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusCreated, "Menu created successfully", result)
 }
 
+// BulkMenus godoc
+// @Summary Create, update and delete menus in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Menu
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.MenuRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Menu bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/master/menu/bulk [post]
+func (h *MenuHandler) BulkMenus(c *fiber.Ctx) error {
+	return h.bulk.Run(c)
+}
+
 // UpdateMenu godoc
 // @Summary Update an existing menu
 // @Description Update the details of an existing menu by its ID
@@ -217,10 +312,14 @@ func (h *MenuHandler) CreateMenu(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Menu ID"
-// @Param menu body model.MstMenu true "Updated menu details"
+// @Param menu body dto.MenuRequest true "Updated menu details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Menu updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Menu not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/master/menu/{id} [put]
 func (h *MenuHandler) UpdateMenu(c *fiber.Ctx) error {
@@ -237,14 +336,26 @@ func (h *MenuHandler) UpdateMenu(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Menu not found"))
 	}
 
-	if err := c.BodyParser(menu); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	if ok, err := etag.Check(c, menu); !ok {
+		return err
 	}
 
+	var input dto.MenuRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	input.Apply(menu)
+
 	menu.ID = uint(ID)
 	menu.IDUpdatedby = userID
 
 	err = h.menuService.Update(menu)
+	if errors.Is(err, service.ErrStale) {
+		return etag.Stale(c, func() (interface{}, error) {
+			return h.menuService.GetByID(uint(ID))
+		})
+	}
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -277,6 +388,8 @@ func (h *MenuHandler) DeleteMenu(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Menu not found"))
 	}
 
+	user.IDUpdatedby = c.Locals("userID").(uint)
+
 	err = h.menuService.Delete(user)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
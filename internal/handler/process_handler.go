@@ -1,95 +1,66 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// ProcessHandler documents the process endpoints, which are served by crud.Resource.
 type ProcessHandler struct {
-	processService *service.ProcessService
+	resource *crud.Resource[model.MstProcess]
 }
 
-func NewProcessHandler(processService *service.ProcessService) *ProcessHandler {
-	return &ProcessHandler{processService: processService}
+// NewProcessHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewProcessHandler(db *gorm.DB, resource crud.Resource[model.MstProcess]) *ProcessHandler {
+	resource.Input = func() dto.Input[model.MstProcess] { return &dto.ProcessRequest{} }
+
+	return &ProcessHandler{resource: crud.New(db, resource)}
 }
 
-// GetProcesss godoc
-// @Summary Get a list of processs
-// @Description Retrieves processs with pagination and optional search
+// GetProcesses godoc
+// @Summary Get a list of processes
+// @Description Retrieves processes by page or by cursor, with optional search, filters and sorting
 // @Tags Process
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering process"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /egd/master/process [get]
 func (h *ProcessHandler) GetProcesses(c *fiber.Ctx) error {
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.processService.GetTotal(search)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	process, err := h.processService.GetAll(offset, rows, search, sortBy, sortDirection)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start *int
-	if int(total) == 0 {
-		start = nil
-	} else {
-		value := offset + 1
-		start = &value
-	}
-
-	var end *int
-	if int(total) == 0 {
-		end = nil
-	} else {
-		value := int(math.Min(float64(offset+rows), float64(total)))
-		end = &value
-	}
-
-	var nextPage *int
-	if page < totalPages {
-		nextPageVal := page + 1
-		nextPage = &nextPageVal
-	}
-
-	result := map[string]interface{}{
-		"items": process,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(process) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportProcesses godoc
+// @Summary Export processes
+// @Description Downloads the processes matching the list parameters as an Excel or CSV file
+// @Tags Process
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering process"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /egd/master/process/export [get]
+func (h *ProcessHandler) ExportProcesses(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetProcess godoc
@@ -99,22 +70,14 @@ func (h *ProcessHandler) GetProcesses(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Process ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Process found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Process not found"
 // @Router /egd/master/process/{id} [get]
 func (h *ProcessHandler) GetProcess(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	process, err := h.processService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Process not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Process found successfully", process)
+	return h.resource.Get(c)
 }
 
 // CreateProcess godoc
@@ -123,32 +86,33 @@ func (h *ProcessHandler) GetProcess(c *fiber.Ctx) error {
 // @Tags Process
 // @Accept json
 // @Produce json
-// @Param process body model.MstProcess true "Process details"
+// @Param process body dto.ProcessRequest true "Process details"
 // @Success 201 {object} map[string]interface{} "Process created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /egd/master/process [post]
 func (h *ProcessHandler) CreateProcess(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var process model.MstProcess
-	if err := c.BodyParser(&process); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	process.IDCreatedby = userID
-	process.IDUpdatedby = userID
-
-	err := h.processService.Create(&process)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": process.ID,
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "Process created successfully", result)
+// BulkProcesses godoc
+// @Summary Create, update and delete processes in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Process
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.ProcessRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /egd/master/process/bulk [post]
+func (h *ProcessHandler) BulkProcesses(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateProcess godoc
@@ -158,70 +122,31 @@ func (h *ProcessHandler) CreateProcess(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Process ID"
-// @Param process body model.MstProcess true "Updated process details"
+// @Param process body dto.ProcessRequest true "Updated process details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Process updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Process not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /egd/master/process/{id} [put]
 func (h *ProcessHandler) UpdateProcess(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	var process *model.MstProcess
-	process, err = h.processService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Process not found"))
-	}
-
-	if err := c.BodyParser(process); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	process.ID = uint(ID)
-	process.IDUpdatedby = userID
-
-	err = h.processService.Update(process)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": process.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Process updated successfully", result)
+	return h.resource.Update(c)
 }
 
 // DeleteProcess godoc
 // @Summary Delete a process
-// @Description Delete a process by its ID
+// @Description Soft delete a process by its ID
 // @Tags Process
 // @Param id path int true "Process ID"
 // @Success 200 {object} map[string]interface{} "Process deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Process not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /egd/master/process/{id} [delete]
 func (h *ProcessHandler) DeleteProcess(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	user, err := h.processService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Process not found"))
-	}
-
-	err = h.processService.Delete(user)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Process deleted successfully", nil)
+	return h.resource.Delete(c)
 }
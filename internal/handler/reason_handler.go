@@ -1,6 +1,12 @@
 package handler
 
 import (
+	"errors"
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/export"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
@@ -9,12 +15,26 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+var reasonExportColumns = []export.Column{
+	{Header: "Menu", Field: "Menu.Label"},
+	{Header: "Key", Field: "Key"},
+	{Header: "Code", Field: "Code"},
+	{Header: "Description", Field: "Description"},
+	{Header: "Remarks", Field: "Remarks"},
+	{Header: "Downtime", Field: "Downtime"},
+	{Header: "Created By", Field: "CreatedBy.Name"},
+	{Header: "Created At", Field: "CreatedAt"},
+	{Header: "Updated By", Field: "UpdatedBy.Name"},
+	{Header: "Updated At", Field: "UpdatedAt"},
+}
+
 type ReasonHandler struct {
 	reasonService *service.ReasonService
+	bulk          *crud.Bulk[model.MstReason]
 }
 
-func NewReasonHandler(reasonService *service.ReasonService) *ReasonHandler {
-	return &ReasonHandler{reasonService: reasonService}
+func NewReasonHandler(reasonService *service.ReasonService, bulk *crud.Bulk[model.MstReason]) *ReasonHandler {
+	return &ReasonHandler{reasonService: reasonService, bulk: bulk}
 }
 
 // GetReasons godoc
@@ -27,6 +47,8 @@ func NewReasonHandler(reasonService *service.ReasonService) *ReasonHandler {
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param id_menu query int false "ID Menu" default(0)
 // @Param search query string false "Search keyword for filtering reason"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -39,13 +61,19 @@ func (h *ReasonHandler) GetReasons(c *fiber.Ctx) error {
 	key := c.Query("key")
 	search := c.Query("search")
 	offset := (page - 1) * rows
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	filters, err := filter.Parse(c, service.ReasonFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
 
-	total, err := h.reasonService.GetTotal(search, path, key, uint(menuID))
+	total, err := h.reasonService.GetTotal(search, path, key, uint(menuID), includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	reasons, err := h.reasonService.GetAll(offset, rows, search, path, key, uint(menuID))
+	reasons, err := h.reasonService.GetAll(offset, rows, search, path, key, uint(menuID), includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -93,6 +121,37 @@ func (h *ReasonHandler) GetReasons(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
 }
 
+// ExportReasons godoc
+// @Summary Export Reasons
+// @Description Downloads the reasons matching the list parameters as an Excel or CSV file
+// @Tags Reason
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/master/reason/export [get]
+func (h *ReasonHandler) ExportReasons(c *fiber.Ctx) error {
+	menuID := c.QueryInt("id_menu", 0)
+	path := c.Query("path")
+	key := c.Query("key")
+	search := c.Query("search")
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	filters, err := filter.Parse(c, service.ReasonFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := export.Write(c, "reasons", reasonExportColumns, func(offset, limit int) ([]model.MstReason, error) {
+		return h.reasonService.GetAll(offset, limit, search, path, key, uint(menuID), includeDeleted, filters)
+	}); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return nil
+}
+
 // GetReason godoc
 // @Summary Get reason by ID
 // @Description Retrieve a specific reason by its ID
@@ -100,7 +159,9 @@ func (h *ReasonHandler) GetReasons(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Reason ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Reason found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Reason not found"
 // @Router /admin/master/reason/{id} [get]
@@ -115,6 +176,10 @@ func (h *ReasonHandler) GetReason(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Reason not found"))
 	}
 
+	if etag.Set(c, reason) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Reason found successfully", reason)
 }
 
@@ -124,19 +189,23 @@ func (h *ReasonHandler) GetReason(c *fiber.Ctx) error {
 // @Tags Reason
 // @Accept json
 // @Produce json
-// @Param reason body model.MstReason true "Reason details"
+// @Param reason body dto.ReasonRequest true "Reason details"
 // @Success 201 {object} map[string]interface{} "Reason created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/master/reason [post]
 func (h *ReasonHandler) CreateReason(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
-	var reason model.MstReason
-	if err := c.BodyParser(&reason); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	var input dto.ReasonRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	var reason model.MstReason
+	input.Apply(&reason)
+
 	reason.IDCreatedby = userID
 	reason.IDUpdatedby = userID
 
@@ -152,6 +221,24 @@ func (h *ReasonHandler) CreateReason(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusCreated, "Reason created successfully", result)
 }
 
+// BulkReasons godoc
+// @Summary Create, update and delete reasons in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Reason
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.ReasonRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Reason bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/master/reason/bulk [post]
+func (h *ReasonHandler) BulkReasons(c *fiber.Ctx) error {
+	return h.bulk.Run(c)
+}
+
 // UpdateReason godoc
 // @Summary Update an existing reason
 // @Description Update the details of an existing reason by its ID
@@ -159,10 +246,14 @@ func (h *ReasonHandler) CreateReason(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Reason ID"
-// @Param reason body model.MstReason true "Updated reason details"
+// @Param reason body dto.ReasonRequest true "Updated reason details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Reason updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Reason not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/master/reason/{id} [put]
 func (h *ReasonHandler) UpdateReason(c *fiber.Ctx) error {
@@ -179,14 +270,26 @@ func (h *ReasonHandler) UpdateReason(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Reason not found"))
 	}
 
-	if err := c.BodyParser(reason); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	if ok, err := etag.Check(c, reason); !ok {
+		return err
 	}
 
+	var input dto.ReasonRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	input.Apply(reason)
+
 	reason.ID = uint(ID)
 	reason.IDUpdatedby = userID
 
 	err = h.reasonService.Update(reason)
+	if errors.Is(err, service.ErrStale) {
+		return etag.Stale(c, func() (interface{}, error) {
+			return h.reasonService.GetByID(uint(ID))
+		})
+	}
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -219,6 +322,8 @@ func (h *ReasonHandler) DeleteReason(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Reason not found"))
 	}
 
+	user.IDUpdatedby = c.Locals("userID").(uint)
+
 	err = h.reasonService.Delete(user)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
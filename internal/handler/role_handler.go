@@ -1,6 +1,12 @@
 package handler
 
 import (
+	"errors"
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/export"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
@@ -9,12 +15,21 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+var roleExportColumns = []export.Column{
+	{Header: "Name", Field: "Name"},
+	{Header: "Created By", Field: "CreatedBy.Name"},
+	{Header: "Created At", Field: "CreatedAt"},
+	{Header: "Updated By", Field: "UpdatedBy.Name"},
+	{Header: "Updated At", Field: "UpdatedAt"},
+}
+
 type RoleHandler struct {
 	roleService *service.RoleService
+	bulk        *crud.Bulk[model.MstRole]
 }
 
-func NewRoleHandler(roleService *service.RoleService) *RoleHandler {
-	return &RoleHandler{roleService: roleService}
+func NewRoleHandler(roleService *service.RoleService, bulk *crud.Bulk[model.MstRole]) *RoleHandler {
+	return &RoleHandler{roleService: roleService, bulk: bulk}
 }
 
 // GetRoles godoc
@@ -26,6 +41,8 @@ func NewRoleHandler(roleService *service.RoleService) *RoleHandler {
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering role"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -37,13 +54,24 @@ func (h *RoleHandler) GetRoles(c *fiber.Ctx) error {
 	sortBy := c.Query("sortBy", "")
 	sortDirection := c.QueryBool("sortDirection")
 	offset := (page - 1) * rows
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.RoleFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
 
-	total, err := h.roleService.GetTotal(search)
+	filters, err := filter.Parse(c, service.RoleFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.roleService.GetTotal(search, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	roles, err := h.roleService.GetAll(offset, rows, search, sortBy, sortDirection)
+	roles, err := h.roleService.GetAll(offset, rows, search, sorts, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -91,6 +119,41 @@ func (h *RoleHandler) GetRoles(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
 }
 
+// ExportRoles godoc
+// @Summary Export Roles
+// @Description Downloads the roles matching the list parameters as an Excel or CSV file
+// @Tags Role
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/master/role/export [get]
+func (h *RoleHandler) ExportRoles(c *fiber.Ctx) error {
+	search := c.Query("search")
+	sortBy := c.Query("sortBy", "")
+	sortDirection := c.QueryBool("sortDirection")
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.RoleFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.RoleFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := export.Write(c, "roles", roleExportColumns, func(offset, limit int) ([]model.MstRole, error) {
+		return h.roleService.GetAll(offset, limit, search, sorts, includeDeleted, filters)
+	}); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return nil
+}
+
 // GetRole godoc
 // @Summary Get role by ID
 // @Description Retrieve a specific role by its ID
@@ -98,7 +161,9 @@ func (h *RoleHandler) GetRoles(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Role ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Role found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Role not found"
 // @Router /admin/master/role/{id} [get]
@@ -113,6 +178,10 @@ func (h *RoleHandler) GetRole(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Role not found"))
 	}
 
+	if etag.Set(c, role) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Role found successfully", role)
 }
 
@@ -122,19 +191,23 @@ func (h *RoleHandler) GetRole(c *fiber.Ctx) error {
 // @Tags Role
 // @Accept json
 // @Produce json
-// @Param role body model.MstRole true "Role details"
+// @Param role body dto.RoleRequest true "Role details"
 // @Success 201 {object} map[string]interface{} "Role created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/master/role [post]
 func (h *RoleHandler) CreateRole(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
-	var role model.MstRole
-	if err := c.BodyParser(&role); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	var input dto.RoleRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	var role model.MstRole
+	input.Apply(&role)
+
 	role.IDCreatedby = userID
 	role.IDUpdatedby = userID
 
@@ -150,6 +223,24 @@ func (h *RoleHandler) CreateRole(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusCreated, "Role created successfully", result)
 }
 
+// BulkRoles godoc
+// @Summary Create, update and delete roles in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Role
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.RoleRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Role bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/master/role/bulk [post]
+func (h *RoleHandler) BulkRoles(c *fiber.Ctx) error {
+	return h.bulk.Run(c)
+}
+
 // UpdateRole godoc
 // @Summary Update an existing role
 // @Description Update the details of an existing role by its ID
@@ -157,10 +248,14 @@ func (h *RoleHandler) CreateRole(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Role ID"
-// @Param role body model.MstRole true "Updated role details"
+// @Param role body dto.RoleRequest true "Updated role details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Role updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Role not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/master/role/{id} [put]
 func (h *RoleHandler) UpdateRole(c *fiber.Ctx) error {
@@ -177,14 +272,26 @@ func (h *RoleHandler) UpdateRole(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Role not found"))
 	}
 
-	if err := c.BodyParser(role); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	if ok, err := etag.Check(c, role); !ok {
+		return err
 	}
 
+	var input dto.RoleRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	input.Apply(role)
+
 	role.ID = uint(ID)
 	role.IDUpdatedby = userID
 
 	err = h.roleService.Update(role)
+	if errors.Is(err, service.ErrStale) {
+		return etag.Stale(c, func() (interface{}, error) {
+			return h.roleService.GetByID(uint(ID))
+		})
+	}
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -217,6 +324,8 @@ func (h *RoleHandler) DeleteRole(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Role not found"))
 	}
 
+	user.IDUpdatedby = c.Locals("userID").(uint)
+
 	err = h.roleService.Delete(user)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
@@ -27,6 +30,7 @@ func NewRoleMenuHandler(roleMenuService *service.RoleMenuService) *RoleMenuHandl
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering Role Menu"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -37,12 +41,17 @@ func (h *RoleMenuHandler) GetRoleMenus(c *fiber.Ctx) error {
 	search := c.Query("search")
 	offset := (page - 1) * rows
 
-	total, err := h.roleMenuService.GetTotal(search)
+	filters, err := filter.Parse(c, service.RoleMenuFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.roleMenuService.GetTotal(search, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	roleMenus, err := h.roleMenuService.GetAll(offset, rows, search)
+	roleMenus, err := h.roleMenuService.GetAll(offset, rows, search, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -97,7 +106,7 @@ func (h *RoleMenuHandler) GetRoleMenus(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Role Menu ID"
-// @Success 200 {object} map[string]interface{} "Role Menu found successfully"
+// @Success 200 {object} map[string]interface{} "Role Menu found successfully, with the ETag of the role"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Role Menu not found"
 // @Router /admin/master/role-menu/{id} [get]
@@ -112,26 +121,46 @@ func (h *RoleMenuHandler) GetRoleMenu(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Role Menu not found"))
 	}
 
+	// The ETag is the version of the role, which replacing its menus moves.
+	// It leaves out the menus themselves, so it does not answer If-None-Match.
+	if tag := etag.Of(roleMenu); tag != "" {
+		c.Set(fiber.HeaderETag, tag)
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Role Menu found successfully", roleMenu)
 }
 
 // UpdateRoleMenu godoc
-// @Summary Update a new Role Menu
-// @Description Update a new Role Menu with the provided details
+// @Summary Set the menus of a role
+// @Description Replace the menus of a role by its ID with the provided ones
 // @Tags Role Menu
 // @Accept json
 // @Produce json
+// @Param id path int true "Role ID"
 // @Param RoleMenu body dto.RoleMenus true "Role Menu details"
+// @Param If-Match header string true "ETag of the role as read, the update is rejected when it changed since"
 // @Success 201 {object} map[string]interface{} "Role Menu created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 404 {object} map[string]interface{} "Not Found: Role not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
-// @Router /admin/master/role-menu [post]
+// @Router /admin/master/role-menu/{id} [put]
 func (h *RoleMenuHandler) UpdateRoleMenu(c *fiber.Ctx) error {
 	ID, err := c.ParamsInt("id")
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
 	}
 
+	role, err := h.roleMenuService.GetByID(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Role not found"))
+	}
+
+	if ok, err := etag.Check(c, role); !ok {
+		return err
+	}
+
 	var input dto.RoleMenus
 	if err := c.BodyParser(&input); err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
@@ -145,12 +174,12 @@ func (h *RoleMenuHandler) UpdateRoleMenu(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.roleMenuService.Delete(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	err = h.roleMenuService.Replace(role, roleMenus)
+	if errors.Is(err, service.ErrStale) {
+		return etag.Stale(c, func() (interface{}, error) {
+			return h.roleMenuService.GetByID(uint(ID))
+		})
 	}
-
-	err = h.roleMenuService.Create(&roleMenus)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"errors"
 	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
@@ -23,7 +25,7 @@ func NewRolePermissionHandler(rolePermissionService *service.RolePermissionServi
 // @Tags Role Permission
 // @Produce json
 // @Param id path int true "Role ID"
-// @Success 200 {object} map[string]interface{} "Menu tree role permission retrieved successfully"
+// @Success 200 {object} map[string]interface{} "Menu tree role permission retrieved successfully, with the ETag of the role"
 // @Failure 400 {object} map[string]interface{} "Bad Request"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/role-permission/{id} [get]
@@ -38,6 +40,14 @@ func (h *RolePermissionHandler) GetMenuTreeByRole(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
+	// The ETag is the version of the role, which setting a permission moves.
+	// It leaves out the menus, so it does not answer If-None-Match.
+	if role, err := h.rolePermissionService.GetRole(uint(ID)); err == nil {
+		if tag := etag.Of(role); tag != "" {
+			c.Set(fiber.HeaderETag, tag)
+		}
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Menu tree role permission retrieved successfully", tree)
 }
 
@@ -45,27 +55,15 @@ func (h *RolePermissionHandler) GetMenuByPath(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 	path := c.Query("path")
 
-	menus, err := h.rolePermissionService.GetByPath(userID, path)
+	result, err := h.rolePermissionService.GetPermission(userID, path)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Menu not found"))
 	}
 
-	if len(menus) == 0 {
+	if result == nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Menu not found"))
 	}
 
-	var result dto.MenuWithPermissions
-
-	for i, menu := range menus {
-		if i == 0 {
-			result = menu
-		} else {
-			result.IsCreate = result.IsCreate || menu.IsCreate
-			result.IsUpdate = result.IsUpdate || menu.IsUpdate
-			result.IsDelete = result.IsDelete || menu.IsDelete
-		}
-	}
-
 	return pkg.Response(c, fiber.StatusOK, "Menu found successfully", result)
 }
 
@@ -75,18 +73,40 @@ func (h *RolePermissionHandler) GetMenuByPath(c *fiber.Ctx) error {
 // @Tags Role Permission
 // @Accept json
 // @Produce json
-// @Param rolePermission body model.MstRolePermission true "Role Permission data"
+// @Param rolePermission body dto.RolePermissionRequest true "Role Permission data"
+// @Param If-Match header string true "ETag of the role as read, the update is rejected when it changed since"
 // @Success 201 {object} map[string]interface{} "Role permission created or updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request"
+// @Failure 404 {object} map[string]interface{} "Not Found: Role not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/role-permission [post]
 func (h *RolePermissionHandler) UpdateOrCreateRolePermission(c *fiber.Ctx) error {
-	var rolePermission model.MstRolePermission
-	if err := c.BodyParser(&rolePermission); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	var input dto.RolePermissionRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
-	err := h.rolePermissionService.UpdateOrCreateRolePermission(&rolePermission)
+	role, err := h.rolePermissionService.GetRole(input.IDRole)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Role not found"))
+	}
+
+	if ok, err := etag.Check(c, role); !ok {
+		return err
+	}
+
+	var rolePermission model.MstRolePermission
+	input.Apply(&rolePermission)
+
+	err = h.rolePermissionService.UpdateOrCreateRolePermission(role, &rolePermission)
+	if errors.Is(err, service.ErrStale) {
+		return etag.Stale(c, func() (interface{}, error) {
+			return h.rolePermissionService.GetRole(input.IDRole)
+		})
+	}
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -1,95 +1,67 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// SectionHandler documents the section endpoints, which are served by crud.Resource.
 type SectionHandler struct {
-	sectionService *service.SectionService
+	resource *crud.Resource[model.MstSection]
 }
 
-func NewSectionHandler(sectionService *service.SectionService) *SectionHandler {
-	return &SectionHandler{sectionService: sectionService}
+// NewSectionHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewSectionHandler(db *gorm.DB, resource crud.Resource[model.MstSection]) *SectionHandler {
+	resource.Input = func() dto.Input[model.MstSection] { return &dto.SectionRequest{} }
+
+	return &SectionHandler{resource: crud.New(db, resource)}
 }
 
 // GetSections godoc
 // @Summary Get a list of sections
-// @Description Retrieves sections with pagination and optional search
+// @Description Retrieves sections by page or by cursor, with optional search, filters and sorting
 // @Tags Section
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering section"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Param id_fcs query string false "Filter by id_fcs"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /prd/master/section [get]
 func (h *SectionHandler) GetSections(c *fiber.Ctx) error {
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	idFCS := c.QueryInt("id_fcs")
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.sectionService.GetTotal(search, uint(idFCS))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	sections, err := h.sectionService.GetAll(offset, rows, search, sortBy, sortDirection, uint(idFCS))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start *int
-	if int(total) == 0 {
-		start = nil
-	} else {
-		value := offset + 1
-		start = &value
-	}
-
-	var end *int
-	if int(total) == 0 {
-		end = nil
-	} else {
-		value := int(math.Min(float64(offset+rows), float64(total)))
-		end = &value
-	}
-	var nextPage *int
-	if page < totalPages {
-		nextPageVal := page + 1
-		nextPage = &nextPageVal
-	}
-
-	result := map[string]interface{}{
-		"items": sections,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(sections) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportSections godoc
+// @Summary Export sections
+// @Description Downloads the sections matching the list parameters as an Excel or CSV file
+// @Tags Section
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering section"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /prd/master/section/export [get]
+func (h *SectionHandler) ExportSections(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetSection godoc
@@ -99,22 +71,14 @@ func (h *SectionHandler) GetSections(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Section ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Section found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Section not found"
 // @Router /prd/master/section/{id} [get]
 func (h *SectionHandler) GetSection(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	section, err := h.sectionService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Section not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Section found successfully", section)
+	return h.resource.Get(c)
 }
 
 // CreateSection godoc
@@ -123,32 +87,33 @@ func (h *SectionHandler) GetSection(c *fiber.Ctx) error {
 // @Tags Section
 // @Accept json
 // @Produce json
-// @Param section body model.MstSection true "Section details"
+// @Param section body dto.SectionRequest true "Section details"
 // @Success 201 {object} map[string]interface{} "Section created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /prd/master/section [post]
 func (h *SectionHandler) CreateSection(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var section model.MstSection
-	if err := c.BodyParser(&section); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	section.IDCreatedby = userID
-	section.IDUpdatedby = userID
-
-	err := h.sectionService.Create(&section)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": section.ID,
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "Section created successfully", result)
+// BulkSections godoc
+// @Summary Create, update and delete sections in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Section
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.SectionRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /prd/master/section/bulk [post]
+func (h *SectionHandler) BulkSections(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateSection godoc
@@ -158,70 +123,31 @@ func (h *SectionHandler) CreateSection(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Section ID"
-// @Param section body model.MstSection true "Updated section details"
+// @Param section body dto.SectionRequest true "Updated section details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Section updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Section not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /prd/master/section/{id} [put]
 func (h *SectionHandler) UpdateSection(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	var section *model.MstSection
-	section, err = h.sectionService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Section not found"))
-	}
-
-	if err := c.BodyParser(section); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	section.ID = uint(ID)
-	section.IDUpdatedby = userID
-
-	err = h.sectionService.Update(section)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": section.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Section updated successfully", result)
+	return h.resource.Update(c)
 }
 
 // DeleteSection godoc
 // @Summary Delete a section
-// @Description Delete a section by its ID
+// @Description Soft delete a section by its ID
 // @Tags Section
 // @Param id path int true "Section ID"
 // @Success 200 {object} map[string]interface{} "Section deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Section not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /prd/master/section/{id} [delete]
 func (h *SectionHandler) DeleteSection(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	user, err := h.sectionService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Section not found"))
-	}
-
-	err = h.sectionService.Delete(user)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Section deleted successfully", nil)
+	return h.resource.Delete(c)
 }
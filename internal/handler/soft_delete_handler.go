@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"errors"
+	"insist-backend-golang/internal/service"
+	"insist-backend-golang/pkg"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type SoftDeleteHandler struct {
+	softDeleteService *service.SoftDeleteService
+}
+
+func NewSoftDeleteHandler(softDeleteService *service.SoftDeleteService) *SoftDeleteHandler {
+	return &SoftDeleteHandler{softDeleteService: softDeleteService}
+}
+
+// Restore godoc
+// @Summary Restore a deleted record
+// @Description Restore a soft deleted master record by its ID. Every master resource exposes this as PUT /{resource}/{id}/restore
+// @Tags Soft Delete
+// @Param id path int true "Record ID"
+// @Success 200 {object} map[string]interface{} "Data restored successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 404 {object} map[string]interface{} "Not Found: Deleted data not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: An active record already uses the same unique value, e.g. a username"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+func (h *SoftDeleteHandler) Restore(resource interface{}) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ID, err := c.ParamsInt("id")
+		if err != nil {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+		}
+
+		err = h.softDeleteService.Restore(resource, uint(ID), c.Locals("userID").(uint))
+		if errors.Is(err, service.ErrNotDeleted) {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Deleted data not found"))
+		}
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusConflict, "Data cannot be restored because an active record already uses the same unique value"))
+		}
+		if err != nil {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+		}
+
+		return pkg.Response(c, fiber.StatusOK, "Data restored successfully", nil)
+	}
+}
@@ -1,227 +1,153 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// SubSectionHandler documents the sub section endpoints, which are served by crud.Resource.
 type SubSectionHandler struct {
-	subSectionService *service.SubSectionService
+	resource *crud.Resource[model.MstSubSection]
 }
 
-func NewSubSectionHandler(subSectionService *service.SubSectionService) *SubSectionHandler {
-	return &SubSectionHandler{subSectionService: subSectionService}
+// NewSubSectionHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewSubSectionHandler(db *gorm.DB, resource crud.Resource[model.MstSubSection]) *SubSectionHandler {
+	resource.Input = func() dto.Input[model.MstSubSection] { return &dto.SubSectionRequest{} }
+
+	return &SubSectionHandler{resource: crud.New(db, resource)}
 }
 
 // GetSubSections godoc
-// @Summary Get a list of subSections
-// @Description Retrieves subSections with pagination and optional search
+// @Summary Get a list of sub sections
+// @Description Retrieves sub sections by page or by cursor, with optional search, filters and sorting
 // @Tags SubSection
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
-// @Param search query string false "Search keyword for filtering subSection"
+// @Param search query string false "Search keyword for filtering sub section"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Param id_section query string false "Filter by id_section"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /prd/master/sub-section [get]
 func (h *SubSectionHandler) GetSubSections(c *fiber.Ctx) error {
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	idSection := c.QueryInt("id_section")
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.subSectionService.GetTotal(search, uint(idSection))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	subSections, err := h.subSectionService.GetAll(offset, rows, search, sortBy, sortDirection, uint(idSection))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start *int
-	if int(total) == 0 {
-		start = nil
-	} else {
-		value := offset + 1
-		start = &value
-	}
-
-	var end *int
-	if int(total) == 0 {
-		end = nil
-	} else {
-		value := int(math.Min(float64(offset+rows), float64(total)))
-		end = &value
-	}
-	var nextPage *int
-	if page < totalPages {
-		nextPageVal := page + 1
-		nextPage = &nextPageVal
-	}
-
-	result := map[string]interface{}{
-		"items": subSections,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(subSections) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportSubSections godoc
+// @Summary Export sub sections
+// @Description Downloads the sub sections matching the list parameters as an Excel or CSV file
+// @Tags SubSection
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering sub section"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /prd/master/sub-section/export [get]
+func (h *SubSectionHandler) ExportSubSections(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetSubSection godoc
-// @Summary Get subSection by ID
-// @Description Retrieve a specific subSection by its ID
+// @Summary Get sub section by ID
+// @Description Retrieve a specific sub section by its ID
 // @Tags SubSection
 // @Accept json
 // @Produce json
 // @Param id path int true "SubSection ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "SubSection found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: SubSection not found"
 // @Router /prd/master/sub-section/{id} [get]
 func (h *SubSectionHandler) GetSubSection(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	subSection, err := h.subSectionService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "SubSection not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "SubSection found successfully", subSection)
+	return h.resource.Get(c)
 }
 
 // CreateSubSection godoc
-// @Summary Create a new subSection
-// @Description Create a new subSection with the provided details
+// @Summary Create a new sub section
+// @Description Create a new sub section with the provided details
 // @Tags SubSection
 // @Accept json
 // @Produce json
-// @Param subSection body model.MstSubSection true "SubSection details"
+// @Param sub_section body dto.SubSectionRequest true "SubSection details"
 // @Success 201 {object} map[string]interface{} "SubSection created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /prd/master/sub-section [post]
 func (h *SubSectionHandler) CreateSubSection(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var subSection model.MstSubSection
-	if err := c.BodyParser(&subSection); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	subSection.IDCreatedby = userID
-	subSection.IDUpdatedby = userID
-
-	err := h.subSectionService.Create(&subSection)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": subSection.ID,
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "SubSection created successfully", result)
+// BulkSubSections godoc
+// @Summary Create, update and delete sub sections in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags SubSection
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.SubSectionRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /prd/master/sub-section/bulk [post]
+func (h *SubSectionHandler) BulkSubSections(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateSubSection godoc
-// @Summary Update an existing subSection
-// @Description Update the details of an existing subSection by its ID
+// @Summary Update an existing sub section
+// @Description Update the details of an existing sub section by its ID
 // @Tags SubSection
 // @Accept json
 // @Produce json
 // @Param id path int true "SubSection ID"
-// @Param subSection body model.MstSubSection true "Updated subSection details"
+// @Param sub_section body dto.SubSectionRequest true "Updated sub section details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "SubSection updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: SubSection not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /prd/master/sub-section/{id} [put]
 func (h *SubSectionHandler) UpdateSubSection(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	var subSection *model.MstSubSection
-	subSection, err = h.subSectionService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "SubSection not found"))
-	}
-
-	if err := c.BodyParser(subSection); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	subSection.ID = uint(ID)
-	subSection.IDUpdatedby = userID
-
-	err = h.subSectionService.Update(subSection)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": subSection.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "SubSection updated successfully", result)
+	return h.resource.Update(c)
 }
 
 // DeleteSubSection godoc
-// @Summary Delete a subSection
-// @Description Delete a subSection by its ID
+// @Summary Delete a sub section
+// @Description Soft delete a sub section by its ID
 // @Tags SubSection
 // @Param id path int true "SubSection ID"
 // @Success 200 {object} map[string]interface{} "SubSection deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: SubSection not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /prd/master/sub-section/{id} [delete]
 func (h *SubSectionHandler) DeleteSubSection(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	user, err := h.subSectionService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "SubSection not found"))
-	}
-
-	err = h.subSectionService.Delete(user)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "SubSection deleted successfully", nil)
+	return h.resource.Delete(c)
 }
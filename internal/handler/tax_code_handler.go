@@ -1,95 +1,66 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// TaxCodeHandler documents the tax code endpoints, which are served by crud.Resource.
 type TaxCodeHandler struct {
-	taxCodeService *service.TaxCodeService
+	resource *crud.Resource[model.MstTaxCode]
 }
 
-func NewTaxCodeHandler(taxCodeService *service.TaxCodeService) *TaxCodeHandler {
-	return &TaxCodeHandler{taxCodeService: taxCodeService}
+// NewTaxCodeHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewTaxCodeHandler(db *gorm.DB, resource crud.Resource[model.MstTaxCode]) *TaxCodeHandler {
+	resource.Input = func() dto.Input[model.MstTaxCode] { return &dto.TaxCodeRequest{} }
+
+	return &TaxCodeHandler{resource: crud.New(db, resource)}
 }
 
 // GetTaxCodes godoc
 // @Summary Get a list of tax codes
-// @Description Retrieves tax codes with pagination and optional search
+// @Description Retrieves tax codes by page or by cursor, with optional search, filters and sorting
 // @Tags Tax Code
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering tax code"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /acf/master/tax-code [get]
 func (h *TaxCodeHandler) GetTaxCodes(c *fiber.Ctx) error {
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.taxCodeService.GetTotal(search)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	taxCodes, err := h.taxCodeService.GetAll(offset, rows, search, sortBy, sortDirection)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start *int
-	if int(total) == 0 {
-		start = nil
-	} else {
-		value := offset + 1
-		start = &value
-	}
-
-	var end *int
-	if int(total) == 0 {
-		end = nil
-	} else {
-		value := int(math.Min(float64(offset+rows), float64(total)))
-		end = &value
-	}
-
-	var nextPage *int
-	if page < totalPages {
-		nextPageVal := page + 1
-		nextPage = &nextPageVal
-	}
-
-	result := map[string]interface{}{
-		"items": taxCodes,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(taxCodes) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportTaxCodes godoc
+// @Summary Export tax codes
+// @Description Downloads the tax codes matching the list parameters as an Excel or CSV file
+// @Tags Tax Code
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering tax code"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /acf/master/tax-code/export [get]
+func (h *TaxCodeHandler) ExportTaxCodes(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetTaxCode godoc
@@ -99,22 +70,14 @@ func (h *TaxCodeHandler) GetTaxCodes(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Tax Code ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Tax Code found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Tax Code not found"
 // @Router /acf/master/tax-code/{id} [get]
 func (h *TaxCodeHandler) GetTaxCode(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	taxCode, err := h.taxCodeService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Tax Code not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Tax Code found successfully", taxCode)
+	return h.resource.Get(c)
 }
 
 // CreateTaxCode godoc
@@ -123,32 +86,33 @@ func (h *TaxCodeHandler) GetTaxCode(c *fiber.Ctx) error {
 // @Tags Tax Code
 // @Accept json
 // @Produce json
-// @Param taxCode body model.MstTaxCode true "Tax Code details"
+// @Param tax_code body dto.TaxCodeRequest true "Tax Code details"
 // @Success 201 {object} map[string]interface{} "Tax Code created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /acf/master/tax-code [post]
 func (h *TaxCodeHandler) CreateTaxCode(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var taxCode model.MstTaxCode
-	if err := c.BodyParser(&taxCode); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	taxCode.IDCreatedby = userID
-	taxCode.IDUpdatedby = userID
-
-	err := h.taxCodeService.Create(&taxCode)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": taxCode.ID,
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "Tax Code created successfully", result)
+// BulkTaxCodes godoc
+// @Summary Create, update and delete tax codes in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Tax Code
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.TaxCodeRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /acf/master/tax-code/bulk [post]
+func (h *TaxCodeHandler) BulkTaxCodes(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateTaxCode godoc
@@ -158,70 +122,31 @@ func (h *TaxCodeHandler) CreateTaxCode(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Tax Code ID"
-// @Param taxCode body model.MstTaxCode true "Updated tax code details"
+// @Param tax_code body dto.TaxCodeRequest true "Updated tax code details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Tax Code updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Tax Code not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /acf/master/tax-code/{id} [put]
 func (h *TaxCodeHandler) UpdateTaxCode(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	var taxCode *model.MstTaxCode
-	taxCode, err = h.taxCodeService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Tax Code not found"))
-	}
-
-	if err := c.BodyParser(taxCode); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	taxCode.ID = uint(ID)
-	taxCode.IDUpdatedby = userID
-
-	err = h.taxCodeService.Update(taxCode)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": taxCode.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Tax Code updated successfully", result)
+	return h.resource.Update(c)
 }
 
 // DeleteTaxCode godoc
 // @Summary Delete a tax code
-// @Description Delete a tax code by its ID
+// @Description Soft delete a tax code by its ID
 // @Tags Tax Code
 // @Param id path int true "Tax Code ID"
 // @Success 200 {object} map[string]interface{} "Tax Code deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Tax Code not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /acf/master/tax-code/{id} [delete]
 func (h *TaxCodeHandler) DeleteTaxCode(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	user, err := h.taxCodeService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Tax Code not found"))
-	}
-
-	err = h.taxCodeService.Delete(user)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Tax Code deleted successfully", nil)
+	return h.resource.Delete(c)
 }
@@ -1,95 +1,66 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// UoMHandler documents the uom endpoints, which are served by crud.Resource.
 type UoMHandler struct {
-	uomService *service.UoMService
+	resource *crud.Resource[model.MstUoms]
 }
 
-func NewUoMHandler(uomService *service.UoMService) *UoMHandler {
-	return &UoMHandler{uomService: uomService}
+// NewUoMHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewUoMHandler(db *gorm.DB, resource crud.Resource[model.MstUoms]) *UoMHandler {
+	resource.Input = func() dto.Input[model.MstUoms] { return &dto.UoMRequest{} }
+
+	return &UoMHandler{resource: crud.New(db, resource)}
 }
 
 // GetUoMs godoc
 // @Summary Get a list of uoms
-// @Description Retrieves uoms with pagination and optional search
+// @Description Retrieves uoms by page or by cursor, with optional search, filters and sorting
 // @Tags UoM
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering uom"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /egd/master/uom [get]
 func (h *UoMHandler) GetUoMs(c *fiber.Ctx) error {
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.uomService.GetTotal(search)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	uoms, err := h.uomService.GetAll(offset, rows, search, sortBy, sortDirection)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start *int
-	if int(total) == 0 {
-		start = nil
-	} else {
-		value := offset + 1
-		start = &value
-	}
-
-	var end *int
-	if int(total) == 0 {
-		end = nil
-	} else {
-		value := int(math.Min(float64(offset+rows), float64(total)))
-		end = &value
-	}
-
-	var nextPage *int
-	if page < totalPages {
-		nextPageVal := page + 1
-		nextPage = &nextPageVal
-	}
-
-	result := map[string]interface{}{
-		"items": uoms,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(uoms) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportUoMs godoc
+// @Summary Export uoms
+// @Description Downloads the uoms matching the list parameters as an Excel or CSV file
+// @Tags UoM
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering uom"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /egd/master/uom/export [get]
+func (h *UoMHandler) ExportUoMs(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetUoM godoc
@@ -99,22 +70,14 @@ func (h *UoMHandler) GetUoMs(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "UoM ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "UoM found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: UoM not found"
 // @Router /egd/master/uom/{id} [get]
 func (h *UoMHandler) GetUoM(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	uom, err := h.uomService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "UoM not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "UoM found successfully", uom)
+	return h.resource.Get(c)
 }
 
 // CreateUoM godoc
@@ -123,32 +86,33 @@ func (h *UoMHandler) GetUoM(c *fiber.Ctx) error {
 // @Tags UoM
 // @Accept json
 // @Produce json
-// @Param uom body model.MstUoms true "UoM details"
+// @Param uom body dto.UoMRequest true "UoM details"
 // @Success 201 {object} map[string]interface{} "UoM created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /egd/master/uom [post]
 func (h *UoMHandler) CreateUoM(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var uom model.MstUoms
-	if err := c.BodyParser(&uom); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	uom.IDCreatedby = userID
-	uom.IDUpdatedby = userID
-
-	err := h.uomService.Create(&uom)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": uom.ID,
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "UoM created successfully", result)
+// BulkUoMs godoc
+// @Summary Create, update and delete uoms in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags UoM
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.UoMRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /egd/master/uom/bulk [post]
+func (h *UoMHandler) BulkUoMs(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateUoM godoc
@@ -158,70 +122,31 @@ func (h *UoMHandler) CreateUoM(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "UoM ID"
-// @Param uom body model.MstUoms true "Updated uom details"
+// @Param uom body dto.UoMRequest true "Updated uom details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "UoM updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: UoM not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /egd/master/uom/{id} [put]
 func (h *UoMHandler) UpdateUoM(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	var uom *model.MstUoms
-	uom, err = h.uomService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "UoM not found"))
-	}
-
-	if err := c.BodyParser(uom); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	uom.ID = uint(ID)
-	uom.IDUpdatedby = userID
-
-	err = h.uomService.Update(uom)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": uom.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "UoM updated successfully", result)
+	return h.resource.Update(c)
 }
 
 // DeleteUoM godoc
-// @Summary Delete a uom
-// @Description Delete a uom by its ID
+// @Summary Delete an uom
+// @Description Soft delete an uom by its ID
 // @Tags UoM
 // @Param id path int true "UoM ID"
 // @Success 200 {object} map[string]interface{} "UoM deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: UoM not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /egd/master/uom/{id} [delete]
 func (h *UoMHandler) DeleteUoM(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	user, err := h.uomService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "UoM not found"))
-	}
-
-	err = h.uomService.Delete(user)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "UoM deleted successfully", nil)
+	return h.resource.Delete(c)
 }
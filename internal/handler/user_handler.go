@@ -1,21 +1,47 @@
 package handler
 
 import (
+	"errors"
 	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/export"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
 	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+var userExportColumns = []export.Column{
+	{Header: "Name", Field: "Name"},
+	{Header: "Username", Field: "Username"},
+	{Header: "Email", Field: "Email"},
+	{Header: "Department", Field: "Dept.Code"},
+	{Header: "Active", Field: "IsActive"},
+	{Header: "Two Factor", Field: "IsTwoFa"},
+	{Header: "Created By", Field: "CreatedBy.Name"},
+	{Header: "Created At", Field: "CreatedAt"},
+	{Header: "Updated By", Field: "UpdatedBy.Name"},
+	{Header: "Updated At", Field: "UpdatedAt"},
+}
+
 type UserHandler struct {
-	userService *service.UserService
+	userService          *service.UserService
+	userSessionService   *service.UserSessionService
+	loginThrottleService *service.LoginThrottleService
+	twoFactorService     *service.TwoFactorService
 }
 
-func NewUserHandler(userService *service.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService *service.UserService, userSessionService *service.UserSessionService, loginThrottleService *service.LoginThrottleService, twoFactorService *service.TwoFactorService) *UserHandler {
+	return &UserHandler{
+		userService:          userService,
+		userSessionService:   userSessionService,
+		loginThrottleService: loginThrottleService,
+		twoFactorService:     twoFactorService,
+	}
 }
 
 // GetUsers godoc
@@ -27,6 +53,8 @@ func NewUserHandler(userService *service.UserService) *UserHandler {
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of users per page" default(20)
 // @Param search query string false "Search keyword for filtering users"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -40,13 +68,24 @@ func (h *UserHandler) GetUsers(c *fiber.Ctx) error {
 	sortBy := c.Query("sortBy", "")
 	sortDirection := c.QueryBool("sortDirection")
 	offset := (page - 1) * rows
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.UserFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
 
-	total, err := h.userService.GetTotal(search, uint(idDept), isActive)
+	filters, err := filter.Parse(c, service.UserFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.userService.GetTotal(search, uint(idDept), isActive, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	users, err := h.userService.GetAll(offset, rows, search, uint(idDept), isActive, sortBy, sortDirection)
+	users, err := h.userService.GetAll(offset, rows, search, uint(idDept), isActive, sorts, includeDeleted, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -76,7 +115,7 @@ func (h *UserHandler) GetUsers(c *fiber.Ctx) error {
 	}
 
 	result := map[string]interface{}{
-		"items": users,
+		"items": model.UserViews(users),
 		"pagination": map[string]interface{}{
 			"current_page":  page,
 			"next_page":     nextPage,
@@ -95,6 +134,43 @@ func (h *UserHandler) GetUsers(c *fiber.Ctx) error {
 	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
 }
 
+// ExportUsers godoc
+// @Summary Export Users
+// @Description Downloads the users matching the list parameters as an Excel or CSV file
+// @Tags Users
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/master/users/export [get]
+func (h *UserHandler) ExportUsers(c *fiber.Ctx) error {
+	search := c.Query("search")
+	idDept := c.QueryInt("idDept", 0)
+	isActive := c.Query("isActive", "")
+	sortBy := c.Query("sortBy", "")
+	sortDirection := c.QueryBool("sortDirection")
+	includeDeleted := c.QueryBool("includeDeleted")
+
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.UserFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.UserFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := export.Write(c, "users", userExportColumns, func(offset, limit int) ([]model.MstUser, error) {
+		return h.userService.GetAll(offset, limit, search, uint(idDept), isActive, sorts, includeDeleted, filters)
+	}); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return nil
+}
+
 // GetUser godoc
 // @Summary Get a user by ID
 // @Description Retrieves user details based on the provided user ID
@@ -102,7 +178,9 @@ func (h *UserHandler) GetUsers(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "User found successfully"
+// @Success 304 "Not Modified"
 // @Failure 404 {object} map[string]interface{} "Not Found: User not found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/master/users/{id} [get]
@@ -117,7 +195,11 @@ func (h *UserHandler) GetUser(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "User not found"))
 	}
 
-	return pkg.Response(c, fiber.StatusOK, "User found successfully", users)
+	if etag.Set(c, users) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "User found successfully", users.View())
 }
 
 // CreateUser godoc
@@ -126,19 +208,23 @@ func (h *UserHandler) GetUser(c *fiber.Ctx) error {
 // @Tags Users
 // @Accept json
 // @Produce json
-// @Param user body model.MstUser true "User data"
+// @Param user body dto.CreateUser true "User data"
 // @Success 201 {object} map[string]interface{} "User created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/master/users [post]
 func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
-	var user model.MstUser
-	if err := c.BodyParser(&user); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	var input dto.CreateUser
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
+	var user model.MstUser
+	input.Apply(&user)
+
 	hashedPassword, err := pkg.HashPassword(user.Password)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
@@ -150,6 +236,9 @@ func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
 
 	err = h.userService.Create(&user)
 	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusConflict, "Username or email already exists"))
+		}
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
@@ -167,10 +256,14 @@ func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id query int true "User ID"
-// @Param user body model.MstUser true "Updated user data"
+// @Param user body dto.UpdateUser true "Updated user data"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "User updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: User not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /admin/master/users/{id} [put]
 func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
@@ -181,26 +274,39 @@ func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, "Invalid user ID"))
 	}
 
-	_, err = h.userService.GetByID(uint(ID))
+	user, err := h.userService.GetByID(uint(ID))
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "User not found"))
 	}
 
-	var updatedUser model.MstUser
-	if err := c.BodyParser(&updatedUser); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+	if ok, err := etag.Check(c, user); !ok {
+		return err
+	}
+
+	var input dto.UpdateUser
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
 	}
 
-	updatedUser.ID = uint(ID)
-	updatedUser.IDUpdatedby = userID
+	input.Apply(user)
+
+	user.IDUpdatedby = userID
 
-	err = h.userService.Update(&updatedUser)
+	err = h.userService.Update(user)
+	if errors.Is(err, service.ErrStale) {
+		return etag.Stale(c, func() (interface{}, error) {
+			return h.userService.GetByID(uint(ID))
+		})
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusConflict, "Username or email already exists"))
+	}
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, "Failed to update user"))
 	}
 
 	result := map[string]interface{}{
-		"id": updatedUser.ID,
+		"id": user.ID,
 	}
 
 	return pkg.Response(c, fiber.StatusOK, "User updated successfully", result)
@@ -228,17 +334,23 @@ func (h *UserHandler) DeleteUser(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "User not found"))
 	}
 
+	user.IDUpdatedby = c.Locals("userID").(uint)
+
 	err = h.userService.Delete(user)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
+	if err := h.userSessionService.RevokeByUser(user.ID, 0, service.SessionRevokedByAdmin); err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "User deleted successfully", nil)
 }
 
 // ChangePassword godoc
 // @Summary Change user password user
-// @Description Changes the password for user
+// @Description Changes the password for user and signs out all of the user's sessions
 // @Tags Users
 // @Accept json
 // @Produce json
@@ -279,5 +391,144 @@ func (h *UserHandler) ChangePassword(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
+	err = h.userSessionService.RevokeByUser(uint(ID), 0, service.SessionRevokedPasswordChange)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "Password changed successfully", nil)
 }
+
+// GetUserSessions godoc
+// @Summary Get active sessions of a user
+// @Description Retrieve the active sessions of the specified user
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{} "Sessions found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 404 {object} map[string]interface{} "Not Found: User not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/master/users/{id}/sessions [get]
+func (h *UserHandler) GetUserSessions(c *fiber.Ctx) error {
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	_, err = h.userService.GetByID(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "User not found"))
+	}
+
+	sessions, err := h.userSessionService.GetActiveByUser(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Sessions found successfully", sessions)
+}
+
+// RevokeUserSessions godoc
+// @Summary Revoke all sessions of a user
+// @Description Sign the specified user out of every device
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{} "Sessions revoked successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 404 {object} map[string]interface{} "Not Found: User not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/master/users/{id}/sessions [delete]
+func (h *UserHandler) RevokeUserSessions(c *fiber.Ctx) error {
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	_, err = h.userService.GetByID(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "User not found"))
+	}
+
+	err = h.userSessionService.RevokeByUser(uint(ID), 0, service.SessionRevokedByAdmin)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Sessions revoked successfully", nil)
+}
+
+// UnlockUser godoc
+// @Summary Unlock a user locked out by failed logins
+// @Description Clears the failed login counter of the specified user so they can log in again immediately
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{} "User unlocked successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 404 {object} map[string]interface{} "Not Found: User not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/master/users/{id}/unlock [put]
+func (h *UserHandler) UnlockUser(c *fiber.Ctx) error {
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	user, err := h.userService.GetByID(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "User not found"))
+	}
+
+	lockedUntil, err := h.loginThrottleService.GetLockedUntil(user.Username)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	err = h.loginThrottleService.Unlock(user.Username)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	result := map[string]interface{}{
+		"was_locked":   lockedUntil != nil,
+		"locked_until": lockedUntil,
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "User unlocked successfully", result)
+}
+
+// ResetTwoFactorAuth godoc
+// @Summary Reset two-factor authentication of a user
+// @Description Clears the OTP key, pending enrollment and recovery codes of the specified user, e.g. after a lost phone; the user enrolls again through /auth/two-fa/enroll. The secret is never returned.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{} "Two-factor authentication reset successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 404 {object} map[string]interface{} "Not Found: User not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /admin/master/users/{id}/two-fa/reset [put]
+func (h *UserHandler) ResetTwoFactorAuth(c *fiber.Ctx) error {
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	_, err = h.userService.GetByID(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "User not found"))
+	}
+
+	err = h.twoFactorService.Reset(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Two-factor authentication reset successfully", nil)
+}
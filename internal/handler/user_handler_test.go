@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/model"
+	"insist-backend-golang/internal/service"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// secretFields are the JSON keys credential material would be sent under.
+var secretFields = []string{
+	"password",
+	"refresh_token",
+	"otp_key",
+	"otp_url",
+	"otp_pending_key",
+	"otp_pending_url",
+}
+
+var userColumns = []string{
+	"id", "id_dept", "name", "email", "username", "password", "refresh_token",
+	"otp_key", "otp_url", "otp_last_step", "otp_pending_key", "otp_pending_url",
+	"is_active", "is_two_fa", "id_createdby", "id_updatedby", "created_at",
+	"updated_at", "deleted_at", "id_deletedby",
+}
+
+func userRow(ID int64, name string) []driver.Value {
+	now := time.Now()
+	return []driver.Value{
+		ID, int64(1), name, name + "@example.com", name, "secret-password-hash", "secret-refresh-token",
+		"SECRETOTPKEY", "otpauth://totp/secret-otp-url", int64(123456), "SECRETPENDINGKEY", "otpauth://totp/secret-pending-url",
+		true, true, int64(1), int64(1), now,
+		now, nil, nil,
+	}
+}
+
+// usersDB answers every query on mst_users with two users whose credential
+// columns are filled, whatever columns the query selects, so a response can
+// only leave them out by how it is serialized.
+func usersDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	tables := map[string]fakeTable{
+		"mst_users": {columns: userColumns, rows: [][]driver.Value{userRow(2, "jane"), userRow(1, "admin")}},
+		"mst_banks": {
+			columns: []string{"id", "code", "name", "id_createdby", "id_updatedby", "updated_at"},
+			rows:    [][]driver.Value{{int64(1), "BCA", "Bank Central Asia", int64(1), int64(2), time.Now()}},
+		},
+	}
+
+	name := fmt.Sprintf("fake-%s-%d", t.Name(), atomic.AddInt64(&fakeDrivers, 1))
+	sql.Register(name, fakeDriver{tables: tables})
+
+	conn, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("gorm: %v", err)
+	}
+
+	return db
+}
+
+func TestUserResponsesLeaveOutSecrets(t *testing.T) {
+	db := usersDB(t)
+
+	userHandler := NewUserHandler(service.NewUserService(db), nil, nil, nil)
+	authHandler := NewAuthHandler(service.NewAuthService(db), nil, nil, nil, nil, nil, nil)
+	bankHandler := NewBankHandler(db, crud.Resource[model.MstBank]{
+		Name: "Bank",
+		Preloads: []crud.Preload{
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+	})
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", uint(2))
+		return c.Next()
+	})
+	app.Get("/users", userHandler.GetUsers)
+	app.Get("/users/:id", userHandler.GetUser)
+	app.Get("/auth/user-info", authHandler.GetUserInfo)
+	app.Get("/banks", bankHandler.GetBanks)
+	app.Get("/banks/:id", bankHandler.GetBank)
+
+	for _, path := range []string{"/users", "/users/2", "/auth/user-info", "/banks", "/banks/1"} {
+		t.Run(path, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil), -1)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+
+			body := string(data)
+			if resp.StatusCode != fiber.StatusOK {
+				t.Fatalf("status %d: %s", resp.StatusCode, body)
+			}
+
+			if !strings.Contains(body, `"username":"jane"`) && !strings.Contains(body, `"created_by":{"id":1`) {
+				t.Fatalf("no user in the response: %s", body)
+			}
+
+			for _, key := range secretFields {
+				if strings.Contains(body, `"`+key+`"`) {
+					t.Errorf("%s is serialized: %s", key, body)
+				}
+			}
+
+			if strings.Contains(strings.ToLower(body), "secret") {
+				t.Errorf("a secret value is serialized: %s", body)
+			}
+		})
+	}
+}
+
+var fakeDrivers int64
+
+type fakeTable struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+// fakeDriver answers a query with the rows of the first table it reads from,
+// or their count. Of its conditions only one on the id is applied. Writes
+// affect nothing.
+type fakeDriver struct {
+	tables map[string]fakeTable
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	return fakeConn(d), nil
+}
+
+type fakeConn fakeDriver
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{conn: c, query: query}, nil
+}
+
+func (c fakeConn) Close() error {
+	return nil
+}
+
+func (c fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+var (
+	fromTable = regexp.MustCompile(`(?i)FROM "?(\w+)"?`)
+	whereID   = regexp.MustCompile(`"id" (?:= (\$\d+|\d+)|IN \(([^)]*)\))`)
+)
+
+type fakeStmt struct {
+	conn  fakeConn
+	query string
+}
+
+func (s fakeStmt) Close() error {
+	return nil
+}
+
+func (s fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	var table fakeTable
+	if match := fromTable.FindStringSubmatch(s.query); match != nil {
+		table = s.conn.tables[match[1]]
+	}
+
+	rows := table.rows
+	if match := whereID.FindStringSubmatch(s.query); match != nil {
+		IDs := map[string]bool{}
+		for _, value := range strings.Split(match[1]+match[2], ",") {
+			value = strings.TrimSpace(value)
+			if strings.HasPrefix(value, "$") {
+				var n int
+				fmt.Sscanf(value, "$%d", &n)
+				value = fmt.Sprint(args[n-1])
+			}
+			IDs[value] = true
+		}
+
+		rows = nil
+		for _, row := range table.rows {
+			if IDs[fmt.Sprint(row[0])] {
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	if strings.Contains(strings.ToLower(s.query), "count(") {
+		return &fakeRows{columns: []string{"count"}, rows: [][]driver.Value{{int64(len(rows))}}}, nil
+	}
+
+	return &fakeRows{columns: table.columns, rows: rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[r.next])
+	r.next++
+
+	return nil
+}
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
@@ -27,6 +30,7 @@ func NewUserRoleHandler(userRoleService *service.UserRoleService) *UserRoleHandl
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering User Role"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -37,12 +41,17 @@ func (h *UserRoleHandler) GetUserRoles(c *fiber.Ctx) error {
 	search := c.Query("search")
 	offset := (page - 1) * rows
 
-	total, err := h.userRoleService.GetTotal(search)
+	filters, err := filter.Parse(c, service.UserRoleFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.userRoleService.GetTotal(search, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
 
-	userRoles, err := h.userRoleService.GetAll(offset, rows, search)
+	userRoles, err := h.userRoleService.GetAll(offset, rows, search, filters)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -97,7 +106,7 @@ func (h *UserRoleHandler) GetUserRoles(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "User Role ID"
-// @Success 200 {object} map[string]interface{} "User Role found successfully"
+// @Success 200 {object} map[string]interface{} "User Role found successfully, with the ETag of the user"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: User Role not found"
 // @Router /admin/master/user-role/{id} [get]
@@ -112,26 +121,46 @@ func (h *UserRoleHandler) GetUserRole(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "User Role not found"))
 	}
 
+	// The ETag is the version of the user, which replacing its roles moves.
+	// It leaves out the roles themselves, so it does not answer If-None-Match.
+	if tag := etag.Of(userRole); tag != "" {
+		c.Set(fiber.HeaderETag, tag)
+	}
+
 	return pkg.Response(c, fiber.StatusOK, "User Role found successfully", userRole)
 }
 
-// CreateUserRole godoc
-// @Summary Create a new User Role
-// @Description Create a new User Role with the provided details
+// UpdateUserRole godoc
+// @Summary Set the roles of a user
+// @Description Replace the roles of a user by its ID with the provided ones
 // @Tags User Role
 // @Accept json
 // @Produce json
+// @Param id path int true "User ID"
 // @Param UserRole body dto.UserRoles true "User Role details"
+// @Param If-Match header string true "ETag of the user as read, the update is rejected when it changed since"
 // @Success 201 {object} map[string]interface{} "User Role created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 404 {object} map[string]interface{} "Not Found: User not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
-// @Router /admin/master/user-role [post]
+// @Router /admin/master/user-role/{id} [put]
 func (h *UserRoleHandler) UpdateUserRole(c *fiber.Ctx) error {
 	ID, err := c.ParamsInt("id")
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
 	}
 
+	user, err := h.userRoleService.GetByID(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "User not found"))
+	}
+
+	if ok, err := etag.Check(c, user); !ok {
+		return err
+	}
+
 	var input dto.UserRoles
 	if err := c.BodyParser(&input); err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
@@ -145,12 +174,12 @@ func (h *UserRoleHandler) UpdateUserRole(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.userRoleService.Delete(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	err = h.userRoleService.Replace(user, userRoles)
+	if errors.Is(err, service.ErrStale) {
+		return etag.Stale(c, func() (interface{}, error) {
+			return h.userRoleService.GetByID(uint(ID))
+		})
 	}
-
-	err = h.userRoleService.Create(&userRoles)
 	if err != nil {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
 	}
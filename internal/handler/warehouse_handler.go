@@ -1,95 +1,67 @@
 package handler
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
-	"insist-backend-golang/internal/service"
-	"insist-backend-golang/pkg"
-	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// WarehouseHandler documents the warehouse endpoints, which are served by crud.Resource.
 type WarehouseHandler struct {
-	warehouseService *service.WarehouseService
+	resource *crud.Resource[model.MstWarehouse]
 }
 
-func NewWarehouseHandler(warehouseService *service.WarehouseService) *WarehouseHandler {
-	return &WarehouseHandler{warehouseService: warehouseService}
+// NewWarehouseHandler sets the Input of the resource, so the body documented here is
+// the one the endpoints bind.
+func NewWarehouseHandler(db *gorm.DB, resource crud.Resource[model.MstWarehouse]) *WarehouseHandler {
+	resource.Input = func() dto.Input[model.MstWarehouse] { return &dto.WarehouseRequest{} }
+
+	return &WarehouseHandler{resource: crud.New(db, resource)}
 }
 
 // GetWarehouses godoc
 // @Summary Get a list of warehouses
-// @Description Retrieves warehouses with pagination and optional search
+// @Description Retrieves warehouses by page or by cursor, with optional search, filters and sorting
 // @Tags Warehouse
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param rows query int false "Number of rows per page" default(20)
 // @Param search query string false "Search keyword for filtering warehouse"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param sortDirection query bool false "Sort descending"
+// @Param after query string false "Cursor of the next page, returned as next_cursor. Leave empty for the first page. Skips the total count"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Param id_building query string false "Filter by id_building"
 // @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
 // @Failure 404 {object} map[string]interface{} "Not Found: No data found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /pid/master/warehouse [get]
 func (h *WarehouseHandler) GetWarehouses(c *fiber.Ctx) error {
-	page := c.QueryInt("page", 1)
-	rows := c.QueryInt("rows", 20)
-	idBuilding := c.QueryInt("id_building")
-	search := c.Query("search")
-	sortBy := c.Query("sortBy", "")
-	sortDirection := c.QueryBool("sortDirection")
-	offset := (page - 1) * rows
-
-	total, err := h.warehouseService.GetTotal(search, uint(idBuilding))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	warehouses, err := h.warehouseService.GetAll(offset, rows, search, sortBy, sortDirection, uint(idBuilding))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	totalPages := int(math.Ceil(float64(total) / float64(rows)))
-
-	var start *int
-	if int(total) == 0 {
-		start = nil
-	} else {
-		value := offset + 1
-		start = &value
-	}
-
-	var end *int
-	if int(total) == 0 {
-		end = nil
-	} else {
-		value := int(math.Min(float64(offset+rows), float64(total)))
-		end = &value
-	}
-	var nextPage *int
-	if page < totalPages {
-		nextPageVal := page + 1
-		nextPage = &nextPageVal
-	}
-
-	result := map[string]interface{}{
-		"items": warehouses,
-		"pagination": map[string]interface{}{
-			"current_page":  page,
-			"next_page":     nextPage,
-			"total_pages":   totalPages,
-			"rows_per_page": rows,
-			"total_rows":    total,
-			"from":          start,
-			"to":            end,
-		},
-	}
-
-	if len(warehouses) == 0 {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
-	}
+	return h.resource.List(c)
+}
 
-	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+// ExportWarehouses godoc
+// @Summary Export warehouses
+// @Description Downloads the warehouses matching the list parameters as an Excel or CSV file
+// @Tags Warehouse
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,text/csv
+// @Param format query string false "xlsx or csv" default(xlsx)
+// @Param search query string false "Search keyword for filtering warehouse"
+// @Param sortBy query string false "Comma separated columns to sort by, prefixed with - to sort descending"
+// @Param includeDeleted query bool false "Include soft deleted records"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {file} file "Exported file"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid format, sortBy or filter"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /pid/master/warehouse/export [get]
+func (h *WarehouseHandler) ExportWarehouses(c *fiber.Ctx) error {
+	return h.resource.Export(c)
 }
 
 // GetWarehouse godoc
@@ -99,22 +71,14 @@ func (h *WarehouseHandler) GetWarehouses(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Warehouse ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
 // @Success 200 {object} map[string]interface{} "Warehouse found successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Warehouse not found"
 // @Router /pid/master/warehouse/{id} [get]
 func (h *WarehouseHandler) GetWarehouse(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	warehouse, err := h.warehouseService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Warehouse not found"))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Warehouse found successfully", warehouse)
+	return h.resource.Get(c)
 }
 
 // CreateWarehouse godoc
@@ -123,32 +87,33 @@ func (h *WarehouseHandler) GetWarehouse(c *fiber.Ctx) error {
 // @Tags Warehouse
 // @Accept json
 // @Produce json
-// @Param warehouse body model.MstWarehouse true "Warehouse details"
+// @Param warehouse body dto.WarehouseRequest true "Warehouse details"
 // @Success 201 {object} map[string]interface{} "Warehouse created successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
+// @Failure 409 {object} map[string]interface{} "Conflict: A record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /pid/master/warehouse [post]
 func (h *WarehouseHandler) CreateWarehouse(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	var warehouse model.MstWarehouse
-	if err := c.BodyParser(&warehouse); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	warehouse.IDCreatedby = userID
-	warehouse.IDUpdatedby = userID
-
-	err := h.warehouseService.Create(&warehouse)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": warehouse.ID,
-	}
+	return h.resource.Create(c)
+}
 
-	return pkg.Response(c, fiber.StatusCreated, "Warehouse created successfully", result)
+// BulkWarehouses godoc
+// @Summary Create, update and delete warehouses in bulk
+// @Description Applies the operations in order in a single transaction. When any of them fails nothing is saved, and the result of every operation is reported. The number of operations is limited by BULK_MAX_SIZE
+// @Tags Warehouse
+// @Accept json
+// @Produce json
+// @Param body body dto.BulkRequest true "Operations, each with action create, update or delete, the id to update or delete, the data to create or update as in dto.WarehouseRequest and the etag the record was read with, required for updates"
+// @Success 200 {object} map[string]interface{} "Bulk operations applied successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or too many operations"
+// @Failure 403 {object} map[string]interface{} "Forbidden: User may not perform the action of an operation"
+// @Failure 409 {object} map[string]interface{} "Conflict: An operation conflicts with an existing record, nothing was saved"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: An operation failed, nothing was saved"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /pid/master/warehouse/bulk [post]
+func (h *WarehouseHandler) BulkWarehouses(c *fiber.Ctx) error {
+	return h.resource.Bulk(c)
 }
 
 // UpdateWarehouse godoc
@@ -158,70 +123,31 @@ func (h *WarehouseHandler) CreateWarehouse(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Warehouse ID"
-// @Param warehouse body model.MstWarehouse true "Updated warehouse details"
+// @Param warehouse body dto.WarehouseRequest true "Updated warehouse details"
+// @Param If-Match header string true "ETag of the record as read, the update is rejected when it changed since"
 // @Success 200 {object} map[string]interface{} "Warehouse updated successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input"
 // @Failure 404 {object} map[string]interface{} "Not Found: Warehouse not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: The record was changed by someone else, the current record is returned, or a record with the same unique value already exists"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /pid/master/warehouse/{id} [put]
 func (h *WarehouseHandler) UpdateWarehouse(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	var warehouse *model.MstWarehouse
-	warehouse, err = h.warehouseService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Warehouse not found"))
-	}
-
-	if err := c.BodyParser(warehouse); err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	warehouse.ID = uint(ID)
-	warehouse.IDUpdatedby = userID
-
-	err = h.warehouseService.Update(warehouse)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	result := map[string]interface{}{
-		"id": warehouse.ID,
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Warehouse updated successfully", result)
+	return h.resource.Update(c)
 }
 
 // DeleteWarehouse godoc
 // @Summary Delete a warehouse
-// @Description Delete a warehouse by its ID
+// @Description Soft delete a warehouse by its ID
 // @Tags Warehouse
 // @Param id path int true "Warehouse ID"
 // @Success 200 {object} map[string]interface{} "Warehouse deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
 // @Failure 404 {object} map[string]interface{} "Not Found: Warehouse not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Data is still used by other records"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /pid/master/warehouse/{id} [delete]
 func (h *WarehouseHandler) DeleteWarehouse(c *fiber.Ctx) error {
-	ID, err := c.ParamsInt("id")
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
-	}
-
-	user, err := h.warehouseService.GetByID(uint(ID))
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Warehouse not found"))
-	}
-
-	err = h.warehouseService.Delete(user)
-	if err != nil {
-		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
-	}
-
-	return pkg.Response(c, fiber.StatusOK, "Warehouse deleted successfully", nil)
+	return h.resource.Delete(c)
 }
@@ -0,0 +1,595 @@
+package handler
+
+import (
+	"errors"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/filter"
+	"insist-backend-golang/internal/model"
+	"insist-backend-golang/internal/service"
+	"insist-backend-golang/pkg"
+	"math"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type WorkOrderHandler struct {
+	workOrderService *service.WorkOrderService
+}
+
+func NewWorkOrderHandler(workOrderService *service.WorkOrderService) *WorkOrderHandler {
+	return &WorkOrderHandler{workOrderService: workOrderService}
+}
+
+// GetWorkOrders godoc
+// @Summary Get a list of work orders
+// @Description Retrieves work orders with pagination, optional search and filters on status, type and machine
+// @Tags Work Order
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param rows query int false "Number of rows per page" default(20)
+// @Param search query string false "Search keyword on title and description"
+// @Param status query string false "open, assigned, in_progress, completed or closed"
+// @Param type query string false "corrective or preventive"
+// @Param id_machine query int false "Machine ID"
+// @Param sortBy query string false "Column name to sort by (default: created_at, newest first)"
+// @Param sortDirection query boolean false "Sorting direction: false for ascending, true for descending"
+// @Param filter[field][op] query string false "Filter on a column, op is one of eq, ne, in, gt, gte, lt, lte, between, like, isnull"
+// @Success 200 {object} map[string]interface{} "Data found successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid sortBy or filter"
+// @Failure 404 {object} map[string]interface{} "Not Found: No data found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/maintenance/work-order [get]
+func (h *WorkOrderHandler) GetWorkOrders(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	rows := c.QueryInt("rows", 20)
+	machineID := c.QueryInt("id_machine", 0)
+	status := c.Query("status")
+	workOrderType := c.Query("type")
+	search := c.Query("search")
+	sortBy := c.Query("sortBy", "")
+	sortDirection := c.QueryBool("sortDirection")
+	offset := (page - 1) * rows
+
+	sorts, err := filter.ParseSort(sortBy, sortDirection, service.WorkOrderFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filters, err := filter.Parse(c, service.WorkOrderFilterFields)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	total, err := h.workOrderService.GetTotal(search, status, workOrderType, uint(machineID), filters)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	workOrders, err := h.workOrderService.GetAll(offset, rows, search, status, workOrderType, uint(machineID), sorts, filters)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(rows)))
+
+	var start *int
+	if int(total) == 0 {
+		start = nil
+	} else {
+		value := offset + 1
+		start = &value
+	}
+
+	var end *int
+	if int(total) == 0 {
+		end = nil
+	} else {
+		value := int(math.Min(float64(offset+rows), float64(total)))
+		end = &value
+	}
+	var nextPage *int
+	if page < totalPages {
+		nextPageVal := page + 1
+		nextPage = &nextPageVal
+	}
+
+	result := map[string]interface{}{
+		"items": workOrders,
+		"pagination": map[string]interface{}{
+			"current_page":  page,
+			"next_page":     nextPage,
+			"total_pages":   totalPages,
+			"rows_per_page": rows,
+			"total_rows":    total,
+			"from":          start,
+			"to":            end,
+		},
+	}
+
+	if len(workOrders) == 0 {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "No data found"))
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Data found successfully", result)
+}
+
+// GetWorkOrder godoc
+// @Summary Get work order by ID
+// @Description Retrieves a work order with its technician, tasks, spare parts and labor
+// @Tags Work Order
+// @Accept json
+// @Produce json
+// @Param id path int true "Work Order ID"
+// @Param If-None-Match header string false "ETag of the record already held, answered with 304 when unchanged"
+// @Success 200 {object} map[string]interface{} "Work order found successfully"
+// @Success 304 "Not Modified"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 404 {object} map[string]interface{} "Not Found: Work order not found"
+// @Router /mnt/maintenance/work-order/{id} [get]
+func (h *WorkOrderHandler) GetWorkOrder(c *fiber.Ctx) error {
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	workOrder, err := h.workOrderService.GetByID(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Work order not found"))
+	}
+
+	if etag.Set(c, workOrder) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Work order found successfully", workOrder)
+}
+
+// CreateWorkOrder godoc
+// @Summary Open a work order
+// @Description Opens a corrective or preventive work order on a machine. A corrective work order needs a reason, which must be downtime when the work order takes the machine down
+// @Tags Work Order
+// @Accept json
+// @Produce json
+// @Param body body dto.WorkOrderRequest true "Work order"
+// @Success 201 {object} map[string]interface{} "Work order created successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input, machine or reason not found"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/maintenance/work-order [post]
+func (h *WorkOrderHandler) CreateWorkOrder(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input dto.WorkOrderRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	var workOrder model.WorkOrder
+	input.Apply(&workOrder)
+
+	if err := h.workOrderService.Create(&workOrder, userID); err != nil {
+		return workOrderErrorResponse(c, err)
+	}
+
+	result := map[string]interface{}{
+		"id": workOrder.ID,
+	}
+
+	return pkg.Response(c, fiber.StatusCreated, "Work order created successfully", result)
+}
+
+// UpdateWorkOrder godoc
+// @Summary Update a work order
+// @Description Changes a work order that is open or assigned
+// @Tags Work Order
+// @Accept json
+// @Produce json
+// @Param id path int true "Work Order ID"
+// @Param body body dto.UpdateWorkOrderRequest true "Work order"
+// @Param If-Match header string true "ETag of the work order as read, the update is rejected when it changed since"
+// @Success 200 {object} map[string]interface{} "Work order updated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid input or reason not found"
+// @Failure 404 {object} map[string]interface{} "Not Found: Work order not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Work order already started, or it was changed by someone else and the current record is returned"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/maintenance/work-order/{id} [put]
+func (h *WorkOrderHandler) UpdateWorkOrder(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	workOrder, err := h.workOrderService.GetByID(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Work order not found"))
+	}
+
+	if ok, err := etag.Check(c, workOrder); !ok {
+		return err
+	}
+
+	var input dto.UpdateWorkOrderRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	err = h.workOrderService.Update(uint(ID), etag.Of(workOrder), &input, userID)
+	if errors.Is(err, service.ErrStale) {
+		return etag.Stale(c, func() (interface{}, error) {
+			return h.workOrderService.GetByID(uint(ID))
+		})
+	}
+	if err != nil {
+		return workOrderErrorResponse(c, err)
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Work order updated successfully", nil)
+}
+
+// AssignWorkOrder godoc
+// @Summary Assign a work order to a technician
+// @Description Assigns or reassigns a work order that has not been started to an active employee
+// @Tags Work Order
+// @Accept json
+// @Produce json
+// @Param id path int true "Work Order ID"
+// @Param body body dto.AssignWorkOrderRequest true "Technician"
+// @Success 200 {object} map[string]interface{} "Work order assigned successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID or employee not found"
+// @Failure 404 {object} map[string]interface{} "Not Found: Work order not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Work order already started"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/maintenance/work-order/{id}/assign [put]
+func (h *WorkOrderHandler) AssignWorkOrder(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	var input dto.AssignWorkOrderRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	if err := h.workOrderService.Assign(uint(ID), input.TechnicianNumber, userID); err != nil {
+		return workOrderErrorResponse(c, err)
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Work order assigned successfully", nil)
+}
+
+// StartWorkOrder godoc
+// @Summary Start a work order
+// @Description Starts an assigned work order. A work order that takes the machine down adds a machine status with its downtime reason
+// @Tags Work Order
+// @Accept json
+// @Produce json
+// @Param id path int true "Work Order ID"
+// @Success 200 {object} map[string]interface{} "Work order started successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID or no downtime reason"
+// @Failure 404 {object} map[string]interface{} "Not Found: Work order not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Work order not assigned"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/maintenance/work-order/{id}/start [put]
+func (h *WorkOrderHandler) StartWorkOrder(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := h.workOrderService.Start(uint(ID), userID); err != nil {
+		return workOrderErrorResponse(c, err)
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Work order started successfully", nil)
+}
+
+// CompleteWorkOrder godoc
+// @Summary Complete a work order
+// @Description Completes a work order in progress. A machine the work order took down gets a machine status with the given reason or, without one, the status it was running in before
+// @Tags Work Order
+// @Accept json
+// @Produce json
+// @Param id path int true "Work Order ID"
+// @Param body body dto.CompleteWorkOrderRequest false "Status to bring the machine back up with"
+// @Success 200 {object} map[string]interface{} "Work order completed successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID or no reason to bring the machine back up"
+// @Failure 404 {object} map[string]interface{} "Not Found: Work order not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Work order not in progress"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/maintenance/work-order/{id}/complete [put]
+func (h *WorkOrderHandler) CompleteWorkOrder(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	var input dto.CompleteWorkOrderRequest
+	if len(c.Body()) > 0 {
+		if err := pkg.BindBody(c, &input); err != nil {
+			return pkg.ErrorResponse(c, err)
+		}
+	}
+
+	if err := h.workOrderService.Complete(uint(ID), input, userID); err != nil {
+		return workOrderErrorResponse(c, err)
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Work order completed successfully", nil)
+}
+
+// CloseWorkOrder godoc
+// @Summary Close a work order
+// @Description Closes a completed work order, after which it cannot be changed
+// @Tags Work Order
+// @Accept json
+// @Produce json
+// @Param id path int true "Work Order ID"
+// @Success 200 {object} map[string]interface{} "Work order closed successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 404 {object} map[string]interface{} "Not Found: Work order not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Work order not completed"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/maintenance/work-order/{id}/close [put]
+func (h *WorkOrderHandler) CloseWorkOrder(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := h.workOrderService.Close(uint(ID), userID); err != nil {
+		return workOrderErrorResponse(c, err)
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Work order closed successfully", nil)
+}
+
+// UpdateWorkOrderTask godoc
+// @Summary Tick off a task of a work order
+// @Description Marks a task of a work order that is not closed as done or not done
+// @Tags Work Order
+// @Accept json
+// @Produce json
+// @Param id path int true "Work Order ID"
+// @Param taskId path int true "Work Order Task ID"
+// @Param body body dto.WorkOrderTaskRequest true "Task"
+// @Param If-Match header string true "ETag of the work order as read, the update is rejected when it changed since"
+// @Success 200 {object} map[string]interface{} "Work order task updated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 404 {object} map[string]interface{} "Not Found: Work order or task not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Work order closed, or it was changed by someone else and the current record is returned"
+// @Failure 428 {object} map[string]interface{} "Precondition Required: If-Match is missing"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/maintenance/work-order/{id}/task/{taskId} [put]
+func (h *WorkOrderHandler) UpdateWorkOrderTask(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	taskID, err := c.ParamsInt("taskId")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	workOrder, err := h.workOrderService.GetByID(uint(ID))
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Work order not found"))
+	}
+
+	if ok, err := etag.Check(c, workOrder); !ok {
+		return err
+	}
+
+	var input dto.WorkOrderTaskRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	err = h.workOrderService.UpdateTask(uint(ID), etag.Of(workOrder), uint(taskID), input.Done, userID)
+	if errors.Is(err, service.ErrStale) {
+		return etag.Stale(c, func() (interface{}, error) {
+			return h.workOrderService.GetByID(uint(ID))
+		})
+	}
+	if err != nil {
+		return workOrderErrorResponse(c, err)
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Work order task updated successfully", nil)
+}
+
+// CreateWorkOrderPart godoc
+// @Summary Add a spare part to a work order
+// @Description Records a spare part consumed by a work order that is not closed, in the UoM of the item unless another is given
+// @Tags Work Order
+// @Accept json
+// @Produce json
+// @Param id path int true "Work Order ID"
+// @Param body body dto.WorkOrderPartRequest true "Spare part"
+// @Success 201 {object} map[string]interface{} "Work order part created successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID, item or UoM not found"
+// @Failure 404 {object} map[string]interface{} "Not Found: Work order not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Work order closed"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/maintenance/work-order/{id}/part [post]
+func (h *WorkOrderHandler) CreateWorkOrderPart(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	var input dto.WorkOrderPartRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	var part model.WorkOrderPart
+	input.Apply(&part)
+
+	if err := h.workOrderService.AddPart(uint(ID), &part, userID); err != nil {
+		return workOrderErrorResponse(c, err)
+	}
+
+	result := map[string]interface{}{
+		"id": part.ID,
+	}
+
+	return pkg.Response(c, fiber.StatusCreated, "Work order part created successfully", result)
+}
+
+// DeleteWorkOrderPart godoc
+// @Summary Remove a spare part from a work order
+// @Description Removes a spare part from a work order that is not closed
+// @Tags Work Order
+// @Accept json
+// @Produce json
+// @Param id path int true "Work Order ID"
+// @Param partId path int true "Work Order Part ID"
+// @Success 200 {object} map[string]interface{} "Work order part deleted successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 404 {object} map[string]interface{} "Not Found: Work order or part not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Work order closed"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/maintenance/work-order/{id}/part/{partId} [delete]
+func (h *WorkOrderHandler) DeleteWorkOrderPart(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	partID, err := c.ParamsInt("partId")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := h.workOrderService.DeletePart(uint(ID), uint(partID), userID); err != nil {
+		return workOrderErrorResponse(c, err)
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Work order part deleted successfully", nil)
+}
+
+// CreateWorkOrderLabor godoc
+// @Summary Add labor hours to a work order
+// @Description Records hours an active employee worked on a work order that is not closed
+// @Tags Work Order
+// @Accept json
+// @Produce json
+// @Param id path int true "Work Order ID"
+// @Param body body dto.WorkOrderLaborRequest true "Labor"
+// @Success 201 {object} map[string]interface{} "Work order labor created successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID or employee not found"
+// @Failure 404 {object} map[string]interface{} "Not Found: Work order not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Work order closed"
+// @Failure 422 {object} map[string]interface{} "Unprocessable Entity: Validation failed, the invalid fields are listed"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/maintenance/work-order/{id}/labor [post]
+func (h *WorkOrderHandler) CreateWorkOrderLabor(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	var input dto.WorkOrderLaborRequest
+	if err := pkg.BindBody(c, &input); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	var labor model.WorkOrderLabor
+	input.Apply(&labor)
+
+	if err := h.workOrderService.AddLabor(uint(ID), &labor, userID); err != nil {
+		return workOrderErrorResponse(c, err)
+	}
+
+	result := map[string]interface{}{
+		"id": labor.ID,
+	}
+
+	return pkg.Response(c, fiber.StatusCreated, "Work order labor created successfully", result)
+}
+
+// DeleteWorkOrderLabor godoc
+// @Summary Remove labor hours from a work order
+// @Description Removes labor hours from a work order that is not closed
+// @Tags Work Order
+// @Accept json
+// @Produce json
+// @Param id path int true "Work Order ID"
+// @Param laborId path int true "Work Order Labor ID"
+// @Success 200 {object} map[string]interface{} "Work order labor deleted successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request: Invalid ID"
+// @Failure 404 {object} map[string]interface{} "Not Found: Work order or labor not found"
+// @Failure 409 {object} map[string]interface{} "Conflict: Work order closed"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /mnt/maintenance/work-order/{id}/labor/{laborId} [delete]
+func (h *WorkOrderHandler) DeleteWorkOrderLabor(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	ID, err := c.ParamsInt("id")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	laborID, err := c.ParamsInt("laborId")
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	if err := h.workOrderService.DeleteLabor(uint(ID), uint(laborID), userID); err != nil {
+		return workOrderErrorResponse(c, err)
+	}
+
+	return pkg.Response(c, fiber.StatusOK, "Work order labor deleted successfully", nil)
+}
+
+func workOrderErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, "Work order not found"))
+	case errors.Is(err, service.ErrWorkOrderTaskNotFound),
+		errors.Is(err, service.ErrWorkOrderPartNotFound),
+		errors.Is(err, service.ErrWorkOrderLaborNotFound):
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, err.Error()))
+	case errors.Is(err, service.ErrWorkOrderInvalidStatus), errors.Is(err, service.ErrWorkOrderClosed):
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusConflict, err.Error()))
+	case errors.Is(err, service.ErrWorkOrderMachineNotFound),
+		errors.Is(err, service.ErrWorkOrderReasonNotFound),
+		errors.Is(err, service.ErrWorkOrderReasonRequired),
+		errors.Is(err, service.ErrWorkOrderItemNotFound),
+		errors.Is(err, service.ErrWorkOrderUOMNotFound),
+		errors.Is(err, service.ErrWorkOrderEmployeeNotFound),
+		errors.Is(err, service.ErrWorkOrderNoDowntimeReason),
+		errors.Is(err, service.ErrWorkOrderNoResumeReason):
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+	}
+
+	return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+}
@@ -0,0 +1,133 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrInvalidFile is returned by Read for files that are not a readable xlsx
+// or csv file.
+var ErrInvalidFile = errors.New("invalid file")
+
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"02/01/2006",
+}
+
+// Read reads the rows of the first sheet of an xlsx file or of a csv file,
+// told apart by the extension of name.
+func Read(name string, file io.Reader) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".xlsx":
+		f, err := excelize.OpenReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+		defer f.Close()
+
+		// Raw values keep numbers and dates as typed instead of as displayed.
+		rows, err := f.GetRows(f.GetSheetName(0), excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+
+		return rows, nil
+	case ".csv":
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+
+		return rows, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s is not an xlsx or csv file", ErrInvalidFile, name)
+}
+
+// parse converts the text of a cell to a value of type t.
+func parse(text string, t reflect.Type) (interface{}, error) {
+	pointer := t.Kind() == reflect.Ptr
+	if pointer {
+		t = t.Elem()
+	}
+
+	var value interface{}
+
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		date, err := parseDate(text)
+		if err != nil {
+			return nil, err
+		}
+		value = date
+	case t.Kind() == reflect.String:
+		value = text
+	case t.Kind() == reflect.Bool:
+		switch strings.ToLower(text) {
+		case "yes", "y", "true", "1":
+			value = true
+		case "no", "n", "false", "0":
+			value = false
+		default:
+			return nil, fmt.Errorf("must be yes or no")
+		}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		number, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a whole number")
+		}
+		value = reflect.ValueOf(number).Convert(t).Interface()
+	case t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64:
+		number, err := strconv.ParseUint(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a positive whole number")
+		}
+		value = reflect.ValueOf(number).Convert(t).Interface()
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		value = reflect.ValueOf(number).Convert(t).Interface()
+	default:
+		return nil, fmt.Errorf("cannot be imported")
+	}
+
+	if pointer {
+		ptr := reflect.New(t)
+		ptr.Elem().Set(reflect.ValueOf(value))
+		return ptr.Interface(), nil
+	}
+
+	return value, nil
+}
+
+// parseDate accepts the usual date formats and Excel serial dates.
+func parseDate(text string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if date, err := time.ParseInLocation(layout, text, time.Local); err == nil {
+			return date, nil
+		}
+	}
+
+	if serial, err := strconv.ParseFloat(text, 64); err == nil {
+		return excelize.ExcelDateToTime(serial, false)
+	}
+
+	return time.Time{}, fmt.Errorf("must be a date like 2025-01-31")
+}
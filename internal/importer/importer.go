@@ -0,0 +1,356 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// batchSize is how many records are inserted per statement.
+const batchSize = 500
+
+// Lookup resolves a code in the file to the ID of a referenced record, e.g.
+// a category code to IDItemCategory. Deleted records are not matched.
+type Lookup struct {
+	Model  interface{}
+	Column string
+	// Where limits the records matched, e.g. reasons to one key.
+	Where map[string]interface{}
+}
+
+// Column is a column of an import file. Field is the field of the record the
+// value is written to.
+type Column struct {
+	Header   string
+	Field    string
+	Required bool
+	// Unique rejects values used twice in the file or by an existing record,
+	// deleted ones included. It is checked against the column of Field, so
+	// it does not apply to Lookup columns.
+	Unique bool
+	Lookup *Lookup
+	// Example is shown in the example row of the template instead of a
+	// value made up from the type of Field.
+	Example string
+}
+
+// RowError is a problem found in one cell of the file. Row is the line in the
+// file, counting the header as line 1.
+type RowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column"`
+	Value   string `json:"value"`
+	Message string `json:"message"`
+}
+
+// Result counts the rows of an import. Nothing is imported when there are
+// errors or on a dry run.
+type Result struct {
+	TotalRows    int
+	ValidRows    int
+	ErrorRows    int
+	ImportedRows int
+	Errors       []RowError
+}
+
+// Importer imports the rows of a file into one master table.
+type Importer interface {
+	Columns() []Column
+	Template(db *gorm.DB) (*excelize.File, error)
+	Import(db *gorm.DB, rows [][]string, mapping map[string]string, userID uint, dryRun bool) (Result, error)
+}
+
+// Master imports the rows of a file as T records, all of them in a single
+// transaction once every row is valid.
+type Master[T any] struct {
+	Fields []Column
+}
+
+var cache = &sync.Map{}
+
+func (m Master[T]) Columns() []Column {
+	return m.Fields
+}
+
+// Import validates rows, the first of which holds the headers, and inserts
+// them unless dryRun is set. mapping maps the header of a column to the
+// header used in the file when they differ.
+func (m Master[T]) Import(db *gorm.DB, rows [][]string, mapping map[string]string, userID uint, dryRun bool) (Result, error) {
+	var result Result
+
+	s, err := schema.Parse(new(T), cache, db.NamingStrategy)
+	if err != nil {
+		return result, err
+	}
+
+	if len(rows) == 0 {
+		result.Errors = append(result.Errors, RowError{Row: 1, Message: "file is empty"})
+		return result, nil
+	}
+
+	indexes, errs := m.indexes(rows[0], mapping)
+	if len(errs) > 0 {
+		result.Errors = errs
+		return result, nil
+	}
+
+	var lines []int
+	var data [][]string
+	for i, row := range rows[1:] {
+		if blank(row) {
+			continue
+		}
+
+		values := make([]string, len(m.Fields))
+		for j := range m.Fields {
+			if index := indexes[j]; index >= 0 && index < len(row) {
+				values[j] = strings.TrimSpace(row[index])
+			}
+		}
+
+		lines = append(lines, i+2)
+		data = append(data, values)
+	}
+
+	result.TotalRows = len(data)
+
+	lookups, err := m.lookups(db, data)
+	if err != nil {
+		return result, err
+	}
+
+	existing, err := m.existing(db, s, data)
+	if err != nil {
+		return result, err
+	}
+
+	seen := make([]map[string]int, len(m.Fields))
+	for j := range seen {
+		seen[j] = map[string]int{}
+	}
+
+	records := make([]T, 0, len(data))
+	for i, values := range data {
+		var record T
+		value := reflect.ValueOf(&record).Elem()
+		var errs []RowError
+
+		for j, column := range m.Fields {
+			text := values[j]
+			fail := func(message string) {
+				errs = append(errs, RowError{Row: lines[i], Column: column.Header, Value: text, Message: message})
+			}
+
+			if text == "" {
+				if column.Required {
+					fail("is required")
+				}
+				continue
+			}
+
+			if column.Unique {
+				key := strings.ToLower(text)
+				if line, ok := seen[j][key]; ok {
+					fail(fmt.Sprintf("is a duplicate of row %d", line))
+				} else if existing[j][key] {
+					fail("already exists")
+				}
+				seen[j][key] = lines[i]
+			}
+
+			field := s.LookUpField(column.Field)
+			if field == nil {
+				return result, fmt.Errorf("%s has no field %s", s.Name, column.Field)
+			}
+
+			if column.Lookup != nil {
+				ID, ok := lookups[j][strings.ToLower(text)]
+				if !ok {
+					fail("not found")
+					continue
+				}
+
+				if err := field.Set(context.Background(), value, ID); err != nil {
+					fail(err.Error())
+				}
+				continue
+			}
+
+			parsed, err := parse(text, field.FieldType)
+			if err != nil {
+				fail(err.Error())
+				continue
+			}
+
+			if err := field.Set(context.Background(), value, parsed); err != nil {
+				fail(err.Error())
+			}
+		}
+
+		if len(errs) > 0 {
+			result.ErrorRows++
+			result.Errors = append(result.Errors, errs...)
+			continue
+		}
+
+		for _, name := range []string{"IDCreatedby", "IDUpdatedby"} {
+			if field := s.LookUpField(name); field != nil {
+				field.Set(context.Background(), value, userID)
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	result.ValidRows = len(records)
+
+	if len(result.Errors) > 0 || dryRun || len(records) == 0 {
+		return result, nil
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(&records, batchSize).Error
+	}); err != nil {
+		return result, err
+	}
+
+	result.ImportedRows = len(records)
+
+	return result, nil
+}
+
+// indexes finds the position of every column in the header row.
+func (m Master[T]) indexes(header []string, mapping map[string]string) ([]int, []RowError) {
+	positions := map[string]int{}
+	for i, name := range header {
+		positions[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	var errs []RowError
+	indexes := make([]int, len(m.Fields))
+	for j, column := range m.Fields {
+		name := column.Header
+		if mapped, ok := mapping[column.Header]; ok && mapped != "" {
+			name = mapped
+		}
+
+		index, ok := positions[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			index = -1
+			if column.Required {
+				errs = append(errs, RowError{Row: 1, Column: column.Header, Value: name, Message: "column not found in file"})
+			}
+		}
+
+		indexes[j] = index
+	}
+
+	return indexes, errs
+}
+
+// lookups loads the IDs of the referenced records used in data, keyed by
+// their lowercased code, per column.
+func (m Master[T]) lookups(db *gorm.DB, data [][]string) ([]map[string]uint, error) {
+	lookups := make([]map[string]uint, len(m.Fields))
+
+	for j, column := range m.Fields {
+		if column.Lookup == nil {
+			continue
+		}
+
+		codes := distinct(data, j)
+		lookups[j] = map[string]uint{}
+		if len(codes) == 0 {
+			continue
+		}
+
+		var records []struct {
+			ID   uint
+			Code string
+		}
+
+		query := db.Model(column.Lookup.Model)
+		if len(column.Lookup.Where) > 0 {
+			query = query.Where(column.Lookup.Where)
+		}
+
+		if err := query.
+			Select("id, "+column.Lookup.Column+" AS code").
+			Where("LOWER("+column.Lookup.Column+") IN ?", codes).
+			Scan(&records).Error; err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			lookups[j][strings.ToLower(record.Code)] = record.ID
+		}
+	}
+
+	return lookups, nil
+}
+
+// existing loads the values of the unique columns already taken, keyed by
+// their lowercased value, per column.
+func (m Master[T]) existing(db *gorm.DB, s *schema.Schema, data [][]string) ([]map[string]bool, error) {
+	existing := make([]map[string]bool, len(m.Fields))
+
+	for j, column := range m.Fields {
+		if !column.Unique {
+			continue
+		}
+
+		field := s.LookUpField(column.Field)
+		if field == nil {
+			return nil, fmt.Errorf("%s has no field %s", s.Name, column.Field)
+		}
+
+		values := distinct(data, j)
+		existing[j] = map[string]bool{}
+		if len(values) == 0 {
+			continue
+		}
+
+		var taken []string
+		if err := db.Unscoped().Model(new(T)).
+			Where("LOWER(CAST("+field.DBName+" AS VARCHAR)) IN ?", values).
+			Pluck(field.DBName, &taken).Error; err != nil {
+			return nil, err
+		}
+
+		for _, value := range taken {
+			existing[j][strings.ToLower(value)] = true
+		}
+	}
+
+	return existing, nil
+}
+
+func distinct(data [][]string, j int) []string {
+	seen := map[string]bool{}
+	var values []string
+	for _, row := range data {
+		value := strings.ToLower(row[j])
+		if value != "" && !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+func blank(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+
+	return true
+}
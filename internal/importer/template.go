@@ -0,0 +1,239 @@
+package importer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	templateSheet = "Import"
+	listsSheet    = "Lists"
+	// templateRows is how far down the dropdowns reach.
+	templateRows = 10000
+)
+
+// Template builds an xlsx file to fill in for the import: the headers with a
+// comment on what each column takes, an example row, and dropdowns with the
+// codes a lookup column accepts.
+func (m Master[T]) Template(db *gorm.DB) (*excelize.File, error) {
+	s, err := schema.Parse(new(T), cache, db.NamingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	f := excelize.NewFile()
+
+	if err := f.SetSheetName(f.GetSheetName(0), templateSheet); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if err := m.template(f, db, s); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (m Master[T]) template(f *excelize.File, db *gorm.DB, s *schema.Schema) error {
+	if _, err := f.NewSheet(listsSheet); err != nil {
+		return err
+	}
+
+	if err := f.SetSheetVisible(listsSheet, false); err != nil {
+		return err
+	}
+
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+
+	lists := 0
+	for i, column := range m.Fields {
+		field := s.LookUpField(column.Field)
+		if field == nil {
+			return fmt.Errorf("%s has no field %s", s.Name, column.Field)
+		}
+
+		name, err := excelize.ColumnNumberToName(i + 1)
+		if err != nil {
+			return err
+		}
+
+		if err := f.SetCellValue(templateSheet, name+"1", column.Header); err != nil {
+			return err
+		}
+
+		if err := f.SetCellStyle(templateSheet, name+"1", name+"1", bold); err != nil {
+			return err
+		}
+
+		var options []string
+		if column.Lookup != nil {
+			options, err = column.Lookup.options(db)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := f.AddComment(templateSheet, excelize.Comment{
+			Cell:   name + "1",
+			Author: "Import",
+			Text:   describe(column, field),
+		}); err != nil {
+			return err
+		}
+
+		if err := f.SetCellValue(templateSheet, name+"2", example(column, field, options)); err != nil {
+			return err
+		}
+
+		validation := excelize.NewDataValidation(true)
+		validation.Sqref = fmt.Sprintf("%s2:%s%d", name, name, templateRows)
+
+		switch {
+		case len(options) > 0:
+			lists++
+			list, err := excelize.ColumnNumberToName(lists)
+			if err != nil {
+				return err
+			}
+
+			if err := f.SetCellValue(listsSheet, list+"1", column.Header); err != nil {
+				return err
+			}
+
+			for j, option := range options {
+				if err := f.SetCellStr(listsSheet, fmt.Sprintf("%s%d", list, j+2), option); err != nil {
+					return err
+				}
+			}
+
+			validation.SetSqrefDropList(fmt.Sprintf("%s!$%s$2:$%s$%d", listsSheet, list, list, len(options)+1))
+		case kind(field) == reflect.Bool:
+			if err := validation.SetDropList([]string{"Yes", "No"}); err != nil {
+				return err
+			}
+		default:
+			continue
+		}
+
+		validation.SetError(excelize.DataValidationErrorStyleStop, column.Header, "Pick a value from the list")
+
+		if err := f.AddDataValidation(templateSheet, validation); err != nil {
+			return err
+		}
+	}
+
+	return f.SetPanes(templateSheet, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	})
+}
+
+// options lists the codes the lookup accepts.
+func (l *Lookup) options(db *gorm.DB) ([]string, error) {
+	var options []string
+
+	query := db.Model(l.Model)
+	if len(l.Where) > 0 {
+		query = query.Where(l.Where)
+	}
+
+	if err := query.Distinct(l.Column).Order(l.Column).Pluck(l.Column, &options).Error; err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+// describe explains what a column takes, from the type of its field.
+func describe(column Column, field *schema.Field) string {
+	var parts []string
+
+	if column.Required {
+		parts = append(parts, "Required.")
+	} else {
+		parts = append(parts, "Optional.")
+	}
+
+	switch {
+	case column.Lookup != nil:
+		parts = append(parts, "One of the codes in the dropdown.")
+	case isTime(field):
+		parts = append(parts, "Date as YYYY-MM-DD.")
+	default:
+		switch kind(field) {
+		case reflect.Bool:
+			parts = append(parts, "Yes or No.")
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			parts = append(parts, "Whole number.")
+		case reflect.Float32, reflect.Float64:
+			parts = append(parts, "Number.")
+		default:
+			parts = append(parts, "Text.")
+		}
+	}
+
+	if column.Unique {
+		parts = append(parts, "Must not exist yet.")
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// example is the value of the column in the example row.
+func example(column Column, field *schema.Field, options []string) interface{} {
+	switch {
+	case column.Example != "":
+		return column.Example
+	case column.Lookup != nil:
+		if len(options) > 0 {
+			return options[0]
+		}
+		return ""
+	case isTime(field):
+		return time.Now().Format("2006-01-02")
+	}
+
+	switch kind(field) {
+	case reflect.Bool:
+		return "Yes"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return 1
+	case reflect.Float32, reflect.Float64:
+		return 1.5
+	}
+
+	return column.Header
+}
+
+func kind(field *schema.Field) reflect.Kind {
+	t := field.FieldType
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind()
+}
+
+func isTime(field *schema.Field) bool {
+	t := field.FieldType
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t == reflect.TypeOf(time.Time{})
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"insist-backend-golang/internal/model"
+	"insist-backend-golang/internal/service"
+	"insist-backend-golang/pkg"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ActivityLogger records every authenticated request into activity_logs. It
+// must run after VerifyToken.
+func ActivityLogger(writer *service.ActivityLogWriter) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		userID, ok := c.Locals("userID").(uint)
+		if !ok || c.Method() == fiber.MethodOptions {
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		message := ""
+		if err != nil {
+			if fiberErr, ok := err.(*fiber.Error); ok {
+				status = fiberErr.Code
+			} else {
+				status = fiber.StatusInternalServerError
+			}
+			message = err.Error()
+		}
+
+		userAgent := c.Get(fiber.HeaderUserAgent)
+
+		writer.Write(model.ActivityLog{
+			IDUser:    userID,
+			IPAddress: c.IP(),
+			Source:    service.ActivityLogSourceRequest,
+			Action:    c.Method() + " " + c.Route().Path,
+			IsSuccess: status < fiber.StatusBadRequest,
+			Message:   message,
+			UserAgent: userAgent,
+			OS:        pkg.ParseOS(userAgent),
+			Method:    c.Method(),
+			Path:      c.Path(),
+			Status:    status,
+			LatencyMs: time.Since(start).Milliseconds(),
+		})
+
+		return err
+	}
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/service"
+	"insist-backend-golang/pkg"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// VerifyPermission rejects mutating requests the caller's roles do not allow
+// on the given menu path. A bulk request needs the permission of the action
+// of every one of its operations. It must run after VerifyToken.
+func VerifyPermission(db *gorm.DB, path string) fiber.Handler {
+	rolePermissionService := service.NewRolePermissionService(db)
+
+	return func(c *fiber.Ctx) error {
+		// Fiber matches group middleware by plain string prefix, so
+		// "master/currency" would also run for "master/currency-rate".
+		rest := strings.TrimPrefix(c.Path(), c.Route().Path)
+		if rest != "" && rest[0] != '/' {
+			return c.Next()
+		}
+
+		if rest == "/bulk" && c.Method() == fiber.MethodPost {
+			return authorize(c, rolePermissionService, path, func(permission *dto.MenuWithPermissions) bool {
+				return bulkAllowed(c, permission)
+			})
+		}
+
+		var allowed func(permission *dto.MenuWithPermissions) bool
+		switch c.Method() {
+		case fiber.MethodPost:
+			allowed = func(permission *dto.MenuWithPermissions) bool { return permission.IsCreate }
+		case fiber.MethodPut, fiber.MethodPatch:
+			allowed = func(permission *dto.MenuWithPermissions) bool { return permission.IsUpdate }
+		case fiber.MethodDelete:
+			allowed = func(permission *dto.MenuWithPermissions) bool { return permission.IsDelete }
+		default:
+			return c.Next()
+		}
+
+		return authorize(c, rolePermissionService, path, allowed)
+	}
+}
+
+// VerifyImportPermission rejects imports into a master the caller's roles may
+// not create records of. menus maps the :master parameter to the menu path of
+// the master; unknown masters are left to the handler. It must run after
+// VerifyToken.
+func VerifyImportPermission(db *gorm.DB, menus map[string]string) fiber.Handler {
+	rolePermissionService := service.NewRolePermissionService(db)
+
+	return func(c *fiber.Ctx) error {
+		path, ok := menus[c.Params("master")]
+		if !ok {
+			return c.Next()
+		}
+
+		return authorize(c, rolePermissionService, path, func(permission *dto.MenuWithPermissions) bool { return permission.IsCreate })
+	}
+}
+
+// VerifyApprovalPermission rejects approval requests on a document of a menu
+// the caller's roles do not include, and on a :refTable that does not go
+// through approval. Who may act is left to the approval engine. It must run
+// after VerifyToken.
+func VerifyApprovalPermission(db *gorm.DB) fiber.Handler {
+	rolePermissionService := service.NewRolePermissionService(db)
+
+	return func(c *fiber.Ctx) error {
+		path, ok := service.ApprovalMenu(c.Params("refTable"))
+		if !ok {
+			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusNotFound, service.ErrApprovalUnknownTable.Error()))
+		}
+
+		return authorize(c, rolePermissionService, path, func(permission *dto.MenuWithPermissions) bool { return true })
+	}
+}
+
+func authorize(c *fiber.Ctx, rolePermissionService *service.RolePermissionService, path string, allowed func(permission *dto.MenuWithPermissions) bool) error {
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusUnauthorized, "Invalid access token"))
+	}
+
+	permission, err := rolePermissionService.GetPermission(userID, path)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	if permission == nil || !allowed(permission) {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusForbidden, "You do not have permission to perform this action"))
+	}
+
+	return c.Next()
+}
+
+// bulkAllowed tells whether permission allows the action of every operation
+// of a bulk request. A body that cannot be read is left to the handler, which
+// rejects it, as are unknown actions.
+func bulkAllowed(c *fiber.Ctx, permission *dto.MenuWithPermissions) bool {
+	var input dto.BulkRequest
+	if err := c.BodyParser(&input); err != nil {
+		return true
+	}
+
+	for _, operation := range input.Operations {
+		switch operation.Action {
+		case dto.BulkActionCreate:
+			if !permission.IsCreate {
+				return false
+			}
+		case dto.BulkActionUpdate:
+			if !permission.IsUpdate {
+				return false
+			}
+		case dto.BulkActionDelete:
+			if !permission.IsDelete {
+				return false
+			}
+		}
+	}
+
+	return true
+}
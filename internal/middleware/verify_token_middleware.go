@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"insist-backend-golang/internal/config"
+	"insist-backend-golang/internal/service"
 	"insist-backend-golang/pkg"
 
 	"github.com/gofiber/fiber/v2"
@@ -18,7 +20,7 @@ func VerifyToken(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusForbidden, "Invalid access token format"))
 	}
 
-	userID, err := pkg.VerifyAccessToken(token)
+	claims, err := pkg.VerifyAccessToken(token)
 	if err != nil {
 		if err.Error() == "token expired" {
 			return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusForbidden, "Access token expired"))
@@ -26,7 +28,18 @@ func VerifyToken(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusUnauthorized, "Invalid access token"))
 	}
 
-	c.Locals("userID", userID)
+	// Sessions live in the INSIST database whichever database the route uses.
+	active, err := service.NewUserSessionService(config.DBINSIST).IsActive(claims.UserID, claims.SessionID)
+	if err != nil {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	if !active {
+		return pkg.ErrorResponse(c, fiber.NewError(fiber.StatusUnauthorized, "Session has been revoked"))
+	}
+
+	c.Locals("userID", claims.UserID)
+	c.Locals("sessionID", claims.SessionID)
 
 	return c.Next()
 }
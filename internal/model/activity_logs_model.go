@@ -7,11 +7,16 @@ type ActivityLog struct {
 	IDUser    uint       `json:"id_user,omitempty"`
 	Username  string     `json:"username,omitempty"`
 	IPAddress string     `json:"ip_address,omitempty"`
+	Source    string     `json:"source,omitempty"`
 	Action    string     `json:"action,omitempty"`
 	IsSuccess bool       `json:"is_success,omitempty"`
 	Message   string     `json:"message,omitempty"`
 	UserAgent string     `json:"user_agent,omitempty"`
 	OS        string     `json:"os,omitempty"`
+	Method    string     `json:"method,omitempty"`
+	Path      string     `json:"path,omitempty"`
+	Status    int        `json:"status,omitempty"`
+	LatencyMs int64      `json:"latency_ms,omitempty"`
 	CreatedAt *time.Time `gorm:"autoCreateTime" json:"created_at,omitempty"`
 
 	User *MstUser `gorm:"foreignKey:IDUser;references:ID" json:"user,omitempty"`
@@ -2,19 +2,23 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type MstApproval struct {
-	ID          uint       `gorm:"primaryKey" json:"id"`
-	IDMenu      uint       `json:"id_menu,omitempty"`
-	Status      string     `json:"status,omitempty"`
-	Action      string     `json:"action,omitempty"`
-	Count       uint       `json:"count,omitempty"`
-	Level       int        `json:"level,omitempty"`
-	IDCreatedby uint       `json:"id_createdby,omitempty"`
-	IDUpdatedby uint       `json:"id_updatedby,omitempty"`
-	CreatedAt   *time.Time `gorm:"autoCreateTime" json:"created_at,omitempty"`
-	UpdatedAt   *time.Time `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	IDMenu      uint           `json:"id_menu,omitempty"`
+	Status      string         `json:"status,omitempty"`
+	Action      string         `json:"action,omitempty"`
+	Count       uint           `json:"count,omitempty"`
+	Level       int            `json:"level,omitempty"`
+	IDCreatedby uint           `json:"id_createdby,omitempty"`
+	IDUpdatedby uint           `json:"id_updatedby,omitempty"`
+	CreatedAt   *time.Time     `gorm:"autoCreateTime" json:"created_at,omitempty"`
+	UpdatedAt   *time.Time     `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty" swaggertype:"string" format:"date-time"`
+	IDDeletedby *uint          `json:"id_deletedby,omitempty"`
 
 	CreatedBy     *MstUser           `gorm:"foreignKey:ID;references:IDCreatedby" json:"created_by,omitempty"`
 	UpdatedBy     *MstUser           `gorm:"foreignKey:ID;references:IDUpdatedby" json:"updated_by,omitempty"`
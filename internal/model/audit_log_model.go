@@ -0,0 +1,18 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditLog struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`
+	TableName string          `gorm:"column:table_name" json:"table_name"`
+	RecordID  uint            `json:"record_id"`
+	Action    string          `json:"action"`
+	IDUser    *uint           `json:"id_user"`
+	Changes   json.RawMessage `gorm:"type:jsonb" json:"changes" swaggertype:"object"`
+	CreatedAt time.Time       `gorm:"autoCreateTime" json:"created_at"`
+
+	User *MstUser `gorm:"foreignKey:IDUser;references:ID" json:"user,omitempty"`
+}
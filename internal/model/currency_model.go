@@ -2,16 +2,20 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type MstCurrency struct {
-	ID          uint       `gorm:"primaryKey" json:"id"`
-	Currency    string     `json:"currency,omitempty"`
-	Description string     `json:"description,omitempty"`
-	IDCreatedby uint       `json:"id_createdby,omitempty"`
-	IDUpdatedby uint       `json:"id_updatedby,omitempty"`
-	CreatedAt   *time.Time `gorm:"autoCreateTime" json:"created_at,omitempty"`
-	UpdatedAt   *time.Time `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	Currency    string         `json:"currency,omitempty"`
+	Description string         `json:"description,omitempty"`
+	IDCreatedby uint           `json:"id_createdby,omitempty"`
+	IDUpdatedby uint           `json:"id_updatedby,omitempty"`
+	CreatedAt   *time.Time     `gorm:"autoCreateTime" json:"created_at,omitempty"`
+	UpdatedAt   *time.Time     `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty" swaggertype:"string" format:"date-time"`
+	IDDeletedby *uint          `json:"id_deletedby,omitempty"`
 
 	CreatedBy *MstUser `gorm:"foreignKey:ID;references:IDCreatedby" json:"created_by,omitempty"`
 	UpdatedBy *MstUser `gorm:"foreignKey:ID;references:IDUpdatedby" json:"updated_by,omitempty"`
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ImportJob struct {
+	ID           uint            `gorm:"primaryKey" json:"id"`
+	Master       string          `json:"master"`
+	FileName     string          `json:"file_name"`
+	Status       string          `json:"status"`
+	DryRun       bool            `json:"dry_run"`
+	TotalRows    int             `json:"total_rows"`
+	ValidRows    int             `json:"valid_rows"`
+	ErrorRows    int             `json:"error_rows"`
+	ImportedRows int             `json:"imported_rows"`
+	Errors       json.RawMessage `gorm:"type:jsonb" json:"errors,omitempty" swaggertype:"object"`
+	Message      string          `json:"message,omitempty"`
+	IDCreatedby  uint            `json:"id_createdby,omitempty"`
+	FinishedAt   *time.Time      `json:"finished_at"`
+	CreatedAt    *time.Time      `gorm:"autoCreateTime" json:"created_at,omitempty"`
+	UpdatedAt    *time.Time      `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+
+	CreatedBy *MstUser `gorm:"foreignKey:ID;references:IDCreatedby" json:"created_by,omitempty"`
+}
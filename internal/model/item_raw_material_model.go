@@ -1,22 +1,28 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type MstItemRawMaterial struct {
-	ID                uint       `gorm:"primaryKey" json:"id"`
-	IDItem            uint       `json:"id_item"`
-	IDItemProductType uint       `json:"id_item_product_type"`
-	IDItemGroupType   uint       `json:"id_item_group_type"`
-	IDItemProcess     uint       `json:"id_item_process"`
-	IDItemSurface     uint       `json:"id_item_surface"`
-	IDItemSource      uint       `json:"id_item_source"`
-	DiameterSize      string     `json:"diameter_size"`
-	LengthSize        string     `json:"length_size"`
-	InnerDiameterSize string     `json:"inner_diameter_size"`
-	IDCreatedby       uint       `json:"id_createdby,omitempty"`
-	IDUpdatedby       uint       `json:"id_updatedby,omitempty"`
-	CreatedAt         *time.Time `gorm:"autoCreateTime" json:"created_at,omitempty"`
-	UpdatedAt         *time.Time `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+	ID                uint           `gorm:"primaryKey" json:"id"`
+	IDItem            uint           `json:"id_item"`
+	IDItemProductType uint           `json:"id_item_product_type"`
+	IDItemGroupType   uint           `json:"id_item_group_type"`
+	IDItemProcess     uint           `json:"id_item_process"`
+	IDItemSurface     uint           `json:"id_item_surface"`
+	IDItemSource      uint           `json:"id_item_source"`
+	DiameterSize      string         `json:"diameter_size"`
+	LengthSize        string         `json:"length_size"`
+	InnerDiameterSize string         `json:"inner_diameter_size"`
+	IDCreatedby       uint           `json:"id_createdby,omitempty"`
+	IDUpdatedby       uint           `json:"id_updatedby,omitempty"`
+	CreatedAt         *time.Time     `gorm:"autoCreateTime" json:"created_at,omitempty"`
+	UpdatedAt         *time.Time     `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty" swaggertype:"string" format:"date-time"`
+	IDDeletedby       *uint          `json:"id_deletedby,omitempty"`
 
 	Item            *MstItem            `gorm:"references:ID;foreignKey:IDItem" json:"item,omitempty"`
 	ItemProductType *MstItemProductType `gorm:"references:ID;foreignKey:IDItemProductType" json:"item_product_type,omitempty"`
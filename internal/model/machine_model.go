@@ -2,14 +2,18 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type MstMachine struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	IDCreatedby uint      `json:"id_createdby,omitempty"`
-	IDUpdatedby uint      `json:"id_updatedby,omitempty"`
-	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at,omitempty"`
-	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	IDCreatedby uint           `json:"id_createdby,omitempty"`
+	IDUpdatedby uint           `json:"id_updatedby,omitempty"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at,omitempty"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty" swaggertype:"string" format:"date-time"`
+	IDDeletedby *uint          `json:"id_deletedby,omitempty"`
 
 	CreatedBy *MstUser `gorm:"foreignKey:ID;references:IDCreatedby" json:"created_by,omitempty"`
 	UpdatedBy *MstUser `gorm:"foreignKey:ID;references:IDUpdatedby" json:"updated_by,omitempty"`
@@ -19,6 +23,7 @@ type MstMachineDetail struct {
 	ID                  uint       `gorm:"primaryKey" json:"id"`
 	IDMachine           uint       `json:"id_machine"`
 	RevNo               int        `json:"rev_no"`
+	State               string     `json:"state"`
 	Code                string     `json:"code"`
 	CodeOld             *string    `json:"code_old,omitempty"`
 	AssetNum            string     `json:"asset_num"`
@@ -47,22 +52,25 @@ type MstMachineDetail struct {
 	IDDimensionFrontUOM uint       `json:"id_dimension_front_uom"`
 	DimensionSide       float64    `json:"dimension_side"`
 	IDDimensionSideUOM  uint       `json:"id_dimension_side_uom"`
+	IDBuilding          *uint      `json:"id_building,omitempty"`
 	IDCreatedby         uint       `json:"id_createdby"`
 	IDUpdatedby         uint       `json:"id_updatedby"`
 	CreatedAt           *time.Time `gorm:"autoCreateTime" json:"created_at,omitempty"`
 	UpdatedAt           *time.Time `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
-
-	Machine           *MstMachine `gorm:"foreignKey:ID;references:IDMachine" json:"machine,omitempty"`
-	PowerUOM          *MstUoms    `gorm:"foreignKey:ID;references:IDPowerUOM" json:"power_uom,omitempty"`
-	ElectricityUOM    *MstUoms    `gorm:"foreignKey:ID;references:IDElectricityUOM" json:"electricity_uom,omitempty"`
-	LubricantUOM      *MstUoms    `gorm:"foreignKey:ID;references:IDLubricantUOM" json:"lubricant_uom,omitempty"`
-	SlidingUOM        *MstUoms    `gorm:"foreignKey:ID;references:IDLubricantUOM" json:"sliding_uom,omitempty"`
-	CoolantUOM        *MstUoms    `gorm:"foreignKey:ID;references:IDCoolantUOM" json:"coolant_uom,omitempty"`
-	HydraulicUOM      *MstUoms    `gorm:"foreignKey:ID;references:IDHydraulicUOM" json:"hydraulic_uom,omitempty"`
-	DimensionFrontUOM *MstUoms    `gorm:"foreignKey:ID;references:IDDimensionFrontUOM" json:"dimension_front_uom,omitempty"`
-	DimensionSideUOM  *MstUoms    `gorm:"foreignKey:ID;references:IDDimensionSideUOM" json:"dimension_side_uom,omitempty"`
-	CreatedBy         *MstUser    `gorm:"foreignKey:ID;references:IDCreatedby" json:"created_by,omitempty"`
-	UpdatedBy         *MstUser    `gorm:"foreignKey:ID;references:IDUpdatedby" json:"updated_by,omitempty"`
+	ReleasedAt          *time.Time `json:"released_at,omitempty"`
+
+	Machine           *MstMachine  `gorm:"foreignKey:ID;references:IDMachine" json:"machine,omitempty"`
+	PowerUOM          *MstUoms     `gorm:"foreignKey:ID;references:IDPowerUOM" json:"power_uom,omitempty"`
+	ElectricityUOM    *MstUoms     `gorm:"foreignKey:ID;references:IDElectricityUOM" json:"electricity_uom,omitempty"`
+	LubricantUOM      *MstUoms     `gorm:"foreignKey:ID;references:IDLubricantUOM" json:"lubricant_uom,omitempty"`
+	SlidingUOM        *MstUoms     `gorm:"foreignKey:ID;references:IDSlidingUOM" json:"sliding_uom,omitempty"`
+	CoolantUOM        *MstUoms     `gorm:"foreignKey:ID;references:IDCoolantUOM" json:"coolant_uom,omitempty"`
+	HydraulicUOM      *MstUoms     `gorm:"foreignKey:ID;references:IDHydraulicUOM" json:"hydraulic_uom,omitempty"`
+	DimensionFrontUOM *MstUoms     `gorm:"foreignKey:ID;references:IDDimensionFrontUOM" json:"dimension_front_uom,omitempty"`
+	DimensionSideUOM  *MstUoms     `gorm:"foreignKey:ID;references:IDDimensionSideUOM" json:"dimension_side_uom,omitempty"`
+	Building          *MstBuilding `gorm:"foreignKey:ID;references:IDBuilding" json:"building,omitempty"`
+	CreatedBy         *MstUser     `gorm:"foreignKey:ID;references:IDCreatedby" json:"created_by,omitempty"`
+	UpdatedBy         *MstUser     `gorm:"foreignKey:ID;references:IDUpdatedby" json:"updated_by,omitempty"`
 }
 
 type MstMachineStatus struct {
@@ -92,6 +100,7 @@ type ViewMstMachine struct {
 
 	DetailID            uint    `json:"detail_id"`
 	RevNo               int     `json:"rev_no"`
+	RevisionState       string  `json:"revision_state"`
 	Code                string  `json:"code"`
 	CodeOld             string  `json:"code_old"`
 	AssetNum            string  `json:"asset_num"`
@@ -144,6 +153,8 @@ type ViewMstMachine struct {
 	DimensionSideUOMCode        string  `json:"dimension_side_uom_code"`
 	DimensionSideUOMDescription string  `json:"dimension_side_uom_description"`
 
+	IDBuilding *uint `json:"id_building"`
+
 	DetailIDCreatedby   uint      `json:"detail_id_createdby"`
 	DetailCreatedbyName string    `json:"detail_createdby_name"`
 	DetailIDUpdatedby   uint      `json:"detail_id_updatedby"`
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MstMaintenancePlan struct {
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	Code          string         `json:"code,omitempty"`
+	Name          string         `json:"name,omitempty"`
+	Description   string         `json:"description,omitempty"`
+	IntervalType  string         `json:"interval_type,omitempty"`
+	IntervalValue int            `json:"interval_value,omitempty"`
+	LeadDays      int            `json:"lead_days"`
+	Remarks       string         `json:"remarks,omitempty"`
+	IDCreatedby   uint           `json:"id_createdby,omitempty"`
+	IDUpdatedby   uint           `json:"id_updatedby,omitempty"`
+	CreatedAt     *time.Time     `gorm:"autoCreateTime" json:"created_at,omitempty"`
+	UpdatedAt     *time.Time     `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty" swaggertype:"string" format:"date-time"`
+	IDDeletedby   *uint          `json:"id_deletedby,omitempty"`
+
+	Tasks     []MstMaintenancePlanTask `gorm:"foreignKey:IDMaintenancePlan" json:"tasks,omitempty"`
+	CreatedBy *MstUser                 `gorm:"foreignKey:ID;references:IDCreatedby" json:"created_by,omitempty"`
+	UpdatedBy *MstUser                 `gorm:"foreignKey:ID;references:IDUpdatedby" json:"updated_by,omitempty"`
+}
+
+type MstMaintenancePlanTask struct {
+	ID                uint           `gorm:"primaryKey" json:"id"`
+	IDMaintenancePlan uint           `json:"id_maintenance_plan,omitempty"`
+	Seq               int            `json:"seq"`
+	Description       string         `json:"description,omitempty"`
+	Remarks           string         `json:"remarks,omitempty"`
+	IDCreatedby       uint           `json:"id_createdby,omitempty"`
+	IDUpdatedby       uint           `json:"id_updatedby,omitempty"`
+	CreatedAt         *time.Time     `gorm:"autoCreateTime" json:"created_at,omitempty"`
+	UpdatedAt         *time.Time     `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty" swaggertype:"string" format:"date-time"`
+	IDDeletedby       *uint          `json:"id_deletedby,omitempty"`
+
+	MaintenancePlan *MstMaintenancePlan `gorm:"foreignKey:ID;references:IDMaintenancePlan" json:"maintenance_plan,omitempty"`
+	CreatedBy       *MstUser            `gorm:"foreignKey:ID;references:IDCreatedby" json:"created_by,omitempty"`
+	UpdatedBy       *MstUser            `gorm:"foreignKey:ID;references:IDUpdatedby" json:"updated_by,omitempty"`
+}
+
+type MstMachineMaintenancePlan struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	IDMachine         uint      `json:"id_machine,omitempty"`
+	IDMaintenancePlan uint      `json:"id_maintenance_plan,omitempty"`
+	StartDate         time.Time `json:"start_date,omitempty"`
+	// LastDueAt is the due date of the last work order opened, which the
+	// next cycle counts from. It is set when the work order is opened.
+	LastDueAt   *time.Time     `json:"last_due_at,omitempty"`
+	IDCreatedby uint           `json:"id_createdby,omitempty"`
+	IDUpdatedby uint           `json:"id_updatedby,omitempty"`
+	CreatedAt   *time.Time     `gorm:"autoCreateTime" json:"created_at,omitempty"`
+	UpdatedAt   *time.Time     `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty" swaggertype:"string" format:"date-time"`
+	IDDeletedby *uint          `json:"id_deletedby,omitempty"`
+
+	Machine         *MstMachine         `gorm:"foreignKey:ID;references:IDMachine" json:"machine,omitempty"`
+	MaintenancePlan *MstMaintenancePlan `gorm:"foreignKey:ID;references:IDMaintenancePlan" json:"maintenance_plan,omitempty"`
+	CreatedBy       *MstUser            `gorm:"foreignKey:ID;references:IDCreatedby" json:"created_by,omitempty"`
+	UpdatedBy       *MstUser            `gorm:"foreignKey:ID;references:IDUpdatedby" json:"updated_by,omitempty"`
+}
@@ -2,19 +2,23 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type MstMenu struct {
-	ID          uint       `gorm:"primaryKey" json:"id"`
-	Label       string     `json:"label,omitempty"`
-	Path        string     `json:"path,omitempty"`
-	IDParent    uint       `json:"id_parent,omitempty"`
-	Icon        string     `json:"icon,omitempty"`
-	Sort        uint       `json:"sort,omitempty"`
-	IDCreatedby uint       `json:"id_createdby,omitempty"`
-	IDUpdatedby uint       `json:"id_updatedby,omitempty"`
-	CreatedAt   *time.Time `gorm:"autoCreateTime" json:"created_at,omitempty"`
-	UpdatedAt   *time.Time `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	Label       string         `json:"label,omitempty"`
+	Path        string         `json:"path,omitempty"`
+	IDParent    uint           `json:"id_parent,omitempty"`
+	Icon        string         `json:"icon,omitempty"`
+	Sort        uint           `json:"sort,omitempty"`
+	IDCreatedby uint           `json:"id_createdby,omitempty"`
+	IDUpdatedby uint           `json:"id_updatedby,omitempty"`
+	CreatedAt   *time.Time     `gorm:"autoCreateTime" json:"created_at,omitempty"`
+	UpdatedAt   *time.Time     `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty" swaggertype:"string" format:"date-time"`
+	IDDeletedby *uint          `json:"id_deletedby,omitempty"`
 
 	CreatedBy     *MstUser       `gorm:"foreignKey:ID;references:IDCreatedby" json:"created_by,omitempty"`
 	UpdatedBy     *MstUser       `gorm:"foreignKey:ID;references:IDUpdatedby" json:"updated_by,omitempty"`
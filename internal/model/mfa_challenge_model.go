@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+)
+
+type MfaChallenge struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	IDUser    uint       `json:"id_user"`
+	TokenHash string     `json:"-"`
+	Device    string     `json:"device"`
+	ExpiredAt time.Time  `json:"expired_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
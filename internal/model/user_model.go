@@ -1,28 +1,98 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type MstUser struct {
-	ID           uint       `gorm:"primaryKey" json:"id"`
-	IDDept       uint       `json:"id_dept,omitempty"`
-	Name         string     `json:"name,omitempty"`
-	Email        string     `json:"email,omitempty"`
-	Username     string     `json:"username,omitempty"`
-	Password     string     `json:"password,omitempty"`
-	RefreshToken string     `json:"refresh_token,omitempty"`
-	OtpKey       string     `json:"otp_key,omitempty"`
-	OtpUrl       string     `json:"otp_url,omitempty"`
-	IsActive     bool       `json:"is_active"`
-	IsTwoFa      bool       `json:"is_two_fa"`
-	IDCreatedby  uint       `json:"id_createdby,omitempty"`
-	IDUpdatedby  uint       `json:"id_updatedby,omitempty"`
-	CreatedAt    *time.Time `gorm:"autoCreateTime" json:"created_at,omitempty"`
-	UpdatedAt    *time.Time `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	IDDept        uint           `json:"id_dept,omitempty"`
+	Name          string         `json:"name,omitempty"`
+	Email         string         `json:"email,omitempty"`
+	Username      string         `json:"username,omitempty"`
+	Password      string         `json:"-"`
+	RefreshToken  string         `json:"-"`
+	OtpKey        string         `json:"-"`
+	OtpUrl        string         `json:"-"`
+	OtpLastStep   *int64         `json:"-"`
+	OtpPendingKey string         `json:"-"`
+	OtpPendingUrl string         `json:"-"`
+	IsActive      bool           `json:"is_active"`
+	IsTwoFa       bool           `json:"is_two_fa"`
+	IDCreatedby   uint           `json:"id_createdby,omitempty"`
+	IDUpdatedby   uint           `json:"id_updatedby,omitempty"`
+	CreatedAt     *time.Time     `gorm:"autoCreateTime" json:"created_at,omitempty"`
+	UpdatedAt     *time.Time     `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty" swaggertype:"string" format:"date-time"`
+	IDDeletedby   *uint          `json:"id_deletedby,omitempty"`
 
 	Dept      *MstDept       `gorm:"foreignKey:IDDept;references:ID" json:"dept,omitempty"`
 	CreatedBy *MstUser       `gorm:"foreignKey:IDCreatedby;references:ID" json:"created_by,omitempty"`
 	UpdatedBy *MstUser       `gorm:"foreignKey:IDUpdatedby;references:ID" json:"updated_by,omitempty"`
 	UserRoles []*MstUserRole `gorm:"foreignKey:IDUser;references:ID" json:"user_roles,omitempty"`
 }
+
+// UserView is the JSON of a user. It never holds credential material: the
+// password hash, refresh token and OTP keys are left out.
+type UserView struct {
+	ID          uint           `json:"id"`
+	IDDept      uint           `json:"id_dept,omitempty"`
+	Name        string         `json:"name,omitempty"`
+	Email       string         `json:"email,omitempty"`
+	Username    string         `json:"username,omitempty"`
+	IsActive    bool           `json:"is_active"`
+	IsTwoFa     bool           `json:"is_two_fa"`
+	IDCreatedby uint           `json:"id_createdby,omitempty"`
+	IDUpdatedby uint           `json:"id_updatedby,omitempty"`
+	CreatedAt   *time.Time     `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time     `json:"updated_at,omitempty"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" swaggertype:"string" format:"date-time"`
+	IDDeletedby *uint          `json:"id_deletedby,omitempty"`
+
+	Dept      *MstDept       `json:"dept,omitempty"`
+	CreatedBy *MstUser       `json:"created_by,omitempty"`
+	UpdatedBy *MstUser       `json:"updated_by,omitempty"`
+	UserRoles []*MstUserRole `json:"user_roles,omitempty"`
+}
+
+// View is the user as it is sent to clients.
+func (u MstUser) View() UserView {
+	return UserView{
+		ID:          u.ID,
+		IDDept:      u.IDDept,
+		Name:        u.Name,
+		Email:       u.Email,
+		Username:    u.Username,
+		IsActive:    u.IsActive,
+		IsTwoFa:     u.IsTwoFa,
+		IDCreatedby: u.IDCreatedby,
+		IDUpdatedby: u.IDUpdatedby,
+		CreatedAt:   u.CreatedAt,
+		UpdatedAt:   u.UpdatedAt,
+		DeletedAt:   u.DeletedAt,
+		IDDeletedby: u.IDDeletedby,
+		Dept:        u.Dept,
+		CreatedBy:   u.CreatedBy,
+		UpdatedBy:   u.UpdatedBy,
+		UserRoles:   u.UserRoles,
+	}
+}
+
+// MarshalJSON serializes every user as its View, also where it is preloaded
+// into another record, e.g. as its CreatedBy.
+func (u MstUser) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.View())
+}
+
+// UserViews maps users to their views.
+func UserViews(users []MstUser) []UserView {
+	views := make([]UserView, len(users))
+	for i, user := range users {
+		views[i] = user.View()
+	}
+
+	return views
+}
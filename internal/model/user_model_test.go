@@ -0,0 +1,101 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// secretKeys are the JSON keys credential material would be sent under.
+var secretKeys = []string{
+	"password",
+	"refresh_token",
+	"otp_key",
+	"otp_url",
+	"otp_last_step",
+	"otp_pending_key",
+	"otp_pending_url",
+	"code_hash",
+}
+
+func secretUser(ID uint) *MstUser {
+	lastStep := int64(123456)
+
+	return &MstUser{
+		ID:            ID,
+		Name:          "Jane",
+		Username:      "jane",
+		Password:      "secret-password-hash",
+		RefreshToken:  "secret-refresh-token",
+		OtpKey:        "SECRETOTPKEY",
+		OtpUrl:        "otpauth://totp/secret-otp-url",
+		OtpLastStep:   &lastStep,
+		OtpPendingKey: "SECRETPENDINGKEY",
+		OtpPendingUrl: "otpauth://totp/secret-pending-url",
+		IsTwoFa:       true,
+	}
+}
+
+func TestUserJSONLeavesOutSecrets(t *testing.T) {
+	author := secretUser(1)
+	user := secretUser(2)
+	user.CreatedBy = author
+	user.UpdatedBy = author
+
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{"user", *user},
+		{"user pointer", user},
+		{"users", []MstUser{*user, *author}},
+		{"user pointers", []*MstUser{user, author}},
+		{"view", user.View()},
+		{"views", UserViews([]MstUser{*user})},
+		{"created and updated by", MstBank{ID: 1, Code: "BCA", CreatedBy: author, UpdatedBy: author}},
+		{"recovery code", UserRecoveryCode{ID: 1, IDUser: 2, CodeHash: "secret-code-hash"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+
+			body := string(data)
+			for _, key := range secretKeys {
+				if strings.Contains(body, `"`+key+`"`) {
+					t.Errorf("%s is serialized: %s", key, body)
+				}
+			}
+
+			if strings.Contains(strings.ToLower(body), "secret") {
+				t.Errorf("a secret value is serialized: %s", body)
+			}
+		})
+	}
+}
+
+func TestUserJSONKeepsProfile(t *testing.T) {
+	user := secretUser(2)
+	user.CreatedBy = secretUser(1)
+
+	data, err := json.Marshal(user)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if body["username"] != "jane" || body["is_two_fa"] != true {
+		t.Errorf("profile is missing: %s", data)
+	}
+
+	if _, ok := body["created_by"].(map[string]interface{}); !ok {
+		t.Errorf("created_by is missing: %s", data)
+	}
+}
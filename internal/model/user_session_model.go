@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+)
+
+type UserSession struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	IDUser        uint       `json:"id_user"`
+	TokenHash     string     `json:"-"`
+	Device        string     `json:"device"`
+	IPAddress     string     `json:"ip_address"`
+	UserAgent     string     `json:"user_agent"`
+	LastUsedAt    *time.Time `json:"last_used_at"`
+	ExpiredAt     time.Time  `json:"expired_at"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	RevokedReason string     `json:"revoked_reason,omitempty"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	IsCurrent bool `gorm:"-" json:"is_current"`
+}
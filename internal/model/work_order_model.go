@@ -0,0 +1,81 @@
+package model
+
+import (
+	"time"
+)
+
+type WorkOrder struct {
+	ID                       uint       `gorm:"primaryKey" json:"id"`
+	Type                     string     `json:"type"`
+	Status                   string     `json:"status"`
+	IDMachine                uint       `json:"id_machine"`
+	IDMachineMaintenancePlan *uint      `json:"id_machine_maintenance_plan,omitempty"`
+	Title                    string     `json:"title"`
+	Description              *string    `json:"description,omitempty"`
+	IDReason                 *uint      `json:"id_reason,omitempty"`
+	MachineDown              bool       `json:"machine_down"`
+	TechnicianNumber         *string    `json:"technician_number,omitempty"`
+	DueAt                    *time.Time `json:"due_at,omitempty"`
+	IDDownStatus             *uint      `json:"id_down_status,omitempty"`
+	IDResumeReason           *uint      `json:"id_resume_reason,omitempty"`
+	IDUpStatus               *uint      `json:"id_up_status,omitempty"`
+	AssignedAt               *time.Time `json:"assigned_at,omitempty"`
+	StartedAt                *time.Time `json:"started_at,omitempty"`
+	CompletedAt              *time.Time `json:"completed_at,omitempty"`
+	ClosedAt                 *time.Time `json:"closed_at,omitempty"`
+	IDCreatedby              *uint      `json:"id_createdby,omitempty"`
+	IDUpdatedby              *uint      `json:"id_updatedby,omitempty"`
+	CreatedAt                *time.Time `gorm:"autoCreateTime" json:"created_at,omitempty"`
+	UpdatedAt                *time.Time `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+
+	Machine                *MstMachine                `gorm:"foreignKey:ID;references:IDMachine" json:"machine,omitempty"`
+	MachineMaintenancePlan *MstMachineMaintenancePlan `gorm:"foreignKey:ID;references:IDMachineMaintenancePlan" json:"machine_maintenance_plan,omitempty"`
+	Reason                 *MstReason                 `gorm:"foreignKey:ID;references:IDReason" json:"reason,omitempty"`
+	Technician             *MstEmployee               `gorm:"foreignKey:Number;references:TechnicianNumber" json:"technician,omitempty"`
+	Tasks                  []WorkOrderTask            `gorm:"foreignKey:IDWorkOrder" json:"tasks,omitempty"`
+	Parts                  []WorkOrderPart            `gorm:"foreignKey:IDWorkOrder" json:"parts,omitempty"`
+	Labors                 []WorkOrderLabor           `gorm:"foreignKey:IDWorkOrder" json:"labors,omitempty"`
+	CreatedBy              *MstUser                   `gorm:"foreignKey:ID;references:IDCreatedby" json:"created_by,omitempty"`
+	UpdatedBy              *MstUser                   `gorm:"foreignKey:ID;references:IDUpdatedby" json:"updated_by,omitempty"`
+}
+
+type WorkOrderTask struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	IDWorkOrder uint       `json:"id_work_order"`
+	Seq         int        `json:"seq"`
+	Description string     `json:"description"`
+	Done        bool       `json:"done"`
+	CreatedAt   *time.Time `gorm:"autoCreateTime" json:"created_at,omitempty"`
+	UpdatedAt   *time.Time `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+}
+
+type WorkOrderPart struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	IDWorkOrder uint       `json:"id_work_order"`
+	IDItem      uint       `json:"id_item"`
+	Quantity    float64    `json:"quantity"`
+	IDUOM       uint       `json:"id_uom"`
+	Remarks     *string    `json:"remarks,omitempty"`
+	IDCreatedby uint       `json:"id_createdby,omitempty"`
+	IDUpdatedby uint       `json:"id_updatedby,omitempty"`
+	CreatedAt   *time.Time `gorm:"autoCreateTime" json:"created_at,omitempty"`
+	UpdatedAt   *time.Time `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+
+	Item *MstItem `gorm:"foreignKey:ID;references:IDItem" json:"item,omitempty"`
+	UOM  *MstUoms `gorm:"foreignKey:ID;references:IDUOM" json:"uom,omitempty"`
+}
+
+type WorkOrderLabor struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	IDWorkOrder    uint       `json:"id_work_order"`
+	EmployeeNumber string     `json:"employee_number"`
+	WorkDate       time.Time  `json:"work_date"`
+	Hours          float64    `json:"hours"`
+	Remarks        *string    `json:"remarks,omitempty"`
+	IDCreatedby    uint       `json:"id_createdby,omitempty"`
+	IDUpdatedby    uint       `json:"id_updatedby,omitempty"`
+	CreatedAt      *time.Time `gorm:"autoCreateTime" json:"created_at,omitempty"`
+	UpdatedAt      *time.Time `gorm:"autoUpdateTime" json:"updated_at,omitempty"`
+
+	Employee *MstEmployee `gorm:"foreignKey:Number;references:EmployeeNumber" json:"employee,omitempty"`
+}
@@ -1,15 +1,21 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/cron"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
 	"insist-backend-golang/internal/service"
+	"log"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
 func ActivityLogRoutes(api fiber.Router, db *gorm.DB) {
-	activityLog := api.Group("log")
+	activityLog := api.Group("log", middleware.VerifyToken)
 
 	activityLogService := service.NewActivityLogService(db)
 	activityLogHandler := handler.NewActivityLogHandler(activityLogService)
@@ -17,4 +23,24 @@ func ActivityLogRoutes(api fiber.Router, db *gorm.DB) {
 	activityLog.Get("/", activityLogHandler.GetActivityLogs)
 	activityLog.Get("/:id", activityLogHandler.GetActivityLog)
 	activityLog.Post("/", activityLogHandler.CreateActivityLog)
+
+	cron.SetupCron(func() {
+		// ACTIVITY_LOG_RETENTION_DAYS defaults to 90; 0 keeps logs forever.
+		retentionDays, err := strconv.Atoi(os.Getenv("ACTIVITY_LOG_RETENTION_DAYS"))
+		if err != nil {
+			retentionDays = 90
+		}
+
+		if retentionDays <= 0 {
+			return
+		}
+
+		purged, err := activityLogService.Purge(time.Now().AddDate(0, 0, -retentionDays))
+		if err != nil {
+			log.Println("Error purging activity logs:", err)
+			return
+		}
+
+		log.Printf("Purged %d activity logs older than %d days", purged, retentionDays)
+	}, "30 1 * * *")
 }
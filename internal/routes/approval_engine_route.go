@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func ApprovalEngineRoutes(api fiber.Router, db *gorm.DB) {
+	approvalEngine := api.Group("approval-engine")
+
+	approvalEngineService := service.NewApprovalEngineService(db)
+	approvalEngineHandler := handler.NewApprovalEngineHandler(approvalEngineService)
+
+	// The document's menu is known from its table only once the route
+	// matched, so the permission is checked per route.
+	verifyPermission := middleware.VerifyApprovalPermission(db)
+
+	approvalEngine.Get("/:refTable/:refID", verifyPermission, approvalEngineHandler.GetApprovalState)
+	approvalEngine.Post("/:refTable/:refID/submit", verifyPermission, approvalEngineHandler.SubmitApproval)
+	approvalEngine.Post("/:refTable/:refID/approve", verifyPermission, approvalEngineHandler.ApproveApproval)
+	approvalEngine.Post("/:refTable/:refID/reject", verifyPermission, approvalEngineHandler.RejectApproval)
+	approvalEngine.Post("/:refTable/:refID/return", verifyPermission, approvalEngineHandler.ReturnApproval)
+	approvalEngine.Post("/:refTable/:refID/cancel", verifyPermission, approvalEngineHandler.CancelApproval)
+}
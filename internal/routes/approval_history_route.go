@@ -18,9 +18,6 @@ func ApprovalHistoryRoutes(api fiber.Router, db *gorm.DB) {
 	approvalHistory.Get("/", approvalHistoryHandler.GetApprovalHistories)
 	approvalHistory.Get("/:id", approvalHistoryHandler.GetApprovalHistory)
 	approvalHistory.Get("/:id/ref", approvalHistoryHandler.GetAllByRefID)
-	approvalHistory.Post("/", approvalHistoryHandler.CreateApprovalHistory)
-	approvalHistory.Put("/:id", approvalHistoryHandler.UpdateApprovalHistory)
-	approvalHistory.Delete("/:id", approvalHistoryHandler.DeleteApprovalHistory)
 
 	approvalNotification.Get("/", approvalHistoryHandler.GetApprovalNotifications)
 }
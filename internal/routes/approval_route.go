@@ -2,6 +2,8 @@ package routes
 
 import (
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,15 +11,20 @@ import (
 )
 
 func ApprovalRoutes(api fiber.Router, db *gorm.DB) {
-	approval := api.Group("approval")
+	approval := api.Group("approval", middleware.VerifyPermission(db, "/admin/approval-structure"))
 
 	approvalService := service.NewApprovalService(db)
 	approvalHandler := handler.NewApprovalHandler(approvalService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
 
 	approval.Get("/", approvalHandler.GetApprovals)
+	approval.Get("/export", approvalHandler.ExportApprovals)
 	approval.Get("/:id", approvalHandler.GetApproval)
 	approval.Get("/:id/menu", approvalHandler.GetApprovalByIdMenu)
 	approval.Post("/", approvalHandler.CreateApproval)
 	approval.Put("/:id", approvalHandler.UpdateApproval)
-	approval.Delete("/:id", approvalHandler.DeleteApproval)
+	approval.Delete("/:id", dependencyHandler.CheckDelete(&model.MstApproval{}), approvalHandler.DeleteApproval)
+	approval.Put("/:id/restore", softDeleteHandler.Restore(&model.MstApproval{}))
+	approval.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstApproval{}))
 }
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,7 +10,7 @@ import (
 )
 
 func ApprovalStructureRoutes(api fiber.Router, db *gorm.DB) {
-	approvalStructure := api.Group("approval-structure")
+	approvalStructure := api.Group("approval-structure", middleware.VerifyPermission(db, "/admin/approval-structure"))
 
 	approvalStructureService := service.NewApprovalStructureService(db)
 	approvalStructureHandler := handler.NewApprovalStructureHandler(approvalStructureService)
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,7 +10,7 @@ import (
 )
 
 func ApprovalUserRoutes(api fiber.Router, db *gorm.DB) {
-	approvalUser := api.Group("approval-user")
+	approvalUser := api.Group("approval-user", middleware.VerifyPermission(db, "/admin/approval-structure"))
 
 	approvalUserService := service.NewApprovalUserService(db)
 	approvalUserHandler := handler.NewApprovalUserHandler(approvalUserService)
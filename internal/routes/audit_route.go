@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func AuditRoutes(api fiber.Router, db *gorm.DB) {
+	audit := api.Group("audit")
+
+	auditService := service.NewAuditService(db)
+	auditHandler := handler.NewAuditHandler(auditService)
+
+	audit.Get("/", auditHandler.GetAudits)
+	audit.Get("/:id", auditHandler.GetAudit)
+	audit.Get("/:table/:recordID", auditHandler.GetRecordHistory)
+}
@@ -12,7 +12,12 @@ import (
 func AuthRoutes(api fiber.Router, db *gorm.DB) {
 	authService := service.NewAuthService(db)
 	passwordResetService := service.NewPasswordResetService(db)
-	authHandler := handler.NewAuthHandler(authService, passwordResetService)
+	userSessionService := service.NewUserSessionService(db)
+	loginThrottleService := service.NewLoginThrottleService(db)
+	mfaChallengeService := service.NewMfaChallengeService(db)
+	twoFactorService := service.NewTwoFactorService(db)
+	activityLogService := service.NewActivityLogService(db)
+	authHandler := handler.NewAuthHandler(authService, passwordResetService, userSessionService, loginThrottleService, mfaChallengeService, twoFactorService, activityLogService)
 
 	api.Post("/login", authHandler.Login)
 	api.Post("/two-fa", authHandler.TwoFactorAuth)
@@ -20,7 +25,12 @@ func AuthRoutes(api fiber.Router, db *gorm.DB) {
 	api.Get("/token", authHandler.RefreshToken)
 	api.Get("/user-info", middleware.VerifyToken, authHandler.GetUserInfo)
 	api.Put("/change-password", middleware.VerifyToken, authHandler.ChangePassword)
-	api.Put("/:id/two-fa", middleware.VerifyToken, authHandler.SetTwoFactorAuth)
+	api.Get("/sessions", middleware.VerifyToken, authHandler.GetSessions)
+	api.Delete("/sessions/:id", middleware.VerifyToken, authHandler.RevokeSession)
+	api.Post("/two-fa/enroll", middleware.VerifyToken, authHandler.EnrollTwoFactorAuth)
+	api.Post("/two-fa/activate", middleware.VerifyToken, authHandler.ActivateTwoFactorAuth)
+	api.Post("/two-fa/recovery-codes", middleware.VerifyToken, authHandler.RegenerateRecoveryCodes)
+	api.Delete("/two-fa", middleware.VerifyToken, authHandler.DisableTwoFactorAuth)
 	api.Post("/:id/send-password-reset", middleware.VerifyToken, authHandler.SendPasswordReset)
 	api.Post("/password-reset", authHandler.PasswordReset)
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,48 @@ import (
 )
 
 func BankRoutes(api fiber.Router, db *gorm.DB) {
-	bank := api.Group("master/bank")
+	bank := api.Group("master/bank", middleware.VerifyPermission(db, "/acf/master/bank"))
 
-	bankService := service.NewBankService(db)
-	bankHandler := handler.NewBankHandler(bankService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	bankHandler := handler.NewBankHandler(db, crud.Resource[model.MstBank]{
+		Name:          "Bank",
+		SearchColumns: []string{"code", "name"},
+		DefaultSort:   "updated_at ASC",
+		Preloads: []crud.Preload{
+			{Name: "Account", Columns: "id, account, description"},
+			{Name: "Currency", Columns: "id, currency, description"},
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Code", Field: "Code"},
+			{Header: "Name", Field: "Name"},
+			{Header: "Account Number", Field: "AccountNum"},
+			{Header: "Account", Field: "Account.Account"},
+			{Header: "Currency", Field: "Currency.Currency"},
+			{Header: "BIC", Field: "BIC"},
+			{Header: "Country", Field: "Country"},
+			{Header: "State", Field: "State"},
+			{Header: "City", Field: "City"},
+			{Header: "Address", Field: "Address"},
+			{Header: "Zip Code", Field: "ZipCode"},
+			{Header: "Remarks", Field: "Remarks"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	bank.Get("/", bankHandler.GetBanks)
+	bank.Get("/export", bankHandler.ExportBanks)
 	bank.Get("/:id", bankHandler.GetBank)
 	bank.Post("/", bankHandler.CreateBank)
+	bank.Post("/bulk", bankHandler.BulkBanks)
 	bank.Put("/:id", bankHandler.UpdateBank)
-	bank.Delete("/:id", bankHandler.DeleteBank)
+	bank.Delete("/:id", dependencyHandler.CheckDelete(&model.MstBank{}), bankHandler.DeleteBank)
+	bank.Put("/:id/restore", softDeleteHandler.Restore(&model.MstBank{}))
+	bank.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstBank{}))
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,48 @@ import (
 )
 
 func BillingTermRoutes(api fiber.Router, db *gorm.DB) {
-	billingTerm := api.Group("master/billing-term")
+	billingTerm := api.Group("master/billing-term", middleware.VerifyPermission(db, "/general/master/billing-term"))
 
-	billingTermService := service.NewBillingTermService(db)
-	billingTermHandler := handler.NewBillingTermHandler(billingTermService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	billingTermHandler := handler.NewBillingTermHandler(db, crud.Resource[model.MstBillingTerm]{
+		Name:          "Billing Term",
+		SearchColumns: []string{"code", "description"},
+		DefaultSort:   "updated_at ASC",
+		Preloads: []crud.Preload{
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Code", Field: "Code"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Due Days", Field: "DueDays"},
+			{Header: "Discount Days", Field: "DiscountDays"},
+			{Header: "Cash Only", Field: "IsCashOnly"},
+			{Header: "Prox Due Day", Field: "ProxDueDay"},
+			{Header: "Prox Discount Day", Field: "ProxDiscountDay"},
+			{Header: "Prox Months Forward", Field: "ProxMonthsForward"},
+			{Header: "Prox Discount Months Forward", Field: "ProxDiscountMonthsForward"},
+			{Header: "Cutoff Day", Field: "CutoffDay"},
+			{Header: "Discount Percent", Field: "DiscountPercent"},
+			{Header: "Holiday Offset Method", Field: "HolidayOffsetMethod"},
+			{Header: "Advanced Terms", Field: "IsAdvancedTerms"},
+			{Header: "Prox Code", Field: "ProxCode"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	billingTerm.Get("/", billingTermHandler.GetBillingTerms)
+	billingTerm.Get("/export", billingTermHandler.ExportBillingTerms)
 	billingTerm.Get("/:id", billingTermHandler.GetBillingTerm)
 	billingTerm.Post("/", billingTermHandler.CreateBillingTerm)
+	billingTerm.Post("/bulk", billingTermHandler.BulkBillingTerms)
 	billingTerm.Put("/:id", billingTermHandler.UpdateBillingTerm)
-	billingTerm.Delete("/:id", billingTermHandler.DeleteBillingTerm)
+	billingTerm.Delete("/:id", dependencyHandler.CheckDelete(&model.MstBillingTerm{}), billingTermHandler.DeleteBillingTerm)
+	billingTerm.Put("/:id/restore", softDeleteHandler.Restore(&model.MstBillingTerm{}))
+	billingTerm.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstBillingTerm{}))
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,23 @@ import (
 )
 
 func BuildingRoutes(api fiber.Router, db *gorm.DB) {
-	building := api.Group("master/building")
+	building := api.Group("master/building", middleware.VerifyPermission(db, "/prd/master/building"))
 
 	buildingService := service.NewBuildingService(db)
-	buildingHandler := handler.NewBuildingHandler(buildingService)
+	buildingHandler := handler.NewBuildingHandler(buildingService, crud.NewBulk(db, crud.Bulk[model.MstBuilding]{
+		Name:  "Building",
+		Input: func() dto.Input[model.MstBuilding] { return &dto.BuildingRequest{} },
+	}))
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
 
 	building.Get("/", buildingHandler.GetBuildings)
+	building.Get("/export", buildingHandler.ExportBuildings)
 	building.Get("/:id", buildingHandler.GetBuilding)
 	building.Post("/", buildingHandler.CreateBuilding)
+	building.Post("/bulk", buildingHandler.BulkBuildings)
 	building.Put("/:id", buildingHandler.UpdateBuilding)
-	building.Delete("/:id", buildingHandler.DeleteBuilding)
+	building.Delete("/:id", dependencyHandler.CheckDelete(&model.MstBuilding{}), buildingHandler.DeleteBuilding)
+	building.Put("/:id/restore", softDeleteHandler.Restore(&model.MstBuilding{}))
+	building.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstBuilding{}))
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,39 @@ import (
 )
 
 func ChartOfAccountRoutes(api fiber.Router, db *gorm.DB) {
-	chartOfAccount := api.Group("master/chart-of-account")
+	chartOfAccount := api.Group("master/chart-of-account", middleware.VerifyPermission(db, "/acf/master/chart-of-account"))
 
-	chartOfAccountService := service.NewChartOfAccountService(db)
-	chartOfAccountHandler := handler.NewChartOfAccountHandler(chartOfAccountService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	chartOfAccountHandler := handler.NewChartOfAccountHandler(db, crud.Resource[model.MstChartOfAccount]{
+		Name:          "Chart Of Account",
+		SearchColumns: []string{"account::TEXT", "description"},
+		DefaultSort:   "updated_at ASC",
+		Preloads: []crud.Preload{
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Account", Field: "Account"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Type", Field: "Type"},
+			{Header: "Class", Field: "Class"},
+			{Header: "Exchange Rate Type", Field: "ExchangeRateType"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	chartOfAccount.Get("/", chartOfAccountHandler.GetChartOfAccounts)
+	chartOfAccount.Get("/export", chartOfAccountHandler.ExportChartOfAccounts)
 	chartOfAccount.Get("/:id", chartOfAccountHandler.GetChartOfAccount)
 	chartOfAccount.Post("/", chartOfAccountHandler.CreateChartOfAccount)
+	chartOfAccount.Post("/bulk", chartOfAccountHandler.BulkChartOfAccounts)
 	chartOfAccount.Put("/:id", chartOfAccountHandler.UpdateChartOfAccount)
-	chartOfAccount.Delete("/:id", chartOfAccountHandler.DeleteChartOfAccount)
+	chartOfAccount.Delete("/:id", dependencyHandler.CheckDelete(&model.MstChartOfAccount{}), chartOfAccountHandler.DeleteChartOfAccount)
+	chartOfAccount.Put("/:id/restore", softDeleteHandler.Restore(&model.MstChartOfAccount{}))
+	chartOfAccount.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstChartOfAccount{}))
 }
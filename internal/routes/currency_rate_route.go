@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,23 @@ import (
 )
 
 func CurrencyRateRoutes(api fiber.Router, db *gorm.DB) {
-	currencyRate := api.Group("master/currency-rate")
+	currencyRate := api.Group("master/currency-rate", middleware.VerifyPermission(db, "/acf/master/currency-rate"))
 
 	currencyRateService := service.NewCurrencyRateService(db)
-	currencyRateHandler := handler.NewCurrencyRateHandler(currencyRateService)
+	currencyRateHandler := handler.NewCurrencyRateHandler(currencyRateService, crud.NewBulk(db, crud.Bulk[model.MstCurrencyRate]{
+		Name:  "Currency Rate",
+		Input: func() dto.Input[model.MstCurrencyRate] { return &dto.CurrencyRateRequest{} },
+	}))
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
 
 	currencyRate.Get("/", currencyRateHandler.GetCurrencyRates)
+	currencyRate.Get("/export", currencyRateHandler.ExportCurrencyRates)
 	currencyRate.Get("/:id", currencyRateHandler.GetCurrencyRate)
 	currencyRate.Post("/", currencyRateHandler.CreateCurrencyRate)
+	currencyRate.Post("/bulk", currencyRateHandler.BulkCurrencyRates)
 	currencyRate.Put("/:id", currencyRateHandler.UpdateCurrencyRate)
-	currencyRate.Delete("/:id", currencyRateHandler.DeleteCurrencyRate)
+	currencyRate.Delete("/:id", dependencyHandler.CheckDelete(&model.MstCurrencyRate{}), currencyRateHandler.DeleteCurrencyRate)
+	currencyRate.Put("/:id/restore", softDeleteHandler.Restore(&model.MstCurrencyRate{}))
+	currencyRate.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstCurrencyRate{}))
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,16 +13,25 @@ import (
 )
 
 func CurrencyRoutes(api fiber.Router, db *gorm.DB) {
-	currency := api.Group("master/currency")
-	generate := api.Group("master/currency-generate")
+	currency := api.Group("master/currency", middleware.VerifyPermission(db, "/acf/master/currency"))
+	generate := api.Group("master/currency-generate", middleware.VerifyPermission(db, "/acf/master/currency"))
 
 	currencyService := service.NewCurrencyService(db)
-	currencyHandler := handler.NewCurrencyHandler(currencyService)
+	currencyHandler := handler.NewCurrencyHandler(currencyService, crud.NewBulk(db, crud.Bulk[model.MstCurrency]{
+		Name:  "Currency",
+		Input: func() dto.Input[model.MstCurrency] { return &dto.CurrencyRequest{} },
+	}))
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
 
 	currency.Get("/", currencyHandler.GetCurrencies)
+	currency.Get("/export", currencyHandler.ExportCurrencies)
 	currency.Get("/:id", currencyHandler.GetCurrency)
 	currency.Post("/", currencyHandler.CreateCurrency)
+	currency.Post("/bulk", currencyHandler.BulkCurrencies)
 	currency.Put("/:id", currencyHandler.UpdateCurrency)
-	currency.Delete("/:id", currencyHandler.DeleteCurrency)
+	currency.Delete("/:id", dependencyHandler.CheckDelete(&model.MstCurrency{}), currencyHandler.DeleteCurrency)
+	currency.Put("/:id/restore", softDeleteHandler.Restore(&model.MstCurrency{}))
+	currency.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstCurrency{}))
 	generate.Post("/", currencyHandler.GenerateCurrency)
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,37 @@ import (
 )
 
 func DeptRoutes(api fiber.Router, db *gorm.DB) {
-	dept := api.Group("master/department")
+	dept := api.Group("master/department", middleware.VerifyPermission(db, "/admin/master/department"))
 
-	deptService := service.NewDeptService(db)
-	deptHandler := handler.NewDeptHandler(deptService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	deptHandler := handler.NewDeptHandler(db, crud.Resource[model.MstDept]{
+		Name:          "Department",
+		SearchColumns: []string{"code", "description"},
+		DefaultSort:   "updated_at ASC",
+		Preloads: []crud.Preload{
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Code", Field: "Code"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Remarks", Field: "Remarks"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	dept.Get("/", deptHandler.GetDepts)
+	dept.Get("/export", deptHandler.ExportDepts)
 	dept.Get("/:id", deptHandler.GetDept)
 	dept.Post("/", deptHandler.CreateDept)
+	dept.Post("/bulk", deptHandler.BulkDepts)
 	dept.Put("/:id", deptHandler.UpdateDept)
-	dept.Delete("/:id", deptHandler.DeleteDept)
+	dept.Delete("/:id", dependencyHandler.CheckDelete(&model.MstDept{}), deptHandler.DeleteDept)
+	dept.Put("/:id/restore", softDeleteHandler.Restore(&model.MstDept{}))
+	dept.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstDept{}))
 }
@@ -3,6 +3,8 @@ package routes
 import (
 	"insist-backend-golang/internal/cron"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 	"log"
 
@@ -12,13 +14,16 @@ import (
 )
 
 func EmployeeRoutes(api fiber.Router, db *gorm.DB) {
-	employee := api.Group("master/employee")
+	employee := api.Group("master/employee", middleware.VerifyPermission(db, "/admin/master/employee"))
 
 	employeeService := service.NewEmployeeService(db)
 	employeeHandler := handler.NewEmployeeHandler(employeeService)
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
 
 	employee.Get("/", employeeHandler.GetEmployees)
+	employee.Get("/export", employeeHandler.ExportEmployees)
 	employee.Get("/:number", employeeHandler.GetEmployee)
+	employee.Get("/:number/where-used", dependencyHandler.WhereUsedByNumber(&model.MstEmployee{}))
 	employee.Post("/sync", employeeHandler.SyncEmployee)
 
 	cron.SetupCron(func() {
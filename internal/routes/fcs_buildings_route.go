@@ -2,6 +2,7 @@ package routes
 
 import (
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,12 +10,13 @@ import (
 )
 
 func FCSBuildingRoutes(api fiber.Router, db *gorm.DB) {
-	fcsBuilding := api.Group("master/fcs-building")
+	fcsBuilding := api.Group("master/fcs-building", middleware.VerifyPermission(db, "/prd/master/fcs-building"))
 
 	fcsBuildingService := service.NewFCSBuildingService(db)
 	fcsBuildingHandler := handler.NewFCSBuildingHandler(fcsBuildingService)
 
 	fcsBuilding.Get("/", fcsBuildingHandler.GetFCSBuildings)
+	fcsBuilding.Post("/bulk", fcsBuildingHandler.BulkFCSBuildings)
 	fcsBuilding.Get("/:id", fcsBuildingHandler.GetFCSBuilding)
 	fcsBuilding.Put("/:id", fcsBuildingHandler.UpdateFCSBuilding)
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,37 @@ import (
 )
 
 func FCSRoutes(api fiber.Router, db *gorm.DB) {
-	fcs := api.Group("master/fcs")
+	fcs := api.Group("master/fcs", middleware.VerifyPermission(db, "/prd/master/fcs"))
 
-	fcsService := service.NewFCSService(db)
-	fcsHandler := handler.NewFCSHandler(fcsService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	fcsHandler := handler.NewFCSHandler(db, crud.Resource[model.MstFCS]{
+		Name:          "FCS",
+		SearchColumns: []string{"code", "description"},
+		DefaultSort:   "updated_at ASC",
+		Preloads: []crud.Preload{
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Code", Field: "Code"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Remarks", Field: "Remarks"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	fcs.Get("/", fcsHandler.GetFCSs)
+	fcs.Get("/export", fcsHandler.ExportFCSs)
 	fcs.Get("/:id", fcsHandler.GetFCS)
 	fcs.Post("/", fcsHandler.CreateFCS)
+	fcs.Post("/bulk", fcsHandler.BulkFCSs)
 	fcs.Put("/:id", fcsHandler.UpdateFCS)
-	fcs.Delete("/:id", fcsHandler.DeleteFCS)
+	fcs.Delete("/:id", dependencyHandler.CheckDelete(&model.MstFCS{}), fcsHandler.DeleteFCS)
+	fcs.Put("/:id/restore", softDeleteHandler.Restore(&model.MstFCS{}))
+	fcs.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstFCS{}))
 }
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func ImportRoutes(api fiber.Router, db *gorm.DB) {
+	imports := api.Group("import")
+
+	importService := service.NewImportService(db)
+	importHandler := handler.NewImportHandler(importService)
+
+	imports.Get("/", importHandler.GetImports)
+	imports.Get("/:id", importHandler.GetImport)
+	imports.Get("/:id/errors", importHandler.ExportImportErrors)
+	imports.Get("/:master/template", importHandler.GetImportTemplate)
+	imports.Post("/:master", middleware.VerifyImportPermission(db, service.ImportMenus), importHandler.Import)
+}
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,23 @@ import (
 )
 
 func ItemCategoryRoutes(api fiber.Router, db *gorm.DB) {
-	itemCategory := api.Group("master/item/category")
+	itemCategory := api.Group("master/item/category", middleware.VerifyPermission(db, "/general/master/item/category"))
 
 	itemCategoryService := service.NewItemCategoryService(db)
-	itemCategoryHandler := handler.NewItemCategoryHandler(itemCategoryService)
+	itemCategoryHandler := handler.NewItemCategoryHandler(itemCategoryService, crud.NewBulk(db, crud.Bulk[model.MstItemCategory]{
+		Name:  "Item Category",
+		Input: func() dto.Input[model.MstItemCategory] { return &dto.ItemCategoryRequest{} },
+	}))
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
 
 	itemCategory.Get("/", itemCategoryHandler.GetItemCategories)
+	itemCategory.Get("/export", itemCategoryHandler.ExportItemCategories)
 	itemCategory.Get("/:identifier", itemCategoryHandler.GetItemCategory)
 	itemCategory.Post("/", itemCategoryHandler.CreateItemCategory)
+	itemCategory.Post("/bulk", itemCategoryHandler.BulkItemCategories)
 	itemCategory.Put("/:id", itemCategoryHandler.UpdateItemCategory)
-	itemCategory.Delete("/:id", itemCategoryHandler.DeleteItemCategory)
+	itemCategory.Delete("/:id", dependencyHandler.CheckDelete(&model.MstItemCategory{}), itemCategoryHandler.DeleteItemCategory)
+	itemCategory.Put("/:id/restore", softDeleteHandler.Restore(&model.MstItemCategory{}))
+	itemCategory.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstItemCategory{}))
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,48 @@ import (
 )
 
 func ItemGroupRoutes(api fiber.Router, db *gorm.DB) {
-	itemGroup := api.Group("master/item/group")
+	itemGroup := api.Group("master/item/group", middleware.VerifyPermission(db, "/general/master/item/group"))
 
-	itemGroupService := service.NewItemGroupService(db)
-	itemGroupHandler := handler.NewItemGroupHandler(itemGroupService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	itemGroupHandler := handler.NewItemGroupHandler(db, crud.Resource[model.MstItemGroup]{
+		Name:          "Item Group",
+		SearchColumns: []string{"mst_item_groups.code", "mst_item_groups.description"},
+		DefaultSort:   "mst_item_groups.code ASC",
+		Joins: []string{
+			"LEFT JOIN mst_item_product_types ON mst_item_product_types.id = mst_item_groups.id_item_product_type",
+			"LEFT JOIN mst_item_products ON mst_item_products.id = mst_item_product_types.id_item_product",
+			"LEFT JOIN mst_item_categories ON mst_item_categories.id = mst_item_products.id_item_category",
+		},
+		Preloads: []crud.Preload{
+			{Name: "ItemProductType", Columns: "id, code, description"},
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		Filters: []crud.Filter{
+			{Query: "idProductType", Column: "mst_item_product_types.id"},
+			{Query: "categoryCode", Column: "mst_item_categories.code"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Item Product Type", Field: "ItemProductType.Code"},
+			{Header: "Code", Field: "Code"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Remarks", Field: "Remarks"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	itemGroup.Get("/", itemGroupHandler.GetItemGroups)
+	itemGroup.Get("/export", itemGroupHandler.ExportItemGroups)
 	itemGroup.Get("/:id", itemGroupHandler.GetItemGroup)
 	itemGroup.Post("/", itemGroupHandler.CreateItemGroup)
+	itemGroup.Post("/bulk", itemGroupHandler.BulkItemGroups)
 	itemGroup.Put("/:id", itemGroupHandler.UpdateItemGroup)
-	itemGroup.Delete("/:id", itemGroupHandler.DeleteItemGroup)
+	itemGroup.Delete("/:id", dependencyHandler.CheckDelete(&model.MstItemGroup{}), itemGroupHandler.DeleteItemGroup)
+	itemGroup.Put("/:id/restore", softDeleteHandler.Restore(&model.MstItemGroup{}))
+	itemGroup.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstItemGroup{}))
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,42 @@ import (
 )
 
 func ItemGroupTypeRoutes(api fiber.Router, db *gorm.DB) {
-	itemGroupType := api.Group("master/item/group-type")
+	itemGroupType := api.Group("master/item/group-type", middleware.VerifyPermission(db, "/general/master/item/group-type"))
 
-	itemGroupTypeService := service.NewItemGroupTypeService(db)
-	itemGroupTypeHandler := handler.NewItemGroupTypeHandler(itemGroupTypeService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	itemGroupTypeHandler := handler.NewItemGroupTypeHandler(db, crud.Resource[model.MstItemGroupType]{
+		Name:          "Item Group Type",
+		SearchColumns: []string{"code", "description"},
+		DefaultSort:   "code ASC",
+		Preloads: []crud.Preload{
+			{Name: "ItemGroup", Columns: "id, code, description"},
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		Filters: []crud.Filter{
+			{Query: "idItemGroup", Column: "id_item_group"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Item Group", Field: "ItemGroup.Code"},
+			{Header: "Code", Field: "Code"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Remarks", Field: "Remarks"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	itemGroupType.Get("/", itemGroupTypeHandler.GetItemGroupTypes)
+	itemGroupType.Get("/export", itemGroupTypeHandler.ExportItemGroupTypes)
 	itemGroupType.Get("/:id", itemGroupTypeHandler.GetItemGroupType)
 	itemGroupType.Post("/", itemGroupTypeHandler.CreateItemGroupType)
+	itemGroupType.Post("/bulk", itemGroupTypeHandler.BulkItemGroupTypes)
 	itemGroupType.Put("/:id", itemGroupTypeHandler.UpdateItemGroupType)
-	itemGroupType.Delete("/:id", itemGroupTypeHandler.DeleteItemGroupType)
+	itemGroupType.Delete("/:id", dependencyHandler.CheckDelete(&model.MstItemGroupType{}), itemGroupTypeHandler.DeleteItemGroupType)
+	itemGroupType.Put("/:id/restore", softDeleteHandler.Restore(&model.MstItemGroupType{}))
+	itemGroupType.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstItemGroupType{}))
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,45 @@ import (
 )
 
 func ItemProcessRoutes(api fiber.Router, db *gorm.DB) {
-	itemProcess := api.Group("master/item/process")
+	itemProcess := api.Group("master/item/process", middleware.VerifyPermission(db, "/general/master/item/process"))
 
-	itemProcessService := service.NewItemProcessService(db)
-	itemProcessHandler := handler.NewItemProcessHandler(itemProcessService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	itemProcessHandler := handler.NewItemProcessHandler(db, crud.Resource[model.MstItemProcess]{
+		Name:          "Item Process",
+		SearchColumns: []string{"mst_item_processes.code", "mst_item_processes.description"},
+		DefaultSort:   "mst_item_processes.code ASC",
+		Joins: []string{
+			"LEFT JOIN mst_item_categories ON mst_item_categories.id = mst_item_processes.id_item_category",
+		},
+		Preloads: []crud.Preload{
+			{Name: "ItemCategory", Columns: "id, code, description"},
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		Filters: []crud.Filter{
+			{Query: "categoryCode", Column: "mst_item_categories.code"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Item Category", Field: "ItemCategory.Code"},
+			{Header: "Code", Field: "Code"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Remarks", Field: "Remarks"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	itemProcess.Get("/", itemProcessHandler.GetItemProcesses)
+	itemProcess.Get("/export", itemProcessHandler.ExportItemProcesses)
 	itemProcess.Get("/:id", itemProcessHandler.GetItemProcess)
 	itemProcess.Post("/", itemProcessHandler.CreateItemProcess)
+	itemProcess.Post("/bulk", itemProcessHandler.BulkItemProcesses)
 	itemProcess.Put("/:id", itemProcessHandler.UpdateItemProcess)
-	itemProcess.Delete("/:id", itemProcessHandler.DeleteItemProcess)
+	itemProcess.Delete("/:id", dependencyHandler.CheckDelete(&model.MstItemProcess{}), itemProcessHandler.DeleteItemProcess)
+	itemProcess.Put("/:id/restore", softDeleteHandler.Restore(&model.MstItemProcess{}))
+	itemProcess.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstItemProcess{}))
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,49 @@ import (
 )
 
 func ItemProductRoutes(api fiber.Router, db *gorm.DB) {
-	itemProduct := api.Group("master/item/product")
+	itemProduct := api.Group("master/item/product", middleware.VerifyPermission(db, "/general/master/item/product"))
 
-	itemProductService := service.NewItemProductService(db)
-	itemProductHandler := handler.NewItemProductHandler(itemProductService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	itemProductHandler := handler.NewItemProductHandler(db, crud.Resource[model.MstItemProduct]{
+		Name:          "Item Product",
+		SearchColumns: []string{"mst_item_products.code", "mst_item_products.description"},
+		DefaultSort:   "mst_item_products.code ASC",
+		Joins: []string{
+			"LEFT JOIN mst_item_categories ON mst_item_categories.id = mst_item_products.id_item_category",
+			"LEFT JOIN mst_item_sub_categories ON mst_item_sub_categories.id = mst_item_products.id_item_sub_category",
+		},
+		Preloads: []crud.Preload{
+			{Name: "ItemCategory", Columns: "id, code, description"},
+			{Name: "ItemSubCategory", Columns: "id, code, description"},
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		Filters: []crud.Filter{
+			{Query: "categoryCode", Column: "mst_item_categories.code"},
+			{Query: "subCategoryCode", Column: "mst_item_sub_categories.code"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Item Category", Field: "ItemCategory.Code"},
+			{Header: "Item Sub Category", Field: "ItemSubCategory.Code"},
+			{Header: "Code", Field: "Code"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Remarks", Field: "Remarks"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	itemProduct.Get("/", itemProductHandler.GetItemProducts)
+	itemProduct.Get("/export", itemProductHandler.ExportItemProducts)
 	itemProduct.Get("/:id", itemProductHandler.GetItemProduct)
 	itemProduct.Post("/", itemProductHandler.CreateItemProduct)
+	itemProduct.Post("/bulk", itemProductHandler.BulkItemProducts)
 	itemProduct.Put("/:id", itemProductHandler.UpdateItemProduct)
-	itemProduct.Delete("/:id", itemProductHandler.DeleteItemProduct)
+	itemProduct.Delete("/:id", dependencyHandler.CheckDelete(&model.MstItemProduct{}), itemProductHandler.DeleteItemProduct)
+	itemProduct.Put("/:id/restore", softDeleteHandler.Restore(&model.MstItemProduct{}))
+	itemProduct.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstItemProduct{}))
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,42 @@ import (
 )
 
 func ItemProductTypeRoutes(api fiber.Router, db *gorm.DB) {
-	itemProductType := api.Group("master/item/product-type")
+	itemProductType := api.Group("master/item/product-type", middleware.VerifyPermission(db, "/general/master/item/product-type"))
 
-	itemProductTypeService := service.NewItemProductTypeService(db)
-	itemProductTypeHandler := handler.NewItemProductTypeHandler(itemProductTypeService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	itemProductTypeHandler := handler.NewItemProductTypeHandler(db, crud.Resource[model.MstItemProductType]{
+		Name:          "Item Product Type",
+		SearchColumns: []string{"code", "description"},
+		DefaultSort:   "code ASC",
+		Preloads: []crud.Preload{
+			{Name: "ItemProduct", Columns: "id, code, description"},
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		Filters: []crud.Filter{
+			{Query: "idItemProduct", Column: "id_item_product"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Item Product", Field: "ItemProduct.Code"},
+			{Header: "Code", Field: "Code"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Remarks", Field: "Remarks"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	itemProductType.Get("/", itemProductTypeHandler.GetItemProductTypes)
+	itemProductType.Get("/export", itemProductTypeHandler.ExportItemProductTypes)
 	itemProductType.Get("/:id", itemProductTypeHandler.GetItemProductType)
 	itemProductType.Post("/", itemProductTypeHandler.CreateItemProductType)
+	itemProductType.Post("/bulk", itemProductTypeHandler.BulkItemProductTypes)
 	itemProductType.Put("/:id", itemProductTypeHandler.UpdateItemProductType)
-	itemProductType.Delete("/:id", itemProductTypeHandler.DeleteItemProductType)
+	itemProductType.Delete("/:id", dependencyHandler.CheckDelete(&model.MstItemProductType{}), itemProductTypeHandler.DeleteItemProductType)
+	itemProductType.Put("/:id/restore", softDeleteHandler.Restore(&model.MstItemProductType{}))
+	itemProductType.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstItemProductType{}))
 }
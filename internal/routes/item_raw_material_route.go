@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,23 @@ import (
 )
 
 func ItemRawMaterialRoutes(api fiber.Router, db *gorm.DB) {
-	itemRawMaterial := api.Group("master/item/generate-raw-material")
+	itemRawMaterial := api.Group("master/item/generate-raw-material", middleware.VerifyPermission(db, "/general/master/item/generate-raw-material"))
 
 	itemRawMaterialService := service.NewItemRawMaterialService(db)
-	itemRawMaterialHandler := handler.NewItemRawMaterialHandler(itemRawMaterialService)
+	itemRawMaterialHandler := handler.NewItemRawMaterialHandler(itemRawMaterialService, crud.NewBulk(db, crud.Bulk[model.MstItemRawMaterial]{
+		Name:  "Item Raw Material",
+		Input: func() dto.Input[model.MstItemRawMaterial] { return &dto.ItemRawMaterialRequest{} },
+	}))
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
 
 	itemRawMaterial.Get("/", itemRawMaterialHandler.GetItemRawMaterials)
+	itemRawMaterial.Get("/export", itemRawMaterialHandler.ExportItemRawMaterials)
 	itemRawMaterial.Get("/:id", itemRawMaterialHandler.GetItemRawMaterial)
 	itemRawMaterial.Post("/", itemRawMaterialHandler.CreateItemRawMaterial)
+	itemRawMaterial.Post("/bulk", itemRawMaterialHandler.BulkItemRawMaterials)
 	itemRawMaterial.Put("/:id", itemRawMaterialHandler.UpdateItemRawMaterial)
-	itemRawMaterial.Delete("/:id", itemRawMaterialHandler.DeleteItemRawMaterial)
+	itemRawMaterial.Delete("/:id", dependencyHandler.CheckDelete(&model.MstItemRawMaterial{}), itemRawMaterialHandler.DeleteItemRawMaterial)
+	itemRawMaterial.Put("/:id/restore", softDeleteHandler.Restore(&model.MstItemRawMaterial{}))
+	itemRawMaterial.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstItemRawMaterial{}))
 }
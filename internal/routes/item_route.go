@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,23 @@ import (
 )
 
 func ItemRoutes(api fiber.Router, db *gorm.DB) {
-	item := api.Group("master/item/generate")
+	item := api.Group("master/item/generate", middleware.VerifyPermission(db, "/general/master/item/generate"))
 
 	itemService := service.NewItemService(db)
-	itemHandler := handler.NewItemHandler(itemService)
+	itemHandler := handler.NewItemHandler(itemService, crud.NewBulk(db, crud.Bulk[model.MstItem]{
+		Name:  "Item",
+		Input: func() dto.Input[model.MstItem] { return &dto.ItemRequest{} },
+	}))
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
 
 	item.Get("/", itemHandler.GetItems)
+	item.Get("/export", itemHandler.ExportItems)
 	item.Get("/:id", itemHandler.GetItem)
 	item.Post("/", itemHandler.CreateItem)
+	item.Post("/bulk", itemHandler.BulkItems)
 	item.Put("/:id", itemHandler.UpdateItem)
-	item.Delete("/:id", itemHandler.DeleteItem)
+	item.Delete("/:id", dependencyHandler.CheckDelete(&model.MstItem{}), itemHandler.DeleteItem)
+	item.Put("/:id/restore", softDeleteHandler.Restore(&model.MstItem{}))
+	item.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstItem{}))
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,45 @@ import (
 )
 
 func ItemSourceRoutes(api fiber.Router, db *gorm.DB) {
-	itemSource := api.Group("master/item/source")
+	itemSource := api.Group("master/item/source", middleware.VerifyPermission(db, "/general/master/item/source"))
 
-	itemSourceService := service.NewItemSourceService(db)
-	itemSourceHandler := handler.NewItemSourceHandler(itemSourceService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	itemSourceHandler := handler.NewItemSourceHandler(db, crud.Resource[model.MstItemSource]{
+		Name:          "Item Source",
+		SearchColumns: []string{"mst_item_sources.code", "mst_item_sources.description"},
+		DefaultSort:   "mst_item_sources.code ASC",
+		Joins: []string{
+			"LEFT JOIN mst_item_categories ON mst_item_categories.id = mst_item_sources.id_item_category",
+		},
+		Preloads: []crud.Preload{
+			{Name: "ItemCategory", Columns: "id, code, description"},
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		Filters: []crud.Filter{
+			{Query: "categoryCode", Column: "mst_item_categories.code"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Item Category", Field: "ItemCategory.Code"},
+			{Header: "Code", Field: "Code"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Remarks", Field: "Remarks"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	itemSource.Get("/", itemSourceHandler.GetItemSources)
+	itemSource.Get("/export", itemSourceHandler.ExportItemSources)
 	itemSource.Get("/:id", itemSourceHandler.GetItemSource)
 	itemSource.Post("/", itemSourceHandler.CreateItemSource)
+	itemSource.Post("/bulk", itemSourceHandler.BulkItemSources)
 	itemSource.Put("/:id", itemSourceHandler.UpdateItemSource)
-	itemSource.Delete("/:id", itemSourceHandler.DeleteItemSource)
+	itemSource.Delete("/:id", dependencyHandler.CheckDelete(&model.MstItemSource{}), itemSourceHandler.DeleteItemSource)
+	itemSource.Put("/:id/restore", softDeleteHandler.Restore(&model.MstItemSource{}))
+	itemSource.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstItemSource{}))
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,45 @@ import (
 )
 
 func ItemSubCategoryRoutes(api fiber.Router, db *gorm.DB) {
-	itemSubCategory := api.Group("master/item/sub-category")
+	itemSubCategory := api.Group("master/item/sub-category", middleware.VerifyPermission(db, "/general/master/item/sub-category"))
 
-	itemSubCategoryService := service.NewItemSubCategoryService(db)
-	itemSubCategoryHandler := handler.NewItemSubCategoryHandler(itemSubCategoryService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	itemSubCategoryHandler := handler.NewItemSubCategoryHandler(db, crud.Resource[model.MstItemSubCategory]{
+		Name:          "Item Sub Category",
+		SearchColumns: []string{"mst_item_sub_categories.code", "mst_item_sub_categories.description"},
+		DefaultSort:   "mst_item_sub_categories.code ASC",
+		Joins: []string{
+			"LEFT JOIN mst_item_categories ON mst_item_categories.id = mst_item_sub_categories.id_item_category",
+		},
+		Preloads: []crud.Preload{
+			{Name: "ItemCategory", Columns: "id, code, description"},
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		Filters: []crud.Filter{
+			{Query: "categoryCode", Column: "mst_item_categories.code"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Item Category", Field: "ItemCategory.Code"},
+			{Header: "Code", Field: "Code"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Remarks", Field: "Remarks"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	itemSubCategory.Get("/", itemSubCategoryHandler.GetItemSubCategories)
+	itemSubCategory.Get("/export", itemSubCategoryHandler.ExportItemSubCategories)
 	itemSubCategory.Get("/:id", itemSubCategoryHandler.GetItemSubCategory)
 	itemSubCategory.Post("/", itemSubCategoryHandler.CreateItemSubCategory)
+	itemSubCategory.Post("/bulk", itemSubCategoryHandler.BulkItemSubCategories)
 	itemSubCategory.Put("/:id", itemSubCategoryHandler.UpdateItemSubCategory)
-	itemSubCategory.Delete("/:id", itemSubCategoryHandler.DeleteItemSubCategory)
+	itemSubCategory.Delete("/:id", dependencyHandler.CheckDelete(&model.MstItemSubCategory{}), itemSubCategoryHandler.DeleteItemSubCategory)
+	itemSubCategory.Put("/:id/restore", softDeleteHandler.Restore(&model.MstItemSubCategory{}))
+	itemSubCategory.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstItemSubCategory{}))
 }
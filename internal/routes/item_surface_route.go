@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,45 @@ import (
 )
 
 func ItemSurfaceRoutes(api fiber.Router, db *gorm.DB) {
-	itemSurface := api.Group("master/item/surface")
+	itemSurface := api.Group("master/item/surface", middleware.VerifyPermission(db, "/general/master/item/surface"))
 
-	itemSurfaceService := service.NewItemSurfaceService(db)
-	itemSurfaceHandler := handler.NewItemSurfaceHandler(itemSurfaceService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	itemSurfaceHandler := handler.NewItemSurfaceHandler(db, crud.Resource[model.MstItemSurface]{
+		Name:          "Item Surface",
+		SearchColumns: []string{"mst_item_surfaces.code", "mst_item_surfaces.description"},
+		DefaultSort:   "mst_item_surfaces.code ASC",
+		Joins: []string{
+			"LEFT JOIN mst_item_categories ON mst_item_categories.id = mst_item_surfaces.id_item_category",
+		},
+		Preloads: []crud.Preload{
+			{Name: "ItemCategory", Columns: "id, code, description"},
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		Filters: []crud.Filter{
+			{Query: "categoryCode", Column: "mst_item_categories.code"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Item Category", Field: "ItemCategory.Code"},
+			{Header: "Code", Field: "Code"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Remarks", Field: "Remarks"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	itemSurface.Get("/", itemSurfaceHandler.GetItemSurfaces)
+	itemSurface.Get("/export", itemSurfaceHandler.ExportItemSurfaces)
 	itemSurface.Get("/:id", itemSurfaceHandler.GetItemSurface)
 	itemSurface.Post("/", itemSurfaceHandler.CreateItemSurface)
+	itemSurface.Post("/bulk", itemSurfaceHandler.BulkItemSurfaces)
 	itemSurface.Put("/:id", itemSurfaceHandler.UpdateItemSurface)
-	itemSurface.Delete("/:id", itemSurfaceHandler.DeleteItemSurface)
+	itemSurface.Delete("/:id", dependencyHandler.CheckDelete(&model.MstItemSurface{}), itemSurfaceHandler.DeleteItemSurface)
+	itemSurface.Put("/:id/restore", softDeleteHandler.Restore(&model.MstItemSurface{}))
+	itemSurface.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstItemSurface{}))
 }
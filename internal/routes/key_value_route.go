@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,23 @@ import (
 )
 
 func KeyValueRoutes(api fiber.Router, db *gorm.DB) {
-	keyValue := api.Group("master/key-value")
+	keyValue := api.Group("master/key-value", middleware.VerifyPermission(db, "/admin/master/key-value"))
 
 	keyValueService := service.NewKeyValueService(db)
-	keyValueHandler := handler.NewKeyValueHandler(keyValueService)
+	keyValueHandler := handler.NewKeyValueHandler(keyValueService, crud.NewBulk(db, crud.Bulk[model.MstKeyValue]{
+		Name:  "Key Value",
+		Input: func() dto.Input[model.MstKeyValue] { return &dto.KeyValueRequest{} },
+	}))
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
 
 	keyValue.Get("/", keyValueHandler.GetKeyValues)
+	keyValue.Get("/export", keyValueHandler.ExportKeyValues)
 	keyValue.Get("/:id", keyValueHandler.GetKeyValue)
 	keyValue.Post("/", keyValueHandler.CreateKeyValue)
+	keyValue.Post("/bulk", keyValueHandler.BulkKeyValues)
 	keyValue.Put("/:id", keyValueHandler.UpdateKeyValue)
-	keyValue.Delete("/:id", keyValueHandler.DeleteKeyValue)
+	keyValue.Delete("/:id", dependencyHandler.CheckDelete(&model.MstKeyValue{}), keyValueHandler.DeleteKeyValue)
+	keyValue.Put("/:id/restore", softDeleteHandler.Restore(&model.MstKeyValue{}))
+	keyValue.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstKeyValue{}))
 }
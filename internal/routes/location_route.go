@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,41 @@ import (
 )
 
 func LocationRoutes(api fiber.Router, db *gorm.DB) {
-	location := api.Group("master/location")
+	location := api.Group("master/location", middleware.VerifyPermission(db, "/pid/master/location"))
 
-	locationService := service.NewLocationService(db)
-	locationHandler := handler.NewLocationHandler(locationService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	locationHandler := handler.NewLocationHandler(db, crud.Resource[model.MstLocation]{
+		Name:          "Location",
+		SearchColumns: []string{"location"},
+		DefaultSort:   "updated_at ASC",
+		Preloads: []crud.Preload{
+			{Name: "Warehouse", Columns: "id, code, description"},
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		Filters: []crud.Filter{
+			{Query: "id_warehouse", Column: "id_warehouse"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Warehouse", Field: "Warehouse.Code"},
+			{Header: "Location", Field: "Location"},
+			{Header: "Remarks", Field: "Remarks"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	location.Get("/", locationHandler.GetLocations)
+	location.Get("/export", locationHandler.ExportLocations)
 	location.Get("/:id", locationHandler.GetLocation)
 	location.Post("/", locationHandler.CreateLocation)
+	location.Post("/bulk", locationHandler.BulkLocations)
 	location.Put("/:id", locationHandler.UpdateLocation)
-	location.Delete("/:id", locationHandler.DeleteLocation)
+	location.Delete("/:id", dependencyHandler.CheckDelete(&model.MstLocation{}), locationHandler.DeleteLocation)
+	location.Put("/:id/restore", softDeleteHandler.Restore(&model.MstLocation{}))
+	location.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstLocation{}))
 }
@@ -2,6 +2,8 @@ package routes
 
 import (
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,18 +11,37 @@ import (
 )
 
 func MachineRoutes(api fiber.Router, db *gorm.DB) {
-	machine := api.Group("master/machine")
-
 	machineService := service.NewMachineService(db)
-	machineHandler := handler.NewMachineHandler(machineService)
+	approvalEngineService := service.NewApprovalEngineService(db)
+	machineHandler := handler.NewMachineHandler(machineService, approvalEngineService)
+	machineTimelineHandler := handler.NewMachineTimelineHandler(service.NewMachineTimelineService(db), machineService)
+	maintenanceHandler := handler.NewMaintenanceHandler(service.NewMaintenanceService(db), machineService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	// Approval actions are authorized by the approval engine against the
+	// approval users, so they are registered before the permission middleware.
+	machineApproval := api.Group("master/machine")
+	machineApproval.Get("/:id/approval", machineHandler.GetMachineApproval)
+	machineApproval.Post("/:id/approval/:action", machineHandler.ActionMachineApproval)
+
+	machine := api.Group("master/machine", middleware.VerifyPermission(db, "/mnt/master/machine"))
 
 	machine.Get("/", machineHandler.GetMachines)
+	machine.Get("/export", machineHandler.ExportMachines)
+	machine.Get("/building/:id/metrics", machineTimelineHandler.GetBuildingMachineMetrics)
 	machine.Get("/:id", machineHandler.GetMachine)
 	machine.Post("/", machineHandler.CreateMachine)
 	machine.Put("/:id", machineHandler.UpdateMachine)
-	machine.Delete("/:id", machineHandler.DeleteMachine)
+	machine.Delete("/:id", dependencyHandler.CheckDelete(&model.MstMachine{}), machineHandler.DeleteMachine)
+	machine.Put("/:id/restore", softDeleteHandler.Restore(&model.MstMachine{}))
+	machine.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstMachine{}))
 	machine.Put("/:id/revision", machineHandler.RevisionMachine)
 	machine.Get("/:id/detail", machineHandler.GetMachineDetails)
+	machine.Get("/:id/diff", machineHandler.DiffMachineRevisions)
 	machine.Get("/:id/status", machineHandler.GetMachineStatus)
 	machine.Put("/:id/status", machineHandler.CreateStatusMachine)
+	machine.Get("/:id/timeline", machineTimelineHandler.GetMachineTimeline)
+	machine.Get("/:id/metrics", machineTimelineHandler.GetMachineMetrics)
+	machine.Get("/:id/maintenance", maintenanceHandler.GetMachineMaintenance)
 }
@@ -0,0 +1,147 @@
+package routes
+
+import (
+	"insist-backend-golang/internal/cron"
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
+	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
+	"insist-backend-golang/internal/service"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func MaintenancePlanRoutes(api fiber.Router, db *gorm.DB) {
+	maintenancePlan := api.Group("master/maintenance-plan", middleware.VerifyPermission(db, "/mnt/master/maintenance-plan"))
+
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	maintenancePlanHandler := handler.NewMaintenancePlanHandler(db, crud.Resource[model.MstMaintenancePlan]{
+		Name:          "Maintenance Plan",
+		SearchColumns: []string{"code", "name", "description"},
+		DefaultSort:   "updated_at ASC",
+		Preloads: []crud.Preload{
+			{Name: "Tasks", Columns: "id, id_maintenance_plan, seq, description, remarks"},
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		Filters: []crud.Filter{
+			{Query: "interval_type", Column: "interval_type"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Code", Field: "Code"},
+			{Header: "Name", Field: "Name"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Interval Type", Field: "IntervalType"},
+			{Header: "Interval", Field: "IntervalValue"},
+			{Header: "Lead Days", Field: "LeadDays"},
+			{Header: "Remarks", Field: "Remarks"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
+
+	maintenancePlan.Get("/", maintenancePlanHandler.GetMaintenancePlans)
+	maintenancePlan.Get("/export", maintenancePlanHandler.ExportMaintenancePlans)
+	maintenancePlan.Get("/:id", maintenancePlanHandler.GetMaintenancePlan)
+	maintenancePlan.Post("/", maintenancePlanHandler.CreateMaintenancePlan)
+	maintenancePlan.Post("/bulk", maintenancePlanHandler.BulkMaintenancePlans)
+	maintenancePlan.Put("/:id", maintenancePlanHandler.UpdateMaintenancePlan)
+	maintenancePlan.Delete("/:id", dependencyHandler.CheckDelete(&model.MstMaintenancePlan{}), maintenancePlanHandler.DeleteMaintenancePlan)
+	maintenancePlan.Put("/:id/restore", softDeleteHandler.Restore(&model.MstMaintenancePlan{}))
+	maintenancePlan.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstMaintenancePlan{}))
+
+	maintenancePlanTask := api.Group("master/maintenance-plan-task", middleware.VerifyPermission(db, "/mnt/master/maintenance-plan"))
+
+	maintenancePlanTaskHandler := handler.NewMaintenancePlanTaskHandler(db, crud.Resource[model.MstMaintenancePlanTask]{
+		Name:          "Maintenance Plan Task",
+		SearchColumns: []string{"description"},
+		DefaultSort:   "seq ASC",
+		Preloads: []crud.Preload{
+			{Name: "MaintenancePlan", Columns: "id, code, name"},
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		Filters: []crud.Filter{
+			{Query: "id_maintenance_plan", Column: "id_maintenance_plan"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Maintenance Plan", Field: "MaintenancePlan.Code"},
+			{Header: "Seq", Field: "Seq"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Remarks", Field: "Remarks"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
+
+	maintenancePlanTask.Get("/", maintenancePlanTaskHandler.GetMaintenancePlanTasks)
+	maintenancePlanTask.Get("/export", maintenancePlanTaskHandler.ExportMaintenancePlanTasks)
+	maintenancePlanTask.Get("/:id", maintenancePlanTaskHandler.GetMaintenancePlanTask)
+	maintenancePlanTask.Post("/", maintenancePlanTaskHandler.CreateMaintenancePlanTask)
+	maintenancePlanTask.Post("/bulk", maintenancePlanTaskHandler.BulkMaintenancePlanTasks)
+	maintenancePlanTask.Put("/:id", maintenancePlanTaskHandler.UpdateMaintenancePlanTask)
+	maintenancePlanTask.Delete("/:id", dependencyHandler.CheckDelete(&model.MstMaintenancePlanTask{}), maintenancePlanTaskHandler.DeleteMaintenancePlanTask)
+	maintenancePlanTask.Put("/:id/restore", softDeleteHandler.Restore(&model.MstMaintenancePlanTask{}))
+	maintenancePlanTask.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstMaintenancePlanTask{}))
+
+	machineMaintenancePlan := api.Group("master/machine-maintenance-plan", middleware.VerifyPermission(db, "/mnt/master/maintenance-plan"))
+
+	machineMaintenancePlanHandler := handler.NewMachineMaintenancePlanHandler(db, crud.Resource[model.MstMachineMaintenancePlan]{
+		Name:        "Machine Maintenance Plan",
+		DefaultSort: "updated_at ASC",
+		Preloads: []crud.Preload{
+			{Name: "MaintenancePlan", Columns: "id, code, name, interval_type, interval_value, lead_days"},
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		Filters: []crud.Filter{
+			{Query: "id_machine", Column: "id_machine"},
+			{Query: "id_maintenance_plan", Column: "id_maintenance_plan"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Machine", Field: "IDMachine"},
+			{Header: "Maintenance Plan", Field: "MaintenancePlan.Code"},
+			{Header: "Start Date", Field: "StartDate"},
+			{Header: "Last Due At", Field: "LastDueAt"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
+
+	machineMaintenancePlan.Get("/", machineMaintenancePlanHandler.GetMachineMaintenancePlans)
+	machineMaintenancePlan.Get("/export", machineMaintenancePlanHandler.ExportMachineMaintenancePlans)
+	machineMaintenancePlan.Get("/:id", machineMaintenancePlanHandler.GetMachineMaintenancePlan)
+	machineMaintenancePlan.Post("/", machineMaintenancePlanHandler.CreateMachineMaintenancePlan)
+	machineMaintenancePlan.Post("/bulk", machineMaintenancePlanHandler.BulkMachineMaintenancePlans)
+	machineMaintenancePlan.Put("/:id", machineMaintenancePlanHandler.UpdateMachineMaintenancePlan)
+	machineMaintenancePlan.Delete("/:id", dependencyHandler.CheckDelete(&model.MstMachineMaintenancePlan{}), machineMaintenancePlanHandler.DeleteMachineMaintenancePlan)
+	machineMaintenancePlan.Put("/:id/restore", softDeleteHandler.Restore(&model.MstMachineMaintenancePlan{}))
+	machineMaintenancePlan.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstMachineMaintenancePlan{}))
+
+	// Running hours add up through the day, so due plans are looked for
+	// every hour.
+	maintenanceService := service.NewMaintenanceService(db)
+	cron.SetupCron(func() {
+		generated, err := maintenanceService.GenerateWorkOrders(time.Now())
+		if err != nil {
+			log.Println("Error generating maintenance work orders:", err)
+			return
+		}
+
+		if generated > 0 {
+			log.Printf("Generated %d maintenance work orders", generated)
+		}
+	}, "5 * * * *")
+}
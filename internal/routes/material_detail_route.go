@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,23 @@ import (
 )
 
 func MaterialDetailRoutes(api fiber.Router, db *gorm.DB) {
-	materialDetail := api.Group("master/material-detail")
+	materialDetail := api.Group("master/material-detail", middleware.VerifyPermission(db, "/egd/master/material-detail"))
 
 	materialDetailService := service.NewMaterialDetailService(db)
-	materialDetailHandler := handler.NewMaterialDetailHandler(materialDetailService)
+	materialDetailHandler := handler.NewMaterialDetailHandler(materialDetailService, crud.NewBulk(db, crud.Bulk[model.MstMaterialDetail]{
+		Name:  "Material Detail",
+		Input: func() dto.Input[model.MstMaterialDetail] { return &dto.MaterialDetailRequest{} },
+	}))
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
 
 	materialDetail.Get("/", materialDetailHandler.GetMaterialDetails)
+	materialDetail.Get("/export", materialDetailHandler.ExportMaterialDetails)
 	materialDetail.Get("/:id", materialDetailHandler.GetMaterialDetail)
 	materialDetail.Post("/", materialDetailHandler.CreateMaterialDetail)
+	materialDetail.Post("/bulk", materialDetailHandler.BulkMaterialDetails)
 	materialDetail.Put("/:id", materialDetailHandler.UpdateMaterialDetail)
-	materialDetail.Delete("/:id", materialDetailHandler.DeleteMaterialDetail)
+	materialDetail.Delete("/:id", dependencyHandler.CheckDelete(&model.MstMaterialDetail{}), materialDetailHandler.DeleteMaterialDetail)
+	materialDetail.Put("/:id/restore", softDeleteHandler.Restore(&model.MstMaterialDetail{}))
+	materialDetail.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstMaterialDetail{}))
 }
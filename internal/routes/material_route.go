@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,23 @@ import (
 )
 
 func MaterialRoutes(api fiber.Router, db *gorm.DB) {
-	material := api.Group("master/material")
+	material := api.Group("master/material", middleware.VerifyPermission(db, "/egd/master/material"))
 
 	materialService := service.NewMaterialService(db)
-	materialHandler := handler.NewMaterialHandler(materialService)
+	materialHandler := handler.NewMaterialHandler(materialService, crud.NewBulk(db, crud.Bulk[model.MstMaterial]{
+		Name:  "Material",
+		Input: func() dto.Input[model.MstMaterial] { return &dto.MaterialRequest{} },
+	}))
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
 
 	material.Get("/", materialHandler.GetMaterials)
+	material.Get("/export", materialHandler.ExportMaterials)
 	material.Get("/:id", materialHandler.GetMaterial)
 	material.Post("/", materialHandler.CreateMaterial)
+	material.Post("/bulk", materialHandler.BulkMaterials)
 	material.Put("/:id", materialHandler.UpdateMaterial)
-	material.Delete("/:id", materialHandler.DeleteMaterial)
+	material.Delete("/:id", dependencyHandler.CheckDelete(&model.MstMaterial{}), materialHandler.DeleteMaterial)
+	material.Put("/:id/restore", softDeleteHandler.Restore(&model.MstMaterial{}))
+	material.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstMaterial{}))
 }
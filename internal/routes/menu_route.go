@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,19 +13,29 @@ import (
 )
 
 func MenuRoutes(api fiber.Router, db *gorm.DB) {
-	menu := api.Group("master/menu")
+	menu := api.Group("master/menu", middleware.VerifyPermission(db, "/admin/master/menu"))
 
 	menuService := service.NewMenuService(db)
-	menuHandler := handler.NewMenuHandler(menuService)
+	menuHandler := handler.NewMenuHandler(menuService, crud.NewBulk(db, crud.Bulk[model.MstMenu]{
+		Name:  "Menu",
+		Input: func() dto.Input[model.MstMenu] { return &dto.MenuRequest{} },
+		After: func() { service.InvalidatePermissionCache() },
+	}))
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
 
 	menu.Get("/", menuHandler.GetMenus)
+	menu.Get("/export", menuHandler.ExportMenus)
 	menu.Get("/user", menuHandler.GetMenusByUser)
 	menu.Get("/:id", menuHandler.GetMenu)
 	menu.Post("/", menuHandler.CreateMenu)
+	menu.Post("/bulk", menuHandler.BulkMenus)
 	menu.Put("/:id", menuHandler.UpdateMenu)
-	menu.Delete("/:id", menuHandler.DeleteMenu)
+	menu.Delete("/:id", dependencyHandler.CheckDelete(&model.MstMenu{}), menuHandler.DeleteMenu)
+	menu.Put("/:id/restore", softDeleteHandler.Restore(&model.MstMenu{}))
+	menu.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstMenu{}))
 
-	treeMenu := api.Group("master/tree-menu")
+	treeMenu := api.Group("master/tree-menu", middleware.VerifyPermission(db, "/admin/master/menu"))
 	treeMenu.Get("/", menuHandler.GetMenuTree)
 	treeMenu.Get("/user", menuHandler.GetMenuTreeByUser)
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,37 @@ import (
 )
 
 func ProcessRoutes(api fiber.Router, db *gorm.DB) {
-	process := api.Group("master/process")
+	process := api.Group("master/process", middleware.VerifyPermission(db, "/egd/master/process"))
 
-	processService := service.NewProcessService(db)
-	processHandler := handler.NewProcessHandler(processService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	processHandler := handler.NewProcessHandler(db, crud.Resource[model.MstProcess]{
+		Name:          "Process",
+		SearchColumns: []string{"code", "description"},
+		DefaultSort:   "updated_at ASC",
+		Preloads: []crud.Preload{
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Code", Field: "Code"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Remarks", Field: "Remarks"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	process.Get("/", processHandler.GetProcesses)
+	process.Get("/export", processHandler.ExportProcesses)
 	process.Get("/:id", processHandler.GetProcess)
 	process.Post("/", processHandler.CreateProcess)
+	process.Post("/bulk", processHandler.BulkProcesses)
 	process.Put("/:id", processHandler.UpdateProcess)
-	process.Delete("/:id", processHandler.DeleteProcess)
+	process.Delete("/:id", dependencyHandler.CheckDelete(&model.MstProcess{}), processHandler.DeleteProcess)
+	process.Put("/:id/restore", softDeleteHandler.Restore(&model.MstProcess{}))
+	process.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstProcess{}))
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,23 @@ import (
 )
 
 func ReasonRoutes(api fiber.Router, db *gorm.DB) {
-	reason := api.Group("master/reason")
+	reason := api.Group("master/reason", middleware.VerifyPermission(db, "/admin/master/reason"))
 
 	reasonService := service.NewReasonService(db)
-	reasonHandler := handler.NewReasonHandler(reasonService)
+	reasonHandler := handler.NewReasonHandler(reasonService, crud.NewBulk(db, crud.Bulk[model.MstReason]{
+		Name:  "Reason",
+		Input: func() dto.Input[model.MstReason] { return &dto.ReasonRequest{} },
+	}))
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
 
 	reason.Get("/", reasonHandler.GetReasons)
+	reason.Get("/export", reasonHandler.ExportReasons)
 	reason.Get("/:id", reasonHandler.GetReason)
 	reason.Post("/", reasonHandler.CreateReason)
+	reason.Post("/bulk", reasonHandler.BulkReasons)
 	reason.Put("/:id", reasonHandler.UpdateReason)
-	reason.Delete("/:id", reasonHandler.DeleteReason)
+	reason.Delete("/:id", dependencyHandler.CheckDelete(&model.MstReason{}), reasonHandler.DeleteReason)
+	reason.Put("/:id/restore", softDeleteHandler.Restore(&model.MstReason{}))
+	reason.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstReason{}))
 }
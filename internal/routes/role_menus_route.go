@@ -2,6 +2,7 @@ package routes
 
 import (
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,7 +10,7 @@ import (
 )
 
 func RoleMenuRoutes(api fiber.Router, db *gorm.DB) {
-	roleMenu := api.Group("master/role-menu")
+	roleMenu := api.Group("master/role-menu", middleware.VerifyPermission(db, "/admin/master/role-menu"))
 
 	roleMenuService := service.NewRoleMenuService(db)
 	roleMenuHandler := handler.NewRoleMenuHandler(roleMenuService)
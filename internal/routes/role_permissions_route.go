@@ -2,6 +2,7 @@ package routes
 
 import (
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,7 +10,7 @@ import (
 )
 
 func RolePermissionRoutes(api fiber.Router, db *gorm.DB) {
-	rolePermission := api.Group("role-permission")
+	rolePermission := api.Group("role-permission", middleware.VerifyPermission(db, "/admin/role-permission"))
 
 	rolePermissionService := service.NewRolePermissionService(db)
 	rolePermissionHandler := handler.NewRolePermissionHandler(rolePermissionService)
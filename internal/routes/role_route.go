@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,24 @@ import (
 )
 
 func RoleRoutes(api fiber.Router, db *gorm.DB) {
-	role := api.Group("master/role")
+	role := api.Group("master/role", middleware.VerifyPermission(db, "/admin/master/role"))
 
 	roleService := service.NewRoleService(db)
-	roleHandler := handler.NewRoleHandler(roleService)
+	roleHandler := handler.NewRoleHandler(roleService, crud.NewBulk(db, crud.Bulk[model.MstRole]{
+		Name:  "Role",
+		Input: func() dto.Input[model.MstRole] { return &dto.RoleRequest{} },
+		After: func() { service.InvalidatePermissionCache() },
+	}))
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
 
 	role.Get("/", roleHandler.GetRoles)
+	role.Get("/export", roleHandler.ExportRoles)
 	role.Get("/:id", roleHandler.GetRole)
 	role.Post("/", roleHandler.CreateRole)
+	role.Post("/bulk", roleHandler.BulkRoles)
 	role.Put("/:id", roleHandler.UpdateRole)
-	role.Delete("/:id", roleHandler.DeleteRole)
+	role.Delete("/:id", dependencyHandler.CheckDelete(&model.MstRole{}), roleHandler.DeleteRole)
+	role.Put("/:id/restore", softDeleteHandler.Restore(&model.MstRole{}))
+	role.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstRole{}))
 }
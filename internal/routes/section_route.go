@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,42 @@ import (
 )
 
 func SectionRoutes(api fiber.Router, db *gorm.DB) {
-	section := api.Group("master/section")
+	section := api.Group("master/section", middleware.VerifyPermission(db, "/prd/master/section"))
 
-	sectionService := service.NewSectionService(db)
-	sectionHandler := handler.NewSectionHandler(sectionService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	sectionHandler := handler.NewSectionHandler(db, crud.Resource[model.MstSection]{
+		Name:          "Section",
+		SearchColumns: []string{"code", "description"},
+		DefaultSort:   "updated_at ASC",
+		Preloads: []crud.Preload{
+			{Name: "FCS", Columns: "id, code, description"},
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		Filters: []crud.Filter{
+			{Query: "id_fcs", Column: "id_fcs"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "FCS", Field: "FCS.Code"},
+			{Header: "Code", Field: "Code"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Remarks", Field: "Remarks"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	section.Get("/", sectionHandler.GetSections)
+	section.Get("/export", sectionHandler.ExportSections)
 	section.Get("/:id", sectionHandler.GetSection)
 	section.Post("/", sectionHandler.CreateSection)
+	section.Post("/bulk", sectionHandler.BulkSections)
 	section.Put("/:id", sectionHandler.UpdateSection)
-	section.Delete("/:id", sectionHandler.DeleteSection)
+	section.Delete("/:id", dependencyHandler.CheckDelete(&model.MstSection{}), sectionHandler.DeleteSection)
+	section.Put("/:id/restore", softDeleteHandler.Restore(&model.MstSection{}))
+	section.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstSection{}))
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,46 @@ import (
 )
 
 func SubSectionRoutes(api fiber.Router, db *gorm.DB) {
-	subSection := api.Group("master/sub-section")
+	subSection := api.Group("master/sub-section", middleware.VerifyPermission(db, "/prd/master/sub-section"))
 
-	subSectionService := service.NewSubSectionService(db)
-	subSectionHandler := handler.NewSubSectionHandler(subSectionService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	subSectionHandler := handler.NewSubSectionHandler(db, crud.Resource[model.MstSubSection]{
+		Name:          "SubSection",
+		SearchColumns: []string{"code", "description"},
+		DefaultSort:   "updated_at ASC",
+		Preloads: []crud.Preload{
+			{Name: "Section", Columns: "id, id_fcs, code, description"},
+			{Name: "Section.FCS", Columns: "id, code, description"},
+			{Name: "Building", Columns: "id, code, description"},
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		Filters: []crud.Filter{
+			{Query: "id_section", Column: "id_section"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "FCS", Field: "Section.FCS.Code"},
+			{Header: "Section", Field: "Section.Code"},
+			{Header: "Building", Field: "Building.Code"},
+			{Header: "Code", Field: "Code"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Remarks", Field: "Remarks"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	subSection.Get("/", subSectionHandler.GetSubSections)
+	subSection.Get("/export", subSectionHandler.ExportSubSections)
 	subSection.Get("/:id", subSectionHandler.GetSubSection)
 	subSection.Post("/", subSectionHandler.CreateSubSection)
+	subSection.Post("/bulk", subSectionHandler.BulkSubSections)
 	subSection.Put("/:id", subSectionHandler.UpdateSubSection)
-	subSection.Delete("/:id", subSectionHandler.DeleteSubSection)
+	subSection.Delete("/:id", dependencyHandler.CheckDelete(&model.MstSubSection{}), subSectionHandler.DeleteSubSection)
+	subSection.Put("/:id/restore", softDeleteHandler.Restore(&model.MstSubSection{}))
+	subSection.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstSubSection{}))
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,57 @@ import (
 )
 
 func TaxCodeRoutes(api fiber.Router, db *gorm.DB) {
-	taxCode := api.Group("master/tax-code")
+	taxCode := api.Group("master/tax-code", middleware.VerifyPermission(db, "/acf/master/tax-code"))
 
-	taxCodeService := service.NewTaxCodeService(db)
-	taxCodeHandler := handler.NewTaxCodeHandler(taxCodeService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	taxCodeHandler := handler.NewTaxCodeHandler(db, crud.Resource[model.MstTaxCode]{
+		Name:          "Tax Code",
+		SearchColumns: []string{"name", "description"},
+		DefaultSort:   "updated_at ASC",
+		Preloads: []crud.Preload{
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+			{Name: "AccountAR"},
+			{Name: "AccountARProc"},
+			{Name: "AccountAP"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Name", Field: "Name"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Type", Field: "Type"},
+			{Header: "Rate", Field: "Rate"},
+			{Header: "Include Price", Field: "IncludePrice"},
+			{Header: "Include Discount", Field: "IncludeDiscount"},
+			{Header: "Include Restock Fee", Field: "IncludeRestockFee"},
+			{Header: "Deductible", Field: "Deductible"},
+			{Header: "Include Freight", Field: "IncludeFreight"},
+			{Header: "Include Duty", Field: "IncludeDuty"},
+			{Header: "Include Brokerage", Field: "IncludeBrokerage"},
+			{Header: "Include Insurance", Field: "IncludeInsurance"},
+			{Header: "Include Local Freight", Field: "IncludeLocalFreight"},
+			{Header: "Include Misc", Field: "IncludeMisc"},
+			{Header: "Include Surcharge", Field: "IncludeSurcharge"},
+			{Header: "Assess On Return", Field: "AssessOnReturn"},
+			{Header: "Include Tax On Previous System", Field: "IncludeTaxOnPrevSystem"},
+			{Header: "AR Account", Field: "AccountAR.Account"},
+			{Header: "AR Process Account", Field: "AccountARProc.Account"},
+			{Header: "AP Account", Field: "AccountAP.Account"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	taxCode.Get("/", taxCodeHandler.GetTaxCodes)
+	taxCode.Get("/export", taxCodeHandler.ExportTaxCodes)
 	taxCode.Get("/:id", taxCodeHandler.GetTaxCode)
 	taxCode.Post("/", taxCodeHandler.CreateTaxCode)
+	taxCode.Post("/bulk", taxCodeHandler.BulkTaxCodes)
 	taxCode.Put("/:id", taxCodeHandler.UpdateTaxCode)
-	taxCode.Delete("/:id", taxCodeHandler.DeleteTaxCode)
+	taxCode.Delete("/:id", dependencyHandler.CheckDelete(&model.MstTaxCode{}), taxCodeHandler.DeleteTaxCode)
+	taxCode.Put("/:id/restore", softDeleteHandler.Restore(&model.MstTaxCode{}))
+	taxCode.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstTaxCode{}))
 }
@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,37 @@ import (
 )
 
 func UoMRoutes(api fiber.Router, db *gorm.DB) {
-	uom := api.Group("master/uom")
+	uom := api.Group("master/uom", middleware.VerifyPermission(db, "/egd/master/uom"))
 
-	uomService := service.NewUoMService(db)
-	uomHandler := handler.NewUoMHandler(uomService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	uomHandler := handler.NewUoMHandler(db, crud.Resource[model.MstUoms]{
+		Name:          "UoM",
+		SearchColumns: []string{"code", "description"},
+		DefaultSort:   "updated_at ASC",
+		Preloads: []crud.Preload{
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Code", Field: "Code"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Remarks", Field: "Remarks"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	uom.Get("/", uomHandler.GetUoMs)
+	uom.Get("/export", uomHandler.ExportUoMs)
 	uom.Get("/:id", uomHandler.GetUoM)
 	uom.Post("/", uomHandler.CreateUoM)
+	uom.Post("/bulk", uomHandler.BulkUoMs)
 	uom.Put("/:id", uomHandler.UpdateUoM)
-	uom.Delete("/:id", uomHandler.DeleteUoM)
+	uom.Delete("/:id", dependencyHandler.CheckDelete(&model.MstUoms{}), uomHandler.DeleteUoM)
+	uom.Put("/:id/restore", softDeleteHandler.Restore(&model.MstUoms{}))
+	uom.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstUoms{}))
 }
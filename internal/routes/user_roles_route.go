@@ -2,6 +2,7 @@ package routes
 
 import (
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,7 +10,7 @@ import (
 )
 
 func UserRoleRoutes(api fiber.Router, db *gorm.DB) {
-	userRole := api.Group("master/user-role")
+	userRole := api.Group("master/user-role", middleware.VerifyPermission(db, "/admin/master/user-role"))
 
 	userRoleService := service.NewUserRoleService(db)
 	userRoleHandler := handler.NewUserRoleHandler(userRoleService)
@@ -2,6 +2,8 @@ package routes
 
 import (
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,15 +11,27 @@ import (
 )
 
 func UserRoutes(api fiber.Router, db *gorm.DB) {
-	user := api.Group("master/users")
+	user := api.Group("master/users", middleware.VerifyPermission(db, "/admin/master/users"))
 
 	userService := service.NewUserService(db)
-	userHandler := handler.NewUserHandler(userService)
+	userSessionService := service.NewUserSessionService(db)
+	loginThrottleService := service.NewLoginThrottleService(db)
+	twoFactorService := service.NewTwoFactorService(db)
+	userHandler := handler.NewUserHandler(userService, userSessionService, loginThrottleService, twoFactorService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
 
 	user.Get("/", userHandler.GetUsers)
+	user.Get("/export", userHandler.ExportUsers)
 	user.Get("/:id", userHandler.GetUser)
 	user.Post("/", userHandler.CreateUser)
 	user.Put("/:id", userHandler.UpdateUser)
-	user.Delete("/:id", userHandler.DeleteUser)
+	user.Delete("/:id", dependencyHandler.CheckDelete(&model.MstUser{}), userHandler.DeleteUser)
+	user.Put("/:id/restore", softDeleteHandler.Restore(&model.MstUser{}))
+	user.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstUser{}))
 	user.Put("/:id/change-password", userHandler.ChangePassword)
+	user.Get("/:id/sessions", userHandler.GetUserSessions)
+	user.Delete("/:id/sessions", userHandler.RevokeUserSessions)
+	user.Put("/:id/unlock", userHandler.UnlockUser)
+	user.Put("/:id/two-fa/reset", userHandler.ResetTwoFactorAuth)
 }
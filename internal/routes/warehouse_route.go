@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"insist-backend-golang/internal/crud"
+	"insist-backend-golang/internal/export"
 	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/model"
 	"insist-backend-golang/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,14 +13,42 @@ import (
 )
 
 func WarehouseRoutes(api fiber.Router, db *gorm.DB) {
-	warehouse := api.Group("master/warehouse")
+	warehouse := api.Group("master/warehouse", middleware.VerifyPermission(db, "/pid/master/warehouse"))
 
-	warehouseService := service.NewWarehouseService(db)
-	warehouseHandler := handler.NewWarehouseHandler(warehouseService)
+	softDeleteHandler := handler.NewSoftDeleteHandler(service.NewSoftDeleteService(db))
+	dependencyHandler := handler.NewDependencyHandler(service.NewDependencyService(db))
+
+	warehouseHandler := handler.NewWarehouseHandler(db, crud.Resource[model.MstWarehouse]{
+		Name:          "Warehouse",
+		SearchColumns: []string{"code", "description"},
+		DefaultSort:   "updated_at ASC",
+		Preloads: []crud.Preload{
+			{Name: "Building", Columns: "id, code, description"},
+			{Name: "CreatedBy", Columns: "id, name"},
+			{Name: "UpdatedBy", Columns: "id, name"},
+		},
+		Filters: []crud.Filter{
+			{Query: "id_building", Column: "id_building"},
+		},
+		ExportColumns: []export.Column{
+			{Header: "Building", Field: "Building.Code"},
+			{Header: "Code", Field: "Code"},
+			{Header: "Description", Field: "Description"},
+			{Header: "Remarks", Field: "Remarks"},
+			{Header: "Created By", Field: "CreatedBy.Name"},
+			{Header: "Created At", Field: "CreatedAt"},
+			{Header: "Updated By", Field: "UpdatedBy.Name"},
+			{Header: "Updated At", Field: "UpdatedAt"},
+		},
+	})
 
 	warehouse.Get("/", warehouseHandler.GetWarehouses)
+	warehouse.Get("/export", warehouseHandler.ExportWarehouses)
 	warehouse.Get("/:id", warehouseHandler.GetWarehouse)
 	warehouse.Post("/", warehouseHandler.CreateWarehouse)
+	warehouse.Post("/bulk", warehouseHandler.BulkWarehouses)
 	warehouse.Put("/:id", warehouseHandler.UpdateWarehouse)
-	warehouse.Delete("/:id", warehouseHandler.DeleteWarehouse)
+	warehouse.Delete("/:id", dependencyHandler.CheckDelete(&model.MstWarehouse{}), warehouseHandler.DeleteWarehouse)
+	warehouse.Put("/:id/restore", softDeleteHandler.Restore(&model.MstWarehouse{}))
+	warehouse.Get("/:id/where-used", dependencyHandler.WhereUsed(&model.MstWarehouse{}))
 }
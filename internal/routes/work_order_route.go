@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"insist-backend-golang/internal/handler"
+	"insist-backend-golang/internal/middleware"
+	"insist-backend-golang/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func WorkOrderRoutes(api fiber.Router, db *gorm.DB) {
+	workOrder := api.Group("maintenance/work-order", middleware.VerifyPermission(db, "/mnt/maintenance/work-order"))
+
+	workOrderService := service.NewWorkOrderService(db)
+	workOrderHandler := handler.NewWorkOrderHandler(workOrderService)
+
+	workOrder.Get("/", workOrderHandler.GetWorkOrders)
+	workOrder.Get("/:id", workOrderHandler.GetWorkOrder)
+	workOrder.Post("/", workOrderHandler.CreateWorkOrder)
+	workOrder.Put("/:id", workOrderHandler.UpdateWorkOrder)
+	workOrder.Put("/:id/assign", workOrderHandler.AssignWorkOrder)
+	workOrder.Put("/:id/start", workOrderHandler.StartWorkOrder)
+	workOrder.Put("/:id/complete", workOrderHandler.CompleteWorkOrder)
+	workOrder.Put("/:id/close", workOrderHandler.CloseWorkOrder)
+	workOrder.Put("/:id/task/:taskId", workOrderHandler.UpdateWorkOrderTask)
+	workOrder.Post("/:id/part", workOrderHandler.CreateWorkOrderPart)
+	workOrder.Delete("/:id/part/:partId", workOrderHandler.DeleteWorkOrderPart)
+	workOrder.Post("/:id/labor", workOrderHandler.CreateWorkOrderLabor)
+	workOrder.Delete("/:id/labor/:laborId", workOrderHandler.DeleteWorkOrderLabor)
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"insist-backend-golang/internal/model"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	activityLogBufferSize    = 1024
+	activityLogBatchSize     = 100
+	activityLogFlushInterval = 2 * time.Second
+)
+
+// ActivityLogWriter buffers activity logs in memory and writes them in
+// batches from a background goroutine, so logging never slows a request.
+type ActivityLogWriter struct {
+	db   *gorm.DB
+	logs chan model.ActivityLog
+}
+
+func NewActivityLogWriter(db *gorm.DB) *ActivityLogWriter {
+	w := &ActivityLogWriter{
+		db:   db,
+		logs: make(chan model.ActivityLog, activityLogBufferSize),
+	}
+
+	go w.run()
+
+	return w
+}
+
+// Write queues a log and drops it when the buffer is full.
+func (w *ActivityLogWriter) Write(activityLog model.ActivityLog) {
+	select {
+	case w.logs <- activityLog:
+	default:
+		log.Println("Activity log buffer is full, dropping log for", activityLog.Path)
+	}
+}
+
+func (w *ActivityLogWriter) run() {
+	ticker := time.NewTicker(activityLogFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]model.ActivityLog, 0, activityLogBatchSize)
+	for {
+		select {
+		case activityLog := <-w.logs:
+			batch = append(batch, activityLog)
+			if len(batch) >= activityLogBatchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+func (w *ActivityLogWriter) flush(batch []model.ActivityLog) {
+	userIDs := make([]uint, 0, len(batch))
+	for _, activityLog := range batch {
+		userIDs = append(userIDs, activityLog.IDUser)
+	}
+
+	var users []model.MstUser
+	if err := w.db.Select("id, username").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		log.Println("Error loading users for activity logs:", err)
+	}
+
+	usernames := map[uint]string{}
+	for _, user := range users {
+		usernames[user.ID] = user.Username
+	}
+
+	logs := make([]model.ActivityLog, len(batch))
+	copy(logs, batch)
+	for i := range logs {
+		if logs[i].Username == "" {
+			logs[i].Username = usernames[logs[i].IDUser]
+		}
+	}
+
+	if err := w.db.Create(&logs).Error; err != nil {
+		log.Println("Error writing activity logs:", err)
+	}
+}
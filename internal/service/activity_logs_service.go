@@ -1,13 +1,24 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 	"time"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
+// Sources tell who wrote an activity log. Only request logs carry method,
+// path, status and latency; client logs are whatever the frontend reports.
+const (
+	ActivityLogSourceRequest = "request"
+	ActivityLogSourceAuth    = "auth"
+	ActivityLogSourceClient  = "client"
+)
+
+// ActivityLogFilterFields are the fields the list accepts in filter[field][op].
+var ActivityLogFilterFields = filter.Columns(&model.ActivityLog{})
+
 type ActivityLogService struct {
 	db *gorm.DB
 }
@@ -27,38 +38,12 @@ func (s *ActivityLogService) GetByID(logID uint) (*model.ActivityLog, error) {
 	return &log, nil
 }
 
-func (s *ActivityLogService) GetTotal(search string, action string, isSuccess string, arrayDate []string) (int64, error) {
+func (s *ActivityLogService) GetTotal(search string, action string, isSuccess string, arrayDate []string, filters []filter.Condition) (int64, error) {
 	var count int64
 
-	query := s.db.Model(&model.ActivityLog{})
-
-	if search != "" {
-		query = query.Joins("JOIN mst_users u ON u.id = activity_logs.id_user").Where("u.name ILIKE ?", "%"+search+"%")
-	}
-
-	if action != "" {
-		query = query.Where("action = ?", action)
-	}
+	query := s.filter(s.db.Model(&model.ActivityLog{}), search, action, isSuccess, arrayDate)
 
-	if isSuccess != "" {
-		query = query.Where("is_success =?", isSuccess)
-	}
-
-	if len(arrayDate) == 2 {
-		startDate, err := time.Parse("2006-01-02", arrayDate[0])
-		if err != nil {
-			startDate = time.Time{}
-		}
-
-		endDate, err := time.Parse("2006-01-02", arrayDate[1])
-		if err != nil {
-			endDate = time.Time{}
-		}
-
-		if !startDate.IsZero() && !endDate.IsZero() {
-			query = query.Where("DATE(created_at) BETWEEN ? AND ?", startDate, endDate)
-		}
-	}
+	query = filter.Apply(query, filters)
 
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
@@ -67,58 +52,58 @@ func (s *ActivityLogService) GetTotal(search string, action string, isSuccess st
 	return count, nil
 }
 
-func (s *ActivityLogService) GetAll(offset, limit int, search string, action string, isSuccess string, sortBy string, sortDirection bool, arrayDate []string) ([]model.ActivityLog, error) {
+func (s *ActivityLogService) GetAll(offset, limit int, search string, action string, isSuccess string, sorts []filter.Order, arrayDate []string, filters []filter.Condition) ([]model.ActivityLog, error) {
 	var logs []model.ActivityLog
 
 	query := s.db.Model(&model.ActivityLog{}).Preload("User", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, name")
 	}).Offset(offset).Limit(limit)
 
-	if sortBy != "" {
-		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: sortDirection})
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
 	} else {
-		query = query.Order("created_at ASC")
+		query = query.Order("activity_logs.created_at ASC")
 	}
 
-	if search != "" {
-		query = query.Joins("JOIN mst_users u ON u.id = activity_logs.id_user").Where("u.name ILIKE ?", "%"+search+"%")
-	}
+	query = s.filter(query, search, action, isSuccess, arrayDate)
 
-	if action != "" {
-		query = query.Where("action = ?", action)
-	}
+	query = filter.Apply(query, filters)
 
-	if isSuccess != "" {
-		query = query.Where("is_success = ?", isSuccess)
+	if err := query.Find(&logs).Error; err != nil {
+		return nil, err
 	}
 
-	if len(arrayDate) == 2 {
-		startDate, err := time.Parse("2006-01-02", arrayDate[0])
-		if err != nil {
-			startDate = time.Time{}
-		}
+	return logs, nil
+}
 
-		endDate, err := time.Parse("2006-01-02", arrayDate[1])
-		if err != nil {
-			endDate = time.Time{}
-		}
+// GetPage lists the logs after the cursor without counting them, for
+// scrolling through the log without OFFSET.
+func (s *ActivityLogService) GetPage(after string, limit int, search string, action string, isSuccess string, sorts []filter.Order, arrayDate []string, filters []filter.Condition) ([]model.ActivityLog, string, error) {
+	query := s.db.Model(&model.ActivityLog{}).Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, name")
+	})
 
-		if !startDate.IsZero() && !endDate.IsZero() {
-			query = query.Where("DATE(created_at) BETWEEN ? AND ?", startDate, endDate)
-		}
-	}
+	query = s.filter(query, search, action, isSuccess, arrayDate)
 
-	if err := query.Find(&logs).Error; err != nil {
-		return nil, err
-	}
+	query = filter.Apply(query, filters)
 
-	return logs, nil
+	return filter.Page[model.ActivityLog](query, sorts, after, limit)
 }
 
 func (s *ActivityLogService) Create(log *model.ActivityLog) error {
 	return s.db.Create(log).Error
 }
 
+// Record stores a log that may not belong to a known user, e.g. a failed
+// login for a username that does not exist.
+func (s *ActivityLogService) Record(log *model.ActivityLog) error {
+	if log.IDUser == 0 {
+		return s.db.Omit("IDUser").Create(log).Error
+	}
+
+	return s.db.Create(log).Error
+}
+
 func (s *ActivityLogService) Update(log *model.ActivityLog) error {
 	return s.db.Save(log).Error
 }
@@ -127,11 +112,48 @@ func (s *ActivityLogService) Delete(log *model.ActivityLog) error {
 	return s.db.Delete(log).Error
 }
 
-func (s *ActivityLogService) GetByUsername(username string) (*model.MstUser, error) {
+func (s *ActivityLogService) Purge(before time.Time) (int64, error) {
+	result := s.db.Where("created_at < ?", before).Delete(&model.ActivityLog{})
+	return result.RowsAffected, result.Error
+}
+
+func (s *ActivityLogService) GetUserByID(userID uint) (*model.MstUser, error) {
 	var user model.MstUser
-	if err := s.db.Where("username = ?", username).First(&user).Error; err != nil {
+	if err := s.db.Select("id, username").First(&user, userID).Error; err != nil {
 		return nil, err
 	}
 
 	return &user, nil
 }
+
+func (s *ActivityLogService) filter(query *gorm.DB, search string, action string, isSuccess string, arrayDate []string) *gorm.DB {
+	if search != "" {
+		query = query.Joins("JOIN mst_users u ON u.id = activity_logs.id_user").Where("u.name ILIKE ?", "%"+search+"%")
+	}
+
+	if action != "" {
+		query = query.Where("activity_logs.action = ?", action)
+	}
+
+	if isSuccess != "" {
+		query = query.Where("activity_logs.is_success = ?", isSuccess)
+	}
+
+	if len(arrayDate) == 2 {
+		startDate, err := time.Parse("2006-01-02", arrayDate[0])
+		if err != nil {
+			startDate = time.Time{}
+		}
+
+		endDate, err := time.Parse("2006-01-02", arrayDate[1])
+		if err != nil {
+			endDate = time.Time{}
+		}
+
+		if !startDate.IsZero() && !endDate.IsZero() {
+			query = query.Where("DATE(activity_logs.created_at) BETWEEN ? AND ?", startDate, endDate)
+		}
+	}
+
+	return query
+}
@@ -0,0 +1,396 @@
+package service
+
+import (
+	"errors"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/model"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ApprovalKeySubmit  = "submit"
+	ApprovalKeyApprove = "approve"
+	ApprovalKeyReject  = "reject"
+	ApprovalKeyReturn  = "return"
+	ApprovalKeyCancel  = "cancel"
+)
+
+const (
+	ApprovalStateDraft     = "draft"
+	ApprovalStatePending   = "pending"
+	ApprovalStateApproved  = "approved"
+	ApprovalStateRejected  = "rejected"
+	ApprovalStateReturned  = "returned"
+	ApprovalStateCancelled = "cancelled"
+)
+
+var (
+	ErrApprovalNoChain       = errors.New("no approval chain configured for this menu")
+	ErrApprovalNoApprovers   = errors.New("the approval chain of this menu has no approver levels")
+	ErrApprovalUnknownTable  = errors.New("documents of this table do not go through approval")
+	ErrApprovalNotFound      = errors.New("document not found")
+	ErrApprovalInvalidState  = errors.New("action is not allowed in the current approval state")
+	ErrApprovalNotApprover   = errors.New("user is not an approver for the current level")
+	ErrApprovalAlreadyActed  = errors.New("user has already approved this level")
+	ErrApprovalNotSubmitter  = errors.New("only the submitter can cancel this approval")
+	ErrApprovalUnknownAction = errors.New("unknown approval action")
+)
+
+// ApprovalHook is called inside the approval transaction after every
+// transition, so a master can update its own rows in the same commit. key is
+// the action userID ran; an error refuses it.
+type ApprovalHook func(tx *gorm.DB, refID uint, key string, userID uint, state *dto.ApprovalState) error
+
+var (
+	approvalHooksMu sync.RWMutex
+	approvalHooks   = map[string][]ApprovalHook{}
+	approvalMenus   = map[string]string{}
+)
+
+func RegisterApprovalHook(refTable string, hook ApprovalHook) {
+	approvalHooksMu.Lock()
+	defer approvalHooksMu.Unlock()
+
+	approvalHooks[refTable] = append(approvalHooks[refTable], hook)
+}
+
+// RegisterApprovalMenu sets the path of the menu whose approval chain the
+// documents of refTable follow. Only registered tables go through approval.
+func RegisterApprovalMenu(refTable string, path string) {
+	approvalHooksMu.Lock()
+	defer approvalHooksMu.Unlock()
+
+	approvalMenus[refTable] = path
+}
+
+// ApprovalMenu is the path of the menu registered for refTable, false when
+// its documents do not go through approval.
+func ApprovalMenu(refTable string) (string, bool) {
+	approvalHooksMu.RLock()
+	defer approvalHooksMu.RUnlock()
+
+	path, ok := approvalMenus[refTable]
+	return path, ok
+}
+
+type ApprovalEngineService struct {
+	db *gorm.DB
+}
+
+func NewApprovalEngineService(db *gorm.DB) *ApprovalEngineService {
+	return &ApprovalEngineService{db: db}
+}
+
+func (s *ApprovalEngineService) GetState(refTable string, refID uint) (*dto.ApprovalState, error) {
+	if err := s.findDocument(s.db, refTable, refID); err != nil {
+		return nil, err
+	}
+
+	state, _, err := s.resolve(s.db, refTable, refID)
+	return state, err
+}
+
+func (s *ApprovalEngineService) Act(key string, refTable string, refID uint, userID uint, input dto.ApprovalAction) (*dto.ApprovalState, error) {
+	var result *dto.ApprovalState
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.findDocument(tx, refTable, refID); err != nil {
+			return err
+		}
+
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?), ?)", refTable, refID).Error; err != nil {
+			return err
+		}
+
+		state, levels, err := s.resolve(tx, refTable, refID)
+		if err != nil {
+			return err
+		}
+
+		var approvalID uint
+
+		switch key {
+		case ApprovalKeySubmit:
+			if state.State != ApprovalStateDraft && state.State != ApprovalStateReturned && state.State != ApprovalStateCancelled {
+				return ErrApprovalInvalidState
+			}
+
+			path, _ := ApprovalMenu(refTable)
+
+			var menu model.MstMenu
+			if err := tx.Select("id").Where("path = ?", path).First(&menu).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrApprovalNoChain
+				}
+				return err
+			}
+
+			levels, err = s.getLevels(tx, menu.ID)
+			if err != nil {
+				return err
+			}
+
+			if err := checkChain(levels); err != nil {
+				return err
+			}
+
+			// The first level is the submitter level; it only restricts who may
+			// submit when users have been assigned to it.
+			if len(levels[0].ApprovalUsers) > 0 && !isApprovalUser(levels[0], userID) {
+				return ErrApprovalNotApprover
+			}
+
+			approvalID = levels[0].ID
+
+		case ApprovalKeyApprove, ApprovalKeyReject, ApprovalKeyReturn:
+			if state.State != ApprovalStatePending {
+				return ErrApprovalInvalidState
+			}
+
+			level := findLevel(levels, *state.PendingLevel)
+			if level == nil || !isApprovalUser(*level, userID) {
+				return ErrApprovalNotApprover
+			}
+
+			if key == ApprovalKeyApprove && hasApproved(state.Histories, level.ID, userID) {
+				return ErrApprovalAlreadyActed
+			}
+
+			approvalID = level.ID
+
+		case ApprovalKeyCancel:
+			if state.State != ApprovalStatePending {
+				return ErrApprovalInvalidState
+			}
+
+			if state.SubmittedBy == nil || *state.SubmittedBy != userID {
+				return ErrApprovalNotSubmitter
+			}
+
+			approvalID = state.Histories[len(state.Histories)-1].IDApproval
+
+		default:
+			return ErrApprovalUnknownAction
+		}
+
+		history := model.ApprovalHistory{
+			IDApproval:  approvalID,
+			RefTable:    refTable,
+			RefID:       refID,
+			Key:         key,
+			Message:     input.Message,
+			IDCreatedby: userID,
+		}
+
+		if err := tx.Create(&history).Error; err != nil {
+			return err
+		}
+
+		state, _, err = s.resolve(tx, refTable, refID)
+		if err != nil {
+			return err
+		}
+
+		approvalHooksMu.RLock()
+		hooks := approvalHooks[refTable]
+		approvalHooksMu.RUnlock()
+
+		for _, hook := range hooks {
+			if err := hook(tx, refID, key, userID, state); err != nil {
+				return err
+			}
+		}
+
+		result = state
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// findDocument checks that refTable goes through approval and has a live
+// record refID.
+func (s *ApprovalEngineService) findDocument(db *gorm.DB, refTable string, refID uint) error {
+	if _, ok := ApprovalMenu(refTable); !ok {
+		return ErrApprovalUnknownTable
+	}
+
+	query := db.Table(refTable).Where("id = ?", refID)
+	if isSoftDeleteTable(db, refTable) {
+		query = query.Where("deleted_at IS NULL")
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return ErrApprovalNotFound
+	}
+
+	return nil
+}
+
+func (s *ApprovalEngineService) getLevels(db *gorm.DB, idMenu uint) ([]model.MstApproval, error) {
+	var levels []model.MstApproval
+	if err := db.Preload("ApprovalUsers.User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, name")
+	}).Where("id_menu = ?", idMenu).Order("level ASC").Find(&levels).Error; err != nil {
+		return nil, err
+	}
+
+	return levels, nil
+}
+
+// resolve replays the approval history of a document. Only rows written since
+// the latest submit belong to the running cycle; earlier cycles are kept in
+// the returned history for display.
+func (s *ApprovalEngineService) resolve(db *gorm.DB, refTable string, refID uint) (*dto.ApprovalState, []model.MstApproval, error) {
+	var histories []model.ApprovalHistory
+	if err := db.Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, name")
+	}).Preload("Approval", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, id_menu, status, action, count, level")
+	}).Where("ref_table = ? AND ref_id = ?", refTable, refID).Order("id ASC").Find(&histories).Error; err != nil {
+		return nil, nil, err
+	}
+
+	state := &dto.ApprovalState{
+		RefTable:  refTable,
+		RefID:     refID,
+		State:     ApprovalStateDraft,
+		Approvers: []model.MstUser{},
+		Histories: histories,
+	}
+
+	start := -1
+	for i, history := range histories {
+		if history.Key == ApprovalKeySubmit {
+			start = i
+		}
+	}
+
+	if start == -1 || histories[start].Approval == nil {
+		return state, nil, nil
+	}
+
+	cycle := histories[start:]
+	idMenu := histories[start].Approval.IDMenu
+	state.IDMenu = &idMenu
+	state.SubmittedBy = &histories[start].IDCreatedby
+
+	levels, err := s.getLevels(db, idMenu)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkChain(levels); err != nil {
+		return nil, nil, err
+	}
+
+	switch cycle[len(cycle)-1].Key {
+	case ApprovalKeyReject:
+		state.State = ApprovalStateRejected
+	case ApprovalKeyReturn:
+		state.State = ApprovalStateReturned
+	case ApprovalKeyCancel:
+		state.State = ApprovalStateCancelled
+	}
+
+	approvals := map[uint]uint{}
+	for _, history := range cycle {
+		if history.Key == ApprovalKeyApprove {
+			approvals[history.IDApproval]++
+		}
+	}
+
+	reached := levels[0]
+	for _, level := range levels[1:] {
+		required := level.Count
+		if required == 0 {
+			required = 1
+		}
+
+		if approvals[level.ID] < required {
+			if state.State == ApprovalStateDraft {
+				state.State = ApprovalStatePending
+				state.PendingLevel = &level.Level
+				state.PendingAction = &level.Action
+				state.Approved = approvals[level.ID]
+				state.Required = required
+
+				for _, approvalUser := range level.ApprovalUsers {
+					if approvalUser.User != nil {
+						state.Approvers = append(state.Approvers, *approvalUser.User)
+					}
+				}
+			}
+			break
+		}
+
+		reached = level
+	}
+
+	if state.State == ApprovalStateDraft {
+		state.State = ApprovalStateApproved
+	}
+
+	state.Level = &reached.Level
+	state.Status = &reached.Status
+
+	return state, levels, nil
+}
+
+// checkChain rejects a chain without a level after the submitter's, which
+// would approve a document as soon as it is submitted.
+func checkChain(levels []model.MstApproval) error {
+	if len(levels) == 0 {
+		return ErrApprovalNoChain
+	}
+
+	if len(levels) == 1 {
+		return ErrApprovalNoApprovers
+	}
+
+	return nil
+}
+
+func findLevel(levels []model.MstApproval, level int) *model.MstApproval {
+	for i := range levels {
+		if levels[i].Level == level {
+			return &levels[i]
+		}
+	}
+
+	return nil
+}
+
+func isApprovalUser(level model.MstApproval, userID uint) bool {
+	for _, approvalUser := range level.ApprovalUsers {
+		if approvalUser.IDUser == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasApproved(histories []model.ApprovalHistory, approvalID uint, userID uint) bool {
+	for i := len(histories) - 1; i >= 0; i-- {
+		if histories[i].Key == ApprovalKeySubmit {
+			break
+		}
+
+		if histories[i].Key == ApprovalKeyApprove && histories[i].IDApproval == approvalID && histories[i].IDCreatedby == userID {
+			return true
+		}
+	}
+
+	return false
+}
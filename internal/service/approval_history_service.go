@@ -1,12 +1,15 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
+// ApprovalHistoryFilterFields are the fields the list accepts in filter[field][op].
+var ApprovalHistoryFilterFields = filter.Columns(&model.ApprovalHistory{})
+
 type ApprovalHistoryService struct {
 	db *gorm.DB
 }
@@ -26,7 +29,7 @@ func (s *ApprovalHistoryService) GetByID(approvalHistoryID uint) (*model.Approva
 	return &approvalHistory, nil
 }
 
-func (s *ApprovalHistoryService) GetTotal(search string) (int64, error) {
+func (s *ApprovalHistoryService) GetTotal(search string, filters []filter.Condition) (int64, error) {
 	var count int64
 
 	query := s.db.Model(&model.ApprovalHistory{})
@@ -35,6 +38,8 @@ func (s *ApprovalHistoryService) GetTotal(search string) (int64, error) {
 		query = query.Where("key ILIKE ?", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
@@ -42,7 +47,7 @@ func (s *ApprovalHistoryService) GetTotal(search string) (int64, error) {
 	return count, nil
 }
 
-func (s *ApprovalHistoryService) GetAll(offset, limit int, search string, sortBy string, sortDirection bool) ([]model.ApprovalHistory, error) {
+func (s *ApprovalHistoryService) GetAll(offset, limit int, search string, sorts []filter.Order, filters []filter.Condition) ([]model.ApprovalHistory, error) {
 	var approvalHistories []model.ApprovalHistory
 
 	query := s.db.Model(&model.ApprovalHistory{}).Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
@@ -53,12 +58,14 @@ func (s *ApprovalHistoryService) GetAll(offset, limit int, search string, sortBy
 		query = query.Where("key ILIKE ?", "%"+search+"%")
 	}
 
-	if sortBy != "" {
-		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: sortDirection})
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
 	} else {
 		query = query.Order("created_at ASC")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&approvalHistories).Error; err != nil {
 		return nil, err
 	}
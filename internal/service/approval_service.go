@@ -1,11 +1,15 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
 )
 
+// ApprovalFilterFields are the fields the list accepts in filter[field][op].
+var ApprovalFilterFields = filter.Columns(&model.MstApproval{})
+
 type ApprovalService struct {
 	db *gorm.DB
 }
@@ -40,15 +44,17 @@ func (s *ApprovalService) GetByIdMenu(idMenu uint) ([]model.MstApproval, error)
 	return approvals, nil
 }
 
-func (s *ApprovalService) GetTotal(search string) (int64, error) {
+func (s *ApprovalService) GetTotal(search string, includeDeleted bool, filters []filter.Condition) (int64, error) {
 	var count int64
 
-	query := s.db.Model(&model.MstApproval{})
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstApproval{})
 
 	if search != "" {
 		query = query.Where("status ILIKE ?", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
@@ -56,10 +62,12 @@ func (s *ApprovalService) GetTotal(search string) (int64, error) {
 	return count, nil
 }
 
-func (s *ApprovalService) GetAll(offset, limit int, search string) ([]model.MstApproval, error) {
+func (s *ApprovalService) GetAll(offset, limit int, search string, includeDeleted bool, filters []filter.Condition) ([]model.MstApproval, error) {
 	var approvals []model.MstApproval
 
-	query := s.db.Model(&model.MstApproval{}).Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstApproval{}).Preload("Menu", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, label, path")
+	}).Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, name")
 	}).Preload("UpdatedBy", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, name")
@@ -69,6 +77,8 @@ func (s *ApprovalService) GetAll(offset, limit int, search string) ([]model.MstA
 		query = query.Where("status ILIKE ?", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&approvals).Error; err != nil {
 		return nil, err
 	}
@@ -81,11 +91,11 @@ func (s *ApprovalService) Create(approval *model.MstApproval) error {
 }
 
 func (s *ApprovalService) Update(approval *model.MstApproval) error {
-	return s.db.Save(approval).Error
+	return SaveVersion(s.db, approval)
 }
 
 func (s *ApprovalService) Delete(approval *model.MstApproval) error {
-	return s.db.Delete(approval).Error
+	return SoftDelete(s.db, approval, approval.IDUpdatedby)
 }
 
 func (s *ApprovalService) DeleteByIdMenu(idMenu uint) error {
@@ -1,12 +1,15 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
+// ApprovalStructureFilterFields are the fields the list accepts in filter[field][op].
+var ApprovalStructureFilterFields = filter.Columns(&model.MstMenu{})
+
 type ApprovalStructureService struct {
 	db *gorm.DB
 }
@@ -32,7 +35,7 @@ func (s *ApprovalStructureService) GetByID(approvalUserID uint) (*model.MstMenu,
 	return &approvalUser, nil
 }
 
-func (s *ApprovalStructureService) GetTotal(search string) (int64, error) {
+func (s *ApprovalStructureService) GetTotal(search string, filters []filter.Condition) (int64, error) {
 	var count int64
 
 	query := s.db.Model(&model.MstMenu{})
@@ -41,6 +44,8 @@ func (s *ApprovalStructureService) GetTotal(search string) (int64, error) {
 		query = query.Where("label ILIKE ?", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
@@ -48,7 +53,7 @@ func (s *ApprovalStructureService) GetTotal(search string) (int64, error) {
 	return count, nil
 }
 
-func (s *ApprovalStructureService) GetAll(offset, limit int, search string, sortBy string, sortDirection bool) ([]model.MstMenu, error) {
+func (s *ApprovalStructureService) GetAll(offset, limit int, search string, sorts []filter.Order, filters []filter.Condition) ([]model.MstMenu, error) {
 	var approvalUsers []model.MstMenu
 
 	query := s.db.Model(&model.MstMenu{}).Preload("MenuApprovals", func(db *gorm.DB) *gorm.DB {
@@ -61,8 +66,8 @@ func (s *ApprovalStructureService) GetAll(offset, limit int, search string, sort
 		return db.Select("id, name")
 	}).Offset(offset).Limit(limit)
 
-	if sortBy != "" {
-		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: sortDirection})
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
 	} else {
 		query = query.Order("updated_at ASC")
 	}
@@ -71,6 +76,8 @@ func (s *ApprovalStructureService) GetAll(offset, limit int, search string, sort
 		query = query.Where("label ILIKE ?", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&approvalUsers).Error; err != nil {
 		return nil, err
 	}
@@ -1,11 +1,15 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
 )
 
+// ApprovalUserFilterFields are the fields the list accepts in filter[field][op].
+var ApprovalUserFilterFields = filter.Columns(&model.MstApprovalUser{})
+
 type ApprovalUserService struct {
 	db *gorm.DB
 }
@@ -27,7 +31,18 @@ func (s *ApprovalUserService) GetByIdApproval(idApproval uint) ([]model.MstAppro
 	return approvalUsers, nil
 }
 
-func (s *ApprovalUserService) GetTotal(search string) (int64, error) {
+// GetApproval loads the approval whose users are listed, which carries their
+// version.
+func (s *ApprovalUserService) GetApproval(idApproval uint) (*model.MstApproval, error) {
+	var approval model.MstApproval
+	if err := s.db.Select("id", "updated_at").First(&approval, idApproval).Error; err != nil {
+		return nil, err
+	}
+
+	return &approval, nil
+}
+
+func (s *ApprovalUserService) GetTotal(search string, filters []filter.Condition) (int64, error) {
 	var count int64
 
 	query := s.db.Model(&model.MstApprovalUser{}).Joins("JOIN mst_users u ON u.id = mst_approval_users.id_user")
@@ -36,6 +51,8 @@ func (s *ApprovalUserService) GetTotal(search string) (int64, error) {
 		query.Where("u.name ILIKE ?", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
@@ -43,7 +60,7 @@ func (s *ApprovalUserService) GetTotal(search string) (int64, error) {
 	return count, nil
 }
 
-func (s *ApprovalUserService) GetAll(offset, limit int, search string) ([]model.MstApprovalUser, error) {
+func (s *ApprovalUserService) GetAll(offset, limit int, search string, filters []filter.Condition) ([]model.MstApprovalUser, error) {
 	var approvalUsers []model.MstApprovalUser
 
 	query := s.db.Model(&model.MstApprovalUser{}).Joins("JOIN mst_users u ON u.id = mst_approval_users.id_user").Preload("User", func(db *gorm.DB) *gorm.DB {
@@ -56,6 +73,8 @@ func (s *ApprovalUserService) GetAll(offset, limit int, search string) ([]model.
 		query.Where("u.name ILIKE ?", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&approvalUsers).Error; err != nil {
 		return nil, err
 	}
@@ -70,3 +89,24 @@ func (s *ApprovalUserService) Create(approvalUser []*model.MstApprovalUser) erro
 func (s *ApprovalUserService) Delete(approvalID uint) error {
 	return s.db.Where("id_approval = ?", approvalID).Delete(&model.MstApprovalUser{}).Error
 }
+
+// Replace sets the users of approval in a transaction, moving its version so
+// the set is replaced only over the one it was read with, and returns ErrStale
+// when the approval was changed since.
+func (s *ApprovalUserService) Replace(approval *model.MstApproval, approvalUsers []*model.MstApprovalUser) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := Touch(tx, approval); err != nil {
+			return err
+		}
+
+		if err := tx.Where("id_approval = ?", approval.ID).Delete(&model.MstApprovalUser{}).Error; err != nil {
+			return err
+		}
+
+		if len(approvalUsers) == 0 {
+			return nil
+		}
+
+		return tx.Create(approvalUsers).Error
+	})
+}
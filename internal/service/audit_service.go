@@ -0,0 +1,134 @@
+package service
+
+import (
+	"insist-backend-golang/internal/filter"
+	"insist-backend-golang/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditFilterFields are the fields the list accepts in filter[field][op].
+var AuditFilterFields = filter.Columns(&model.AuditLog{})
+
+type AuditService struct {
+	db *gorm.DB
+}
+
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+func (s *AuditService) GetByID(auditID uint) (*model.AuditLog, error) {
+	var audit model.AuditLog
+	if err := s.db.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, name")
+	}).First(&audit, auditID).Error; err != nil {
+		return nil, err
+	}
+
+	return &audit, nil
+}
+
+func (s *AuditService) GetTotal(tableName string, recordID uint, userID uint, action string, arrayDate []string, filters []filter.Condition) (int64, error) {
+	var count int64
+
+	query := s.filter(s.db.Model(&model.AuditLog{}), tableName, recordID, userID, action, arrayDate)
+
+	query = filter.Apply(query, filters)
+
+	if err := query.Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (s *AuditService) GetAll(offset, limit int, tableName string, recordID uint, userID uint, action string, sorts []filter.Order, arrayDate []string, filters []filter.Condition) ([]model.AuditLog, error) {
+	var audits []model.AuditLog
+
+	query := s.db.Model(&model.AuditLog{}).Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, name")
+	}).Offset(offset).Limit(limit)
+
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
+	} else {
+		query = query.Order("created_at DESC").Order("id DESC")
+	}
+
+	query = s.filter(query, tableName, recordID, userID, action, arrayDate)
+
+	query = filter.Apply(query, filters)
+
+	if err := query.Find(&audits).Error; err != nil {
+		return nil, err
+	}
+
+	return audits, nil
+}
+
+// GetPage lists the audit logs after the cursor without counting them, newest
+// first unless sorted otherwise.
+func (s *AuditService) GetPage(after string, limit int, tableName string, recordID uint, userID uint, action string, sorts []filter.Order, arrayDate []string, filters []filter.Condition) ([]model.AuditLog, string, error) {
+	query := s.db.Model(&model.AuditLog{}).Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, name")
+	})
+
+	if len(sorts) == 0 {
+		sorts = []filter.Order{{Column: "audit_logs.created_at", Desc: true}}
+	}
+
+	query = s.filter(query, tableName, recordID, userID, action, arrayDate)
+
+	query = filter.Apply(query, filters)
+
+	return filter.Page[model.AuditLog](query, sorts, after, limit)
+}
+
+func (s *AuditService) GetByRecord(tableName string, recordID uint) ([]model.AuditLog, error) {
+	var audits []model.AuditLog
+	if err := s.db.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, name")
+	}).Where("table_name = ? AND record_id = ?", tableName, recordID).Order("created_at DESC").Order("id DESC").Find(&audits).Error; err != nil {
+		return nil, err
+	}
+
+	return audits, nil
+}
+
+func (s *AuditService) filter(query *gorm.DB, tableName string, recordID uint, userID uint, action string, arrayDate []string) *gorm.DB {
+	if tableName != "" {
+		query = query.Where("table_name = ?", tableName)
+	}
+
+	if recordID != 0 {
+		query = query.Where("record_id = ?", recordID)
+	}
+
+	if userID != 0 {
+		query = query.Where("id_user = ?", userID)
+	}
+
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+
+	if len(arrayDate) == 2 {
+		startDate, err := time.Parse("2006-01-02", arrayDate[0])
+		if err != nil {
+			startDate = time.Time{}
+		}
+
+		endDate, err := time.Parse("2006-01-02", arrayDate[1])
+		if err != nil {
+			endDate = time.Time{}
+		}
+
+		if !startDate.IsZero() && !endDate.IsZero() {
+			query = query.Where("DATE(created_at) BETWEEN ? AND ?", startDate, endDate)
+		}
+	}
+
+	return query
+}
@@ -38,10 +38,6 @@ func (s *AuthService) UpdatePassword(userID uint, newPassword string) error {
 	return s.db.Model(&model.MstUser{ID: userID}).Update("password", newPassword).Error
 }
 
-func (s *AuthService) UpdateRefreshToken(userID uint, refreshToken string) error {
-	return s.db.Model(&model.MstUser{ID: userID}).Update("refresh_token", refreshToken).Error
-}
-
 func (s *AuthService) UpdateTwoFactorAuth(userID uint, isTwoFa bool) error {
 	return s.db.Model(&model.MstUser{ID: userID}).Update("is_two_fa", isTwoFa).Error
 }
@@ -1,12 +1,17 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
+// BuildingFilterFields are the fields the list accepts in filter[field][op].
+var BuildingFilterFields = filter.Columns(&model.MstBuilding{}).With(filter.Fields{
+	"id_fcs": "mst_fcs_buildings.id_fcs",
+})
+
 type BuildingService struct {
 	db *gorm.DB
 }
@@ -28,10 +33,10 @@ func (s *BuildingService) GetByID(buildingID uint) (*model.MstBuilding, error) {
 	return &building, nil
 }
 
-func (s *BuildingService) GetTotal(search string, IDFCS uint, plant string) (int64, error) {
+func (s *BuildingService) GetTotal(search string, IDFCS uint, plant string, includeDeleted bool, filters []filter.Condition) (int64, error) {
 	var count int64
 
-	query := s.db.Model(&model.MstBuilding{}).Select("COUNT(DISTINCT mst_buildings.id)").Joins("LEFT JOIN mst_fcs_buildings ON mst_fcs_buildings.id_building = mst_buildings.id")
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstBuilding{}).Select("COUNT(DISTINCT mst_buildings.id)").Joins("LEFT JOIN mst_fcs_buildings ON mst_fcs_buildings.id_building = mst_buildings.id")
 
 	if IDFCS != 0 {
 		query = query.Where("mst_fcs_buildings.id_fcs = ?", IDFCS)
@@ -45,6 +50,8 @@ func (s *BuildingService) GetTotal(search string, IDFCS uint, plant string) (int
 		query = query.Where("code ILIKE ? OR description ILIKE ?", "%"+search+"%", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
@@ -52,17 +59,17 @@ func (s *BuildingService) GetTotal(search string, IDFCS uint, plant string) (int
 	return count, nil
 }
 
-func (s *BuildingService) GetAll(offset, limit int, search string, sortBy string, sortDirection bool, IDFCS uint, plant string) ([]model.MstBuilding, error) {
+func (s *BuildingService) GetAll(offset, limit int, search string, sorts []filter.Order, IDFCS uint, plant string, includeDeleted bool, filters []filter.Condition) ([]model.MstBuilding, error) {
 	var buildings []model.MstBuilding
 
-	query := s.db.Model(&model.MstBuilding{}).Select("DISTINCT mst_buildings.*").Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstBuilding{}).Select("DISTINCT mst_buildings.*").Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, name")
 	}).Preload("UpdatedBy", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, name")
 	}).Joins("LEFT JOIN mst_fcs_buildings ON mst_fcs_buildings.id_building = mst_buildings.id").Offset(offset).Limit(limit)
 
-	if sortBy != "" {
-		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: sortDirection})
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
 	} else {
 		query = query.Order("updated_at ASC")
 	}
@@ -79,6 +86,8 @@ func (s *BuildingService) GetAll(offset, limit int, search string, sortBy string
 		query = query.Where("code ILIKE ? OR description ILIKE ?", "%"+search+"%", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&buildings).Error; err != nil {
 		return nil, err
 	}
@@ -91,9 +100,9 @@ func (s *BuildingService) Create(building *model.MstBuilding) error {
 }
 
 func (s *BuildingService) Update(building *model.MstBuilding) error {
-	return s.db.Save(building).Error
+	return SaveVersion(s.db, building)
 }
 
 func (s *BuildingService) Delete(building *model.MstBuilding) error {
-	return s.db.Delete(building).Error
+	return SoftDelete(s.db, building, building.IDUpdatedby)
 }
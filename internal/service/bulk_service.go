@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"insist-backend-golang/internal/dto"
+
+	"gorm.io/gorm"
+)
+
+// ErrBulkFailed is returned by RunBulk when an operation failed and nothing
+// was saved.
+var ErrBulkFailed = errors.New("bulk operations failed")
+
+// ErrBulkConflict is returned by RunBulk along with ErrBulkFailed when an
+// operation failed because a record with the same unique value exists.
+var ErrBulkConflict = errors.New("a bulk operation conflicts with an existing record")
+
+// BulkFunc applies one operation within tx and returns the ID of the record.
+type BulkFunc func(tx *gorm.DB, operation dto.BulkOperation) (interface{}, error)
+
+// RunBulk applies the operations in order in a single transaction. Every
+// operation runs in its own savepoint, so the ones after a failure are still
+// tried and reported, but the transaction is rolled back as a whole when any
+// of them failed.
+func RunBulk(db *gorm.DB, operations []dto.BulkOperation, apply BulkFunc) ([]dto.BulkResult, error) {
+	results := make([]dto.BulkResult, len(operations))
+	failed := 0
+	conflict := false
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for i, operation := range operations {
+			result := dto.BulkResult{Index: i, Action: operation.Action, Status: dto.BulkStatusOK}
+
+			err := tx.Transaction(func(op *gorm.DB) error {
+				ID, err := apply(op, operation)
+				result.ID = ID
+				return err
+			})
+			if err != nil {
+				result.Status = dto.BulkStatusFailed
+				result.Error = err.Error()
+				failed++
+
+				if errors.Is(err, gorm.ErrDuplicatedKey) {
+					result.Error = "a record with the same unique value already exists"
+					conflict = true
+				}
+			}
+
+			results[i] = result
+		}
+
+		if failed > 0 {
+			return ErrBulkFailed
+		}
+
+		return nil
+	})
+
+	if errors.Is(err, ErrBulkFailed) {
+		for i := range results {
+			if results[i].Status == dto.BulkStatusOK {
+				results[i].Status = dto.BulkStatusRolledBack
+			}
+		}
+
+		if conflict {
+			return results, fmt.Errorf("%w: %w: %d of %d", ErrBulkFailed, ErrBulkConflict, failed, len(operations))
+		}
+
+		return results, fmt.Errorf("%w: %d of %d", ErrBulkFailed, failed, len(operations))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -1,12 +1,15 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
+// CurrencyRateFilterFields are the fields the list accepts in filter[field][op].
+var CurrencyRateFilterFields = filter.Columns(&model.MstCurrencyRate{})
+
 type CurrencyRateService struct {
 	db *gorm.DB
 }
@@ -28,10 +31,10 @@ func (s *CurrencyRateService) GetByID(currencyRateID uint) (*model.MstCurrencyRa
 	return &currencyRate, nil
 }
 
-func (s *CurrencyRateService) GetTotal(idCurrency uint, search string) (int64, error) {
+func (s *CurrencyRateService) GetTotal(idCurrency uint, search string, includeDeleted bool, filters []filter.Condition) (int64, error) {
 	var count int64
 
-	query := s.db.Model(&model.MstCurrencyRate{})
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstCurrencyRate{})
 
 	if idCurrency != 0 {
 		query = query.Where("id_from_currency = ?", idCurrency)
@@ -44,6 +47,8 @@ func (s *CurrencyRateService) GetTotal(idCurrency uint, search string) (int64, e
 
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
@@ -51,10 +56,10 @@ func (s *CurrencyRateService) GetTotal(idCurrency uint, search string) (int64, e
 	return count, nil
 }
 
-func (s *CurrencyRateService) GetAll(idCurrency uint, offset, limit int, search string, sortBy string, sortDirection bool) ([]model.MstCurrencyRate, error) {
+func (s *CurrencyRateService) GetAll(idCurrency uint, offset, limit int, search string, sorts []filter.Order, includeDeleted bool, filters []filter.Condition) ([]model.MstCurrencyRate, error) {
 	var currencyRates []model.MstCurrencyRate
 
-	query := s.db.Model(&model.MstCurrencyRate{}).Preload("FromCurrency").Preload("ToCurrency").Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstCurrencyRate{}).Preload("FromCurrency").Preload("ToCurrency").Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, name")
 	}).Preload("UpdatedBy", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, name")
@@ -64,8 +69,8 @@ func (s *CurrencyRateService) GetAll(idCurrency uint, offset, limit int, search
 		query = query.Where("id_from_currency = ?", idCurrency)
 	}
 
-	if sortBy != "" {
-		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: sortDirection})
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
 	} else {
 		query = query.Order("updated_at ASC")
 	}
@@ -77,6 +82,8 @@ func (s *CurrencyRateService) GetAll(idCurrency uint, offset, limit int, search
 
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&currencyRates).Error; err != nil {
 		return nil, err
 	}
@@ -89,9 +96,9 @@ func (s *CurrencyRateService) Create(currencyRate *model.MstCurrencyRate) error
 }
 
 func (s *CurrencyRateService) Update(currencyRate *model.MstCurrencyRate) error {
-	return s.db.Save(currencyRate).Error
+	return SaveVersion(s.db, currencyRate)
 }
 
 func (s *CurrencyRateService) Delete(currencyRate *model.MstCurrencyRate) error {
-	return s.db.Delete(currencyRate).Error
+	return SoftDelete(s.db, currencyRate, currencyRate.IDUpdatedby)
 }
@@ -1,12 +1,15 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
+// CurrencyFilterFields are the fields the list accepts in filter[field][op].
+var CurrencyFilterFields = filter.Columns(&model.MstCurrency{})
+
 type CurrencyService struct {
 	db *gorm.DB
 }
@@ -28,15 +31,17 @@ func (s *CurrencyService) GetByID(currencyID uint) (*model.MstCurrency, error) {
 	return &currency, nil
 }
 
-func (s *CurrencyService) GetTotal(search string) (int64, error) {
+func (s *CurrencyService) GetTotal(search string, includeDeleted bool, filters []filter.Condition) (int64, error) {
 	var count int64
 
-	query := s.db.Model(&model.MstCurrency{})
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstCurrency{})
 
 	if search != "" {
 		query = query.Where("currency ILIKE ? OR description ILIKE ?", "%"+search+"%", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
@@ -44,17 +49,17 @@ func (s *CurrencyService) GetTotal(search string) (int64, error) {
 	return count, nil
 }
 
-func (s *CurrencyService) GetAll(offset, limit int, search string, sortBy string, sortDirection bool) ([]model.MstCurrency, error) {
+func (s *CurrencyService) GetAll(offset, limit int, search string, sorts []filter.Order, includeDeleted bool, filters []filter.Condition) ([]model.MstCurrency, error) {
 	var currencys []model.MstCurrency
 
-	query := s.db.Model(&model.MstCurrency{}).Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstCurrency{}).Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, name")
 	}).Preload("UpdatedBy", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, name")
 	}).Offset(offset).Limit(limit)
 
-	if sortBy != "" {
-		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: sortDirection})
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
 	} else {
 		query = query.Order("currency ASC")
 	}
@@ -63,6 +68,8 @@ func (s *CurrencyService) GetAll(offset, limit int, search string, sortBy string
 		query = query.Where("currency ILIKE ? OR description ILIKE ?", "%"+search+"%", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&currencys).Error; err != nil {
 		return nil, err
 	}
@@ -75,11 +82,11 @@ func (s *CurrencyService) Create(currency *model.MstCurrency) error {
 }
 
 func (s *CurrencyService) Update(currency *model.MstCurrency) error {
-	return s.db.Save(currency).Error
+	return SaveVersion(s.db, currency)
 }
 
 func (s *CurrencyService) Delete(currency *model.MstCurrency) error {
-	return s.db.Delete(currency).Error
+	return SoftDelete(s.db, currency, currency.IDUpdatedby)
 }
 
 func (s *CurrencyService) GetByCurrencyCode(code string) (*model.MstCurrency, error) {
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"insist-backend-golang/internal/dto"
+
+	"gorm.io/gorm"
+)
+
+// whereUsedLimit caps how many referencing records are listed per dependency.
+const whereUsedLimit = 10
+
+// Dependency describes a column of Table that references a master record.
+type Dependency struct {
+	Table     string
+	Column    string
+	Reference string // column of the referenced record, its primary key unless set
+	Key       string // column identifying the referencing record, "id" unless set
+	Label     string // column describing the referencing record
+}
+
+// dependencies maps a master table to the columns referencing it. Rows that
+// belong to the record itself, like the revisions of a machine or the menus
+// of a role, are not listed as they go with it.
+var dependencies = map[string][]Dependency{
+	"mst_approvals": {
+		{Table: "approval_histories", Column: "id_approval", Label: "ref_table"},
+	},
+	"mst_buildings": {
+		{Table: "mst_fcs_buildings", Column: "id_building", Key: "id_fcs"},
+		{Table: "mst_machine_details", Column: "id_building", Key: "id_machine", Label: "code"},
+		{Table: "mst_sub_sections", Column: "id_building", Label: "code"},
+		{Table: "mst_warehouses", Column: "id_building", Label: "code"},
+	},
+	"mst_chart_of_accounts": {
+		{Table: "mst_banks", Column: "id_account", Label: "code"},
+		{Table: "mst_tax_codes", Column: "id_account_ar", Label: "name"},
+		{Table: "mst_tax_codes", Column: "id_account_ar_process", Label: "name"},
+		{Table: "mst_tax_codes", Column: "id_account_ap", Label: "name"},
+	},
+	"mst_currencies": {
+		{Table: "mst_banks", Column: "id_currency", Label: "code"},
+		{Table: "mst_currency_rates", Column: "id_from_currency", Label: "effective_date"},
+		{Table: "mst_currency_rates", Column: "id_to_currency", Label: "effective_date"},
+	},
+	"mst_depts": {
+		{Table: "mst_users", Column: "id_dept", Label: "username"},
+	},
+	"mst_employees": {
+		{Table: "work_orders", Column: "technician_number", Label: "title"},
+		{Table: "work_order_labors", Column: "employee_number", Key: "id_work_order"},
+	},
+	"mst_fcs": {
+		{Table: "mst_sections", Column: "id_fcs", Label: "code"},
+	},
+	"mst_item_categories": {
+		{Table: "mst_items", Column: "id_item_category", Label: "code"},
+		{Table: "mst_item_processes", Column: "id_item_category", Label: "code"},
+		{Table: "mst_item_products", Column: "id_item_category", Label: "code"},
+		{Table: "mst_item_sources", Column: "id_item_category", Label: "code"},
+		{Table: "mst_item_sub_categories", Column: "id_item_category", Label: "code"},
+		{Table: "mst_item_surfaces", Column: "id_item_category", Label: "code"},
+	},
+	"mst_item_groups": {
+		{Table: "mst_item_group_types", Column: "id_item_group", Label: "code"},
+	},
+	"mst_item_group_types": {
+		{Table: "mst_item_raw_materials", Column: "id_item_group_type"},
+	},
+	"mst_item_processes": {
+		{Table: "mst_item_raw_materials", Column: "id_item_process"},
+	},
+	"mst_item_products": {
+		{Table: "mst_item_product_types", Column: "id_item_product", Label: "code"},
+	},
+	"mst_item_product_types": {
+		{Table: "mst_item_groups", Column: "id_item_product_type", Label: "code"},
+		{Table: "mst_item_raw_materials", Column: "id_item_product_type"},
+	},
+	"mst_item_sources": {
+		{Table: "mst_item_raw_materials", Column: "id_item_source"},
+	},
+	"mst_item_sub_categories": {
+		{Table: "mst_item_products", Column: "id_item_sub_category", Label: "code"},
+	},
+	"mst_item_surfaces": {
+		{Table: "mst_item_raw_materials", Column: "id_item_surface"},
+	},
+	"mst_items": {
+		{Table: "mst_item_raw_materials", Column: "id_item"},
+		{Table: "mst_materials", Column: "code", Reference: "code", Label: "code"},
+		{Table: "work_order_parts", Column: "id_item", Key: "id_work_order"},
+	},
+	"mst_machines": {
+		{Table: "mst_machine_maintenance_plans", Column: "id_machine", Key: "id_maintenance_plan"},
+		{Table: "work_orders", Column: "id_machine", Label: "title"},
+	},
+	"mst_maintenance_plans": {
+		{Table: "mst_machine_maintenance_plans", Column: "id_maintenance_plan", Key: "id_machine"},
+	},
+	"mst_materials": {
+		{Table: "mst_material_details", Column: "id_material", Label: "rev_no"},
+	},
+	"mst_menus": {
+		{Table: "mst_menus", Column: "id_parent", Label: "label"},
+		{Table: "mst_approvals", Column: "id_menu", Label: "status"},
+		{Table: "mst_reasons", Column: "id_menu", Label: "code"},
+		{Table: "mst_role_menus", Column: "id_menu", Key: "id_role"},
+		{Table: "mst_role_permissions", Column: "id_menu", Key: "id_role"},
+	},
+	"mst_reasons": {
+		{Table: "mst_machine_statuses", Column: "id_reason", Key: "id_machine", Label: "remarks"},
+		{Table: "work_orders", Column: "id_reason", Label: "title"},
+		{Table: "work_orders", Column: "id_resume_reason", Label: "title"},
+	},
+	"mst_roles": {
+		{Table: "mst_user_roles", Column: "id_role", Key: "id_user"},
+	},
+	"mst_sections": {
+		{Table: "mst_sub_sections", Column: "id_section", Label: "code"},
+	},
+	"mst_uoms": {
+		{Table: "mst_items", Column: "id_uom", Label: "code"},
+		{Table: "mst_machine_details", Column: "id_power_uom", Key: "id_machine", Label: "code"},
+		{Table: "mst_machine_details", Column: "id_electricity_uom", Key: "id_machine", Label: "code"},
+		{Table: "mst_machine_details", Column: "id_lubricant_uom", Key: "id_machine", Label: "code"},
+		{Table: "mst_machine_details", Column: "id_sliding_uom", Key: "id_machine", Label: "code"},
+		{Table: "mst_machine_details", Column: "id_coolant_uom", Key: "id_machine", Label: "code"},
+		{Table: "mst_machine_details", Column: "id_hydraulic_uom", Key: "id_machine", Label: "code"},
+		{Table: "mst_machine_details", Column: "id_dimension_front_uom", Key: "id_machine", Label: "code"},
+		{Table: "mst_machine_details", Column: "id_dimension_side_uom", Key: "id_machine", Label: "code"},
+		{Table: "work_order_parts", Column: "id_uom", Key: "id_work_order"},
+	},
+	"mst_users": {
+		{Table: "mst_approval_users", Column: "id_user", Key: "id_approval"},
+	},
+	"mst_warehouses": {
+		{Table: "mst_locations", Column: "id_warehouse", Label: "location"},
+	},
+}
+
+type DependencyService struct {
+	db *gorm.DB
+}
+
+func NewDependencyService(db *gorm.DB) *DependencyService {
+	return &DependencyService{db: db}
+}
+
+// GetWhereUsed lists the records still referencing the record of resource's
+// table with the given primary key, an ID or the number of an employee. Soft
+// deleted referencing records are left out.
+func (s *DependencyService) GetWhereUsed(resource interface{}, ID interface{}) ([]dto.WhereUsed, error) {
+	stmt := &gorm.Statement{DB: s.db}
+	if err := stmt.Parse(resource); err != nil {
+		return nil, err
+	}
+
+	table := stmt.Schema.Table
+	primaryKey := "id"
+	if field := stmt.Schema.PrioritizedPrimaryField; field != nil {
+		primaryKey = field.DBName
+	}
+
+	whereUsed := []dto.WhereUsed{}
+	for _, dependency := range dependencies[table] {
+		value := ID
+		if dependency.Reference != "" && dependency.Reference != primaryKey {
+			var reference string
+			if err := s.db.Table(table).Select(dependency.Reference).Where(primaryKey+" = ?", ID).Scan(&reference).Error; err != nil {
+				return nil, err
+			}
+			value = reference
+		}
+
+		query := func() *gorm.DB {
+			query := s.db.Table(dependency.Table).Where(dependency.Column+" = ?", value)
+			if isSoftDeleteTable(s.db, dependency.Table) {
+				query = query.Where("deleted_at IS NULL")
+			}
+			return query
+		}
+
+		var total int64
+		if err := query().Count(&total).Error; err != nil {
+			return nil, err
+		}
+
+		if total == 0 {
+			continue
+		}
+
+		key := dependency.Key
+		if key == "" {
+			key = "id"
+		}
+
+		columns := key + " AS id"
+		if dependency.Label != "" {
+			columns += ", CAST(" + dependency.Label + " AS VARCHAR) AS label"
+		}
+
+		var records []dto.WhereUsedRecord
+		if err := query().Select(columns).Order(key + " ASC").Limit(whereUsedLimit).Scan(&records).Error; err != nil {
+			return nil, err
+		}
+
+		whereUsed = append(whereUsed, dto.WhereUsed{
+			Table:   dependency.Table,
+			Column:  dependency.Column,
+			Total:   total,
+			Records: records,
+		})
+	}
+
+	return whereUsed, nil
+}
@@ -1,12 +1,15 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
+// EmployeeFilterFields are the fields the list accepts in filter[field][op].
+var EmployeeFilterFields = filter.Columns(&model.MstEmployee{})
+
 type EmployeeService struct {
 	db *gorm.DB
 }
@@ -24,7 +27,7 @@ func (s *EmployeeService) GetByNumber(number string) (*model.MstEmployee, error)
 	return &employee, nil
 }
 
-func (s *EmployeeService) GetTotal(search string) (int64, error) {
+func (s *EmployeeService) GetTotal(search string, filters []filter.Condition) (int64, error) {
 	var count int64
 
 	query := s.db.Model(&model.MstEmployee{})
@@ -33,6 +36,8 @@ func (s *EmployeeService) GetTotal(search string) (int64, error) {
 		query = query.Where("number ILIKE ? OR name ILIKE ? OR division ILIKE ? OR department ILIKE ?", "%"+search+"%", "%"+search+"%", "%"+search+"%", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
@@ -40,13 +45,13 @@ func (s *EmployeeService) GetTotal(search string) (int64, error) {
 	return count, nil
 }
 
-func (s *EmployeeService) GetAll(offset, limit int, search string, sortBy string, sortDirection bool) ([]model.MstEmployee, error) {
+func (s *EmployeeService) GetAll(offset, limit int, search string, sorts []filter.Order, filters []filter.Condition) ([]model.MstEmployee, error) {
 	var depts []model.MstEmployee
 
 	query := s.db.Model(&model.MstEmployee{}).Offset(offset).Limit(limit)
 
-	if sortBy != "" {
-		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: sortDirection})
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
 	} else {
 		query = query.Order("updated_at ASC")
 	}
@@ -55,6 +60,8 @@ func (s *EmployeeService) GetAll(offset, limit int, search string, sortBy string
 		query = query.Where("number ILIKE ? OR name ILIKE ? OR division ILIKE ? OR department ILIKE ?", "%"+search+"%", "%"+search+"%", "%"+search+"%", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&depts).Error; err != nil {
 		return nil, err
 	}
@@ -1,11 +1,20 @@
 package service
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
 )
 
+// FCSBuildingFilterFields are the fields the list accepts in filter[field][op].
+var FCSBuildingFilterFields = filter.Columns(&model.MstFCS{})
+
 type FCSBuildingService struct {
 	db *gorm.DB
 }
@@ -31,7 +40,7 @@ func (s *FCSBuildingService) GetByID(fcsID uint) (*model.MstFCS, error) {
 	return &fcsBuilding, nil
 }
 
-func (s *FCSBuildingService) GetTotal(search string) (int64, error) {
+func (s *FCSBuildingService) GetTotal(search string, filters []filter.Condition) (int64, error) {
 	var count int64
 
 	query := s.db.Model(&model.MstFCS{})
@@ -40,6 +49,8 @@ func (s *FCSBuildingService) GetTotal(search string) (int64, error) {
 		query = query.Where("code ILIKE ? OR description ILIKE ?", "%"+search+"%", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
@@ -47,7 +58,7 @@ func (s *FCSBuildingService) GetTotal(search string) (int64, error) {
 	return count, nil
 }
 
-func (s *FCSBuildingService) GetAll(offset, limit int, search string) ([]model.MstFCS, error) {
+func (s *FCSBuildingService) GetAll(offset, limit int, search string, filters []filter.Condition) ([]model.MstFCS, error) {
 	var fcsBuildings []model.MstFCS
 
 	query := s.db.Model(&model.MstFCS{}).Preload("FCSBuilding", func(db *gorm.DB) *gorm.DB {
@@ -64,6 +75,8 @@ func (s *FCSBuildingService) GetAll(offset, limit int, search string) ([]model.M
 		query = query.Where("code ILIKE ? OR description ILIKE ?", "%"+search+"%", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&fcsBuildings).Error; err != nil {
 		return nil, err
 	}
@@ -82,3 +95,95 @@ func (s *FCSBuildingService) Update(fcsBuilding *model.MstFCSBuilding) error {
 func (s *FCSBuildingService) Delete(fcsID uint) error {
 	return s.db.Where("id_fcs = ?", fcsID).Delete(&model.MstFCSBuilding{}).Error
 }
+
+// Replace sets the buildings of fcs in a transaction, so the old ones are
+// kept when the new ones cannot be saved. It moves the version of fcs so the
+// set is replaced only over the one it was read with, and returns ErrStale
+// when fcs was changed since.
+func (s *FCSBuildingService) Replace(fcs *model.MstFCS, buildingIDs []uint) ([]model.MstFCSBuilding, error) {
+	var fcsBuilding []model.MstFCSBuilding
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := Touch(tx, fcs); err != nil {
+			return err
+		}
+
+		var err error
+		fcsBuilding, err = replaceFCSBuilding(tx, fcs.ID, buildingIDs)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fcsBuilding, nil
+}
+
+// Bulk replaces the buildings of the FCS of every update operation, whose
+// data is a dto.FCSBuildings, and removes those of every delete operation, in
+// a single transaction. Updates need the etag the FCS was read with, deletes
+// check it when given.
+func (s *FCSBuildingService) Bulk(operations []dto.BulkOperation) ([]dto.BulkResult, error) {
+	return RunBulk(s.db, operations, func(tx *gorm.DB, operation dto.BulkOperation) (interface{}, error) {
+		if operation.Action != dto.BulkActionUpdate && operation.Action != dto.BulkActionDelete {
+			return nil, fmt.Errorf("invalid action %q, use update or delete", operation.Action)
+		}
+
+		var input dto.FCSBuildings
+		if operation.Action == dto.BulkActionUpdate {
+			if err := json.Unmarshal(operation.Data, &input); err != nil {
+				return operation.ID, err
+			}
+		}
+
+		var fcs model.MstFCS
+		if err := tx.Select("id", "updated_at").First(&fcs, operation.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return operation.ID, errors.New("FCS not found")
+			}
+			return operation.ID, err
+		}
+
+		if operation.Action == dto.BulkActionUpdate && operation.ETag == "" && etag.Of(&fcs) != "" {
+			return operation.ID, errors.New("etag is required, send the one the record was read with")
+		}
+
+		if operation.ETag != "" && !etag.Matches(operation.ETag, &fcs) {
+			return operation.ID, errors.New("the record was changed by someone else, reload it and try again")
+		}
+
+		if err := Touch(tx, &fcs); err != nil {
+			if errors.Is(err, ErrStale) {
+				return operation.ID, errors.New("the record was changed by someone else, reload it and try again")
+			}
+			return operation.ID, err
+		}
+
+		_, err := replaceFCSBuilding(tx, operation.ID, input.IDBuilding)
+		return operation.ID, err
+	})
+}
+
+func replaceFCSBuilding(tx *gorm.DB, fcsID uint, buildingIDs []uint) ([]model.MstFCSBuilding, error) {
+	var fcsBuilding []model.MstFCSBuilding
+	for _, buildingID := range buildingIDs {
+		fcsBuilding = append(fcsBuilding, model.MstFCSBuilding{
+			IDFCS:      fcsID,
+			IDBuilding: buildingID,
+		})
+	}
+
+	if err := tx.Where("id_fcs = ?", fcsID).Delete(&model.MstFCSBuilding{}).Error; err != nil {
+		return nil, err
+	}
+
+	if len(fcsBuilding) == 0 {
+		return fcsBuilding, nil
+	}
+
+	if err := tx.Create(&fcsBuilding).Error; err != nil {
+		return nil, err
+	}
+
+	return fcsBuilding, nil
+}
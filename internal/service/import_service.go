@@ -0,0 +1,285 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"insist-backend-golang/internal/filter"
+	"insist-backend-golang/internal/importer"
+	"insist-backend-golang/internal/model"
+	"io"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	ImportStatusRunning   = "running"
+	ImportStatusValidated = "validated"
+	ImportStatusFailed    = "failed"
+	ImportStatusCompleted = "completed"
+)
+
+// MachineStatusReasonKey is the key of the reasons a machine status is set
+// with.
+const MachineStatusReasonKey = "machine_status"
+
+var ErrUnknownImportMaster = errors.New("unknown import master")
+
+// ImportFilterFields are the fields the list accepts in filter[field][op].
+var ImportFilterFields = filter.Columns(&model.ImportJob{}).Without("errors")
+
+// ImportMasters are the master tables that can be imported, by the name used
+// in the import endpoints.
+var ImportMasters = map[string]importer.Importer{
+	"items": importer.Master[model.MstItem]{Fields: []importer.Column{
+		{Header: "Item Category", Field: "IDItemCategory", Required: true, Lookup: &importer.Lookup{Model: &model.MstItemCategory{}, Column: "code"}},
+		{Header: "Code", Field: "Code", Required: true, Unique: true, Example: "RM-0001"},
+		{Header: "Description", Field: "Description", Required: true, Example: "Round bar S45C"},
+		{Header: "UoM", Field: "IDUOM", Required: true, Lookup: &importer.Lookup{Model: &model.MstUoms{}, Column: "code"}},
+		{Header: "Infor Code", Field: "InforCode"},
+		{Header: "Infor Description", Field: "InforDescription"},
+		{Header: "Remarks", Field: "Remarks"},
+	}},
+	"item-raw-materials": importer.Master[model.MstItemRawMaterial]{Fields: []importer.Column{
+		{Header: "Item", Field: "IDItem", Required: true, Lookup: &importer.Lookup{Model: &model.MstItem{}, Column: "code"}},
+		{Header: "Item Product Type", Field: "IDItemProductType", Required: true, Lookup: &importer.Lookup{Model: &model.MstItemProductType{}, Column: "code"}},
+		{Header: "Item Group Type", Field: "IDItemGroupType", Required: true, Lookup: &importer.Lookup{Model: &model.MstItemGroupType{}, Column: "code"}},
+		{Header: "Item Process", Field: "IDItemProcess", Required: true, Lookup: &importer.Lookup{Model: &model.MstItemProcess{}, Column: "code"}},
+		{Header: "Item Surface", Field: "IDItemSurface", Required: true, Lookup: &importer.Lookup{Model: &model.MstItemSurface{}, Column: "code"}},
+		{Header: "Item Source", Field: "IDItemSource", Required: true, Lookup: &importer.Lookup{Model: &model.MstItemSource{}, Column: "code"}},
+		{Header: "Diameter Size", Field: "DiameterSize", Example: "25"},
+		{Header: "Length Size", Field: "LengthSize", Example: "6000"},
+		{Header: "Inner Diameter Size", Field: "InnerDiameterSize", Example: "0"},
+	}},
+	"locations": importer.Master[model.MstLocation]{Fields: []importer.Column{
+		{Header: "Warehouse", Field: "IDWarehouse", Required: true, Lookup: &importer.Lookup{Model: &model.MstWarehouse{}, Column: "code"}},
+		{Header: "Location", Field: "Location", Required: true, Example: "A-01-01"},
+		{Header: "Remarks", Field: "Remarks"},
+	}},
+	"currency-rates": importer.Master[model.MstCurrencyRate]{Fields: []importer.Column{
+		{Header: "From Currency", Field: "IDFromCurrency", Required: true, Lookup: &importer.Lookup{Model: &model.MstCurrency{}, Column: "currency"}},
+		{Header: "To Currency", Field: "IDToCurrency", Required: true, Lookup: &importer.Lookup{Model: &model.MstCurrency{}, Column: "currency"}},
+		{Header: "Buy Rate", Field: "BuyRate", Required: true},
+		{Header: "Sell Rate", Field: "SellRate", Required: true},
+		{Header: "Effective Date", Field: "EffectiveDate", Required: true},
+	}},
+	"machine-statuses": importer.Master[model.MstMachineStatus]{Fields: []importer.Column{
+		{Header: "Machine", Field: "IDMachine", Required: true, Lookup: &importer.Lookup{Model: &model.ViewMstMachine{}, Column: "code"}},
+		{Header: "Reason", Field: "IDReason", Required: true, Lookup: &importer.Lookup{Model: &model.MstReason{}, Column: "code", Where: map[string]interface{}{"key": MachineStatusReasonKey}}},
+		{Header: "Remarks", Field: "Remarks"},
+	}},
+}
+
+// ImportMenus are the menu paths of the masters in ImportMasters, whose create
+// permission an import requires. The jobs of a master are read by the users
+// whose roles include its menu.
+var ImportMenus = map[string]string{
+	"items":              "/general/master/item/generate",
+	"item-raw-materials": "/general/master/item/generate-raw-material",
+	"locations":          "/pid/master/location",
+	"currency-rates":     "/acf/master/currency-rate",
+	"machine-statuses":   "/mnt/master/machine",
+}
+
+type ImportService struct {
+	db *gorm.DB
+}
+
+func NewImportService(db *gorm.DB) *ImportService {
+	return &ImportService{db: db}
+}
+
+func (s *ImportService) GetByID(importID uint) (*model.ImportJob, error) {
+	var job model.ImportJob
+	if err := s.db.Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, name")
+	}).First(&job, importID).Error; err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// ReadableMasters are the masters of ImportMenus whose import jobs userID may
+// read.
+func (s *ImportService) ReadableMasters(userID uint) ([]string, error) {
+	var masters []string
+	for master := range ImportMenus {
+		ok, err := s.CanRead(userID, master)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			masters = append(masters, master)
+		}
+	}
+
+	return masters, nil
+}
+
+// CanRead tells whether userID may read the import jobs of master, which it
+// may when its roles include the menu of master in ImportMenus.
+func (s *ImportService) CanRead(userID uint, master string) (bool, error) {
+	path, ok := ImportMenus[master]
+	if !ok {
+		return false, nil
+	}
+
+	permission, err := NewRolePermissionService(s.db).GetPermission(userID, path)
+	if err != nil {
+		return false, err
+	}
+
+	return permission != nil, nil
+}
+
+// GetTotal counts the import jobs of masters, the ones the caller may read.
+func (s *ImportService) GetTotal(masters []string, master string, status string, filters []filter.Condition) (int64, error) {
+	var count int64
+
+	query := s.filter(s.db.Model(&model.ImportJob{}), masters, master, status)
+
+	query = filter.Apply(query, filters)
+
+	if err := query.Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetAll lists the import jobs of masters, the ones the caller may read.
+func (s *ImportService) GetAll(offset, limit int, masters []string, master string, status string, sorts []filter.Order, filters []filter.Condition) ([]model.ImportJob, error) {
+	var jobs []model.ImportJob
+
+	query := s.db.Model(&model.ImportJob{}).Omit("errors").Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, name")
+	}).Offset(offset).Limit(limit)
+
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
+	} else {
+		query = query.Order("created_at DESC").Order("id DESC")
+	}
+
+	query = s.filter(query, masters, master, status)
+
+	query = filter.Apply(query, filters)
+
+	if err := query.Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// Import reads the file into the master, recording the run as an import job.
+// A file that cannot be read fails the job without an error, other errors
+// are returned together with the failed job.
+func (s *ImportService) Import(master string, fileName string, file io.Reader, mapping map[string]string, dryRun bool, userID uint) (*model.ImportJob, error) {
+	masterImporter, ok := ImportMasters[master]
+	if !ok {
+		return nil, ErrUnknownImportMaster
+	}
+
+	job := model.ImportJob{
+		Master:      master,
+		FileName:    fileName,
+		Status:      ImportStatusRunning,
+		DryRun:      dryRun,
+		IDCreatedby: userID,
+	}
+
+	if err := s.db.Create(&job).Error; err != nil {
+		return nil, err
+	}
+
+	result, runErr := s.run(masterImporter, fileName, file, mapping, dryRun, userID)
+
+	now := time.Now()
+	job.FinishedAt = &now
+	job.TotalRows = result.TotalRows
+	job.ValidRows = result.ValidRows
+	job.ErrorRows = result.ErrorRows
+	job.ImportedRows = result.ImportedRows
+
+	switch {
+	case runErr != nil:
+		job.Status = ImportStatusFailed
+		job.Message = runErr.Error()
+	case len(result.Errors) > 0:
+		job.Status = ImportStatusFailed
+		job.Message = "The file has errors, nothing was imported"
+	case dryRun:
+		job.Status = ImportStatusValidated
+	default:
+		job.Status = ImportStatusCompleted
+	}
+
+	if len(result.Errors) > 0 {
+		errs, err := json.Marshal(result.Errors)
+		if err != nil {
+			return nil, err
+		}
+		job.Errors = errs
+	}
+
+	if err := s.db.Save(&job).Error; err != nil {
+		return nil, err
+	}
+
+	if runErr != nil && !errors.Is(runErr, importer.ErrInvalidFile) {
+		return &job, runErr
+	}
+
+	return &job, nil
+}
+
+// Template builds the xlsx template of the import of master.
+func (s *ImportService) Template(master string) (*excelize.File, error) {
+	masterImporter, ok := ImportMasters[master]
+	if !ok {
+		return nil, ErrUnknownImportMaster
+	}
+
+	return masterImporter.Template(s.db)
+}
+
+// GetErrors lists the errors of an import job, for the error report.
+func (s *ImportService) GetErrors(job *model.ImportJob) ([]importer.RowError, error) {
+	var errs []importer.RowError
+	if len(job.Errors) == 0 {
+		return errs, nil
+	}
+
+	if err := json.Unmarshal(job.Errors, &errs); err != nil {
+		return nil, err
+	}
+
+	return errs, nil
+}
+
+func (s *ImportService) run(masterImporter importer.Importer, fileName string, file io.Reader, mapping map[string]string, dryRun bool, userID uint) (importer.Result, error) {
+	rows, err := importer.Read(fileName, file)
+	if err != nil {
+		return importer.Result{}, err
+	}
+
+	return masterImporter.Import(s.db, rows, mapping, userID, dryRun)
+}
+
+func (s *ImportService) filter(query *gorm.DB, masters []string, master string, status string) *gorm.DB {
+	query = query.Where("master IN ?", masters)
+
+	if master != "" {
+		query = query.Where("master = ?", master)
+	}
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	return query
+}
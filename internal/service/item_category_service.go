@@ -1,12 +1,15 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
+// ItemCategoryFilterFields are the fields the list accepts in filter[field][op].
+var ItemCategoryFilterFields = filter.Columns(&model.MstItemCategory{})
+
 type ItemCategoryService struct {
 	db *gorm.DB
 }
@@ -41,15 +44,17 @@ func (s *ItemCategoryService) GetByCode(code string) (*model.MstItemCategory, er
 	return &itemCategory, nil
 }
 
-func (s *ItemCategoryService) GetTotal(search string) (int64, error) {
+func (s *ItemCategoryService) GetTotal(search string, includeDeleted bool, filters []filter.Condition) (int64, error) {
 	var count int64
 
-	query := s.db.Model(&model.MstItemCategory{})
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstItemCategory{})
 
 	if search != "" {
 		query = query.Where("code ILIKE ? OR description ILIKE ?", "%"+search+"%", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
@@ -57,17 +62,17 @@ func (s *ItemCategoryService) GetTotal(search string) (int64, error) {
 	return count, nil
 }
 
-func (s *ItemCategoryService) GetAll(offset, limit int, search string, sortBy string, sortDirection bool) ([]model.MstItemCategory, error) {
+func (s *ItemCategoryService) GetAll(offset, limit int, search string, sorts []filter.Order, includeDeleted bool, filters []filter.Condition) ([]model.MstItemCategory, error) {
 	var itemCategorys []model.MstItemCategory
 
-	query := s.db.Model(&model.MstItemCategory{}).Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstItemCategory{}).Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, name")
 	}).Preload("UpdatedBy", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, name")
 	}).Offset(offset).Limit(limit)
 
-	if sortBy != "" {
-		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: sortDirection})
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
 	} else {
 		query = query.Order("code ASC")
 	}
@@ -76,6 +81,8 @@ func (s *ItemCategoryService) GetAll(offset, limit int, search string, sortBy st
 		query = query.Where("code ILIKE ? OR description ILIKE ?", "%"+search+"%", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&itemCategorys).Error; err != nil {
 		return nil, err
 	}
@@ -88,9 +95,9 @@ func (s *ItemCategoryService) Create(itemCategory *model.MstItemCategory) error
 }
 
 func (s *ItemCategoryService) Update(itemCategory *model.MstItemCategory) error {
-	return s.db.Save(itemCategory).Error
+	return SaveVersion(s.db, itemCategory)
 }
 
 func (s *ItemCategoryService) Delete(itemCategory *model.MstItemCategory) error {
-	return s.db.Delete(itemCategory).Error
+	return SoftDelete(s.db, itemCategory, itemCategory.IDUpdatedby)
 }
@@ -3,10 +3,20 @@ package service
 import (
 	"fmt"
 	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/filter"
+	"strings"
 
 	"gorm.io/gorm"
 )
 
+// ItemInforSortFields are the columns of the Infor item list accepted in
+// sortBy. They end up in raw SQL, so nothing else may be sorted on.
+var ItemInforSortFields = filter.Fields{
+	"code":        "code",
+	"description": "description",
+	"uom":         "uom",
+}
+
 type ItemInforService struct {
 	db *gorm.DB
 }
@@ -57,16 +67,19 @@ func (s *ItemInforService) GetTotal(search string) (int64, error) {
 	return count, nil
 }
 
-func (s *ItemInforService) GetAll(offset, limit int, search, sortBy string, sortAsc bool) ([]dto.ItemInforDTO, error) {
+func (s *ItemInforService) GetAll(offset, limit int, search string, sorts []filter.Order) ([]dto.ItemInforDTO, error) {
 	var items []dto.ItemInforDTO
 
-	sortColumn := "code"
-	if sortBy != "" {
-		sortColumn = sortBy
-	}
-	sortDirection := "ASC"
-	if !sortAsc {
-		sortDirection = "DESC"
+	orderBy := "code ASC"
+	if len(sorts) > 0 {
+		columns := make([]string, len(sorts))
+		for i, sort := range sorts {
+			columns[i] = sort.Column + " ASC"
+			if sort.Desc {
+				columns[i] = sort.Column + " DESC"
+			}
+		}
+		orderBy = strings.Join(columns, ", ")
 	}
 
 	var raw string
@@ -86,9 +99,9 @@ func (s *ItemInforService) GetAll(offset, limit int, search, sortBy string, sort
 				FROM non_inventory_item_mst
 				WHERE LOWER(item) LIKE LOWER(?) OR LOWER(Uf_description2) LIKE LOWER(?)
 			) AS item_infor
-			ORDER BY %s %s
+			ORDER BY %s
 			OFFSET ? ROWS FETCH NEXT ? ROWS ONLY
-		`, sortColumn, sortDirection)
+		`, orderBy)
 		args = []interface{}{search, search, search, search, offset, limit}
 	} else {
 		raw = fmt.Sprintf(`
@@ -101,9 +114,9 @@ func (s *ItemInforService) GetAll(offset, limit int, search, sortBy string, sort
 				SELECT item AS code, Uf_description2 AS description, u_m AS uom
 				FROM non_inventory_item_mst
 			) AS item_infor
-			ORDER BY %s %s
+			ORDER BY %s
 			OFFSET ? ROWS FETCH NEXT ? ROWS ONLY
-		`, sortColumn, sortDirection)
+		`, orderBy)
 		args = []interface{}{offset, limit}
 	}
 
@@ -1,12 +1,15 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
+// ItemRawMaterialFilterFields are the fields the list accepts in filter[field][op].
+var ItemRawMaterialFilterFields = filter.Columns(&model.MstItemRawMaterial{})
+
 type ItemRawMaterialService struct {
 	db *gorm.DB
 }
@@ -36,9 +39,9 @@ func (s *ItemRawMaterialService) GetByID(id uint) (*model.MstItemRawMaterial, er
 	return &itemRawMaterial, nil
 }
 
-func (s *ItemRawMaterialService) GetTotal(search, categoryCode string) (int64, error) {
+func (s *ItemRawMaterialService) GetTotal(search, categoryCode string, includeDeleted bool, filters []filter.Condition) (int64, error) {
 	var count int64
-	query := s.db.Model(&model.MstItemRawMaterial{}).
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstItemRawMaterial{}).
 		Joins("JOIN mst_items ON mst_items.id = mst_item_raw_materials.id_item").
 		Joins("JOIN mst_item_categories ON mst_items.id_item_category = mst_item_categories.id")
 
@@ -50,16 +53,18 @@ func (s *ItemRawMaterialService) GetTotal(search, categoryCode string) (int64, e
 		query = query.Where("mst_item_categories.code = ?", categoryCode)
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
-func (s *ItemRawMaterialService) GetAll(offset, limit int, search, categoryCode, sortBy string, sortAsc bool) ([]model.MstItemRawMaterial, error) {
+func (s *ItemRawMaterialService) GetAll(offset, limit int, search, categoryCode string, sorts []filter.Order, includeDeleted bool, filters []filter.Condition) ([]model.MstItemRawMaterial, error) {
 	var itemRawMaterials []model.MstItemRawMaterial
 
-	query := s.db.Model(&model.MstItemRawMaterial{}).
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstItemRawMaterial{}).
 		Joins("JOIN mst_items ON mst_items.id = mst_item_raw_materials.id_item").
 		Joins("JOIN mst_item_categories ON mst_items.id_item_category = mst_item_categories.id").
 		Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
@@ -79,8 +84,8 @@ func (s *ItemRawMaterialService) GetAll(offset, limit int, search, categoryCode,
 		Preload("ItemSource").
 		Offset(offset).Limit(limit)
 
-	if sortBy != "" {
-		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: !sortAsc})
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
 	} else {
 		query = query.Order("mst_items.code ASC")
 	}
@@ -93,6 +98,8 @@ func (s *ItemRawMaterialService) GetAll(offset, limit int, search, categoryCode,
 		query = query.Where("mst_item_categories.code = ?", categoryCode)
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&itemRawMaterials).Error; err != nil {
 		return nil, err
 	}
@@ -104,11 +111,9 @@ func (s *ItemRawMaterialService) Create(itemRawMaterial *model.MstItemRawMateria
 }
 
 func (s *ItemRawMaterialService) Update(itemRawMaterial *model.MstItemRawMaterial) error {
-	return s.db.Model(&model.MstItemRawMaterial{}).
-		Where("id = ?", itemRawMaterial.ID).
-		Updates(itemRawMaterial).Error
+	return SaveVersion(s.db, itemRawMaterial)
 }
 
 func (s *ItemRawMaterialService) Delete(itemRawMaterial *model.MstItemRawMaterial) error {
-	return s.db.Delete(itemRawMaterial).Error
+	return SoftDelete(s.db, itemRawMaterial, itemRawMaterial.IDUpdatedby)
 }
@@ -1,12 +1,17 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
+// ItemFilterFields are the fields the list accepts in filter[field][op].
+var ItemFilterFields = filter.Columns(&model.MstItem{}).With(filter.Fields{
+	"category_code": "mst_item_categories.code",
+})
+
 type ItemService struct {
 	db *gorm.DB
 }
@@ -29,9 +34,9 @@ func (s *ItemService) GetByID(id uint) (*model.MstItem, error) {
 	return &item, nil
 }
 
-func (s *ItemService) GetTotal(search string, idItemCategory uint, categoryCode string) (int64, error) {
+func (s *ItemService) GetTotal(search string, idItemCategory uint, categoryCode string, includeDeleted bool, filters []filter.Condition) (int64, error) {
 	var count int64
-	query := s.db.Model(&model.MstItem{}).Joins("LEFT JOIN mst_item_categories ON mst_item_categories.id = mst_items.id_item_category")
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstItem{}).Joins("LEFT JOIN mst_item_categories ON mst_item_categories.id = mst_items.id_item_category")
 
 	if search != "" {
 		query = query.Where("mst_items.code ILIKE ? OR mst_items.description ILIKE ?", "%"+search+"%", "%"+search+"%")
@@ -43,16 +48,18 @@ func (s *ItemService) GetTotal(search string, idItemCategory uint, categoryCode
 		query = query.Where("mst_item_categories.code = ?", categoryCode)
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
-func (s *ItemService) GetAll(offset, limit int, search, sortBy string, sortAsc bool, idItemCategory uint, categoryCode string) ([]model.MstItem, error) {
+func (s *ItemService) GetAll(offset, limit int, search string, sorts []filter.Order, idItemCategory uint, categoryCode string, includeDeleted bool, filters []filter.Condition) ([]model.MstItem, error) {
 	var items []model.MstItem
 
-	query := s.db.Model(&model.MstItem{}).
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstItem{}).
 		Joins("LEFT JOIN mst_item_categories ON mst_item_categories.id = mst_items.id_item_category").
 		Preload("ItemCategory").
 		Preload("UOM").
@@ -62,8 +69,8 @@ func (s *ItemService) GetAll(offset, limit int, search, sortBy string, sortAsc b
 		return db.Select("id, name")
 	}).Offset(offset).Limit(limit)
 
-	if sortBy != "" {
-		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: !sortAsc})
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
 	}
 
 	if search != "" {
@@ -80,6 +87,8 @@ func (s *ItemService) GetAll(offset, limit int, search, sortBy string, sortAsc b
 		query = query.Where("mst_item_categories.code = ?", categoryCode)
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&items).Error; err != nil {
 		return nil, err
 	}
@@ -91,9 +100,9 @@ func (s *ItemService) Create(item *model.MstItem) error {
 }
 
 func (s *ItemService) Update(item *model.MstItem) error {
-	return s.db.Save(item).Error
+	return SaveVersion(s.db, item)
 }
 
 func (s *ItemService) Delete(item *model.MstItem) error {
-	return s.db.Delete(item).Error
+	return SoftDelete(s.db, item, item.IDUpdatedby)
 }
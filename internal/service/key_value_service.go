@@ -1,11 +1,15 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
 )
 
+// KeyValueFilterFields are the fields the list accepts in filter[field][op].
+var KeyValueFilterFields = filter.Columns(&model.MstKeyValue{})
+
 type KeyValueService struct {
 	db *gorm.DB
 }
@@ -27,15 +31,17 @@ func (s *KeyValueService) GetByID(keyValueID uint) (*model.MstKeyValue, error) {
 	return &keyValue, nil
 }
 
-func (s *KeyValueService) GetTotal(search string) (int64, error) {
+func (s *KeyValueService) GetTotal(search string, includeDeleted bool, filters []filter.Condition) (int64, error) {
 	var count int64
 
-	query := s.db.Model(&model.MstKeyValue{})
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstKeyValue{})
 
 	if search != "" {
 		query = query.Where("key ILIKE ? OR value ILIKE ?", "%"+search+"%", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
@@ -43,10 +49,10 @@ func (s *KeyValueService) GetTotal(search string) (int64, error) {
 	return count, nil
 }
 
-func (s *KeyValueService) GetAll(offset, limit int, search string) ([]model.MstKeyValue, error) {
+func (s *KeyValueService) GetAll(offset, limit int, search string, includeDeleted bool, filters []filter.Condition) ([]model.MstKeyValue, error) {
 	var keyValues []model.MstKeyValue
 
-	query := s.db.Model(&model.MstKeyValue{}).Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstKeyValue{}).Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, name")
 	}).Preload("UpdatedBy", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, name")
@@ -56,6 +62,8 @@ func (s *KeyValueService) GetAll(offset, limit int, search string) ([]model.MstK
 		query = query.Where("key ILIKE ? OR value ILIKE ?", "%"+search+"%", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&keyValues).Error; err != nil {
 		return nil, err
 	}
@@ -68,9 +76,9 @@ func (s *KeyValueService) Create(keyValue *model.MstKeyValue) error {
 }
 
 func (s *KeyValueService) Update(keyValue *model.MstKeyValue) error {
-	return s.db.Save(keyValue).Error
+	return SaveVersion(s.db, keyValue)
 }
 
 func (s *KeyValueService) Delete(keyValue *model.MstKeyValue) error {
-	return s.db.Delete(keyValue).Error
+	return SoftDelete(s.db, keyValue, keyValue.IDUpdatedby)
 }
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"insist-backend-golang/internal/model"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	LoginThrottleScopeUsername = "username"
+	LoginThrottleScopeIP       = "ip"
+)
+
+const (
+	loginMaxUsernameFailures = 5
+	loginMaxIPFailures       = 20
+	loginBackoffAfter        = 3
+	loginLockoutDuration     = 15 * time.Minute
+	loginFailureWindow       = time.Hour
+)
+
+type LoginThrottleService struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleService(db *gorm.DB) *LoginThrottleService {
+	return &LoginThrottleService{db: db}
+}
+
+// Attempt runs attempt, which validates the credentials of username and
+// tells whether they were valid, while holding the counters of username and
+// ipAddress, so parallel attempts are checked and counted one after the
+// other. When either is locked it returns how long the caller has to wait
+// without running attempt. An invalid attempt is counted as a failure; a valid
+// one is left to Succeed, as a login may still need its second factor.
+func (s *LoginThrottleService) Attempt(username string, ipAddress string, attempt func() (bool, error)) (time.Duration, bool, error) {
+	var wait time.Duration
+	var valid bool
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		usernameThrottle, err := s.lock(tx, LoginThrottleScopeUsername, normalizeUsername(username))
+		if err != nil {
+			return err
+		}
+
+		ipThrottle, err := s.lock(tx, LoginThrottleScopeIP, ipAddress)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, throttle := range []*model.LoginThrottle{usernameThrottle, ipThrottle} {
+			if throttle != nil && throttle.LockedUntil != nil && throttle.LockedUntil.After(now) {
+				if remaining := throttle.LockedUntil.Sub(now); remaining > wait {
+					wait = remaining
+				}
+			}
+		}
+
+		if wait > 0 {
+			return nil
+		}
+
+		valid, err = attempt()
+		if err != nil || valid {
+			return err
+		}
+
+		if err := s.fail(tx, usernameThrottle, loginMaxUsernameFailures); err != nil {
+			return err
+		}
+
+		return s.fail(tx, ipThrottle, loginMaxIPFailures)
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	return wait, valid, nil
+}
+
+// Fail counts a failed attempt that did not go through Attempt, like an
+// unknown two-factor challenge.
+func (s *LoginThrottleService) Fail(username string, ipAddress string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		usernameThrottle, err := s.lock(tx, LoginThrottleScopeUsername, normalizeUsername(username))
+		if err != nil {
+			return err
+		}
+
+		ipThrottle, err := s.lock(tx, LoginThrottleScopeIP, ipAddress)
+		if err != nil {
+			return err
+		}
+
+		if err := s.fail(tx, usernameThrottle, loginMaxUsernameFailures); err != nil {
+			return err
+		}
+
+		return s.fail(tx, ipThrottle, loginMaxIPFailures)
+	})
+}
+
+// Succeed clears the username counter. The IP counter is left to expire on
+// its own so one valid account cannot be used to reset it.
+func (s *LoginThrottleService) Succeed(username string) error {
+	return s.Unlock(username)
+}
+
+func (s *LoginThrottleService) Unlock(username string) error {
+	return s.db.Where("scope = ? AND key = ?", LoginThrottleScopeUsername, normalizeUsername(username)).
+		Delete(&model.LoginThrottle{}).Error
+}
+
+func (s *LoginThrottleService) GetLockedUntil(username string) (*time.Time, error) {
+	var throttle model.LoginThrottle
+	if err := s.db.Where("scope = ? AND key = ? AND locked_until > ?", LoginThrottleScopeUsername, normalizeUsername(username), time.Now()).
+		Limit(1).Find(&throttle).Error; err != nil {
+		return nil, err
+	}
+
+	return throttle.LockedUntil, nil
+}
+
+// lock loads the counter of key, creating it, and locks it until tx ends. The
+// counter of an empty key is nil.
+func (s *LoginThrottleService) lock(tx *gorm.DB, scope string, key string) (*model.LoginThrottle, error) {
+	if key == "" {
+		return nil, nil
+	}
+
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.LoginThrottle{Scope: scope, Key: key}).Error; err != nil {
+		return nil, err
+	}
+
+	var throttle model.LoginThrottle
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("scope = ? AND key = ?", scope, key).First(&throttle).Error; err != nil {
+		return nil, err
+	}
+
+	return &throttle, nil
+}
+
+func (s *LoginThrottleService) fail(tx *gorm.DB, throttle *model.LoginThrottle, maxFailures int) error {
+	if throttle == nil {
+		return nil
+	}
+
+	now := time.Now()
+	if throttle.LastFailedAt != nil && now.Sub(*throttle.LastFailedAt) > loginFailureWindow {
+		throttle.Failures = 0
+	}
+
+	throttle.Failures++
+	throttle.LastFailedAt = &now
+
+	var lockedUntil *time.Time
+	if wait := loginBackoff(throttle.Failures, maxFailures); wait > 0 {
+		until := now.Add(wait)
+		lockedUntil = &until
+	}
+
+	return tx.Model(throttle).Updates(map[string]interface{}{
+		"failures":       throttle.Failures,
+		"last_failed_at": now,
+		"locked_until":   lockedUntil,
+	}).Error
+}
+
+// loginBackoff doubles the wait for every failure past loginBackoffAfter and
+// locks the key out completely once maxFailures is reached.
+func loginBackoff(failures int, maxFailures int) time.Duration {
+	if failures >= maxFailures {
+		return loginLockoutDuration
+	}
+
+	if failures < loginBackoffAfter {
+		return 0
+	}
+
+	wait := time.Second << (failures - loginBackoffAfter)
+	if wait > loginLockoutDuration {
+		return loginLockoutDuration
+	}
+
+	return wait
+}
+
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
@@ -1,12 +1,82 @@
 package service
 
 import (
+	"errors"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
+	"reflect"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
+const MachineApprovalRefTable = "mst_machine_details"
+
+// deletedMachines is used to filter the machine views, which do not know about
+// soft deleted machines.
+const deletedMachines = "SELECT id FROM mst_machines WHERE deleted_at IS NOT NULL"
+
+// MachineFilterFields are the fields the list accepts in filter[field][op].
+var MachineFilterFields = filter.Columns(&model.ViewMstMachine{})
+
+// MachineDetailFilterFields are the fields the revision list accepts in sortBy.
+var MachineDetailFilterFields = filter.Columns(&model.ViewMstMachineDetail{})
+
+// MachineStatusFilterFields are the fields the status list accepts in sortBy.
+var MachineStatusFilterFields = filter.Columns(&model.MstMachineStatus{})
+
+// A revision is a draft until it is submitted for approval and released once
+// approved; only released revisions are current. Returned and cancelled
+// approvals put it back to draft.
+const (
+	MachineRevisionDraft    = "draft"
+	MachineRevisionPending  = "pending"
+	MachineRevisionReleased = "released"
+	MachineRevisionRejected = "rejected"
+)
+
+var (
+	ErrMachineRevisionNotFound   = errors.New("machine revision not found")
+	ErrMachineRevisionNotDraft   = errors.New("only a draft revision can be changed")
+	ErrMachineRevisionNotAuthor  = errors.New("only the author of a draft revision can change it")
+	ErrMachineRevisionUnreleased = errors.New("machine already has a revision that is not released")
+)
+
+// MachineMenuPath is the menu of machines, whose approval chain revisions
+// follow.
+const MachineMenuPath = "/mnt/master/machine"
+
+func init() {
+	RegisterApprovalMenu(MachineApprovalRefTable, MachineMenuPath)
+	RegisterApprovalHook(MachineApprovalRefTable, updateMachineRevisionState)
+}
+
+// machineRevisionFields are the fields of a revision compared by
+// DiffRevisions, in the order of the form. UoMs and the building are compared
+// by code.
+var machineRevisionFields = []string{
+	"Code", "CodeOld", "AssetNum", "AssetNumOld", "Description", "Name", "Maker",
+	"Power", "PowerUOM",
+	"Electricity", "ElectricityUOM",
+	"Cavity",
+	"Lubricant", "LubricantCapacity", "LubricantUOM",
+	"Sliding", "SlidingCapacity", "SlidingUOM",
+	"Coolant", "CoolantCapacity", "CoolantUOM",
+	"Hydraulic", "HydraulicCapacity", "HydraulicUOM",
+	"DimensionFront", "DimensionFrontUOM",
+	"DimensionSide", "DimensionSideUOM",
+	"Building",
+}
+
+// machineDetailLookups are the UoM and building relations of a revision.
+var machineDetailLookups = []string{
+	"PowerUOM", "ElectricityUOM", "LubricantUOM", "SlidingUOM",
+	"CoolantUOM", "HydraulicUOM", "DimensionFrontUOM", "DimensionSideUOM",
+	"Building",
+}
+
 type MachineService struct {
 	db *gorm.DB
 }
@@ -21,17 +91,21 @@ func (s *MachineService) BeginTx() *gorm.DB {
 
 func (s *MachineService) GetByID(machineID uint) (*model.ViewMstMachine, error) {
 	var machine model.ViewMstMachine
-	if err := s.db.First(&machine, machineID).Error; err != nil {
+	if err := s.db.Where("id NOT IN ("+deletedMachines+")").First(&machine, machineID).Error; err != nil {
 		return nil, err
 	}
 	return &machine, nil
 }
 
-func (s *MachineService) GetTotal(search string, reasonID uint, approval string) (int64, error) {
+func (s *MachineService) GetTotal(search string, reasonID uint, approval string, includeDeleted bool, filters []filter.Condition) (int64, error) {
 	var count int64
 
 	query := s.db.Model(&model.ViewMstMachine{})
 
+	if !includeDeleted {
+		query = query.Where("id NOT IN (" + deletedMachines + ")")
+	}
+
 	if reasonID != 0 {
 		query = query.Where("id_reason = ?", reasonID)
 	}
@@ -48,6 +122,8 @@ func (s *MachineService) GetTotal(search string, reasonID uint, approval string)
 		query = query.Where("code ILIKE ? OR description ILIKE ? OR name ILIKE ?", "%"+search+"%", "%"+search+"%", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
@@ -55,13 +131,17 @@ func (s *MachineService) GetTotal(search string, reasonID uint, approval string)
 	return count, nil
 }
 
-func (s *MachineService) GetAll(offset, limit int, search string, reasonID uint, approval string, sortBy string, sortDirection bool) ([]model.ViewMstMachine, error) {
+func (s *MachineService) GetAll(offset, limit int, search string, reasonID uint, approval string, sorts []filter.Order, includeDeleted bool, filters []filter.Condition) ([]model.ViewMstMachine, error) {
 	var machines []model.ViewMstMachine
 
 	query := s.db.Model(&model.ViewMstMachine{}).Offset(offset).Limit(limit)
 
-	if sortBy != "" {
-		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: sortDirection})
+	if !includeDeleted {
+		query = query.Where("id NOT IN (" + deletedMachines + ")")
+	}
+
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
 	} else {
 		query = query.Order("machine_updated_at ASC")
 	}
@@ -82,6 +162,8 @@ func (s *MachineService) GetAll(offset, limit int, search string, reasonID uint,
 		query = query.Where("code ILIKE ? OR description ILIKE ? OR name ILIKE ?", "%"+search+"%", "%"+search+"%", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&machines).Error; err != nil {
 		return nil, err
 	}
@@ -97,8 +179,8 @@ func (s *MachineService) Update(machine *model.MstMachine) error {
 	return s.db.Save(machine).Error
 }
 
-func (s *MachineService) Delete(machineID uint) error {
-	return s.db.Where("id = ?", machineID).Delete(&model.MstMachine{}).Error
+func (s *MachineService) Delete(machineID uint, userID uint) error {
+	return SoftDelete(s.db, &model.MstMachine{ID: machineID, IDUpdatedby: userID}, userID)
 }
 
 func (s *MachineService) GetTotalDetail(machineID uint) (int64, error) {
@@ -113,7 +195,7 @@ func (s *MachineService) GetTotalDetail(machineID uint) (int64, error) {
 	return count, nil
 }
 
-func (s *MachineService) GetAllDetail(offset, limit int, sortBy string, sortDirection bool, machineID uint) ([]model.ViewMstMachineDetail, error) {
+func (s *MachineService) GetAllDetail(offset, limit int, sorts []filter.Order, machineID uint) ([]model.ViewMstMachineDetail, error) {
 	var machineDetails []model.ViewMstMachineDetail
 
 	query := s.db.Model(&model.ViewMstMachineDetail{}).
@@ -121,8 +203,8 @@ func (s *MachineService) GetAllDetail(offset, limit int, sortBy string, sortDire
 		Offset(offset).
 		Limit(limit)
 
-	if sortBy != "" {
-		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: sortDirection})
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
 	} else {
 		query = query.Order("detail_updated_at ASC")
 	}
@@ -155,7 +237,7 @@ func (s *MachineService) CreateDetail(machineDetail *model.MstMachineDetail) err
 }
 
 func (s *MachineService) UpdateDetail(machineDetail *model.MstMachineDetail) error {
-	return s.db.Save(machineDetail).Error
+	return SaveVersion(s.db, machineDetail)
 }
 
 func (s *MachineService) DeleteDetail(machineDetailID uint) error {
@@ -174,7 +256,7 @@ func (s *MachineService) GetTotalStatus(machineID uint) (int64, error) {
 	return count, nil
 }
 
-func (s *MachineService) GetAllStatus(offset, limit int, sortBy string, sortDirection bool, machineID uint) ([]model.MstMachineStatus, error) {
+func (s *MachineService) GetAllStatus(offset, limit int, sorts []filter.Order, machineID uint) ([]model.MstMachineStatus, error) {
 	var machineStatus []model.MstMachineStatus
 
 	query := s.db.Model(&model.MstMachineStatus{}).Preload("Reason", func(db *gorm.DB) *gorm.DB {
@@ -187,8 +269,8 @@ func (s *MachineService) GetAllStatus(offset, limit int, sortBy string, sortDire
 		Offset(offset).
 		Limit(limit)
 
-	if sortBy != "" {
-		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: sortDirection})
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
 	} else {
 		query = query.Order("updated_at DESC")
 	}
@@ -217,3 +299,239 @@ func (s *MachineService) CreateStatus(machineStatus *model.MstMachineStatus) err
 func (s *MachineService) DeleteStatus(machineID uint) error {
 	return s.db.Where("id_machine = ?", machineID).Delete(&model.MstMachineStatus{}).Error
 }
+
+// GetDetailByRevNo finds a revision of a machine with its UoMs and building.
+func (s *MachineService) GetDetailByRevNo(machineID uint, revNo int) (*model.MstMachineDetail, error) {
+	var machineDetail model.MstMachineDetail
+	err := s.preloadLookups(s.db).Where("id_machine = ? AND rev_no = ?", machineID, revNo).First(&machineDetail).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMachineRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &machineDetail, nil
+}
+
+// GetLatestRevNo is the number of the newest revision of a machine.
+func (s *MachineService) GetLatestRevNo(machineID uint) (int, error) {
+	var revNo *int
+	if err := s.db.Model(&model.MstMachineDetail{}).Where("id_machine = ?", machineID).Select("MAX(rev_no)").Scan(&revNo).Error; err != nil {
+		return 0, err
+	}
+
+	if revNo == nil {
+		return 0, ErrMachineRevisionNotFound
+	}
+
+	return *revNo, nil
+}
+
+// DiffRevisions compares two revisions of a machine field by field.
+func (s *MachineService) DiffRevisions(machineID uint, fromRevNo int, toRevNo int) (*dto.MachineRevisionDiff, error) {
+	from, err := s.GetDetailByRevNo(machineID, fromRevNo)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := s.GetDetailByRevNo(machineID, toRevNo)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := dto.MachineRevisionDiff{
+		IDMachine: machineID,
+		From:      dto.MachineRevision{ID: from.ID, RevNo: from.RevNo, CreatedAt: from.CreatedAt},
+		To:        dto.MachineRevision{ID: to.ID, RevNo: to.RevNo, CreatedAt: to.CreatedAt},
+		Changes:   []dto.MachineRevisionChange{},
+	}
+
+	fromValue := reflect.ValueOf(from).Elem()
+	toValue := reflect.ValueOf(to).Elem()
+	detailType := fromValue.Type()
+
+	for _, name := range machineRevisionFields {
+		field, _ := detailType.FieldByName(name)
+
+		fromField := revisionValue(fromValue.FieldByName(name))
+		toField := revisionValue(toValue.FieldByName(name))
+
+		if reflect.DeepEqual(fromField, toField) {
+			continue
+		}
+
+		diff.Changes = append(diff.Changes, dto.MachineRevisionChange{
+			Field: strings.SplitN(field.Tag.Get("json"), ",", 2)[0],
+			From:  fromField,
+			To:    toField,
+		})
+	}
+
+	return &diff, nil
+}
+
+// GetDetailAsOf finds the revision of a machine in effect at asOf, the
+// newest one released by then.
+func (s *MachineService) GetDetailAsOf(machineID uint, asOf time.Time) (*model.MstMachineDetail, error) {
+	var machineDetail model.MstMachineDetail
+	err := s.preloadLookups(s.db).
+		Where("id_machine = ? AND state = ? AND released_at <= ?", machineID, MachineRevisionReleased, asOf).
+		Order("rev_no DESC").
+		First(&machineDetail).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMachineRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &machineDetail, nil
+}
+
+// GetStatusAsOf finds the status of a machine in effect at asOf, the last one
+// set by then, or nil when none was.
+func (s *MachineService) GetStatusAsOf(machineID uint, asOf time.Time) (*model.MstMachineStatus, error) {
+	var machineStatus model.MstMachineStatus
+	err := s.db.Preload("Reason", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, key, code, description")
+	}).
+		Where("id_machine = ? AND created_at <= ?", machineID, asOf).
+		Order("created_at DESC").
+		Order("id DESC").
+		First(&machineStatus).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &machineStatus, nil
+}
+
+// CreateRevision starts a draft revision of a machine as a copy of
+// machineDetail, numbered after the newest revision. A machine has at most
+// one draft or pending revision at a time.
+func (s *MachineService) CreateRevision(machineDetail *model.MstMachineDetail, userID uint) (*model.MstMachineDetail, error) {
+	revision := *machineDetail
+	revision.ID = 0
+	revision.State = MachineRevisionDraft
+	revision.ReleasedAt = nil
+	revision.IDCreatedby = userID
+	revision.IDUpdatedby = userID
+	revision.CreatedAt = nil
+	revision.UpdatedAt = nil
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?), ?)", "mst_machines", machineDetail.IDMachine).Error; err != nil {
+			return err
+		}
+
+		var unreleased int64
+		if err := tx.Model(&model.MstMachineDetail{}).
+			Where("id_machine = ? AND state IN ?", machineDetail.IDMachine, []string{MachineRevisionDraft, MachineRevisionPending}).
+			Count(&unreleased).Error; err != nil {
+			return err
+		}
+
+		if unreleased > 0 {
+			return ErrMachineRevisionUnreleased
+		}
+
+		if err := tx.Model(&model.MstMachineDetail{}).
+			Where("id_machine = ?", machineDetail.IDMachine).
+			Select("MAX(rev_no)").
+			Scan(&revision.RevNo).Error; err != nil {
+			return err
+		}
+
+		revision.RevNo++
+
+		return tx.Create(&revision).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &revision, nil
+}
+
+// CanEditRevision checks that userID may change machineDetail, a draft they
+// wrote. Submitted and released revisions cannot be changed.
+func CanEditRevision(machineDetail *model.MstMachineDetail, userID uint) error {
+	if machineDetail.State != MachineRevisionDraft {
+		return ErrMachineRevisionNotDraft
+	}
+
+	if machineDetail.IDCreatedby != userID {
+		return ErrMachineRevisionNotAuthor
+	}
+
+	return nil
+}
+
+// updateMachineRevisionState follows the approval of a revision, releasing it
+// once the last level approves. Only drafts can be submitted, and only by
+// their author.
+func updateMachineRevisionState(tx *gorm.DB, refID uint, key string, userID uint, state *dto.ApprovalState) error {
+	var machineDetail model.MstMachineDetail
+	if err := tx.Select("id, state, id_createdby").First(&machineDetail, refID).Error; err != nil {
+		return err
+	}
+
+	if machineDetail.State == MachineRevisionReleased || machineDetail.State == MachineRevisionRejected {
+		return ErrMachineRevisionNotDraft
+	}
+
+	if key == ApprovalKeySubmit && machineDetail.IDCreatedby != userID {
+		return ErrMachineRevisionNotAuthor
+	}
+
+	updates := map[string]interface{}{}
+
+	switch state.State {
+	case ApprovalStatePending:
+		updates["state"] = MachineRevisionPending
+	case ApprovalStateApproved:
+		updates["state"] = MachineRevisionReleased
+		updates["released_at"] = time.Now()
+	case ApprovalStateRejected:
+		updates["state"] = MachineRevisionRejected
+	default:
+		updates["state"] = MachineRevisionDraft
+	}
+
+	return tx.Model(&machineDetail).Updates(updates).Error
+}
+
+func (s *MachineService) preloadLookups(db *gorm.DB) *gorm.DB {
+	for _, name := range machineDetailLookups {
+		db = db.Preload(name, func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Select("id, code, description")
+		})
+	}
+
+	return db
+}
+
+// revisionValue is the value of a revision field as compared and reported by
+// DiffRevisions: the code of a UoM or building and the value behind a pointer.
+func revisionValue(value reflect.Value) interface{} {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+
+		switch lookup := value.Interface().(type) {
+		case *model.MstUoms:
+			return lookup.Code
+		case *model.MstBuilding:
+			return lookup.Code
+		}
+
+		value = value.Elem()
+	}
+
+	return value.Interface()
+}
@@ -0,0 +1,328 @@
+package service
+
+import (
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/model"
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MachineTimelineService turns the status history of machines into the
+// intervals between status changes and reports downtime over them. A status
+// is downtime when its reason is marked as such.
+type MachineTimelineService struct {
+	db *gorm.DB
+}
+
+func NewMachineTimelineService(db *gorm.DB) *MachineTimelineService {
+	return &MachineTimelineService{db: db}
+}
+
+// GetTimeline lists the status intervals of a machine between from and to,
+// the latter capped at now.
+func (s *MachineTimelineService) GetTimeline(machineID uint, from time.Time, to time.Time) (*dto.MachineTimeline, error) {
+	to = capAtNow(to)
+
+	intervals, err := s.getIntervals([]uint{machineID}, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.MachineTimeline{
+		IDMachine: machineID,
+		From:      from,
+		To:        to,
+		Intervals: intervals[machineID],
+	}, nil
+}
+
+// GetMetrics reports the downtime of a machine between from and to with its
+// top downtime reasons.
+func (s *MachineTimelineService) GetMetrics(machineID uint, from time.Time, to time.Time, top int) (*dto.MachineMetrics, error) {
+	to = capAtNow(to)
+
+	intervals, err := s.getIntervals([]uint{machineID}, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := tallyIntervals(intervals[machineID]).metrics(from, to, top)
+	metrics.IDMachine = machineID
+
+	return &metrics, nil
+}
+
+// GetBuildingMetrics reports the downtime of the machines whose current
+// revision is in a building, in total and per machine.
+func (s *MachineTimelineService) GetBuildingMetrics(buildingID uint, from time.Time, to time.Time, top int) (*dto.BuildingMachineMetrics, error) {
+	to = capAtNow(to)
+
+	var machines []model.ViewMstMachine
+	if err := s.db.Model(&model.ViewMstMachine{}).
+		Select("id, code, name").
+		Where("id_building = ? AND id NOT IN ("+deletedMachines+")", buildingID).
+		Order("code ASC").
+		Find(&machines).Error; err != nil {
+		return nil, err
+	}
+
+	machineIDs := make([]uint, len(machines))
+	for i, machine := range machines {
+		machineIDs[i] = machine.ID
+	}
+
+	intervals, err := s.getIntervals(machineIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := dto.BuildingMachineMetrics{
+		IDBuilding: buildingID,
+		Machines:   make([]dto.MachineMetrics, len(machines)),
+	}
+
+	total := newMachineTally()
+	for i, machine := range machines {
+		tally := tallyIntervals(intervals[machine.ID])
+		total.add(tally)
+
+		result.Machines[i] = tally.metrics(from, to, top)
+		result.Machines[i].IDMachine = machine.ID
+		result.Machines[i].Code = machine.Code
+		result.Machines[i].Name = machine.Name
+	}
+
+	result.Total = total.metrics(from, to, top)
+
+	return &result, nil
+}
+
+// getIntervals cuts the status history of machines into intervals within
+// from and to. The status set last before from is in effect at from.
+func (s *MachineTimelineService) getIntervals(machineIDs []uint, from time.Time, to time.Time) (map[uint][]dto.MachineStatusInterval, error) {
+	result := map[uint][]dto.MachineStatusInterval{}
+	for _, machineID := range machineIDs {
+		result[machineID] = []dto.MachineStatusInterval{}
+	}
+
+	if len(machineIDs) == 0 || !from.Before(to) {
+		return result, nil
+	}
+
+	var statuses []model.MstMachineStatus
+	if err := s.db.Raw(`SELECT * FROM (
+			SELECT DISTINCT ON (id_machine) * FROM mst_machine_statuses
+			WHERE id_machine IN ? AND created_at <= ?
+			ORDER BY id_machine, created_at DESC, id DESC
+		) previous
+		UNION ALL
+		SELECT * FROM mst_machine_statuses
+		WHERE id_machine IN ? AND created_at > ? AND created_at < ?`,
+		machineIDs, from, machineIDs, from, to).Scan(&statuses).Error; err != nil {
+		return nil, err
+	}
+
+	reasons, err := s.getReasons(statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].IDMachine != statuses[j].IDMachine {
+			return statuses[i].IDMachine < statuses[j].IDMachine
+		}
+		if !statuses[i].CreatedAt.Equal(*statuses[j].CreatedAt) {
+			return statuses[i].CreatedAt.Before(*statuses[j].CreatedAt)
+		}
+		return statuses[i].ID < statuses[j].ID
+	})
+
+	for i, status := range statuses {
+		start := *status.CreatedAt
+		if start.Before(from) {
+			start = from
+		}
+
+		end := to
+		if i+1 < len(statuses) && statuses[i+1].IDMachine == status.IDMachine {
+			end = *statuses[i+1].CreatedAt
+		}
+
+		if !end.After(start) {
+			continue
+		}
+
+		interval := dto.MachineStatusInterval{
+			IDStatus: status.ID,
+			IDReason: status.IDReason,
+			Remarks:  status.Remarks,
+			Start:    start,
+			End:      end,
+			Hours:    roundMetric(end.Sub(start).Hours()),
+		}
+
+		if reason, ok := reasons[status.IDReason]; ok {
+			interval.ReasonCode = reason.Code
+			interval.ReasonDescription = reason.Description
+			interval.Downtime = reason.Downtime
+		}
+
+		result[status.IDMachine] = append(result[status.IDMachine], interval)
+	}
+
+	return result, nil
+}
+
+// getReasons loads the reasons of statuses, deleted ones included as they
+// still describe the history.
+func (s *MachineTimelineService) getReasons(statuses []model.MstMachineStatus) (map[uint]model.MstReason, error) {
+	reasonIDs := []uint{}
+	for _, status := range statuses {
+		reasonIDs = append(reasonIDs, status.IDReason)
+	}
+
+	result := map[uint]model.MstReason{}
+	if len(reasonIDs) == 0 {
+		return result, nil
+	}
+
+	var reasons []model.MstReason
+	if err := s.db.Unscoped().Select("id, code, description, downtime").Where("id IN ?", reasonIDs).Find(&reasons).Error; err != nil {
+		return nil, err
+	}
+
+	for _, reason := range reasons {
+		result[reason.ID] = reason
+	}
+
+	return result, nil
+}
+
+type machineTally struct {
+	observed float64
+	uptime   float64
+	downtime float64
+	failures int
+	reasons  map[uint]*dto.MachineDowntimeReason
+}
+
+func newMachineTally() *machineTally {
+	return &machineTally{reasons: map[uint]*dto.MachineDowntimeReason{}}
+}
+
+func tallyIntervals(intervals []dto.MachineStatusInterval) *machineTally {
+	tally := newMachineTally()
+
+	down := false
+	for _, interval := range intervals {
+		hours := interval.End.Sub(interval.Start).Hours()
+		tally.observed += hours
+
+		if !interval.Downtime {
+			tally.uptime += hours
+			down = false
+			continue
+		}
+
+		tally.downtime += hours
+
+		// Consecutive downtime statuses, say a breakdown followed by repair,
+		// are one failure.
+		if !down {
+			tally.failures++
+		}
+		down = true
+
+		reason, ok := tally.reasons[interval.IDReason]
+		if !ok {
+			reason = &dto.MachineDowntimeReason{
+				IDReason:          interval.IDReason,
+				ReasonCode:        interval.ReasonCode,
+				ReasonDescription: interval.ReasonDescription,
+			}
+			tally.reasons[interval.IDReason] = reason
+		}
+
+		reason.Hours += hours
+		reason.Count++
+	}
+
+	return tally
+}
+
+func (t *machineTally) add(other *machineTally) {
+	t.observed += other.observed
+	t.uptime += other.uptime
+	t.downtime += other.downtime
+	t.failures += other.failures
+
+	for reasonID, reason := range other.reasons {
+		sum, ok := t.reasons[reasonID]
+		if !ok {
+			copied := *reason
+			t.reasons[reasonID] = &copied
+			continue
+		}
+
+		sum.Hours += reason.Hours
+		sum.Count += reason.Count
+	}
+}
+
+func (t *machineTally) metrics(from time.Time, to time.Time, top int) dto.MachineMetrics {
+	metrics := dto.MachineMetrics{
+		From:          from,
+		To:            to,
+		ObservedHours: roundMetric(t.observed),
+		UptimeHours:   roundMetric(t.uptime),
+		DowntimeHours: roundMetric(t.downtime),
+		Failures:      t.failures,
+		TopReasons:    []dto.MachineDowntimeReason{},
+	}
+
+	if t.observed > 0 {
+		availability := roundMetric(t.uptime / t.observed * 100)
+		metrics.Availability = &availability
+	}
+
+	if t.failures > 0 {
+		mtbf := roundMetric(t.uptime / float64(t.failures))
+		mttr := roundMetric(t.downtime / float64(t.failures))
+		metrics.MTBF = &mtbf
+		metrics.MTTR = &mttr
+	}
+
+	for _, reason := range t.reasons {
+		reason := *reason
+		reason.Hours = roundMetric(reason.Hours)
+		metrics.TopReasons = append(metrics.TopReasons, reason)
+	}
+
+	sort.Slice(metrics.TopReasons, func(i, j int) bool {
+		if metrics.TopReasons[i].Hours != metrics.TopReasons[j].Hours {
+			return metrics.TopReasons[i].Hours > metrics.TopReasons[j].Hours
+		}
+		return metrics.TopReasons[i].ReasonCode < metrics.TopReasons[j].ReasonCode
+	})
+
+	if top > 0 && len(metrics.TopReasons) > top {
+		metrics.TopReasons = metrics.TopReasons[:top]
+	}
+
+	return metrics
+}
+
+func capAtNow(to time.Time) time.Time {
+	if now := time.Now(); to.After(now) {
+		return now
+	}
+
+	return to
+}
+
+func roundMetric(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/model"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	MaintenanceIntervalDays         = "days"
+	MaintenanceIntervalRunningHours = "running_hours"
+)
+
+const (
+	MaintenanceStateOverdue   = "overdue"
+	MaintenanceStateUpcoming  = "upcoming"
+	MaintenanceStateScheduled = "scheduled"
+)
+
+// maxEstimateHours bounds the estimate of when a plan by running hours is
+// due, for machines that hardly run.
+const maxEstimateHours = 10 * 365 * 24
+
+type MaintenanceService struct {
+	db                     *gorm.DB
+	machineTimelineService *MachineTimelineService
+}
+
+func NewMaintenanceService(db *gorm.DB) *MaintenanceService {
+	return &MaintenanceService{db: db, machineTimelineService: NewMachineTimelineService(db)}
+}
+
+// GetSchedule lists the plans assigned to a machine with when they are next
+// due and their open work orders. A plan is overdue once it is due or has an
+// open work order past its due date, and upcoming when either falls within
+// the given number of days.
+func (s *MaintenanceService) GetSchedule(machineID uint, within int, now time.Time) ([]dto.MaintenanceSchedule, error) {
+	assignments, err := s.getAssignments(s.db.Where("id_machine = ?", machineID))
+	if err != nil {
+		return nil, err
+	}
+
+	var workOrders []model.WorkOrder
+	if err := s.db.Preload("Tasks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("seq ASC, id ASC")
+	}).Where("id_machine = ? AND status IN ? AND id_machine_maintenance_plan IS NOT NULL", machineID, openWorkOrderStatuses).
+		Order("due_at ASC").
+		Find(&workOrders).Error; err != nil {
+		return nil, err
+	}
+
+	openWorkOrders := map[uint][]model.WorkOrder{}
+	for _, workOrder := range workOrders {
+		openWorkOrders[*workOrder.IDMachineMaintenancePlan] = append(openWorkOrders[*workOrder.IDMachineMaintenancePlan], workOrder)
+	}
+
+	schedules := make([]dto.MaintenanceSchedule, len(assignments))
+	for i, assignment := range assignments {
+		schedule, err := s.schedule(assignment, now)
+		if err != nil {
+			return nil, err
+		}
+
+		schedule.OpenWorkOrders = openWorkOrders[assignment.ID]
+		if schedule.OpenWorkOrders == nil {
+			schedule.OpenWorkOrders = []model.WorkOrder{}
+		}
+
+		schedule.State = maintenanceState(schedule, now, now.AddDate(0, 0, within))
+		schedules[i] = schedule
+	}
+
+	return schedules, nil
+}
+
+// GenerateWorkOrders opens a preventive work order, with the tasks of its
+// plan, for every plan that is due. Plans by calendar days are opened their
+// lead days ahead; cycles missed meanwhile are caught up by one work order
+// due at the latest of them. A plan that fails is logged and skipped, so it
+// does not hold back the others. It returns the number of work orders opened.
+//
+// The next cycle counts from the due date of the last work order opened, kept
+// in last_due_at when it is opened, not from when the work is completed: a
+// late or still open work order does not push the schedule back, and plans by
+// running hours count from when their work order was opened.
+func (s *MaintenanceService) GenerateWorkOrders(now time.Time) (int, error) {
+	assignments, err := s.getAssignments(s.db)
+	if err != nil {
+		return 0, err
+	}
+
+	generated := 0
+	for _, assignment := range assignments {
+		schedule, err := s.schedule(assignment, now)
+		if err != nil {
+			log.Printf("Skipping maintenance plan %d of machine %d: %v", assignment.ID, assignment.IDMachine, err)
+			continue
+		}
+
+		plan := assignment.MaintenancePlan
+
+		var dueAt time.Time
+		switch plan.IntervalType {
+		case MaintenanceIntervalDays:
+			horizon := now.AddDate(0, 0, plan.LeadDays)
+			if schedule.NextDueAt.After(horizon) {
+				continue
+			}
+
+			dueAt = *schedule.NextDueAt
+			for next := dueAt.AddDate(0, 0, plan.IntervalValue); !next.After(horizon); next = next.AddDate(0, 0, plan.IntervalValue) {
+				dueAt = next
+			}
+
+		case MaintenanceIntervalRunningHours:
+			if *schedule.RemainingHours > 0 {
+				continue
+			}
+
+			dueAt = now
+
+		default:
+			continue
+		}
+
+		created, err := s.createWorkOrder(assignment, dueAt)
+		if err != nil {
+			log.Printf("Skipping maintenance plan %d of machine %d: %v", assignment.ID, assignment.IDMachine, err)
+			continue
+		}
+
+		if created {
+			generated++
+		}
+	}
+
+	return generated, nil
+}
+
+// getAssignments loads the plan assignments matched by db with their plans,
+// leaving out deleted machines and plans.
+func (s *MaintenanceService) getAssignments(db *gorm.DB) ([]model.MstMachineMaintenancePlan, error) {
+	var assignments []model.MstMachineMaintenancePlan
+	if err := db.Preload("MaintenancePlan").
+		Preload("MaintenancePlan.Tasks", func(db *gorm.DB) *gorm.DB {
+			return db.Order("seq ASC, id ASC")
+		}).
+		Where("id_machine NOT IN (" + deletedMachines + ")").
+		Where("id_maintenance_plan IN (SELECT id FROM mst_maintenance_plans WHERE deleted_at IS NULL)").
+		Order("id ASC").
+		Find(&assignments).Error; err != nil {
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
+func (s *MaintenanceService) schedule(assignment model.MstMachineMaintenancePlan, now time.Time) (dto.MaintenanceSchedule, error) {
+	plan := assignment.MaintenancePlan
+
+	since := assignment.StartDate
+	if assignment.LastDueAt != nil {
+		since = *assignment.LastDueAt
+	}
+
+	schedule := dto.MaintenanceSchedule{
+		IDMachineMaintenancePlan: assignment.ID,
+		IDMachine:                assignment.IDMachine,
+		MaintenancePlan:          plan,
+		Since:                    since,
+	}
+
+	switch plan.IntervalType {
+	case MaintenanceIntervalDays:
+		nextDueAt := since.AddDate(0, 0, plan.IntervalValue)
+		schedule.NextDueAt = &nextDueAt
+
+	case MaintenanceIntervalRunningHours:
+		runningHours := 0.0
+		if since.Before(now) {
+			intervals, err := s.machineTimelineService.getIntervals([]uint{assignment.IDMachine}, since, now)
+			if err != nil {
+				return schedule, err
+			}
+
+			runningHours = tallyIntervals(intervals[assignment.IDMachine]).uptime
+		}
+
+		remainingHours := float64(plan.IntervalValue) - runningHours
+		if remainingHours < 0 {
+			remainingHours = 0
+		}
+
+		if remainingHours == 0 {
+			schedule.NextDueAt = &now
+		} else if runningHours > 0 {
+			hours := remainingHours / (runningHours / now.Sub(since).Hours())
+			if hours < maxEstimateHours {
+				nextDueAt := now.Add(time.Duration(hours * float64(time.Hour)))
+				schedule.NextDueAt = &nextDueAt
+			}
+		}
+
+		runningHours = roundMetric(runningHours)
+		remainingHours = roundMetric(remainingHours)
+		schedule.RunningHours = &runningHours
+		schedule.RemainingHours = &remainingHours
+	}
+
+	return schedule, nil
+}
+
+// createWorkOrder opens the work order of a plan due at dueAt, unless another
+// run opened it already, and moves its last_due_at to dueAt.
+func (s *MaintenanceService) createWorkOrder(assignment model.MstMachineMaintenancePlan, dueAt time.Time) (bool, error) {
+	plan := assignment.MaintenancePlan
+	created := false
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		workOrder := model.WorkOrder{
+			Type:                     WorkOrderTypePreventive,
+			Status:                   WorkOrderStatusOpen,
+			IDMachine:                assignment.IDMachine,
+			IDMachineMaintenancePlan: &assignment.ID,
+			Title:                    plan.Code + " " + plan.Name,
+			DueAt:                    &dueAt,
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit("Tasks").Create(&workOrder)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected > 0 {
+			created = true
+
+			tasks := make([]model.WorkOrderTask, len(plan.Tasks))
+			for i, task := range plan.Tasks {
+				tasks[i] = model.WorkOrderTask{
+					IDWorkOrder: workOrder.ID,
+					Seq:         task.Seq,
+					Description: task.Description,
+				}
+			}
+
+			if len(tasks) > 0 {
+				if err := tx.Create(&tasks).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.Model(&model.MstMachineMaintenancePlan{}).
+			Where("id = ?", assignment.ID).
+			Update("last_due_at", dueAt).Error
+	})
+
+	return created, err
+}
+
+func maintenanceState(schedule dto.MaintenanceSchedule, now time.Time, horizon time.Time) string {
+	dueDates := []*time.Time{schedule.NextDueAt}
+	for _, workOrder := range schedule.OpenWorkOrders {
+		dueDates = append(dueDates, workOrder.DueAt)
+	}
+
+	state := MaintenanceStateScheduled
+	for _, dueAt := range dueDates {
+		if dueAt == nil {
+			continue
+		}
+
+		if !dueAt.After(now) {
+			return MaintenanceStateOverdue
+		}
+
+		if dueAt.Before(horizon) {
+			state = MaintenanceStateUpcoming
+		}
+	}
+
+	return state
+}
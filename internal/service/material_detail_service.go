@@ -1,12 +1,15 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
+// MaterialDetailFilterFields are the fields the list accepts in filter[field][op].
+var MaterialDetailFilterFields = filter.Columns(&model.MstMaterialDetail{})
+
 type MaterialDetailService struct {
 	db *gorm.DB
 }
@@ -29,9 +32,9 @@ func (s *MaterialDetailService) GetByID(id uint) (*model.MstMaterialDetail, erro
 	return &materialDetail, nil
 }
 
-func (s *MaterialDetailService) GetTotal(search string, idMaterial uint) (int64, error) {
+func (s *MaterialDetailService) GetTotal(search string, idMaterial uint, includeDeleted bool, filters []filter.Condition) (int64, error) {
 	var count int64
-	query := s.db.Model(&model.MstMaterialDetail{}).
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstMaterialDetail{}).
 		Joins("LEFT JOIN mst_materials ON mst_materials.id = mst_material_details.id_material").
 		Joins("LEFT JOIN mst_items ON mst_items.code = mst_materials.code")
 
@@ -44,16 +47,18 @@ func (s *MaterialDetailService) GetTotal(search string, idMaterial uint) (int64,
 		query = query.Where("mst_material_details.id_material = ?", idMaterial)
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
-func (s *MaterialDetailService) GetAll(offset, limit int, search, sortBy string, sortAsc bool, idMaterial uint) ([]model.MstMaterialDetail, error) {
+func (s *MaterialDetailService) GetAll(offset, limit int, search string, sorts []filter.Order, idMaterial uint, includeDeleted bool, filters []filter.Condition) ([]model.MstMaterialDetail, error) {
 	var materialDetails []model.MstMaterialDetail
 
-	query := s.db.Model(&model.MstMaterialDetail{}).
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstMaterialDetail{}).
 		Joins("LEFT JOIN mst_materials ON mst_materials.id = mst_material_details.id_material").
 		Joins("LEFT JOIN mst_items ON mst_items.code = mst_materials.code").
 		Preload("Material").
@@ -66,8 +71,8 @@ func (s *MaterialDetailService) GetAll(offset, limit int, search, sortBy string,
 		Offset(offset).
 		Limit(limit)
 
-	if sortBy != "" {
-		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: !sortAsc})
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
 	}
 
 	if search != "" {
@@ -81,6 +86,8 @@ func (s *MaterialDetailService) GetAll(offset, limit int, search, sortBy string,
 		query = query.Where("mst_material_details.id_material = ?", idMaterial)
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&materialDetails).Error; err != nil {
 		return nil, err
 	}
@@ -92,9 +99,9 @@ func (s *MaterialDetailService) Create(materialDetail *model.MstMaterialDetail)
 }
 
 func (s *MaterialDetailService) Update(materialDetail *model.MstMaterialDetail) error {
-	return s.db.Save(materialDetail).Error
+	return SaveVersion(s.db, materialDetail)
 }
 
 func (s *MaterialDetailService) Delete(materialDetail *model.MstMaterialDetail) error {
-	return s.db.Delete(materialDetail).Error
+	return SoftDelete(s.db, materialDetail, materialDetail.IDUpdatedby)
 }
@@ -1,12 +1,15 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
+// MaterialFilterFields are the fields the list accepts in filter[field][op].
+var MaterialFilterFields = filter.Columns(&model.MstMaterial{})
+
 type MaterialService struct {
 	db *gorm.DB
 }
@@ -29,25 +32,27 @@ func (s *MaterialService) GetByID(id uint) (*model.MstMaterial, error) {
 	return &material, nil
 }
 
-func (s *MaterialService) GetTotal(search string) (int64, error) {
+func (s *MaterialService) GetTotal(search string, includeDeleted bool, filters []filter.Condition) (int64, error) {
 	var count int64
-	query := s.db.Model(&model.MstMaterial{}).
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstMaterial{}).
 		Joins("LEFT JOIN mst_items ON mst_items.code = mst_materials.code")
 
 	if search != "" {
 		query = query.Where("mst_items.code ILIKE ? OR mst_items.description ILIKE ?", "%"+search+"%", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
-func (s *MaterialService) GetAll(offset, limit int, search, sortBy string, sortAsc bool) ([]model.MstMaterial, error) {
+func (s *MaterialService) GetAll(offset, limit int, search string, sorts []filter.Order, includeDeleted bool, filters []filter.Condition) ([]model.MstMaterial, error) {
 	var materials []model.MstMaterial
 
-	query := s.db.Model(&model.MstMaterial{}).
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstMaterial{}).
 		Joins("LEFT JOIN mst_items ON mst_items.code = mst_materials.code").
 		Preload("Item").
 		Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
@@ -59,8 +64,8 @@ func (s *MaterialService) GetAll(offset, limit int, search, sortBy string, sortA
 		Offset(offset).
 		Limit(limit)
 
-	if sortBy != "" {
-		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: !sortAsc})
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
 	}
 
 	if search != "" {
@@ -69,6 +74,8 @@ func (s *MaterialService) GetAll(offset, limit int, search, sortBy string, sortA
 		query = query.Order("mst_materials.code ASC")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&materials).Error; err != nil {
 		return nil, err
 	}
@@ -80,9 +87,9 @@ func (s *MaterialService) Create(material *model.MstMaterial) error {
 }
 
 func (s *MaterialService) Update(material *model.MstMaterial) error {
-	return s.db.Save(material).Error
+	return SaveVersion(s.db, material)
 }
 
 func (s *MaterialService) Delete(material *model.MstMaterial) error {
-	return s.db.Delete(material).Error
+	return SoftDelete(s.db, material, material.IDUpdatedby)
 }
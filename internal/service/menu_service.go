@@ -1,12 +1,15 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
+// MenuFilterFields are the fields the list accepts in filter[field][op].
+var MenuFilterFields = filter.Columns(&model.MstMenu{})
+
 type MenuService struct {
 	db *gorm.DB
 }
@@ -73,15 +76,17 @@ func (s *MenuService) GetByUser(userID uint, offset, limit int, search string) (
 	return menus, nil
 }
 
-func (s *MenuService) GetTotal(search string) (int64, error) {
+func (s *MenuService) GetTotal(search string, includeDeleted bool, filters []filter.Condition) (int64, error) {
 	var count int64
 
-	query := s.db.Model(&model.MstMenu{})
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstMenu{})
 
 	if search != "" {
 		query = query.Where("label ILIKE ?", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
@@ -89,10 +94,10 @@ func (s *MenuService) GetTotal(search string) (int64, error) {
 	return count, nil
 }
 
-func (s *MenuService) GetAll(offset, limit int, search string, sortBy string, sortDirection bool) ([]model.MstMenu, error) {
+func (s *MenuService) GetAll(offset, limit int, search string, sorts []filter.Order, includeDeleted bool, filters []filter.Condition) ([]model.MstMenu, error) {
 	var menus []model.MstMenu
 
-	query := s.db.Model(&model.MstMenu{}).Preload("Parent", func(db *gorm.DB) *gorm.DB {
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstMenu{}).Preload("Parent", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, label")
 	}).Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, name")
@@ -100,8 +105,8 @@ func (s *MenuService) GetAll(offset, limit int, search string, sortBy string, so
 		return db.Select("id, name")
 	}).Offset(offset).Limit(limit)
 
-	if sortBy != "" {
-		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: sortDirection})
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
 	} else {
 		query = query.Order("updated_at ASC")
 	}
@@ -110,6 +115,8 @@ func (s *MenuService) GetAll(offset, limit int, search string, sortBy string, so
 		query = query.Where("label ILIKE ?", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&menus).Error; err != nil {
 		return nil, err
 	}
@@ -122,11 +129,15 @@ func (s *MenuService) Create(menu *model.MstMenu) error {
 }
 
 func (s *MenuService) Update(menu *model.MstMenu) error {
-	return s.db.Save(menu).Error
+	defer InvalidatePermissionCache()
+
+	return SaveVersion(s.db, menu)
 }
 
 func (s *MenuService) Delete(menu *model.MstMenu) error {
-	return s.db.Delete(menu).Error
+	defer InvalidatePermissionCache()
+
+	return SoftDelete(s.db, menu, menu.IDUpdatedby)
 }
 
 func (s *MenuService) GetMenuTree() ([]model.MstMenu, error) {
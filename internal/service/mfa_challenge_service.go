@@ -0,0 +1,99 @@
+package service
+
+import (
+	"errors"
+	"insist-backend-golang/internal/model"
+	"insist-backend-golang/pkg"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const mfaChallengeTTL = 5 * time.Minute
+
+var (
+	ErrMfaChallengeInvalid = errors.New("invalid or expired two-factor challenge")
+	ErrOtpReused           = errors.New("OTP has already been used")
+)
+
+type MfaChallengeService struct {
+	db *gorm.DB
+}
+
+func NewMfaChallengeService(db *gorm.DB) *MfaChallengeService {
+	return &MfaChallengeService{db: db}
+}
+
+func (s *MfaChallengeService) Create(userID uint, device string) (*model.MfaChallenge, string, error) {
+	token, err := pkg.GenerateToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	challenge := model.MfaChallenge{
+		IDUser:    userID,
+		TokenHash: hashToken(token),
+		Device:    device,
+		ExpiredAt: time.Now().Add(mfaChallengeTTL),
+	}
+
+	if err := s.db.Create(&challenge).Error; err != nil {
+		return nil, "", err
+	}
+
+	return &challenge, token, nil
+}
+
+func (s *MfaChallengeService) GetValid(token string) (*model.MfaChallenge, error) {
+	var challenge model.MfaChallenge
+	if err := s.db.Where("token_hash = ? AND used_at IS NULL AND expired_at > ?", hashToken(token), time.Now()).
+		First(&challenge).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMfaChallengeInvalid
+		}
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+// Complete burns the challenge together with the OTP time step or recovery
+// code in one transaction, so neither the challenge nor the code can be used
+// twice.
+func (s *MfaChallengeService) Complete(challenge *model.MfaChallenge, otpStep int64, recoveryCode string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.MfaChallenge{}).
+			Where("id = ? AND used_at IS NULL", challenge.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrMfaChallengeInvalid
+		}
+
+		if recoveryCode != "" {
+			return useRecoveryCode(tx, challenge.IDUser, recoveryCode)
+		}
+
+		return useOtpStep(tx, challenge.IDUser, otpStep)
+	})
+}
+
+// useOtpStep only moves the last used step forward; a code from the same or
+// an earlier step is a replay.
+func useOtpStep(tx *gorm.DB, userID uint, otpStep int64) error {
+	result := tx.Model(&model.MstUser{}).
+		Where("id = ? AND (otp_last_step IS NULL OR otp_last_step < ?)", userID, otpStep).
+		Update("otp_last_step", otpStep)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrOtpReused
+	}
+
+	return nil
+}
@@ -1,11 +1,15 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
 )
 
+// ReasonFilterFields are the fields the list accepts in filter[field][op].
+var ReasonFilterFields = filter.Columns(&model.MstReason{})
+
 type ReasonService struct {
 	db *gorm.DB
 }
@@ -29,10 +33,10 @@ func (s *ReasonService) GetByID(reasonID uint) (*model.MstReason, error) {
 	return &reason, nil
 }
 
-func (s *ReasonService) GetTotal(search, path string, key string, menuID uint) (int64, error) {
+func (s *ReasonService) GetTotal(search, path string, key string, menuID uint, includeDeleted bool, filters []filter.Condition) (int64, error) {
 	var count int64
 
-	query := s.db.Model(&model.MstReason{}).
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstReason{}).
 		Joins("JOIN mst_menus ON mst_menus.id = mst_reasons.id_menu")
 
 	if menuID != 0 {
@@ -51,6 +55,8 @@ func (s *ReasonService) GetTotal(search, path string, key string, menuID uint) (
 		query = query.Where("mst_reasons.key ILIKE ? OR mst_reasons.code ILIKE ? OR mst_reasons.description ILIKE ?", "%"+search+"%", "%"+search+"%", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
@@ -58,10 +64,10 @@ func (s *ReasonService) GetTotal(search, path string, key string, menuID uint) (
 	return count, nil
 }
 
-func (s *ReasonService) GetAll(offset, limit int, search, path string, key string, menuID uint) ([]model.MstReason, error) {
+func (s *ReasonService) GetAll(offset, limit int, search, path string, key string, menuID uint, includeDeleted bool, filters []filter.Condition) ([]model.MstReason, error) {
 	var reasons []model.MstReason
 
-	query := s.db.Model(&model.MstReason{}).
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstReason{}).
 		Joins("JOIN mst_menus ON mst_menus.id = mst_reasons.id_menu").
 		Preload("Menu", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id, label, path")
@@ -91,6 +97,8 @@ func (s *ReasonService) GetAll(offset, limit int, search, path string, key strin
 		query = query.Where("mst_reasons.key ILIKE ? OR mst_reasons.code ILIKE ? OR mst_reasons.description ILIKE ?", "%"+search+"%", "%"+search+"%", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&reasons).Error; err != nil {
 		return nil, err
 	}
@@ -103,9 +111,9 @@ func (s *ReasonService) Create(reason *model.MstReason) error {
 }
 
 func (s *ReasonService) Update(reason *model.MstReason) error {
-	return s.db.Save(reason).Error
+	return SaveVersion(s.db, reason)
 }
 
 func (s *ReasonService) Delete(reason *model.MstReason) error {
-	return s.db.Delete(reason).Error
+	return SoftDelete(s.db, reason, reason.IDUpdatedby)
 }
@@ -1,11 +1,15 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
 )
 
+// RoleMenuFilterFields are the fields the list accepts in filter[field][op].
+var RoleMenuFilterFields = filter.Columns(&model.MstRole{})
+
 type RoleMenuService struct {
 	db *gorm.DB
 }
@@ -16,7 +20,7 @@ func NewRoleMenuService(db *gorm.DB) *RoleMenuService {
 
 func (s *RoleMenuService) GetByID(roleMenuID uint) (*model.MstRole, error) {
 	var roleMenu model.MstRole
-	if err := s.db.Select("id", "name").Preload("RoleMenus.Menu", func(db *gorm.DB) *gorm.DB {
+	if err := s.db.Select("id", "name", "updated_at").Preload("RoleMenus.Menu", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, label")
 	}).First(&roleMenu, roleMenuID).Error; err != nil {
 		return nil, err
@@ -25,7 +29,7 @@ func (s *RoleMenuService) GetByID(roleMenuID uint) (*model.MstRole, error) {
 	return &roleMenu, nil
 }
 
-func (s *RoleMenuService) GetTotal(search string) (int64, error) {
+func (s *RoleMenuService) GetTotal(search string, filters []filter.Condition) (int64, error) {
 	var count int64
 
 	query := s.db.Model(&model.MstRole{})
@@ -34,6 +38,8 @@ func (s *RoleMenuService) GetTotal(search string) (int64, error) {
 		query = query.Where("name ILIKE ?", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
@@ -41,7 +47,7 @@ func (s *RoleMenuService) GetTotal(search string) (int64, error) {
 	return count, nil
 }
 
-func (s *RoleMenuService) GetAll(offset, limit int, search string) ([]model.MstRole, error) {
+func (s *RoleMenuService) GetAll(offset, limit int, search string, filters []filter.Condition) ([]model.MstRole, error) {
 	var roleMenus []model.MstRole
 
 	query := s.db.Model(&model.MstRole{}).Preload("RoleMenus.Menu", func(db *gorm.DB) *gorm.DB {
@@ -52,6 +58,8 @@ func (s *RoleMenuService) GetAll(offset, limit int, search string) ([]model.MstR
 		query = query.Where("name ILIKE ?", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&roleMenus).Error; err != nil {
 		return nil, err
 	}
@@ -70,3 +78,24 @@ func (s *RoleMenuService) Update(roleMenu *model.MstRoleMenu) error {
 func (s *RoleMenuService) Delete(roleMenuID uint) error {
 	return s.db.Where("id_role = ?", roleMenuID).Delete(&model.MstRoleMenu{}).Error
 }
+
+// Replace sets the menus of role in a transaction, moving its version so the
+// set is replaced only over the one it was read with, and returns ErrStale
+// when the role was changed since.
+func (s *RoleMenuService) Replace(role *model.MstRole, roleMenus []model.MstRoleMenu) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := Touch(tx, role); err != nil {
+			return err
+		}
+
+		if err := tx.Where("id_role = ?", role.ID).Delete(&model.MstRoleMenu{}).Error; err != nil {
+			return err
+		}
+
+		if len(roleMenus) == 0 {
+			return nil
+		}
+
+		return tx.Create(&roleMenus).Error
+	})
+}
@@ -3,10 +3,40 @@ package service
 import (
 	"insist-backend-golang/internal/dto"
 	"insist-backend-golang/internal/model"
+	"sync"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+const permissionCacheTTL = 5 * time.Minute
+
+type permissionCacheEntry struct {
+	permission *dto.MenuWithPermissions
+	expiredAt  time.Time
+}
+
+var (
+	permissionCacheMu sync.RWMutex
+	permissionCache   = map[uint]map[string]permissionCacheEntry{}
+)
+
+// InvalidatePermissionCache drops the cached permissions of the given users,
+// or of every user when called without arguments.
+func InvalidatePermissionCache(userIDs ...uint) {
+	permissionCacheMu.Lock()
+	defer permissionCacheMu.Unlock()
+
+	if len(userIDs) == 0 {
+		permissionCache = map[uint]map[string]permissionCacheEntry{}
+		return
+	}
+
+	for _, userID := range userIDs {
+		delete(permissionCache, userID)
+	}
+}
+
 type RolePermissionService struct {
 	db *gorm.DB
 }
@@ -31,6 +61,46 @@ func (s *RolePermissionService) GetByPath(userID uint, path string) ([]dto.MenuW
 	return menus, nil
 }
 
+// GetPermission merges the permissions of every role the user has on the menu
+// path. It returns nil when none of the roles grant anything on the path.
+func (s *RolePermissionService) GetPermission(userID uint, path string) (*dto.MenuWithPermissions, error) {
+	permissionCacheMu.RLock()
+	entry, ok := permissionCache[userID][path]
+	permissionCacheMu.RUnlock()
+
+	if ok && entry.expiredAt.After(time.Now()) {
+		return entry.permission, nil
+	}
+
+	menus, err := s.GetByPath(userID, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var permission *dto.MenuWithPermissions
+	for i, menu := range menus {
+		if i == 0 {
+			permission = &menus[i]
+		} else {
+			permission.IsCreate = permission.IsCreate || menu.IsCreate
+			permission.IsUpdate = permission.IsUpdate || menu.IsUpdate
+			permission.IsDelete = permission.IsDelete || menu.IsDelete
+		}
+	}
+
+	permissionCacheMu.Lock()
+	if permissionCache[userID] == nil {
+		permissionCache[userID] = map[string]permissionCacheEntry{}
+	}
+	permissionCache[userID][path] = permissionCacheEntry{
+		permission: permission,
+		expiredAt:  time.Now().Add(permissionCacheTTL),
+	}
+	permissionCacheMu.Unlock()
+
+	return permission, nil
+}
+
 func (s *RolePermissionService) GetMenuTreeByRole(roleID uint) ([]model.MstMenu, error) {
 	var rootMenus []model.MstMenu
 
@@ -88,46 +158,65 @@ func (s *RolePermissionService) loadChildrenByRole(menu *model.MstMenu, roleMenu
 	return nil
 }
 
-func (s *RolePermissionService) UpdateOrCreateRolePermission(rolePermission *model.MstRolePermission) error {
-	var existingPermission model.MstRolePermission
-	err := s.db.Where("id_role = ? AND id_menu = ?", rolePermission.IDRole, rolePermission.IDMenu).First(&existingPermission).Error
+// GetRole loads the role whose permissions are set, which carries their
+// version.
+func (s *RolePermissionService) GetRole(roleID uint) (*model.MstRole, error) {
+	var role model.MstRole
+	if err := s.db.Select("id", "name", "updated_at").First(&role, roleID).Error; err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
 
-	if err == nil {
-		if !rolePermission.IsCreate && !rolePermission.IsUpdate && !rolePermission.IsDelete {
-			return s.DeleteRolePermission(rolePermission.IDRole, rolePermission.IDMenu)
-		}
+// UpdateOrCreateRolePermission sets a permission of role in a transaction,
+// removing it when it grants nothing. It moves the version of role so the
+// permission is set only over the one it was read with, and returns ErrStale
+// when role was changed since.
+func (s *RolePermissionService) UpdateOrCreateRolePermission(role *model.MstRole, rolePermission *model.MstRolePermission) error {
+	defer InvalidatePermissionCache()
 
-		existingPermission.IsCreate = rolePermission.IsCreate
-		existingPermission.IsUpdate = rolePermission.IsUpdate
-		existingPermission.IsDelete = rolePermission.IsDelete
-
-		if err := s.db.Model(&existingPermission).
-			Where("id_role = ? AND id_menu = ?", rolePermission.IDRole, rolePermission.IDMenu).
-			Updates(map[string]interface{}{
-				"is_create": rolePermission.IsCreate,
-				"is_update": rolePermission.IsUpdate,
-				"is_delete": rolePermission.IsDelete,
-			}).Error; err != nil {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := Touch(tx, role); err != nil {
 			return err
 		}
-		return nil
-	}
 
-	if err == gorm.ErrRecordNotFound {
-		if !rolePermission.IsCreate && !rolePermission.IsUpdate && !rolePermission.IsDelete {
-			return nil
+		var existingPermission model.MstRolePermission
+		err := tx.Where("id_role = ? AND id_menu = ?", rolePermission.IDRole, rolePermission.IDMenu).First(&existingPermission).Error
+
+		if err == nil {
+			if !rolePermission.IsCreate && !rolePermission.IsUpdate && !rolePermission.IsDelete {
+				return tx.Where("id_role = ? AND id_menu = ?", rolePermission.IDRole, rolePermission.IDMenu).Delete(&existingPermission).Error
+			}
+
+			existingPermission.IsCreate = rolePermission.IsCreate
+			existingPermission.IsUpdate = rolePermission.IsUpdate
+			existingPermission.IsDelete = rolePermission.IsDelete
+
+			return tx.Model(&existingPermission).
+				Where("id_role = ? AND id_menu = ?", rolePermission.IDRole, rolePermission.IDMenu).
+				Updates(map[string]interface{}{
+					"is_create": rolePermission.IsCreate,
+					"is_update": rolePermission.IsUpdate,
+					"is_delete": rolePermission.IsDelete,
+				}).Error
 		}
 
-		if err := s.db.Create(rolePermission).Error; err != nil {
-			return err
+		if err == gorm.ErrRecordNotFound {
+			if !rolePermission.IsCreate && !rolePermission.IsUpdate && !rolePermission.IsDelete {
+				return nil
+			}
+
+			return tx.Create(rolePermission).Error
 		}
-		return nil
-	}
 
-	return err
+		return err
+	})
 }
 
 func (s *RolePermissionService) DeleteRolePermission(idRole uint, idMenu uint) error {
+	defer InvalidatePermissionCache()
+
 	var rolePermission model.MstRolePermission
 	err := s.db.Where("id_role = ? AND id_menu = ?", idRole, idMenu).First(&rolePermission).Error
 	if err != nil {
@@ -1,12 +1,15 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
+// RoleFilterFields are the fields the list accepts in filter[field][op].
+var RoleFilterFields = filter.Columns(&model.MstRole{})
+
 type RoleService struct {
 	db *gorm.DB
 }
@@ -28,15 +31,17 @@ func (s *RoleService) GetByID(roleID uint) (*model.MstRole, error) {
 	return &role, nil
 }
 
-func (s *RoleService) GetTotal(search string) (int64, error) {
+func (s *RoleService) GetTotal(search string, includeDeleted bool, filters []filter.Condition) (int64, error) {
 	var count int64
 
-	query := s.db.Model(&model.MstRole{})
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstRole{})
 
 	if search != "" {
 		query = query.Where("name ILIKE ?", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
@@ -44,17 +49,17 @@ func (s *RoleService) GetTotal(search string) (int64, error) {
 	return count, nil
 }
 
-func (s *RoleService) GetAll(offset, limit int, search string, sortBy string, sortDirection bool) ([]model.MstRole, error) {
+func (s *RoleService) GetAll(offset, limit int, search string, sorts []filter.Order, includeDeleted bool, filters []filter.Condition) ([]model.MstRole, error) {
 	var roles []model.MstRole
 
-	query := s.db.Model(&model.MstRole{}).Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstRole{}).Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, name")
 	}).Preload("UpdatedBy", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, name")
 	}).Offset(offset).Limit(limit)
 
-	if sortBy != "" {
-		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: sortDirection})
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
 	} else {
 		query = query.Order("updated_at ASC")
 	}
@@ -63,6 +68,8 @@ func (s *RoleService) GetAll(offset, limit int, search string, sortBy string, so
 		query = query.Where("name ILIKE ?", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&roles).Error; err != nil {
 		return nil, err
 	}
@@ -75,9 +82,11 @@ func (s *RoleService) Create(role *model.MstRole) error {
 }
 
 func (s *RoleService) Update(role *model.MstRole) error {
-	return s.db.Save(role).Error
+	return SaveVersion(s.db, role)
 }
 
 func (s *RoleService) Delete(role *model.MstRole) error {
-	return s.db.Delete(role).Error
+	defer InvalidatePermissionCache()
+
+	return SoftDelete(s.db, role, role.IDUpdatedby)
 }
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"errors"
+	"insist-backend-golang/internal/model"
+	"log"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrNotDeleted = errors.New("record is not deleted")
+
+// softDeleteModels are purged in order, so rows that reference other master
+// data go before the rows they reference.
+var softDeleteModels = []interface{}{
+	&model.MstItemRawMaterial{},
+	&model.MstItem{},
+	&model.MstItemSubCategory{},
+	&model.MstItemCategory{},
+	&model.MstItemProduct{},
+	&model.MstItemProductType{},
+	&model.MstItemGroup{},
+	&model.MstItemGroupType{},
+	&model.MstItemProcess{},
+	&model.MstItemSurface{},
+	&model.MstItemSource{},
+	&model.MstMaterialDetail{},
+	&model.MstMaterial{},
+	&model.MstMachineMaintenancePlan{},
+	&model.MstMaintenancePlanTask{},
+	&model.MstMaintenancePlan{},
+	&model.MstMachine{},
+	&model.MstProcess{},
+	&model.MstReason{},
+	&model.MstSubSection{},
+	&model.MstSection{},
+	&model.MstFCS{},
+	&model.MstLocation{},
+	&model.MstWarehouse{},
+	&model.MstBuilding{},
+	&model.MstTaxCode{},
+	&model.MstBank{},
+	&model.MstCurrencyRate{},
+	&model.MstCurrency{},
+	&model.MstChartOfAccount{},
+	&model.MstBillingTerm{},
+	&model.MstKeyValue{},
+	&model.MstApproval{},
+	&model.MstMenu{},
+	&model.MstRole{},
+	&model.MstUoms{},
+	&model.MstUser{},
+	&model.MstDept{},
+}
+
+type SoftDeleteService struct {
+	db *gorm.DB
+}
+
+func NewSoftDeleteService(db *gorm.DB) *SoftDeleteService {
+	return &SoftDeleteService{db: db}
+}
+
+// Restore undeletes the row of resource's table with the given ID.
+func (s *SoftDeleteService) Restore(resource interface{}, ID uint, userID uint) error {
+	result := s.db.Unscoped().Model(newModel(resource)).
+		Where("id = ? AND deleted_at IS NOT NULL", ID).
+		Updates(map[string]interface{}{
+			"deleted_at":   nil,
+			"id_deletedby": nil,
+			"id_updatedby": userID,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrNotDeleted
+	}
+
+	switch resource.(type) {
+	case *model.MstMenu, *model.MstRole:
+		InvalidatePermissionCache()
+	}
+
+	return nil
+}
+
+// Purge permanently deletes rows soft deleted before the given time. Rows
+// that are still referenced are kept and tried again on the next run.
+func (s *SoftDeleteService) Purge(before time.Time) (int64, error) {
+	var purged int64
+
+	for _, resource := range softDeleteModels {
+		var IDs []uint
+		if err := s.db.Unscoped().Model(newModel(resource)).
+			Where("deleted_at < ?", before).
+			Pluck("id", &IDs).Error; err != nil {
+			return purged, err
+		}
+
+		for _, ID := range IDs {
+			if err := s.db.Transaction(func(tx *gorm.DB) error {
+				// A deleted machine keeps its revisions and status history
+				// until it is purged.
+				if _, ok := resource.(*model.MstMachine); ok {
+					if err := tx.Where("id_machine = ?", ID).Delete(&model.MstMachineStatus{}).Error; err != nil {
+						return err
+					}
+
+					if err := tx.Where("id_machine = ?", ID).Delete(&model.MstMachineDetail{}).Error; err != nil {
+						return err
+					}
+				}
+
+				return tx.Unscoped().Delete(newModel(resource), ID).Error
+			}); err != nil {
+				log.Printf("Skipping purge of %T %d: %v", resource, ID, err)
+				continue
+			}
+
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// SoftDelete records who deleted value before GORM marks it as deleted.
+func SoftDelete(db *gorm.DB, value interface{}, userID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(value).Updates(map[string]interface{}{
+			"id_deletedby": userID,
+			"id_updatedby": userID,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(value).Error
+	})
+}
+
+// WithDeleted lets list queries include soft deleted rows on request.
+func WithDeleted(db *gorm.DB, includeDeleted bool) *gorm.DB {
+	if includeDeleted {
+		return db.Unscoped()
+	}
+
+	return db
+}
+
+func tableName(db *gorm.DB, resource interface{}) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(resource); err != nil {
+		return "", err
+	}
+
+	return stmt.Schema.Table, nil
+}
+
+func isSoftDeleteTable(db *gorm.DB, table string) bool {
+	for _, resource := range softDeleteModels {
+		if name, err := tableName(db, resource); err == nil && name == table {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newModel(resource interface{}) interface{} {
+	return reflect.New(reflect.TypeOf(resource).Elem()).Interface()
+}
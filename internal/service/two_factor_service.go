@@ -0,0 +1,227 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"insist-backend-golang/internal/model"
+	"insist-backend-golang/pkg"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"gorm.io/gorm"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	ErrTwoFaAlreadyActive   = errors.New("two-factor authentication is already active")
+	ErrTwoFaNotActive       = errors.New("two-factor authentication is not active")
+	ErrTwoFaNotEnrolled     = errors.New("two-factor authentication has not been enrolled")
+	ErrOtpInvalid           = errors.New("invalid OTP")
+	ErrRecoveryCodeInvalid  = errors.New("invalid recovery code")
+	ErrSecondFactorRequired = errors.New("OTP or recovery code is required")
+)
+
+type TwoFactorService struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorService(db *gorm.DB) *TwoFactorService {
+	return &TwoFactorService{db: db}
+}
+
+// Enroll generates a new secret and keeps it pending until the user proves
+// they scanned it by activating with a first code.
+func (s *TwoFactorService) Enroll(user *model.MstUser) (*otp.Key, error) {
+	if user.IsTwoFa {
+		return nil, ErrTwoFaAlreadyActive
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      "INSIST",
+		AccountName: strings.ToUpper(user.Name),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(&model.MstUser{ID: user.ID}).Updates(map[string]interface{}{
+		"otp_pending_key": key.Secret(),
+		"otp_pending_url": key.URL(),
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (s *TwoFactorService) Activate(user *model.MstUser, code string) ([]string, error) {
+	if user.IsTwoFa {
+		return nil, ErrTwoFaAlreadyActive
+	}
+
+	if user.OtpPendingKey == "" {
+		return nil, ErrTwoFaNotEnrolled
+	}
+
+	otpStep, valid := pkg.ValidateTOTP(code, user.OtpPendingKey, time.Now())
+	if !valid {
+		return nil, ErrOtpInvalid
+	}
+
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.MstUser{ID: user.ID}).Updates(map[string]interface{}{
+			"otp_key":         user.OtpPendingKey,
+			"otp_url":         user.OtpPendingUrl,
+			"otp_pending_key": nil,
+			"otp_pending_url": nil,
+			"otp_last_step":   otpStep,
+			"is_two_fa":       true,
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *TwoFactorService) RegenerateRecoveryCodes(user *model.MstUser) ([]string, error) {
+	if !user.IsTwoFa {
+		return nil, ErrTwoFaNotActive
+	}
+
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *TwoFactorService) Disable(user *model.MstUser) error {
+	if !user.IsTwoFa {
+		return ErrTwoFaNotActive
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.MstUser{ID: user.ID}).Updates(map[string]interface{}{
+			"otp_key":       nil,
+			"otp_url":       nil,
+			"otp_last_step": nil,
+			"is_two_fa":     false,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("id_user = ?", user.ID).Delete(&model.UserRecoveryCode{}).Error
+	})
+}
+
+// Reset is the admin counterpart of Disable for users who lost their second
+// factor: it clears active and pending keys without re-authentication, and the
+// user enrolls again through the self-service flow.
+func (s *TwoFactorService) Reset(userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.MstUser{ID: userID}).Updates(map[string]interface{}{
+			"otp_key":         nil,
+			"otp_url":         nil,
+			"otp_pending_key": nil,
+			"otp_pending_url": nil,
+			"otp_last_step":   nil,
+			"is_two_fa":       false,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("id_user = ?", userID).Delete(&model.UserRecoveryCode{}).Error
+	})
+}
+
+// VerifySecondFactor consumes either a TOTP code or a recovery code, used to
+// re-authenticate before sensitive 2FA changes.
+func (s *TwoFactorService) VerifySecondFactor(user *model.MstUser, code string, recoveryCode string) error {
+	if recoveryCode != "" {
+		return useRecoveryCode(s.db, user.ID, recoveryCode)
+	}
+
+	if code == "" {
+		return ErrSecondFactorRequired
+	}
+
+	otpStep, valid := pkg.ValidateTOTP(code, user.OtpKey, time.Now())
+	if user.OtpKey == "" || !valid {
+		return ErrOtpInvalid
+	}
+
+	return useOtpStep(s.db, user.ID, otpStep)
+}
+
+func (s *TwoFactorService) CountRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	if err := s.db.Model(&model.UserRecoveryCode{}).Where("id_user = ? AND used_at IS NULL", userID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("id_user = ?", userID).Delete(&model.UserRecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	recoveryCodes := make([]model.UserRecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+
+		code := hex.EncodeToString(b)
+		codes = append(codes, code[:5]+"-"+code[5:])
+		recoveryCodes = append(recoveryCodes, model.UserRecoveryCode{
+			IDUser:   userID,
+			CodeHash: hashToken(code),
+		})
+	}
+
+	if err := tx.Create(&recoveryCodes).Error; err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func useRecoveryCode(tx *gorm.DB, userID uint, recoveryCode string) error {
+	code := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(recoveryCode))
+
+	result := tx.Model(&model.UserRecoveryCode{}).
+		Where("id_user = ? AND code_hash = ? AND used_at IS NULL", userID, hashToken(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrRecoveryCodeInvalid
+	}
+
+	return nil
+}
@@ -1,11 +1,23 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
 )
 
+// UserRoleFilterFields are the fields the list accepts in filter[field][op].
+var UserRoleFilterFields = filter.Columns(&model.MstUser{}).Without(
+	"password",
+	"refresh_token",
+	"otp_key",
+	"otp_url",
+	"otp_last_step",
+	"otp_pending_key",
+	"otp_pending_url",
+)
+
 type UserRoleService struct {
 	db *gorm.DB
 }
@@ -16,7 +28,7 @@ func NewUserRoleService(db *gorm.DB) *UserRoleService {
 
 func (s *UserRoleService) GetByID(userID uint) (*model.MstUser, error) {
 	var user model.MstUser
-	if err := s.db.Select("id", "name").Preload("UserRoles.Role", func(db *gorm.DB) *gorm.DB {
+	if err := s.db.Select("id", "name", "updated_at").Preload("UserRoles.Role", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, name")
 	}).First(&user, userID).Error; err != nil {
 		return nil, err
@@ -25,7 +37,7 @@ func (s *UserRoleService) GetByID(userID uint) (*model.MstUser, error) {
 	return &user, nil
 }
 
-func (s *UserRoleService) GetTotal(search string) (int64, error) {
+func (s *UserRoleService) GetTotal(search string, filters []filter.Condition) (int64, error) {
 	var count int64
 
 	query := s.db.Model(&model.MstUser{})
@@ -34,6 +46,8 @@ func (s *UserRoleService) GetTotal(search string) (int64, error) {
 		query = query.Where("name ILIKE ?", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
@@ -41,7 +55,7 @@ func (s *UserRoleService) GetTotal(search string) (int64, error) {
 	return count, nil
 }
 
-func (s *UserRoleService) GetAll(offset, limit int, search string) ([]model.MstUser, error) {
+func (s *UserRoleService) GetAll(offset, limit int, search string, filters []filter.Condition) ([]model.MstUser, error) {
 	var users []model.MstUser
 
 	query := s.db.Model(&model.MstUser{}).Preload("UserRoles.Role", func(db *gorm.DB) *gorm.DB {
@@ -52,6 +66,8 @@ func (s *UserRoleService) GetAll(offset, limit int, search string) ([]model.MstU
 		query = query.Where("name ILIKE ?", "%"+search+"%")
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&users).Error; err != nil {
 		return nil, err
 	}
@@ -60,13 +76,44 @@ func (s *UserRoleService) GetAll(offset, limit int, search string) ([]model.MstU
 }
 
 func (s *UserRoleService) Create(user *[]model.MstUserRole) error {
+	for _, userRole := range *user {
+		defer InvalidatePermissionCache(userRole.IDUser)
+	}
+
 	return s.db.Create(user).Error
 }
 
 func (s *UserRoleService) Update(user *model.MstUserRole) error {
+	defer InvalidatePermissionCache(user.IDUser)
+
 	return s.db.Save(user).Error
 }
 
 func (s *UserRoleService) Delete(userID uint) error {
+	defer InvalidatePermissionCache(userID)
+
 	return s.db.Where("id_user = ?", userID).Delete(&model.MstUserRole{}).Error
 }
+
+// Replace sets the roles of user in a transaction, moving its version so the
+// set is replaced only over the one it was read with, and returns ErrStale
+// when the user was changed since.
+func (s *UserRoleService) Replace(user *model.MstUser, userRoles []model.MstUserRole) error {
+	defer InvalidatePermissionCache(user.ID)
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := Touch(tx, user); err != nil {
+			return err
+		}
+
+		if err := tx.Where("id_user = ?", user.ID).Delete(&model.MstUserRole{}).Error; err != nil {
+			return err
+		}
+
+		if len(userRoles) == 0 {
+			return nil
+		}
+
+		return tx.Create(&userRoles).Error
+	})
+}
@@ -1,10 +1,21 @@
 package service
 
 import (
+	"insist-backend-golang/internal/filter"
 	"insist-backend-golang/internal/model"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
+)
+
+// UserFilterFields are the fields the list accepts in filter[field][op].
+var UserFilterFields = filter.Columns(&model.MstUser{}).Without(
+	"password",
+	"refresh_token",
+	"otp_key",
+	"otp_url",
+	"otp_last_step",
+	"otp_pending_key",
+	"otp_pending_url",
 )
 
 type UserService struct {
@@ -30,10 +41,10 @@ func (s *UserService) GetByID(userID uint) (*model.MstUser, error) {
 	return &user, nil
 }
 
-func (s *UserService) GetTotal(search string, idDept uint, isActive string) (int64, error) {
+func (s *UserService) GetTotal(search string, idDept uint, isActive string, includeDeleted bool, filters []filter.Condition) (int64, error) {
 	var count int64
 
-	query := s.db.Model(&model.MstUser{})
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstUser{})
 
 	if search != "" {
 		query = query.Where("name ILIKE ? OR username ILIKE ?", "%"+search+"%", "%"+search+"%")
@@ -47,6 +58,8 @@ func (s *UserService) GetTotal(search string, idDept uint, isActive string) (int
 		query = query.Where("is_active = ?", isActive)
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
@@ -54,10 +67,10 @@ func (s *UserService) GetTotal(search string, idDept uint, isActive string) (int
 	return count, nil
 }
 
-func (s *UserService) GetAll(offset, limit int, search string, idDept uint, isActive string, sortBy string, sortDirection bool) ([]model.MstUser, error) {
+func (s *UserService) GetAll(offset, limit int, search string, idDept uint, isActive string, sorts []filter.Order, includeDeleted bool, filters []filter.Condition) ([]model.MstUser, error) {
 	var users []model.MstUser
 
-	query := s.db.Model(&model.MstUser{}).Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
+	query := WithDeleted(s.db, includeDeleted).Model(&model.MstUser{}).Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, name")
 	}).Preload("UpdatedBy", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, name")
@@ -65,8 +78,8 @@ func (s *UserService) GetAll(offset, limit int, search string, idDept uint, isAc
 		return db.Select("id, code, description")
 	}).Omit("password", "otp_key", "otp_url", "refresh_token").Offset(offset).Limit(limit)
 
-	if sortBy != "" {
-		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: sortDirection})
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
 	} else {
 		query = query.Order("updated_at ASC")
 	}
@@ -83,6 +96,8 @@ func (s *UserService) GetAll(offset, limit int, search string, idDept uint, isAc
 		query = query.Where("is_active = ?", isActive)
 	}
 
+	query = filter.Apply(query, filters)
+
 	if err := query.Find(&users).Error; err != nil {
 		return nil, err
 	}
@@ -94,26 +109,13 @@ func (s *UserService) Create(user *model.MstUser) error {
 	return s.db.Create(user).Error
 }
 
+// Update saves the fields dto.UpdateUser sets, false values included.
 func (s *UserService) Update(user *model.MstUser) error {
-
-	var existingUser model.MstUser
-	if err := s.db.First(&existingUser, user.ID).Error; err != nil {
-		return err
-	}
-
-	if !user.IsActive {
-		s.db.Model(&model.MstUser{ID: user.ID}).Update("is_active", false)
-	}
-
-	if !user.IsTwoFa {
-		s.db.Model(&model.MstUser{ID: user.ID}).Update("is_two_fa", false)
-	}
-
-	return s.db.Model(&existingUser).Updates(user).Error
+	return SaveVersion(s.db, user, "id_dept", "name", "email", "username", "is_active", "id_updatedby")
 }
 
 func (s *UserService) Delete(user *model.MstUser) error {
-	return s.db.Delete(user).Error
+	return SoftDelete(s.db, user, user.IDUpdatedby)
 }
 
 func (s *UserService) UpdatePassword(userID uint, newPassword string) error {
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"insist-backend-golang/internal/model"
+	"insist-backend-golang/pkg"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	SessionRevokedLogout         = "logout"
+	SessionRevokedByUser         = "revoked by user"
+	SessionRevokedByAdmin        = "revoked by admin"
+	SessionRevokedPasswordChange = "password changed"
+	SessionRevokedTokenReuse     = "refresh token reuse detected"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionRevoked  = errors.New("session has been revoked")
+	ErrSessionExpired  = errors.New("session has expired")
+	ErrSessionReused   = errors.New("refresh token has already been used")
+)
+
+type UserSessionService struct {
+	db *gorm.DB
+}
+
+func NewUserSessionService(db *gorm.DB) *UserSessionService {
+	return &UserSessionService{db: db}
+}
+
+func (s *UserSessionService) GetActiveByUser(userID uint) ([]model.UserSession, error) {
+	var sessions []model.UserSession
+	if err := s.db.Where("id_user = ? AND revoked_at IS NULL AND expired_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// IsActive reports whether a session can still authorize access tokens. It is
+// checked on every request so a revoked session stops working immediately
+// instead of when its last access token expires.
+func (s *UserSessionService) IsActive(userID uint, sessionID uint) (bool, error) {
+	var count int64
+	if err := s.db.Model(&model.UserSession{}).
+		Where("id = ? AND id_user = ? AND revoked_at IS NULL AND expired_at > ?", sessionID, userID, time.Now()).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (s *UserSessionService) Create(userID uint, device string, ipAddress string, userAgent string) (*model.UserSession, string, error) {
+	var session model.UserSession
+	var refreshToken string
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		session = model.UserSession{
+			IDUser:     userID,
+			Device:     device,
+			IPAddress:  ipAddress,
+			UserAgent:  userAgent,
+			LastUsedAt: &now,
+			ExpiredAt:  now.Add(pkg.RefreshTokenTTL),
+		}
+
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+
+		token, err := pkg.GenerateRefreshToken(userID, session.ID)
+		if err != nil {
+			return err
+		}
+
+		session.TokenHash = hashToken(token)
+		if err := tx.Model(&session).Update("token_hash", session.TokenHash).Error; err != nil {
+			return err
+		}
+
+		refreshToken = token
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &session, refreshToken, nil
+}
+
+// Rotate exchanges a refresh token for a new one. Presenting a token that has
+// already been rotated means it was copied, so the whole session is revoked.
+func (s *UserSessionService) Rotate(claims *pkg.Claims, refreshToken string, ipAddress string, userAgent string) (*model.UserSession, string, error) {
+	var session model.UserSession
+	var newToken string
+	var reused bool
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND id_user = ?", claims.SessionID, claims.UserID).First(&session).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrSessionNotFound
+			}
+			return err
+		}
+
+		if session.RevokedAt != nil {
+			return ErrSessionRevoked
+		}
+
+		now := time.Now()
+		if session.ExpiredAt.Before(now) {
+			return ErrSessionExpired
+		}
+
+		if session.TokenHash != hashToken(refreshToken) {
+			reused = true
+			return tx.Model(&session).Updates(map[string]interface{}{
+				"revoked_at":     now,
+				"revoked_reason": SessionRevokedTokenReuse,
+			}).Error
+		}
+
+		token, err := pkg.GenerateRefreshToken(session.IDUser, session.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&session).Updates(map[string]interface{}{
+			"token_hash":   hashToken(token),
+			"ip_address":   ipAddress,
+			"user_agent":   userAgent,
+			"last_used_at": now,
+			"expired_at":   now.Add(pkg.RefreshTokenTTL),
+		}).Error; err != nil {
+			return err
+		}
+
+		newToken = token
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if reused {
+		return nil, "", ErrSessionReused
+	}
+
+	return &session, newToken, nil
+}
+
+func (s *UserSessionService) Revoke(userID uint, sessionID uint, reason string) error {
+	result := s.db.Model(&model.UserSession{}).
+		Where("id = ? AND id_user = ? AND revoked_at IS NULL", sessionID, userID).
+		Updates(map[string]interface{}{
+			"revoked_at":     time.Now(),
+			"revoked_reason": reason,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RevokeByUser revokes every active session of a user except exceptID, which
+// is used to keep the caller's own session alive; pass 0 to revoke all.
+func (s *UserSessionService) RevokeByUser(userID uint, exceptID uint, reason string) error {
+	return s.db.Model(&model.UserSession{}).
+		Where("id_user = ? AND id <> ? AND revoked_at IS NULL", userID, exceptID).
+		Updates(map[string]interface{}{
+			"revoked_at":     time.Now(),
+			"revoked_reason": reason,
+		}).Error
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
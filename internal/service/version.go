@@ -0,0 +1,63 @@
+package service
+
+import (
+	"errors"
+	"insist-backend-golang/internal/etag"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrStale is returned when a record was changed by someone else since it was
+// read, so writing it would overwrite their change.
+var ErrStale = errors.New("the record was changed by someone else")
+
+// SaveVersion saves value like Save, or only its columns when given, but only
+// while its row still has the UpdatedAt value was read with, and returns
+// ErrStale otherwise. Records without an UpdatedAt are saved as is.
+func SaveVersion(db *gorm.DB, value interface{}, columns ...string) error {
+	version, ok := etag.Version(value)
+	if !ok {
+		return db.Save(value).Error
+	}
+
+	query := db.Model(value).Where("updated_at = ?", version).Omit(clause.Associations)
+	if len(columns) > 0 {
+		query = query.Select(columns)
+	} else {
+		query = query.Select("*")
+	}
+
+	result := query.Updates(value)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrStale
+	}
+
+	return nil
+}
+
+// Touch moves the UpdatedAt of value, the record a set of others belongs to,
+// e.g. the role of its menus, so replacing the set changes its version. It
+// returns ErrStale when value was changed since it was read.
+func Touch(db *gorm.DB, value interface{}) error {
+	version, ok := etag.Version(value)
+	if !ok {
+		return nil
+	}
+
+	result := db.Model(value).Where("updated_at = ?", version).Update("updated_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrStale
+	}
+
+	return nil
+}
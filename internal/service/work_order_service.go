@@ -0,0 +1,509 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"insist-backend-golang/internal/dto"
+	"insist-backend-golang/internal/etag"
+	"insist-backend-golang/internal/filter"
+	"insist-backend-golang/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	WorkOrderTypeCorrective = "corrective"
+	WorkOrderTypePreventive = "preventive"
+)
+
+// A work order is opened, assigned to a technician, started, completed and
+// closed, in that order. It may be reassigned until it is started.
+const (
+	WorkOrderStatusOpen       = "open"
+	WorkOrderStatusAssigned   = "assigned"
+	WorkOrderStatusInProgress = "in_progress"
+	WorkOrderStatusCompleted  = "completed"
+	WorkOrderStatusClosed     = "closed"
+)
+
+// openWorkOrderStatuses are the statuses of work orders that are not done.
+var openWorkOrderStatuses = []string{WorkOrderStatusOpen, WorkOrderStatusAssigned, WorkOrderStatusInProgress}
+
+// changeableWorkOrderStatuses are the statuses in which the tasks, spare
+// parts and labor of a work order can still be changed.
+var changeableWorkOrderStatuses = []string{WorkOrderStatusOpen, WorkOrderStatusAssigned, WorkOrderStatusInProgress, WorkOrderStatusCompleted}
+
+var (
+	ErrWorkOrderInvalidStatus    = errors.New("action is not allowed in the current status of the work order")
+	ErrWorkOrderClosed           = errors.New("a closed work order cannot be changed")
+	ErrWorkOrderMachineNotFound  = errors.New("machine not found")
+	ErrWorkOrderReasonNotFound   = errors.New("reason not found")
+	ErrWorkOrderReasonRequired   = errors.New("id_reason is required for a corrective work order")
+	ErrWorkOrderItemNotFound     = errors.New("item not found")
+	ErrWorkOrderUOMNotFound      = errors.New("uom not found")
+	ErrWorkOrderEmployeeNotFound = errors.New("employee not found or inactive")
+	ErrWorkOrderNoDowntimeReason = errors.New("a work order that takes the machine down needs a downtime reason")
+	ErrWorkOrderNoResumeReason   = errors.New("id_reason of a status that is not downtime is required to bring the machine back up")
+	ErrWorkOrderTaskNotFound     = errors.New("work order task not found")
+	ErrWorkOrderPartNotFound     = errors.New("work order part not found")
+	ErrWorkOrderLaborNotFound    = errors.New("work order labor not found")
+)
+
+// WorkOrderFilterFields are the fields the list accepts in filter[field][op].
+var WorkOrderFilterFields = filter.Columns(&model.WorkOrder{})
+
+type WorkOrderService struct {
+	db *gorm.DB
+}
+
+func NewWorkOrderService(db *gorm.DB) *WorkOrderService {
+	return &WorkOrderService{db: db}
+}
+
+func (s *WorkOrderService) query(search string, status string, workOrderType string, machineID uint, filters []filter.Condition) *gorm.DB {
+	query := s.db.Model(&model.WorkOrder{})
+
+	if search != "" {
+		query = query.Where("title ILIKE ? OR description ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if workOrderType != "" {
+		query = query.Where("type = ?", workOrderType)
+	}
+
+	if machineID != 0 {
+		query = query.Where("id_machine = ?", machineID)
+	}
+
+	return filter.Apply(query, filters)
+}
+
+func (s *WorkOrderService) GetTotal(search string, status string, workOrderType string, machineID uint, filters []filter.Condition) (int64, error) {
+	var count int64
+	if err := s.query(search, status, workOrderType, machineID, filters).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (s *WorkOrderService) GetAll(offset, limit int, search string, status string, workOrderType string, machineID uint, sorts []filter.Order, filters []filter.Condition) ([]model.WorkOrder, error) {
+	var workOrders []model.WorkOrder
+
+	query := s.query(search, status, workOrderType, machineID, filters).
+		Preload("Reason", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Select("id, code, description, downtime")
+		}).
+		Preload("Technician", func(db *gorm.DB) *gorm.DB {
+			return db.Select("number, name")
+		}).
+		Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, name")
+		}).
+		Preload("UpdatedBy", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, name")
+		}).
+		Offset(offset).Limit(limit)
+
+	if len(sorts) > 0 {
+		query = filter.Sort(query, sorts)
+	} else {
+		query = query.Order("created_at DESC")
+	}
+
+	if err := query.Find(&workOrders).Error; err != nil {
+		return nil, err
+	}
+
+	return workOrders, nil
+}
+
+// GetByID loads a work order with its tasks, spare parts and labor.
+func (s *WorkOrderService) GetByID(workOrderID uint) (*model.WorkOrder, error) {
+	var workOrder model.WorkOrder
+	if err := s.db.Preload("Reason", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped().Select("id, code, description, downtime")
+	}).
+		Preload("Technician", func(db *gorm.DB) *gorm.DB {
+			return db.Select("number, name, department, position")
+		}).
+		Preload("Tasks", func(db *gorm.DB) *gorm.DB {
+			return db.Order("seq ASC, id ASC")
+		}).
+		Preload("Parts", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("Parts.Item", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Select("id, code, description")
+		}).
+		Preload("Parts.UOM", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Select("id, code, description")
+		}).
+		Preload("Labors", func(db *gorm.DB) *gorm.DB {
+			return db.Order("work_date ASC, id ASC")
+		}).
+		Preload("Labors.Employee", func(db *gorm.DB) *gorm.DB {
+			return db.Select("number, name")
+		}).
+		Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, name")
+		}).
+		Preload("UpdatedBy", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, name")
+		}).
+		First(&workOrder, workOrderID).Error; err != nil {
+		return nil, err
+	}
+
+	return &workOrder, nil
+}
+
+// Create opens a work order on a machine that is not deleted.
+func (s *WorkOrderService) Create(workOrder *model.WorkOrder, userID uint) error {
+	var count int64
+	if err := s.db.Model(&model.MstMachine{}).Where("id = ?", workOrder.IDMachine).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return ErrWorkOrderMachineNotFound
+	}
+
+	if err := s.checkReason(s.db, workOrder.IDReason); err != nil {
+		return err
+	}
+
+	workOrder.Status = WorkOrderStatusOpen
+	workOrder.IDCreatedby = &userID
+	workOrder.IDUpdatedby = &userID
+
+	return s.db.Omit(clause.Associations).Create(workOrder).Error
+}
+
+// Update changes a work order that has not been started yet, read at
+// version.
+func (s *WorkOrderService) Update(workOrderID uint, version string, input *dto.UpdateWorkOrderRequest, userID uint) error {
+	return s.transition(workOrderID, version, []string{WorkOrderStatusOpen, WorkOrderStatusAssigned}, func(tx *gorm.DB, workOrder *model.WorkOrder) error {
+		input.Apply(workOrder)
+		if workOrder.Type == WorkOrderTypeCorrective && workOrder.IDReason == nil {
+			return ErrWorkOrderReasonRequired
+		}
+
+		if err := s.checkReason(tx, workOrder.IDReason); err != nil {
+			return err
+		}
+
+		workOrder.IDUpdatedby = &userID
+		return nil
+	})
+}
+
+// Assign hands a work order that has not been started to an active employee.
+func (s *WorkOrderService) Assign(workOrderID uint, technicianNumber string, userID uint) error {
+	return s.transition(workOrderID, "", []string{WorkOrderStatusOpen, WorkOrderStatusAssigned}, func(tx *gorm.DB, workOrder *model.WorkOrder) error {
+		if err := s.checkEmployee(tx, technicianNumber); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		workOrder.TechnicianNumber = &technicianNumber
+		workOrder.AssignedAt = &now
+		workOrder.Status = WorkOrderStatusAssigned
+		workOrder.IDUpdatedby = &userID
+		return nil
+	})
+}
+
+// Start begins work on an assigned work order. A work order that takes the
+// machine down records a status with its downtime reason, remembering the
+// status the machine was running in to resume it on completion.
+func (s *WorkOrderService) Start(workOrderID uint, userID uint) error {
+	return s.transition(workOrderID, "", []string{WorkOrderStatusAssigned}, func(tx *gorm.DB, workOrder *model.WorkOrder) error {
+		now := time.Now()
+
+		if workOrder.MachineDown {
+			if workOrder.IDReason == nil {
+				return ErrWorkOrderNoDowntimeReason
+			}
+
+			var reason model.MstReason
+			if err := tx.First(&reason, *workOrder.IDReason).Error; err != nil || !reason.Downtime {
+				return ErrWorkOrderNoDowntimeReason
+			}
+
+			var current model.MstMachineStatus
+			err := tx.Preload("Reason", func(db *gorm.DB) *gorm.DB {
+				return db.Unscoped()
+			}).Where("id_machine = ?", workOrder.IDMachine).
+				Order("created_at DESC, id DESC").
+				First(&current).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			if err == nil && current.Reason != nil && !current.Reason.Downtime {
+				workOrder.IDResumeReason = &current.IDReason
+			}
+
+			remarks := fmt.Sprintf("Work order %d started: %s", workOrder.ID, workOrder.Title)
+			status, err := s.createStatus(tx, workOrder.IDMachine, reason.ID, remarks, userID)
+			if err != nil {
+				return err
+			}
+
+			workOrder.IDDownStatus = &status.ID
+		}
+
+		workOrder.StartedAt = &now
+		workOrder.Status = WorkOrderStatusInProgress
+		workOrder.IDUpdatedby = &userID
+		return nil
+	})
+}
+
+// Complete finishes the work on a work order in progress. A machine the work
+// order took down is brought back up with the given reason or, without one,
+// the status it was running in before.
+func (s *WorkOrderService) Complete(workOrderID uint, input dto.CompleteWorkOrderRequest, userID uint) error {
+	return s.transition(workOrderID, "", []string{WorkOrderStatusInProgress}, func(tx *gorm.DB, workOrder *model.WorkOrder) error {
+		now := time.Now()
+
+		if workOrder.IDDownStatus != nil {
+			reasonID := input.IDReason
+			if reasonID == nil {
+				reasonID = workOrder.IDResumeReason
+			}
+
+			if reasonID == nil {
+				return ErrWorkOrderNoResumeReason
+			}
+
+			var reason model.MstReason
+			if err := tx.First(&reason, *reasonID).Error; err != nil || reason.Downtime {
+				return ErrWorkOrderNoResumeReason
+			}
+
+			remarks := fmt.Sprintf("Work order %d completed: %s", workOrder.ID, workOrder.Title)
+			if input.Remarks != nil && *input.Remarks != "" {
+				remarks = *input.Remarks
+			}
+
+			status, err := s.createStatus(tx, workOrder.IDMachine, reason.ID, remarks, userID)
+			if err != nil {
+				return err
+			}
+
+			workOrder.IDUpStatus = &status.ID
+		}
+
+		workOrder.CompletedAt = &now
+		workOrder.Status = WorkOrderStatusCompleted
+		workOrder.IDUpdatedby = &userID
+		return nil
+	})
+}
+
+// Close signs off a completed work order, after which it cannot be changed.
+func (s *WorkOrderService) Close(workOrderID uint, userID uint) error {
+	return s.transition(workOrderID, "", []string{WorkOrderStatusCompleted}, func(tx *gorm.DB, workOrder *model.WorkOrder) error {
+		now := time.Now()
+		workOrder.ClosedAt = &now
+		workOrder.Status = WorkOrderStatusClosed
+		workOrder.IDUpdatedby = &userID
+		return nil
+	})
+}
+
+// UpdateTask ticks a task of a work order, read at version, off or on.
+func (s *WorkOrderService) UpdateTask(workOrderID uint, version string, taskID uint, done bool, userID uint) error {
+	return s.transition(workOrderID, version, changeableWorkOrderStatuses, func(tx *gorm.DB, workOrder *model.WorkOrder) error {
+		result := tx.Model(&model.WorkOrderTask{}).
+			Where("id = ? AND id_work_order = ?", taskID, workOrder.ID).
+			Update("done", done)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrWorkOrderTaskNotFound
+		}
+
+		workOrder.IDUpdatedby = &userID
+		return nil
+	})
+}
+
+// AddPart records a spare part consumed by a work order, in the unit of
+// measure of the item unless another is given.
+func (s *WorkOrderService) AddPart(workOrderID uint, part *model.WorkOrderPart, userID uint) error {
+	return s.transition(workOrderID, "", changeableWorkOrderStatuses, func(tx *gorm.DB, workOrder *model.WorkOrder) error {
+		var item model.MstItem
+		if err := tx.Select("id, id_uom").First(&item, part.IDItem).Error; err != nil {
+			return ErrWorkOrderItemNotFound
+		}
+
+		if part.IDUOM == 0 {
+			part.IDUOM = item.IDUOM
+		} else {
+			var count int64
+			if err := tx.Model(&model.MstUoms{}).Where("id = ?", part.IDUOM).Count(&count).Error; err != nil {
+				return err
+			}
+
+			if count == 0 {
+				return ErrWorkOrderUOMNotFound
+			}
+		}
+
+		part.IDWorkOrder = workOrder.ID
+		part.IDCreatedby = userID
+		part.IDUpdatedby = userID
+		if err := tx.Omit(clause.Associations).Create(part).Error; err != nil {
+			return err
+		}
+
+		workOrder.IDUpdatedby = &userID
+		return nil
+	})
+}
+
+func (s *WorkOrderService) DeletePart(workOrderID uint, partID uint, userID uint) error {
+	return s.transition(workOrderID, "", changeableWorkOrderStatuses, func(tx *gorm.DB, workOrder *model.WorkOrder) error {
+		result := tx.Where("id = ? AND id_work_order = ?", partID, workOrder.ID).Delete(&model.WorkOrderPart{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrWorkOrderPartNotFound
+		}
+
+		workOrder.IDUpdatedby = &userID
+		return nil
+	})
+}
+
+// AddLabor records hours an active employee worked on a work order.
+func (s *WorkOrderService) AddLabor(workOrderID uint, labor *model.WorkOrderLabor, userID uint) error {
+	return s.transition(workOrderID, "", changeableWorkOrderStatuses, func(tx *gorm.DB, workOrder *model.WorkOrder) error {
+		if err := s.checkEmployee(tx, labor.EmployeeNumber); err != nil {
+			return err
+		}
+
+		labor.IDWorkOrder = workOrder.ID
+		labor.IDCreatedby = userID
+		labor.IDUpdatedby = userID
+		if err := tx.Omit(clause.Associations).Create(labor).Error; err != nil {
+			return err
+		}
+
+		workOrder.IDUpdatedby = &userID
+		return nil
+	})
+}
+
+func (s *WorkOrderService) DeleteLabor(workOrderID uint, laborID uint, userID uint) error {
+	return s.transition(workOrderID, "", changeableWorkOrderStatuses, func(tx *gorm.DB, workOrder *model.WorkOrder) error {
+		result := tx.Where("id = ? AND id_work_order = ?", laborID, workOrder.ID).Delete(&model.WorkOrderLabor{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrWorkOrderLaborNotFound
+		}
+
+		workOrder.IDUpdatedby = &userID
+		return nil
+	})
+}
+
+// transition locks a work order, checks that it is in one of the from
+// statuses and saves it after fn changed it, all in one transaction so that
+// concurrent actions on the same work order are applied one after another.
+// When version, an ETag, is given, the work order must still be at it.
+func (s *WorkOrderService) transition(workOrderID uint, version string, from []string, fn func(tx *gorm.DB, workOrder *model.WorkOrder) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var workOrder model.WorkOrder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&workOrder, workOrderID).Error; err != nil {
+			return err
+		}
+
+		if version != "" && !etag.Matches(version, &workOrder) {
+			return ErrStale
+		}
+
+		allowed := false
+		for _, status := range from {
+			if workOrder.Status == status {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			if workOrder.Status == WorkOrderStatusClosed {
+				return ErrWorkOrderClosed
+			}
+			return ErrWorkOrderInvalidStatus
+		}
+
+		if err := fn(tx, &workOrder); err != nil {
+			return err
+		}
+
+		return tx.Omit(clause.Associations).Save(&workOrder).Error
+	})
+}
+
+func (s *WorkOrderService) checkReason(db *gorm.DB, reasonID *uint) error {
+	if reasonID == nil {
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(&model.MstReason{}).Where("id = ?", *reasonID).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return ErrWorkOrderReasonNotFound
+	}
+
+	return nil
+}
+
+func (s *WorkOrderService) checkEmployee(db *gorm.DB, number string) error {
+	var count int64
+	if err := db.Model(&model.MstEmployee{}).Where("number = ? AND is_active", number).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return ErrWorkOrderEmployeeNotFound
+	}
+
+	return nil
+}
+
+func (s *WorkOrderService) createStatus(tx *gorm.DB, machineID uint, reasonID uint, remarks string, userID uint) (*model.MstMachineStatus, error) {
+	status := model.MstMachineStatus{
+		IDMachine:   machineID,
+		IDReason:    reasonID,
+		Remarks:     &remarks,
+		IDCreatedby: userID,
+		IDUpdatedby: userID,
+	}
+
+	if err := tx.Omit(clause.Associations).Create(&status).Error; err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
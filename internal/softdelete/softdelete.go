@@ -0,0 +1,38 @@
+package softdelete
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Register installs a callback so preloading a single referenced record, like
+// the UOM of an item or the user who created it, still finds it after it has
+// been soft deleted. Lists of children keep hiding deleted rows.
+func Register(db *gorm.DB) error {
+	return db.Callback().Query().Before("gorm:preload").Register("softdelete:preload_references", preloadReferences)
+}
+
+func preloadReferences(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil || len(db.Statement.Preloads) == 0 {
+		return
+	}
+
+	for name, conds := range db.Statement.Preloads {
+		if strings.Contains(name, ".") {
+			continue
+		}
+
+		rel, ok := db.Statement.Schema.Relationships.Relations[name]
+		if !ok || (rel.Type != schema.BelongsTo && rel.Type != schema.HasOne) {
+			continue
+		}
+
+		db.Statement.Preloads[name] = append(conds, unscoped)
+	}
+}
+
+func unscoped(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
@@ -0,0 +1,17 @@
+CREATE TABLE
+    user_sessions (
+        id SERIAL PRIMARY KEY,
+        id_user INT NOT NULL REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE CASCADE,
+        token_hash VARCHAR NOT NULL,
+        device VARCHAR,
+        ip_address VARCHAR,
+        user_agent VARCHAR,
+        last_used_at TIMESTAMPTZ,
+        expired_at TIMESTAMPTZ NOT NULL,
+        revoked_at TIMESTAMPTZ,
+        revoked_reason VARCHAR,
+        created_at TIMESTAMPTZ,
+        updated_at TIMESTAMPTZ
+    );
+
+CREATE INDEX idx_user_sessions_id_user ON user_sessions (id_user);
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE
+    login_throttles (
+        id SERIAL PRIMARY KEY,
+        scope VARCHAR NOT NULL,
+        key VARCHAR NOT NULL,
+        failures INT NOT NULL DEFAULT 0,
+        last_failed_at TIMESTAMPTZ,
+        locked_until TIMESTAMPTZ,
+        created_at TIMESTAMPTZ,
+        updated_at TIMESTAMPTZ,
+        UNIQUE (scope, key)
+    );
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE mst_users DROP COLUMN IF EXISTS otp_last_step;
+
+DROP TABLE IF EXISTS mfa_challenges;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE
+    mfa_challenges (
+        id SERIAL PRIMARY KEY,
+        id_user INT NOT NULL REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE CASCADE,
+        token_hash VARCHAR NOT NULL UNIQUE,
+        device VARCHAR,
+        expired_at TIMESTAMPTZ NOT NULL,
+        used_at TIMESTAMPTZ,
+        created_at TIMESTAMPTZ
+    );
+
+ALTER TABLE mst_users ADD COLUMN otp_last_step BIGINT;
\ No newline at end of file
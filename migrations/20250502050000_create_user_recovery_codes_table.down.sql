@@ -0,0 +1,3 @@
+ALTER TABLE mst_users DROP COLUMN IF EXISTS otp_pending_key, DROP COLUMN IF EXISTS otp_pending_url;
+
+DROP TABLE IF EXISTS user_recovery_codes;
\ No newline at end of file
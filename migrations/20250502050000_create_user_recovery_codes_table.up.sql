@@ -0,0 +1,12 @@
+CREATE TABLE
+    user_recovery_codes (
+        id SERIAL PRIMARY KEY,
+        id_user INT NOT NULL REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE CASCADE,
+        code_hash VARCHAR NOT NULL,
+        used_at TIMESTAMPTZ,
+        created_at TIMESTAMPTZ
+    );
+
+CREATE INDEX idx_user_recovery_codes_id_user ON user_recovery_codes (id_user);
+
+ALTER TABLE mst_users ADD COLUMN otp_pending_key VARCHAR, ADD COLUMN otp_pending_url VARCHAR;
\ No newline at end of file
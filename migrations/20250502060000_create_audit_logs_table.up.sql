@@ -0,0 +1,16 @@
+CREATE TABLE
+    audit_logs (
+        id BIGSERIAL PRIMARY KEY,
+        table_name VARCHAR NOT NULL,
+        record_id BIGINT NOT NULL,
+        action VARCHAR NOT NULL,
+        id_user INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE SET NULL,
+        changes JSONB,
+        created_at TIMESTAMPTZ
+    );
+
+CREATE INDEX idx_audit_logs_record ON audit_logs (table_name, record_id);
+
+CREATE INDEX idx_audit_logs_id_user ON audit_logs (id_user);
+
+CREATE INDEX idx_audit_logs_created_at ON audit_logs (created_at);
\ No newline at end of file
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_activity_logs_created_at;
+
+ALTER TABLE activity_logs
+DROP COLUMN IF EXISTS source,
+DROP COLUMN IF EXISTS method,
+DROP COLUMN IF EXISTS path,
+DROP COLUMN IF EXISTS status,
+DROP COLUMN IF EXISTS latency_ms;
\ No newline at end of file
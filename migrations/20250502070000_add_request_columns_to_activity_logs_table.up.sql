@@ -0,0 +1,8 @@
+ALTER TABLE activity_logs
+ADD COLUMN source VARCHAR NOT NULL DEFAULT 'client',
+ADD COLUMN method VARCHAR,
+ADD COLUMN path VARCHAR,
+ADD COLUMN status INT,
+ADD COLUMN latency_ms BIGINT;
+
+CREATE INDEX idx_activity_logs_created_at ON activity_logs (created_at);
\ No newline at end of file
@@ -0,0 +1,147 @@
+DROP INDEX mst_users_email_key;
+
+DROP INDEX mst_users_username_key;
+
+ALTER TABLE mst_users
+ADD CONSTRAINT mst_users_email_key UNIQUE (email),
+ADD CONSTRAINT mst_users_username_key UNIQUE (username);
+
+ALTER TABLE mst_approvals
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_banks
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_billing_terms
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_buildings
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_chart_of_accounts
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_currencies
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_currency_rates
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_depts
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_fcs
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_item_categories
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_item_groups
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_item_group_types
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_items
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_item_processes
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_item_products
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_item_product_types
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_item_raw_materials
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_item_sources
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_item_sub_categories
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_item_surfaces
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_key_values
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_locations
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_machines
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_material_details
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_materials
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_menus
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_processes
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_reasons
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_roles
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_sections
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_sub_sections
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_tax_codes
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_uoms
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_users
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
+
+ALTER TABLE mst_warehouses
+DROP COLUMN deleted_at,
+DROP COLUMN id_deletedby;
\ No newline at end of file
@@ -0,0 +1,219 @@
+ALTER TABLE mst_approvals
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_approvals_deleted_at ON mst_approvals (deleted_at);
+
+ALTER TABLE mst_banks
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_banks_deleted_at ON mst_banks (deleted_at);
+
+ALTER TABLE mst_billing_terms
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_billing_terms_deleted_at ON mst_billing_terms (deleted_at);
+
+ALTER TABLE mst_buildings
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_buildings_deleted_at ON mst_buildings (deleted_at);
+
+ALTER TABLE mst_chart_of_accounts
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_chart_of_accounts_deleted_at ON mst_chart_of_accounts (deleted_at);
+
+ALTER TABLE mst_currencies
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_currencies_deleted_at ON mst_currencies (deleted_at);
+
+ALTER TABLE mst_currency_rates
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_currency_rates_deleted_at ON mst_currency_rates (deleted_at);
+
+ALTER TABLE mst_depts
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_depts_deleted_at ON mst_depts (deleted_at);
+
+ALTER TABLE mst_fcs
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_fcs_deleted_at ON mst_fcs (deleted_at);
+
+ALTER TABLE mst_item_categories
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_item_categories_deleted_at ON mst_item_categories (deleted_at);
+
+ALTER TABLE mst_item_groups
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_item_groups_deleted_at ON mst_item_groups (deleted_at);
+
+ALTER TABLE mst_item_group_types
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_item_group_types_deleted_at ON mst_item_group_types (deleted_at);
+
+ALTER TABLE mst_items
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_items_deleted_at ON mst_items (deleted_at);
+
+ALTER TABLE mst_item_processes
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_item_processes_deleted_at ON mst_item_processes (deleted_at);
+
+ALTER TABLE mst_item_products
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_item_products_deleted_at ON mst_item_products (deleted_at);
+
+ALTER TABLE mst_item_product_types
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_item_product_types_deleted_at ON mst_item_product_types (deleted_at);
+
+ALTER TABLE mst_item_raw_materials
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_item_raw_materials_deleted_at ON mst_item_raw_materials (deleted_at);
+
+ALTER TABLE mst_item_sources
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_item_sources_deleted_at ON mst_item_sources (deleted_at);
+
+ALTER TABLE mst_item_sub_categories
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_item_sub_categories_deleted_at ON mst_item_sub_categories (deleted_at);
+
+ALTER TABLE mst_item_surfaces
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_item_surfaces_deleted_at ON mst_item_surfaces (deleted_at);
+
+ALTER TABLE mst_key_values
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_key_values_deleted_at ON mst_key_values (deleted_at);
+
+ALTER TABLE mst_locations
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_locations_deleted_at ON mst_locations (deleted_at);
+
+ALTER TABLE mst_machines
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_machines_deleted_at ON mst_machines (deleted_at);
+
+ALTER TABLE mst_material_details
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_material_details_deleted_at ON mst_material_details (deleted_at);
+
+ALTER TABLE mst_materials
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_materials_deleted_at ON mst_materials (deleted_at);
+
+ALTER TABLE mst_menus
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_menus_deleted_at ON mst_menus (deleted_at);
+
+ALTER TABLE mst_processes
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_processes_deleted_at ON mst_processes (deleted_at);
+
+ALTER TABLE mst_reasons
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_reasons_deleted_at ON mst_reasons (deleted_at);
+
+ALTER TABLE mst_roles
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_roles_deleted_at ON mst_roles (deleted_at);
+
+ALTER TABLE mst_sections
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_sections_deleted_at ON mst_sections (deleted_at);
+
+ALTER TABLE mst_sub_sections
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_sub_sections_deleted_at ON mst_sub_sections (deleted_at);
+
+ALTER TABLE mst_tax_codes
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_tax_codes_deleted_at ON mst_tax_codes (deleted_at);
+
+ALTER TABLE mst_uoms
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_uoms_deleted_at ON mst_uoms (deleted_at);
+
+ALTER TABLE mst_users
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_users_deleted_at ON mst_users (deleted_at);
+
+ALTER TABLE mst_warehouses
+ADD COLUMN deleted_at TIMESTAMPTZ,
+ADD COLUMN id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_mst_warehouses_deleted_at ON mst_warehouses (deleted_at);
+
+-- mst_items.code keeps its full unique constraint because mst_materials
+-- references items by code, so codes of deleted items stay reserved.
+ALTER TABLE mst_users
+DROP CONSTRAINT mst_users_email_key,
+DROP CONSTRAINT mst_users_username_key;
+
+CREATE UNIQUE INDEX mst_users_email_key ON mst_users (email) WHERE deleted_at IS NULL;
+
+CREATE UNIQUE INDEX mst_users_username_key ON mst_users (username) WHERE deleted_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,22 @@
+CREATE TABLE
+    import_jobs (
+        id SERIAL PRIMARY KEY,
+        master VARCHAR NOT NULL,
+        file_name VARCHAR,
+        status VARCHAR NOT NULL,
+        dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+        total_rows INT NOT NULL DEFAULT 0,
+        valid_rows INT NOT NULL DEFAULT 0,
+        error_rows INT NOT NULL DEFAULT 0,
+        imported_rows INT NOT NULL DEFAULT 0,
+        errors JSONB,
+        message VARCHAR,
+        id_createdby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE SET NULL,
+        finished_at TIMESTAMPTZ,
+        created_at TIMESTAMPTZ,
+        updated_at TIMESTAMPTZ
+    );
+
+CREATE INDEX idx_import_jobs_master ON import_jobs (master);
+
+CREATE INDEX idx_import_jobs_id_createdby ON import_jobs (id_createdby);
\ No newline at end of file
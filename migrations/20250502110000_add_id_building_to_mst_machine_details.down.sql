@@ -0,0 +1,147 @@
+DROP VIEW IF EXISTS view_mst_machines;
+
+CREATE VIEW
+    view_mst_machines AS
+SELECT
+    m.id,
+    m.id_createdby,
+    mc.name AS machine_createdby_name,
+    m.id_updatedby,
+    mu.name AS machine_updatedby_name,
+    m.created_at AS machine_created_at,
+    m.updated_at AS machine_updated_at,
+    d.id AS detail_id,
+    d.rev_no,
+    d.state AS revision_state,
+    d.code,
+    d.code_old,
+    d.asset_num,
+    d.asset_num_old,
+    d.description,
+    d.name,
+    d.maker,
+    d.power,
+    d.id_power_uom,
+    pu.code AS power_uom_code,
+    pu.description AS power_uom_description,
+    d.electricity,
+    d.id_electricity_uom,
+    eu.code AS electricity_uom_code,
+    eu.description AS electricity_uom_description,
+    d.cavity,
+    d.lubricant,
+    d.lubricant_capacity,
+    d.id_lubricant_uom,
+    lu.code AS lubricant_uom_code,
+    lu.description AS lubricant_uom_description,
+    d.sliding,
+    d.sliding_capacity,
+    d.id_sliding_uom,
+    su.code AS sliding_uom_code,
+    su.description AS sliding_uom_description,
+    d.coolant,
+    d.coolant_capacity,
+    d.id_coolant_uom,
+    cu.code AS coolant_uom_code,
+    cu.description AS coolant_uom_description,
+    d.hydraulic,
+    d.hydraulic_capacity,
+    d.id_hydraulic_uom,
+    hu.code AS hydraulic_uom_code,
+    hu.description AS hydraulic_uom_description,
+    d.dimension_front,
+    d.id_dimension_front_uom,
+    dfu.code AS dimension_front_uom_code,
+    dfu.description AS dimension_front_uom_description,
+    d.dimension_side,
+    d.id_dimension_side_uom,
+    dsu.code AS dimension_side_uom_code,
+    dsu.description AS dimension_side_uom_description,
+    d.id_createdby AS detail_id_createdby,
+    dc.name AS detail_createdby_name,
+    d.id_updatedby AS detail_id_updatedby,
+    du.name AS detail_updatedby_name,
+    d.created_at AS detail_created_at,
+    d.updated_at AS detail_updated_at,
+    s.id_reason,
+    s.remarks,
+    s.created_at AS status_created_at,
+    s.updated_at AS status_updated_at,
+    r.key AS reason_key,
+    r.code AS reason_code,
+    r.description AS reason_description,
+    r.remarks AS reason_remarks,
+    ah.id_approval AS approval_id,
+    ah.ref_table,
+    ah.ref_id,
+    ah.key AS approval_key,
+    ah.message AS approval_message,
+    ah.id_createdby AS approval_createdby,
+    ac.name AS approval_createdby_name,
+    ah.created_at AS approval_created_at,
+    a.status AS approval_status,
+    a.action AS approval_action,
+    a.count AS approval_count,
+    a.level AS approval_level
+FROM
+    mst_machines m
+    JOIN LATERAL (
+        SELECT
+            *
+        FROM
+            mst_machine_details
+        WHERE
+            id_machine = m.id
+        -- The current revision is the newest released one. A machine without
+        -- a released revision yet is listed with its first revision.
+        ORDER BY
+            state = 'released' DESC,
+            CASE WHEN state = 'released' THEN rev_no END DESC NULLS LAST,
+            rev_no ASC
+        LIMIT
+            1
+    ) d ON TRUE
+    LEFT JOIN LATERAL (
+        SELECT
+            *
+        FROM
+            mst_machine_statuses
+        WHERE
+            id_machine = m.id
+        ORDER BY
+            created_at DESC,
+            id DESC
+        LIMIT
+            1
+    ) s ON TRUE
+    LEFT JOIN LATERAL (
+        SELECT
+            *
+        FROM
+            approval_histories
+        WHERE
+            ref_table = 'mst_machine_details'
+            AND ref_id = d.id
+        ORDER BY
+            id DESC
+        LIMIT
+            1
+    ) ah ON TRUE
+    LEFT JOIN mst_users mc ON mc.id = m.id_createdby
+    LEFT JOIN mst_users mu ON mu.id = m.id_updatedby
+    LEFT JOIN mst_users dc ON dc.id = d.id_createdby
+    LEFT JOIN mst_users du ON du.id = d.id_updatedby
+    LEFT JOIN mst_uoms pu ON pu.id = d.id_power_uom
+    LEFT JOIN mst_uoms eu ON eu.id = d.id_electricity_uom
+    LEFT JOIN mst_uoms lu ON lu.id = d.id_lubricant_uom
+    LEFT JOIN mst_uoms su ON su.id = d.id_sliding_uom
+    LEFT JOIN mst_uoms cu ON cu.id = d.id_coolant_uom
+    LEFT JOIN mst_uoms hu ON hu.id = d.id_hydraulic_uom
+    LEFT JOIN mst_uoms dfu ON dfu.id = d.id_dimension_front_uom
+    LEFT JOIN mst_uoms dsu ON dsu.id = d.id_dimension_side_uom
+    LEFT JOIN mst_reasons r ON r.id = s.id_reason
+    LEFT JOIN mst_approvals a ON a.id = ah.id_approval
+    LEFT JOIN mst_users ac ON ac.id = ah.id_createdby;
+
+ALTER TABLE mst_machine_details
+DROP COLUMN id_building;
\ No newline at end of file
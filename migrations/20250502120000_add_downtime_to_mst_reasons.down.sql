@@ -0,0 +1,2 @@
+ALTER TABLE mst_reasons
+DROP COLUMN downtime;
\ No newline at end of file
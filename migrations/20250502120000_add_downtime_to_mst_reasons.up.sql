@@ -0,0 +1,2 @@
+ALTER TABLE mst_reasons
+ADD COLUMN downtime BOOLEAN NOT NULL DEFAULT FALSE;
\ No newline at end of file
@@ -0,0 +1,21 @@
+CREATE TABLE
+    mst_maintenance_plans (
+        id SERIAL PRIMARY KEY,
+        code VARCHAR NOT NULL,
+        name VARCHAR NOT NULL,
+        description VARCHAR,
+        interval_type VARCHAR NOT NULL,
+        interval_value INT NOT NULL,
+        lead_days INT NOT NULL DEFAULT 0,
+        remarks VARCHAR,
+        id_createdby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+        id_updatedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+        created_at TIMESTAMPTZ,
+        updated_at TIMESTAMPTZ,
+        deleted_at TIMESTAMPTZ,
+        id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT
+    );
+
+CREATE UNIQUE INDEX mst_maintenance_plans_code_key ON mst_maintenance_plans (code) WHERE deleted_at IS NULL;
+
+CREATE INDEX idx_mst_maintenance_plans_deleted_at ON mst_maintenance_plans (deleted_at);
\ No newline at end of file
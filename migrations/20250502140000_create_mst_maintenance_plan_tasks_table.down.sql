@@ -0,0 +1 @@
+DROP TABLE IF EXISTS mst_maintenance_plan_tasks;
\ No newline at end of file
@@ -0,0 +1,16 @@
+CREATE TABLE
+    mst_maintenance_plan_tasks (
+        id SERIAL PRIMARY KEY,
+        id_maintenance_plan INT REFERENCES mst_maintenance_plans (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+        seq INT NOT NULL DEFAULT 0,
+        description VARCHAR NOT NULL,
+        remarks VARCHAR,
+        id_createdby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+        id_updatedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+        created_at TIMESTAMPTZ,
+        updated_at TIMESTAMPTZ,
+        deleted_at TIMESTAMPTZ,
+        id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT
+    );
+
+CREATE INDEX idx_mst_maintenance_plan_tasks_deleted_at ON mst_maintenance_plan_tasks (deleted_at);
\ No newline at end of file
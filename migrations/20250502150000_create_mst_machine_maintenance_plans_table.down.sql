@@ -0,0 +1 @@
+DROP TABLE IF EXISTS mst_machine_maintenance_plans;
\ No newline at end of file
@@ -0,0 +1,18 @@
+CREATE TABLE
+    mst_machine_maintenance_plans (
+        id SERIAL PRIMARY KEY,
+        id_machine INT REFERENCES mst_machines (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+        id_maintenance_plan INT REFERENCES mst_maintenance_plans (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+        start_date TIMESTAMPTZ NOT NULL,
+        last_due_at TIMESTAMPTZ,
+        id_createdby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+        id_updatedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+        created_at TIMESTAMPTZ,
+        updated_at TIMESTAMPTZ,
+        deleted_at TIMESTAMPTZ,
+        id_deletedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT
+    );
+
+CREATE UNIQUE INDEX mst_machine_maintenance_plans_id_machine_id_maintenance_plan_key ON mst_machine_maintenance_plans (id_machine, id_maintenance_plan) WHERE deleted_at IS NULL;
+
+CREATE INDEX idx_mst_machine_maintenance_plans_deleted_at ON mst_machine_maintenance_plans (deleted_at);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS work_orders;
\ No newline at end of file
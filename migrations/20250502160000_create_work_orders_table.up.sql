@@ -0,0 +1,19 @@
+CREATE TABLE
+    work_orders (
+        id SERIAL PRIMARY KEY,
+        type VARCHAR NOT NULL,
+        status VARCHAR NOT NULL,
+        id_machine INT REFERENCES mst_machines (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+        id_machine_maintenance_plan INT REFERENCES mst_machine_maintenance_plans (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+        title VARCHAR NOT NULL,
+        due_at TIMESTAMPTZ,
+        id_createdby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+        id_updatedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+        created_at TIMESTAMPTZ,
+        updated_at TIMESTAMPTZ
+    );
+
+-- A plan is due once per due date, however often the generator runs.
+CREATE UNIQUE INDEX work_orders_id_machine_maintenance_plan_due_at_key ON work_orders (id_machine_maintenance_plan, due_at);
+
+CREATE INDEX idx_work_orders_id_machine_status ON work_orders (id_machine, status);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS work_order_tasks;
\ No newline at end of file
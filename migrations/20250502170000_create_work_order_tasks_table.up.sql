@@ -0,0 +1,10 @@
+CREATE TABLE
+    work_order_tasks (
+        id SERIAL PRIMARY KEY,
+        id_work_order INT REFERENCES work_orders (id) ON UPDATE CASCADE ON DELETE CASCADE,
+        seq INT NOT NULL DEFAULT 0,
+        description VARCHAR NOT NULL,
+        done BOOLEAN NOT NULL DEFAULT FALSE,
+        created_at TIMESTAMPTZ,
+        updated_at TIMESTAMPTZ
+    );
\ No newline at end of file
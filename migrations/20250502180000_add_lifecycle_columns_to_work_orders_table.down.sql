@@ -0,0 +1,12 @@
+ALTER TABLE work_orders
+DROP COLUMN description,
+DROP COLUMN id_reason,
+DROP COLUMN machine_down,
+DROP COLUMN technician_number,
+DROP COLUMN id_down_status,
+DROP COLUMN id_resume_reason,
+DROP COLUMN id_up_status,
+DROP COLUMN assigned_at,
+DROP COLUMN started_at,
+DROP COLUMN completed_at,
+DROP COLUMN closed_at;
\ No newline at end of file
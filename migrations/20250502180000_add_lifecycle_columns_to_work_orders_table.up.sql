@@ -0,0 +1,12 @@
+ALTER TABLE work_orders
+ADD COLUMN description VARCHAR,
+ADD COLUMN id_reason INT REFERENCES mst_reasons (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+ADD COLUMN machine_down BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN technician_number VARCHAR,
+ADD COLUMN id_down_status INT REFERENCES mst_machine_statuses (id) ON UPDATE CASCADE ON DELETE SET NULL,
+ADD COLUMN id_resume_reason INT REFERENCES mst_reasons (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+ADD COLUMN id_up_status INT REFERENCES mst_machine_statuses (id) ON UPDATE CASCADE ON DELETE SET NULL,
+ADD COLUMN assigned_at TIMESTAMPTZ,
+ADD COLUMN started_at TIMESTAMPTZ,
+ADD COLUMN completed_at TIMESTAMPTZ,
+ADD COLUMN closed_at TIMESTAMPTZ;
\ No newline at end of file
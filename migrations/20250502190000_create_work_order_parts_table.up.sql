@@ -0,0 +1,13 @@
+CREATE TABLE
+    work_order_parts (
+        id SERIAL PRIMARY KEY,
+        id_work_order INT REFERENCES work_orders (id) ON UPDATE CASCADE ON DELETE CASCADE,
+        id_item INT REFERENCES mst_items (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+        quantity FLOAT NOT NULL,
+        id_uom INT REFERENCES mst_uoms (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+        remarks VARCHAR,
+        id_createdby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+        id_updatedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+        created_at TIMESTAMPTZ,
+        updated_at TIMESTAMPTZ
+    );
\ No newline at end of file
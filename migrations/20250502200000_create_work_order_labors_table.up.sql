@@ -0,0 +1,13 @@
+CREATE TABLE
+    work_order_labors (
+        id SERIAL PRIMARY KEY,
+        id_work_order INT REFERENCES work_orders (id) ON UPDATE CASCADE ON DELETE CASCADE,
+        employee_number VARCHAR NOT NULL,
+        work_date DATE NOT NULL,
+        hours FLOAT NOT NULL,
+        remarks VARCHAR,
+        id_createdby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+        id_updatedby INT REFERENCES mst_users (id) ON UPDATE CASCADE ON DELETE RESTRICT,
+        created_at TIMESTAMPTZ,
+        updated_at TIMESTAMPTZ
+    );
\ No newline at end of file
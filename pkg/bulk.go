@@ -0,0 +1,17 @@
+package pkg
+
+import (
+	"os"
+	"strconv"
+)
+
+// BulkMaxSize is the number of operations a bulk request may hold, read from
+// BULK_MAX_SIZE and defaulting to 100.
+func BulkMaxSize() int {
+	size, err := strconv.Atoi(os.Getenv("BULK_MAX_SIZE"))
+	if err != nil || size <= 0 {
+		return 100
+	}
+
+	return size
+}
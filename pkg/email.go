@@ -34,7 +34,9 @@ func (es *EmailSender) SendEmail(to string, subject string, typeBody string, bod
 	return d.DialAndSend(m)
 }
 
-func TemplateSendOTP(name string, username string, code string, token string) string {
+// TemplateSendPasswordReset is the email of a password reset link. It never
+// holds the OTP key, which the user enrolls after logging in.
+func TemplateSendPasswordReset(name string, username string, token string) string {
 	html := fmt.Sprintf(`
 	<div
       style="
@@ -132,36 +134,17 @@ func TemplateSendOTP(name string, username string, code string, token string) st
                         </td>
                         <td style="font-size: 14px; color: #666">%s</td>
                       </tr>
-                      <tr>
-                        <td style="width: 70px; font-size: 14px; color: #666">
-                          <strong>Code</strong>
-                        </td>
-                        <td style="width: 10px; font-size: 14px; color: #666">
-                          :
-                        </td>
-                        <td style="font-size: 14px; color: #666">%s</td>
-                      </tr>
                     </table>
                   </td>
                 </tr>
 
-				<tr>
-                    <td align="center" style="padding-top: 20px">
-                      <img
-                        src="https://insist.indoseiki.com:4004/images/20241011_rizki.png"
-                        alt="QR Code"
-                        style="width: 150px; height: 150px; border: 0"
-                      />
-                    </td>
-                </tr>
-
                 <tr>
                   <td style="font-size: 14px; line-height: 30px; color: #666; padding: 20px 0;">
-                    Untuk meningkatkan keamanan akun Anda, silakan scan kode QR
-                    yang telah kami kirimkan menggunakan aplikasi <strong>Google
-                    Authenticator</strong>, atau masukkan kode yang tersedia untuk
-                    mengaktifkan autentikasi dua faktor (2FA). Ini akan
-                    menambahkan lapisan perlindungan ekstra pada akun Anda.
+                    Untuk meningkatkan keamanan akun Anda, setelah login silakan
+                    aktifkan autentikasi dua faktor (2FA) dan scan kode QR yang
+                    ditampilkan menggunakan aplikasi <strong>Google
+                    Authenticator</strong>. Ini akan menambahkan lapisan
+                    perlindungan ekstra pada akun Anda.
                   </td>
                 </tr>
 
@@ -272,7 +255,7 @@ func TemplateSendOTP(name string, username string, code string, token string) st
       </tbody>
     </table>
     </div>
-`, name, token, username, code)
+`, name, token, username)
 
 	return html
 }
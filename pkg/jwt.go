@@ -9,18 +9,22 @@ import (
 )
 
 type Claims struct {
-	UserID uint `json:"user_id"`
+	UserID    uint `json:"user_id"`
+	SessionID uint `json:"session_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
 var ACCESS_KEY = []byte(os.Getenv("ACCESS_TOKEN_SECRET"))
 var REFRESH_KEY = []byte(os.Getenv("REFRESH_TOKEN_SECRET"))
 
-func GenerateAccessToken(userID uint) (string, error) {
+// GenerateAccessToken binds the access token to its session so that revoking
+// the session also invalidates access tokens that have not expired yet.
+func GenerateAccessToken(userID uint, sessionID uint) (string, error) {
 	expirationTime := time.Now().Add(15 * time.Minute)
 
 	claims := &Claims{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 		},
@@ -36,12 +40,21 @@ func GenerateAccessToken(userID uint) (string, error) {
 	return tokenString, nil
 }
 
-func GenerateRefreshToken(userID uint) (string, error) {
-	expirationTime := time.Now().Add(1 * 24 * time.Hour)
+const RefreshTokenTTL = 1 * 24 * time.Hour
+
+func GenerateRefreshToken(userID uint, sessionID uint) (string, error) {
+	expirationTime := time.Now().Add(RefreshTokenTTL)
+
+	tokenID, err := GenerateToken()
+	if err != nil {
+		return "", err
+	}
 
 	claims := &Claims{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 		},
 	}
@@ -56,7 +69,7 @@ func GenerateRefreshToken(userID uint) (string, error) {
 	return tokenString, nil
 }
 
-func VerifyAccessToken(accessToken string) (uint, error) {
+func VerifyAccessToken(accessToken string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(accessToken, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("invalid signing method")
@@ -65,20 +78,20 @@ func VerifyAccessToken(accessToken string) (uint, error) {
 	})
 
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
 		if claims.ExpiresAt.Before(time.Now()) {
-			return 0, errors.New("token expired")
+			return nil, errors.New("token expired")
 		}
-		return claims.UserID, nil
+		return claims, nil
 	}
 
-	return 0, errors.New("invalid token")
+	return nil, errors.New("invalid token")
 }
 
-func VerifyRefreshToken(refreshToken string) (uint, error) {
+func VerifyRefreshToken(refreshToken string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(refreshToken, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("invalid signing method")
@@ -87,15 +100,15 @@ func VerifyRefreshToken(refreshToken string) (uint, error) {
 	})
 
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
 		if claims.ExpiresAt.Before(time.Now()) {
-			return 0, errors.New("token expired")
+			return nil, errors.New("token expired")
 		}
-		return claims.UserID, nil
+		return claims, nil
 	}
 
-	return 0, errors.New("invalid token")
+	return nil, errors.New("invalid token")
 }
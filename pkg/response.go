@@ -1,6 +1,8 @@
 package pkg
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -13,6 +15,13 @@ func Response(c *fiber.Ctx, status int, message string, data interface{}) error
 }
 
 func ErrorResponse(c *fiber.Ctx, err error) error {
+	var validationError ValidationError
+	if errors.As(err, &validationError) {
+		return Response(c, fiber.StatusUnprocessableEntity, "Validation failed", fiber.Map{
+			"errors": validationError,
+		})
+	}
+
 	var statusCode int
 	var errorMessage string
 
@@ -0,0 +1,49 @@
+package pkg
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const totpPeriod = 30
+
+// TOTPSkew is the number of 30 second periods accepted on either side of the
+// current one, read from TOTP_SKEW and defaulting to 1.
+func TOTPSkew() uint {
+	skew, err := strconv.Atoi(os.Getenv("TOTP_SKEW"))
+	if err != nil || skew < 0 {
+		return 1
+	}
+
+	return uint(skew)
+}
+
+// ValidateTOTP checks a code against the secret and returns the time step it
+// belongs to, so callers can reject a step that has already been used.
+func ValidateTOTP(code string, secret string, t time.Time) (int64, bool) {
+	opts := totp.ValidateOpts{
+		Period:    totpPeriod,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	}
+
+	current := t.Unix() / totpPeriod
+	skew := int64(TOTPSkew())
+
+	for step := current - skew; step <= current+skew; step++ {
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*totpPeriod, 0), opts)
+		if err != nil {
+			return 0, false
+		}
+
+		if expected == code {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
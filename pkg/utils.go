@@ -3,6 +3,7 @@ package pkg
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -23,3 +24,24 @@ func CalculateServiceDuration(employeeNumber string) string {
 
 	return fmt.Sprintf("%d Years, %d Months", years, months)
 }
+
+func ParseOS(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+
+	switch {
+	case strings.Contains(ua, "windows"):
+		return "Windows"
+	case strings.Contains(ua, "android"):
+		return "Android"
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"):
+		return "iOS"
+	case strings.Contains(ua, "mac os"), strings.Contains(ua, "macintosh"):
+		return "macOS"
+	case strings.Contains(ua, "linux"):
+		return "Linux"
+	case ua == "":
+		return ""
+	}
+
+	return "Other"
+}
@@ -0,0 +1,181 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+var validate = newValidator()
+
+// FieldError is a validation failure of one field of a request body, named
+// by its JSON name.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists the fields of a request body that failed validation,
+// answered by ErrorResponse with 422.
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldError := range e {
+		messages[i] = fieldError.Field + " " + fieldError.Message
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+
+		return name
+	})
+
+	return v
+}
+
+// BindBody parses the JSON body of the request into input and validates it
+// against its validate tags. A body that cannot be parsed is a 400 error,
+// invalid fields are a ValidationError.
+func BindBody(c *fiber.Ctx, input interface{}) error {
+	if err := c.BodyParser(input); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	return Validate(input)
+}
+
+// Validate checks input against its validate tags.
+func Validate(input interface{}) error {
+	err := validate.Struct(input)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	fieldErrors := make(ValidationError, len(validationErrors))
+	for i, fieldError := range validationErrors {
+		param := fieldError.Param()
+		if strings.HasSuffix(fieldError.Tag(), "field") {
+			param = siblingName(input, fieldError)
+		}
+
+		fieldErrors[i] = FieldError{
+			Field:   fieldName(fieldError),
+			Rule:    fieldError.Tag(),
+			Param:   param,
+			Message: message(fieldError.Tag(), param, fieldError),
+		}
+	}
+
+	return fieldErrors
+}
+
+// fieldName is the path of the field below the request body, e.g.
+// machine_detail.code.
+func fieldName(fieldError validator.FieldError) string {
+	namespace := fieldError.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+
+	return fieldError.Field()
+}
+
+// siblingName is the JSON name of the field a cross-field rule such as
+// nefield compares with, which the rule names by its Go name.
+func siblingName(input interface{}, fieldError validator.FieldError) string {
+	path := strings.Split(fieldError.StructNamespace(), ".")
+	path[len(path)-1] = fieldError.Param()
+
+	name := fieldError.Param()
+	t := reflect.TypeOf(input)
+	for _, part := range path[1:] {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			t = t.Elem()
+		}
+
+		if i := strings.Index(part, "["); i >= 0 {
+			part = part[:i]
+		}
+
+		field, ok := t.FieldByName(part)
+		if t.Kind() != reflect.Struct || !ok {
+			return fieldError.Param()
+		}
+
+		name = part
+		if tag := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]; tag != "" && tag != "-" {
+			name = tag
+		}
+
+		t = field.Type
+	}
+
+	return name
+}
+
+func message(tag string, param string, fieldError validator.FieldError) string {
+	kind := fieldError.Kind()
+	if kind == reflect.Ptr {
+		kind = fieldError.Type().Elem().Kind()
+	}
+
+	length := kind == reflect.String || kind == reflect.Slice || kind == reflect.Map
+
+	switch tag {
+	case "required", "required_if", "required_with", "required_without":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "max":
+		if length {
+			return fmt.Sprintf("must be at most %s characters long", param)
+		}
+		return "must be at most " + param
+	case "min":
+		if length {
+			return fmt.Sprintf("must be at least %s characters long", param)
+		}
+		return "must be at least " + param
+	case "len":
+		return fmt.Sprintf("must be exactly %s characters long", param)
+	case "gt":
+		return "must be greater than " + param
+	case "gte":
+		return "must be greater than or equal to " + param
+	case "lt":
+		return "must be less than " + param
+	case "lte":
+		return "must be less than or equal to " + param
+	case "nefield":
+		return "must differ from " + param
+	case "eqfield":
+		return "must match " + param
+	case "alphanum":
+		return "must contain only letters and digits"
+	}
+
+	return "is invalid"
+}